@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -14,6 +15,8 @@ import (
 var version = "dev"
 
 func newRootCmd(opts *linter.Config) *cobra.Command {
+	var at string
+
 	root := &cobra.Command{
 		Use:     "pcl",
 		Short:   "Policy-based X.509 certificate linter",
@@ -26,6 +29,14 @@ func newRootCmd(opts *linter.Config) *cobra.Command {
 					fmt.Fprintf(os.Stderr, "Warning: PSL not loaded (%v), using regex fallback\n", err)
 				}
 			}
+			// Load the weak key list if specified; the default one is optional
+			if err := data.DefaultLoader.LoadWeakKeys(opts.WeakKeysFile); err != nil && opts.WeakKeysFile != "" {
+				fmt.Fprintf(os.Stderr, "Warning: weak key list not loaded (%v)\n", err)
+			}
+			// Load the CT log list if specified; the default one is optional
+			if err := data.DefaultLoader.LoadCTLogs(opts.CTLogListFile); err != nil && opts.CTLogListFile != "" {
+				fmt.Fprintf(os.Stderr, "Warning: CT log list not loaded (%v)\n", err)
+			}
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -34,9 +45,18 @@ func newRootCmd(opts *linter.Config) *cobra.Command {
 			}
 			hasCert := opts.CertPath != "" || len(opts.CertURLs) > 0
 			hasIssuer := len(opts.IssuerPaths) > 0 || len(opts.IssuerURLs) > 0
-			if !hasCert && !hasIssuer && opts.CRLPath == "" && opts.OCSPPath == "" {
-				return fmt.Errorf("at least one of --cert, --cert-url, --issuer, --issuer-url, --crl, or --ocsp is required")
+			if !hasCert && !hasIssuer && opts.CRLPath == "" && opts.OCSPPath == "" && opts.SCTPath == "" && opts.CSRPath == "" && opts.TSTPath == "" && opts.AttrCertPath == "" {
+				return fmt.Errorf("at least one of --cert, --cert-url, --issuer, --issuer-url, --crl, --ocsp, --sct, --csr, --tst, or --attr-cert is required")
 			}
+			if at != "" {
+				t, err := time.Parse(time.RFC3339, at)
+				if err != nil {
+					return fmt.Errorf("invalid --at %q: expected RFC3339, e.g. 2024-01-02T15:04:05Z", at)
+				}
+				opts.At = t
+			}
+			// Arguments are valid; lint and runtime errors should not print usage.
+			cmd.SilenceUsage = true
 			return linter.Run(*opts, cmd.OutOrStdout())
 		},
 	}
@@ -50,10 +70,22 @@ func newRootCmd(opts *linter.Config) *cobra.Command {
 	root.Flags().StringSliceVar(&opts.IssuerURLs, "issuer-url", nil, "Issuer certificate URL (repeatable)")
 	root.Flags().StringVar(&opts.CRLPath, "crl", "", "Path to CRL file or directory (PEM/DER)")
 	root.Flags().StringVar(&opts.OCSPPath, "ocsp", "", "Path to OCSP response file or directory (DER/PEM)")
+	root.Flags().StringVar(&opts.SCTPath, "sct", "", "Path to SCT list file or directory (TLS-encoded, or DER as stapled in OCSP), verified over the --cert leaf")
+	root.Flags().StringVar(&opts.FinalCertPath, "final-cert", "", "Path to final certificate file or directory (PEM/DER) that CT precertificates under --cert are compared with")
+	root.Flags().StringVar(&opts.CSRPath, "csr", "", "Path to certificate signing request file or directory (PKCS#10, PEM/DER)")
+	root.Flags().StringVar(&opts.TSTPath, "tst", "", "Path to RFC 3161 time-stamp response or token file or directory (DER/PEM)")
+	root.Flags().StringVar(&opts.AttrCertPath, "attr-cert", "", "Path to RFC 5755 attribute certificate file or directory (PEM/DER), verified against --issuer")
 	root.Flags().DurationVar(&opts.OCSPTimeout, "ocsp-url-timeout", 5*time.Second, "OCSP request timeout (e.g. 5s, 10s)")
-	root.Flags().StringVar(&opts.OutputFmt, "output", "text", "Output format: text, json, or yaml")
+	root.Flags().StringVar(&opts.OutputFmt, "output", "text", "Output format: text, json, yaml, sarif, or junit")
 	root.Flags().CountVarP(&opts.Verbosity, "verbose", "v", "Increase output detail: -v shows passed, -vv includes skipped")
 	root.Flags().BoolVar(&opts.ShowMeta, "show-meta", true, "Show lint meta information")
+	root.Flags().BoolVar(&opts.Batch, "batch", false, "Lint each file under --cert as its own subject, building its chain from the file and the --issuer pool")
+	root.Flags().IntVarP(&opts.Jobs, "jobs", "j", 0, "Number of certificates/policies evaluated in parallel (default: number of CPUs)")
+	root.Flags().BoolVar(&opts.AllPaths, "all-paths", false, "Lint every valid certification path through the certificates, not only the best ranked one")
+	root.Flags().StringSliceVar(&opts.TrustAnchorPaths, "trust-anchors", nil, "Trust anchor certificate file or directory (repeatable, PEM bundle/DER); only these anchor a chain")
+	root.Flags().BoolVar(&opts.SystemRoots, "system-roots", false, "Add the system CA bundle (Linux) to the trust anchors")
+	root.Flags().StringVar(&at, "at", "", "Evaluate date and validity checks at this RFC3339 time instead of now")
+	root.Flags().StringVar(&opts.FailOn, "fail-on", linter.FailOnError, "Minimum failed-rule severity that causes a non-zero exit: error, warning, or notice")
 
 	// Auto-validate mode flags
 	root.Flags().BoolVar(&opts.AutoValidate, "auto-validate", false, "Enable automatic PKI resource fetching (OCSP, CRL, chain climbing)")
@@ -73,6 +105,8 @@ func newRootCmd(opts *linter.Config) *cobra.Command {
 	// PSL/TLD data options
 	root.Flags().StringVar(&opts.PSLFile, "psl-file", "", "Path to Public Suffix List file (default: ./data/public_suffix_list.dat or ~/.pcl/data/public_suffix_list.dat)")
 	root.Flags().BoolVar(&opts.UsePSL, "use-psl", true, "Enable PSL loading for TLD validation (BR 4.2.2, 3.2.2.6)")
+	root.Flags().StringVar(&opts.WeakKeysFile, "weak-keys-file", "", "Path to known weak key list for weakKeyFingerprint (default: ./data/weak_keys.txt or ~/.pcl/data/weak_keys.txt)")
+	root.Flags().StringVar(&opts.CTLogListFile, "ct-log-list", "", "Path to CT log list JSON (v3 schema) for sctValid and sctLogKnown (default: ./data/ct_log_list.json or ~/.pcl/data/ct_log_list.json)")
 	root.Flags().StringVar(&opts.DataDir, "data-dir", "", "Directory for external data files (default: ./data or ~/.pcl/data)")
 
 	return root
@@ -83,7 +117,7 @@ func newUpdateDataCmd() *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "update-data",
-		Short: "Download and update external data files (PSL)",
+		Short: "Download and update external data files (PSL, CT log list)",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return data.UpdateData(dataDir)
 		},
@@ -94,14 +128,39 @@ func newUpdateDataCmd() *cobra.Command {
 	return cmd
 }
 
+func newPolicyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Work with policy files",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "validate <path>...",
+		Short: "Check policy files for unknown operators, bad operands, unknown targets and duplicate rule IDs",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return linter.ValidatePolicies(args, cmd.OutOrStdout())
+		},
+	})
+
+	return cmd
+}
+
 func main() {
 	var opts linter.Config
 
 	root := newRootCmd(&opts)
 	root.AddCommand(newUpdateDataCmd())
+	root.AddCommand(newPolicyCmd())
 
+	root.SilenceErrors = true
 	if err := root.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		var verdictErr *linter.VerdictError
+		if errors.As(err, &verdictErr) {
+			os.Exit(verdictErr.Code)
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(linter.ExitError)
 	}
 }
\ No newline at end of file
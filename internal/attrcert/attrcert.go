@@ -0,0 +1,96 @@
+// Package attrcert provides RFC 5755 attribute certificate data types.
+package attrcert
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	fileio "github.com/cavoq/PCL/internal/io"
+	"github.com/cavoq/PCL/internal/source"
+)
+
+var extensions = []string{".acert", ".ac", ".pem", ".der"}
+
+type Info struct {
+	AttrCert *AttributeCertificate
+	FilePath string
+	Hash     string
+	Source   source.Info
+	Format   source.Format
+}
+
+func ParseAttrCert(data []byte) (*AttributeCertificate, error) {
+	ac, _, err := parseAttrCert(data)
+	return ac, err
+}
+
+func parseAttrCert(data []byte) (*AttributeCertificate, source.Format, error) {
+	block, _ := pem.Decode(data)
+	if block != nil && block.Type == "ATTRIBUTE CERTIFICATE" {
+		ac, err := Parse(block.Bytes)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse PEM attribute certificate: %w", err)
+		}
+		return ac, source.FormatPEM, nil
+	}
+
+	ac, err := Parse(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse PEM or DER attribute certificate: %w", err)
+	}
+	return ac, source.FormatDER, nil
+}
+
+func GetAttrCertFiles(path string) ([]string, error) {
+	return fileio.GetFilesWithExtensions(path, extensions...)
+}
+
+func GetAttrCerts(path string) ([]*Info, error) {
+	files, err := GetAttrCertFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*Info, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		info, err := NewInfo(data, file, source.Info{Type: source.Local})
+		if err != nil {
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	if len(infos) == 0 && len(files) > 0 {
+		return nil, fmt.Errorf("no valid items found in %s", path)
+	}
+
+	return infos, nil
+}
+
+// NewInfo parses an attribute certificate held in memory, either DER or
+// PEM with the "ATTRIBUTE CERTIFICATE" label. Its attribute authority is
+// only resolved at evaluation; name labels it in lint results.
+func NewInfo(data []byte, name string, sourceInfo source.Info) (*Info, error) {
+	ac, format, err := parseAttrCert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(ac.Raw)
+	sourceInfo.Format = format
+	return &Info{
+		AttrCert: ac,
+		FilePath: name,
+		Hash:     hex.EncodeToString(hash[:]),
+		Source:   sourceInfo,
+		Format:   format,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package attrcert
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cavoq/PCL/internal/loader"
+	"github.com/cavoq/PCL/internal/source"
+)
+
+func TestParseAttrCert_PEM(t *testing.T) {
+	ac, err := loader.Load(filepath.Join(testdata, "attrcert.pem"), ParseAttrCert)
+	if err != nil {
+		t.Fatalf("failed to load attribute certificate: %v", err)
+	}
+	if ac.Version != 1 || ac.SerialNumber.Int64() != 0x2a01 {
+		t.Errorf("version %d, serial %s", ac.Version, ac.SerialNumber)
+	}
+}
+
+func TestParseAttrCert_DER(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(testdata, "attrcert.der"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := NewInfo(data, "attrcert.der", source.Info{Type: source.Local})
+	if err != nil {
+		t.Fatalf("NewInfo: %v", err)
+	}
+	if info.Format != source.FormatDER || info.Source.Format != source.FormatDER {
+		t.Errorf("format = %q, source format = %q, want DER", info.Format, info.Source.Format)
+	}
+}
+
+func TestParseAttrCert_Invalid(t *testing.T) {
+	if _, err := ParseAttrCert([]byte("not an attribute certificate")); err == nil {
+		t.Fatal("expected error for invalid attribute certificate data")
+	}
+}
+
+func TestGetAttrCerts(t *testing.T) {
+	infos, err := GetAttrCerts(testdata)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(infos) != 3 {
+		t.Fatalf("expected 3 attribute certificates, got %d", len(infos))
+	}
+	for _, info := range infos {
+		if info.Hash == "" || info.Source.Type != source.Local {
+			t.Errorf("%s: hash %q, source %q", info.FilePath, info.Hash, info.Source.Type)
+		}
+	}
+}
+
+func TestGetAttrCerts_NoValidItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.acert")
+	if err := os.WriteFile(path, []byte("not an attribute certificate"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetAttrCerts(path); err == nil {
+		t.Fatal("expected error when no file parses")
+	}
+}
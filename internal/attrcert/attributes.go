@@ -0,0 +1,280 @@
+package attrcert
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// Attribute OIDs (RFC 5755 4.4)
+const (
+	OIDServiceAuthInfo  = "1.3.6.1.5.5.7.10.1"
+	OIDAccessIdentity   = "1.3.6.1.5.5.7.10.2"
+	OIDChargingIdentity = "1.3.6.1.5.5.7.10.3"
+	OIDGroup            = "1.3.6.1.5.5.7.10.4"
+	OIDRole             = "2.5.4.72"
+	OIDClearance        = "2.5.4.55"
+
+	// OIDClearanceX501 is the clearance OID of X.501 (1993) that RFC 3281
+	// used; RFC 5755 4.4.6 requires implementations to accept it.
+	OIDClearanceX501 = "2.5.1.5.55"
+)
+
+// Extension OIDs (RFC 5755 4.3)
+const (
+	OIDAuditIdentity          = "1.3.6.1.5.5.7.1.4"
+	OIDTargetInformation      = "2.5.29.55"
+	OIDNoRevAvail             = "2.5.29.56"
+	OIDAuthorityKeyIdentifier = "2.5.29.35"
+)
+
+var attributeNames = map[string]string{
+	OIDServiceAuthInfo:  "svceAuthInfo",
+	OIDAccessIdentity:   "accessIdentity",
+	OIDChargingIdentity: "chargingIdentity",
+	OIDGroup:            "group",
+	OIDRole:             "role",
+	OIDClearance:        "clearance",
+	OIDClearanceX501:    "clearance",
+}
+
+// AttributeName returns the name of an attribute OID, or "" if unknown.
+func AttributeName(oid string) string {
+	return attributeNames[oid]
+}
+
+// ClassList bits (RFC 5755 4.4.6)
+var classNames = []string{"unmarked", "unclassified", "restricted", "confidential", "secret", "topSecret"}
+
+// IetfAttrSyntax is the value of the chargingIdentity and group
+// attributes.
+//
+//	IetfAttrSyntax ::= SEQUENCE {
+//	    policyAuthority [0] GeneralNames OPTIONAL,
+//	    values SEQUENCE OF CHOICE {
+//	        octets OCTET STRING,
+//	        oid    OBJECT IDENTIFIER,
+//	        string UTF8String } }
+type IetfAttrSyntax struct {
+	PolicyAuthority [][]byte // DER GeneralNames
+	Values          []IetfAttrValue
+}
+
+// IetfAttrValue is one value of an IetfAttrSyntax. Type is "octets", "oid"
+// or "string"; octets are hex encoded.
+type IetfAttrValue struct {
+	Type  string
+	Value string
+}
+
+// Role is the value of the role attribute.
+//
+//	RoleSyntax ::= SEQUENCE {
+//	    roleAuthority [0] GeneralNames OPTIONAL,
+//	    roleName      [1] GeneralName }
+type Role struct {
+	RoleAuthority [][]byte // DER GeneralNames
+	RoleName      []byte   // DER GeneralName
+}
+
+// Clearance is the value of the clearance attribute.
+//
+//	Clearance ::= SEQUENCE {
+//	    policyId           OBJECT IDENTIFIER,
+//	    classList          ClassList DEFAULT {unclassified},
+//	    securityCategories SET OF SecurityCategory OPTIONAL }
+type Clearance struct {
+	PolicyID           string
+	ClassList          []string
+	SecurityCategories []string // SecurityCategory type OIDs
+}
+
+// Target is one entry of the targetInformation extension. Type is
+// "targetName", "targetGroup" or "targetCert"; Name is the DER GeneralName
+// of the first two.
+//
+//	Target ::= CHOICE {
+//	    targetName  [0] GeneralName,
+//	    targetGroup [1] GeneralName,
+//	    targetCert  [2] TargetCert }
+type Target struct {
+	Type string
+	Name []byte
+}
+
+// ParseIetfAttrSyntax decodes a DER IetfAttrSyntax.
+func ParseIetfAttrSyntax(der []byte) (*IetfAttrSyntax, error) {
+	input := cryptobyte.String(der)
+	var seq cryptobyte.String
+	if !input.ReadASN1(&seq, cryptobyte_asn1.SEQUENCE) || !input.Empty() {
+		return nil, errors.New("malformed IetfAttrSyntax")
+	}
+
+	attr := &IetfAttrSyntax{}
+	var authority cryptobyte.String
+	var present bool
+	if !seq.ReadOptionalASN1(&authority, &present, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) {
+		return nil, errors.New("malformed IetfAttrSyntax: invalid policyAuthority")
+	}
+	if present {
+		names, err := parseGeneralNames(authority)
+		if err != nil {
+			return nil, err
+		}
+		attr.PolicyAuthority = names
+	}
+
+	var values cryptobyte.String
+	if !seq.ReadASN1(&values, cryptobyte_asn1.SEQUENCE) || !seq.Empty() {
+		return nil, errors.New("malformed IetfAttrSyntax: invalid values")
+	}
+	for !values.Empty() {
+		var value cryptobyte.String
+		var id asn1.ObjectIdentifier
+		switch {
+		case values.PeekASN1Tag(cryptobyte_asn1.OCTET_STRING) && values.ReadASN1(&value, cryptobyte_asn1.OCTET_STRING):
+			attr.Values = append(attr.Values, IetfAttrValue{Type: "octets", Value: fmt.Sprintf("%x", []byte(value))})
+		case values.PeekASN1Tag(cryptobyte_asn1.OBJECT_IDENTIFIER) && values.ReadASN1ObjectIdentifier(&id):
+			attr.Values = append(attr.Values, IetfAttrValue{Type: "oid", Value: id.String()})
+		case values.PeekASN1Tag(cryptobyte_asn1.UTF8String) && values.ReadASN1(&value, cryptobyte_asn1.UTF8String):
+			attr.Values = append(attr.Values, IetfAttrValue{Type: "string", Value: string(value)})
+		default:
+			return nil, errors.New("malformed IetfAttrSyntax: invalid value")
+		}
+	}
+	return attr, nil
+}
+
+// ParseRole decodes a DER RoleSyntax.
+func ParseRole(der []byte) (*Role, error) {
+	input := cryptobyte.String(der)
+	var seq cryptobyte.String
+	if !input.ReadASN1(&seq, cryptobyte_asn1.SEQUENCE) || !input.Empty() {
+		return nil, errors.New("malformed RoleSyntax")
+	}
+
+	role := &Role{}
+	var authority cryptobyte.String
+	var present bool
+	if !seq.ReadOptionalASN1(&authority, &present, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) {
+		return nil, errors.New("malformed RoleSyntax: invalid roleAuthority")
+	}
+	if present {
+		names, err := parseGeneralNames(authority)
+		if err != nil {
+			return nil, err
+		}
+		role.RoleAuthority = names
+	}
+
+	// roleName is an EXPLICIT [1] around the GeneralName CHOICE
+	var roleName, name cryptobyte.String
+	var tag cryptobyte_asn1.Tag
+	if !seq.ReadASN1(&roleName, cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) ||
+		!roleName.ReadAnyASN1Element(&name, &tag) || !roleName.Empty() || !seq.Empty() {
+		return nil, errors.New("malformed RoleSyntax: invalid roleName")
+	}
+	role.RoleName = name
+	return role, nil
+}
+
+// ParseClearance decodes a DER Clearance.
+func ParseClearance(der []byte) (*Clearance, error) {
+	input := cryptobyte.String(der)
+	var seq cryptobyte.String
+	var policyID asn1.ObjectIdentifier
+	if !input.ReadASN1(&seq, cryptobyte_asn1.SEQUENCE) || !input.Empty() ||
+		!seq.ReadASN1ObjectIdentifier(&policyID) {
+		return nil, errors.New("malformed Clearance")
+	}
+
+	clearance := &Clearance{PolicyID: policyID.String(), ClassList: []string{"unclassified"}}
+	if seq.PeekASN1Tag(cryptobyte_asn1.BIT_STRING) {
+		var classList asn1.BitString
+		if !seq.ReadASN1BitString(&classList) {
+			return nil, errors.New("malformed Clearance: invalid classList")
+		}
+		clearance.ClassList = nil
+		for i := 0; i < classList.BitLength; i++ {
+			if classList.At(i) == 0 {
+				continue
+			}
+			if i < len(classNames) {
+				clearance.ClassList = append(clearance.ClassList, classNames[i])
+			}
+		}
+	}
+
+	if seq.PeekASN1Tag(cryptobyte_asn1.SET) {
+		var categories cryptobyte.String
+		if !seq.ReadASN1(&categories, cryptobyte_asn1.SET) {
+			return nil, errors.New("malformed Clearance: invalid securityCategories")
+		}
+		for !categories.Empty() {
+			// SecurityCategory ::= SEQUENCE { type [0] OBJECT IDENTIFIER, value [1] ANY }
+			var category, typeID cryptobyte.String
+			var id asn1.ObjectIdentifier
+			if !categories.ReadASN1(&category, cryptobyte_asn1.SEQUENCE) ||
+				!category.ReadASN1Element(&typeID, cryptobyte_asn1.Tag(0).ContextSpecific()) {
+				return nil, errors.New("malformed Clearance: invalid SecurityCategory")
+			}
+			if _, err := asn1.UnmarshalWithParams(typeID, &id, "tag:0"); err != nil {
+				return nil, fmt.Errorf("malformed Clearance: invalid SecurityCategory type: %w", err)
+			}
+			clearance.SecurityCategories = append(clearance.SecurityCategories, id.String())
+		}
+	}
+
+	if !seq.Empty() {
+		return nil, errors.New("malformed Clearance: trailing data")
+	}
+	return clearance, nil
+}
+
+// ParseTargetInformation decodes a targetInformation extension value.
+//
+//	SEQUENCE OF Targets
+//	Targets ::= SEQUENCE OF Target
+func ParseTargetInformation(value []byte) ([]Target, error) {
+	input := cryptobyte.String(value)
+	var outer cryptobyte.String
+	if !input.ReadASN1(&outer, cryptobyte_asn1.SEQUENCE) || !input.Empty() {
+		return nil, errors.New("malformed targetInformation")
+	}
+
+	var targets []Target
+	for !outer.Empty() {
+		var inner cryptobyte.String
+		if !outer.ReadASN1(&inner, cryptobyte_asn1.SEQUENCE) {
+			return nil, errors.New("malformed targetInformation: invalid Targets")
+		}
+		for !inner.Empty() {
+			var target, name cryptobyte.String
+			var tag, nameTag cryptobyte_asn1.Tag
+			if !inner.ReadAnyASN1(&target, &tag) {
+				return nil, errors.New("malformed targetInformation: invalid Target")
+			}
+			switch tag {
+			case cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(),
+				cryptobyte_asn1.Tag(1).Constructed().ContextSpecific():
+				// EXPLICIT around the GeneralName CHOICE
+				if !target.ReadAnyASN1Element(&name, &nameTag) {
+					return nil, errors.New("malformed targetInformation: invalid GeneralName")
+				}
+				t := Target{Type: "targetName", Name: name}
+				if tag == cryptobyte_asn1.Tag(1).Constructed().ContextSpecific() {
+					t.Type = "targetGroup"
+				}
+				targets = append(targets, t)
+			case cryptobyte_asn1.Tag(2).Constructed().ContextSpecific():
+				targets = append(targets, Target{Type: "targetCert"})
+			default:
+				return nil, errors.New("malformed targetInformation: unexpected Target")
+			}
+		}
+	}
+	return targets, nil
+}
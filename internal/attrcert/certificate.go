@@ -0,0 +1,509 @@
+package attrcert
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	zasn1 "github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
+	"github.com/zmap/zcrypto/x509/pkix"
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+
+	pclasn1 "github.com/cavoq/PCL/internal/asn1"
+)
+
+// AttributeCertificate is a parsed RFC 5755 attribute certificate.
+//
+// ASN.1 structure (RFC 5755 4.1):
+//
+//	AttributeCertificate ::= SEQUENCE {
+//	    acinfo             AttributeCertificateInfo,
+//	    signatureAlgorithm AlgorithmIdentifier,
+//	    signatureValue     BIT STRING }
+//	AttributeCertificateInfo ::= SEQUENCE {
+//	    version                AttCertVersion, -- version is v2
+//	    holder                 Holder,
+//	    issuer                 AttCertIssuer,
+//	    signature              AlgorithmIdentifier,
+//	    serialNumber           CertificateSerialNumber,
+//	    attrCertValidityPeriod AttCertValidityPeriod,
+//	    attributes             SEQUENCE OF Attribute,
+//	    issuerUniqueID         UniqueIdentifier OPTIONAL,
+//	    extensions             Extensions OPTIONAL }
+type AttributeCertificate struct {
+	Raw     []byte // DER AttributeCertificate
+	RawInfo []byte // DER AttributeCertificateInfo, the signed data
+
+	// Version is the encoded AttCertVersion: 1 for v2.
+	Version      int
+	Holder       Holder
+	Issuer       Issuer
+	Signature    pclasn1.ParamsState // acinfo signature algorithm
+	SerialNumber *big.Int
+	NotBefore    time.Time
+	NotAfter     time.Time
+	Attributes   []Attribute
+
+	IssuerUniqueID []byte
+	Extensions     []pkix.Extension
+
+	SignatureAlgorithm    pclasn1.ParamsState
+	RawSignatureAlgorithm []byte // DER AlgorithmIdentifier
+	SignatureValue        []byte
+}
+
+// Holder identifies the entity the attributes are bound to.
+//
+//	Holder ::= SEQUENCE {
+//	    baseCertificateID [0] IssuerSerial OPTIONAL,
+//	    entityName        [1] GeneralNames OPTIONAL,
+//	    objectDigestInfo  [2] ObjectDigestInfo OPTIONAL }
+type Holder struct {
+	BaseCertificateID *IssuerSerial
+	EntityName        [][]byte // DER GeneralNames
+	ObjectDigestInfo  *ObjectDigestInfo
+}
+
+// Issuer identifies the attribute authority. V1Form is set when the
+// issuer uses the v1Form GeneralNames, which RFC 5755 4.2.3 forbids;
+// IssuerName then holds those names.
+//
+//	AttCertIssuer ::= CHOICE {
+//	    v1Form GeneralNames,
+//	    v2Form [0] V2Form }
+//	V2Form ::= SEQUENCE {
+//	    issuerName        GeneralNames OPTIONAL,
+//	    baseCertificateID [0] IssuerSerial OPTIONAL,
+//	    objectDigestInfo  [1] ObjectDigestInfo OPTIONAL }
+type Issuer struct {
+	V1Form            bool
+	IssuerName        [][]byte // DER GeneralNames
+	BaseCertificateID *IssuerSerial
+	ObjectDigestInfo  *ObjectDigestInfo
+}
+
+// IssuerSerial identifies a public key certificate by issuer and serial
+// number.
+//
+//	IssuerSerial ::= SEQUENCE {
+//	    issuer    GeneralNames,
+//	    serial    CertificateSerialNumber,
+//	    issuerUID UniqueIdentifier OPTIONAL }
+type IssuerSerial struct {
+	Issuer    [][]byte // DER GeneralNames
+	Serial    *big.Int
+	IssuerUID []byte
+}
+
+// ObjectDigestInfo identifies an object by its digest.
+//
+//	ObjectDigestInfo ::= SEQUENCE {
+//	    digestedObjectType ENUMERATED { publicKey(0), publicKeyCert(1), otherObjectTypes(2) },
+//	    otherObjectTypeID  OBJECT IDENTIFIER OPTIONAL,
+//	    digestAlgorithm    AlgorithmIdentifier,
+//	    objectDigest       BIT STRING }
+type ObjectDigestInfo struct {
+	DigestedObjectType int
+	OtherObjectTypeID  string
+	DigestAlgorithm    pclasn1.ParamsState
+	ObjectDigest       []byte
+}
+
+// Attribute is one attribute with its DER values.
+type Attribute struct {
+	OID    string
+	Values [][]byte
+}
+
+// Parse parses a DER attribute certificate.
+func Parse(der []byte) (*AttributeCertificate, error) {
+	input := cryptobyte.String(der)
+	var outer, info cryptobyte.String
+	if !input.ReadASN1(&outer, cryptobyte_asn1.SEQUENCE) || !input.Empty() {
+		return nil, errors.New("malformed attribute certificate: not a DER SEQUENCE")
+	}
+	if !outer.ReadASN1Element(&info, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("malformed attribute certificate: invalid acinfo")
+	}
+
+	ac := &AttributeCertificate{Raw: der, RawInfo: info}
+	if err := ac.parseInfo(info); err != nil {
+		return nil, err
+	}
+
+	var algorithm cryptobyte.String
+	var signature asn1.BitString
+	if !outer.ReadASN1Element(&algorithm, cryptobyte_asn1.SEQUENCE) ||
+		!outer.ReadASN1BitString(&signature) || !outer.Empty() {
+		return nil, errors.New("malformed attribute certificate: invalid signature")
+	}
+	ac.SignatureAlgorithm = pclasn1.ParseAlgorithmIDParams(algorithm)
+	ac.RawSignatureAlgorithm = algorithm
+	ac.SignatureValue = signature.RightAlign()
+
+	return ac, nil
+}
+
+func (ac *AttributeCertificate) parseInfo(element cryptobyte.String) error {
+	var info cryptobyte.String
+	if !element.ReadASN1(&info, cryptobyte_asn1.SEQUENCE) || !info.ReadASN1Integer(&ac.Version) {
+		return errors.New("malformed attribute certificate: invalid version")
+	}
+
+	var holder cryptobyte.String
+	if !info.ReadASN1(&holder, cryptobyte_asn1.SEQUENCE) {
+		return errors.New("malformed attribute certificate: invalid holder")
+	}
+	if err := ac.Holder.parse(holder); err != nil {
+		return err
+	}
+
+	if err := ac.Issuer.parse(&info); err != nil {
+		return err
+	}
+
+	var signature cryptobyte.String
+	ac.SerialNumber = new(big.Int)
+	if !info.ReadASN1Element(&signature, cryptobyte_asn1.SEQUENCE) ||
+		!info.ReadASN1Integer(ac.SerialNumber) {
+		return errors.New("malformed attribute certificate: invalid serialNumber")
+	}
+	ac.Signature = pclasn1.ParseAlgorithmIDParams(signature)
+
+	var validity cryptobyte.String
+	if !info.ReadASN1(&validity, cryptobyte_asn1.SEQUENCE) ||
+		!validity.ReadASN1GeneralizedTime(&ac.NotBefore) ||
+		!validity.ReadASN1GeneralizedTime(&ac.NotAfter) {
+		return errors.New("malformed attribute certificate: invalid attrCertValidityPeriod")
+	}
+
+	var attributes cryptobyte.String
+	if !info.ReadASN1(&attributes, cryptobyte_asn1.SEQUENCE) {
+		return errors.New("malformed attribute certificate: invalid attributes")
+	}
+	for !attributes.Empty() {
+		var attr, values cryptobyte.String
+		var oid asn1.ObjectIdentifier
+		if !attributes.ReadASN1(&attr, cryptobyte_asn1.SEQUENCE) ||
+			!attr.ReadASN1ObjectIdentifier(&oid) ||
+			!attr.ReadASN1(&values, cryptobyte_asn1.SET) {
+			return errors.New("malformed attribute certificate: invalid attribute")
+		}
+		a := Attribute{OID: oid.String()}
+		for !values.Empty() {
+			var value cryptobyte.String
+			var tag cryptobyte_asn1.Tag
+			if !values.ReadAnyASN1Element(&value, &tag) {
+				return fmt.Errorf("malformed attribute certificate: invalid attribute %s", oid)
+			}
+			a.Values = append(a.Values, value)
+		}
+		ac.Attributes = append(ac.Attributes, a)
+	}
+
+	if info.PeekASN1Tag(cryptobyte_asn1.BIT_STRING) {
+		var uid asn1.BitString
+		if !info.ReadASN1BitString(&uid) {
+			return errors.New("malformed attribute certificate: invalid issuerUniqueID")
+		}
+		ac.IssuerUniqueID = uid.RightAlign()
+	}
+
+	if info.PeekASN1Tag(cryptobyte_asn1.SEQUENCE) {
+		var extensions cryptobyte.String
+		if !info.ReadASN1(&extensions, cryptobyte_asn1.SEQUENCE) {
+			return errors.New("malformed attribute certificate: invalid extensions")
+		}
+		for !extensions.Empty() {
+			var element cryptobyte.String
+			var ext pkix.Extension
+			if !extensions.ReadASN1Element(&element, cryptobyte_asn1.SEQUENCE) {
+				return errors.New("malformed attribute certificate: invalid extensions")
+			}
+			if _, err := zasn1.Unmarshal(element, &ext); err != nil {
+				return fmt.Errorf("malformed attribute certificate: invalid extension: %w", err)
+			}
+			ac.Extensions = append(ac.Extensions, ext)
+		}
+	}
+
+	if !info.Empty() {
+		return errors.New("malformed attribute certificate: trailing data in acinfo")
+	}
+	return nil
+}
+
+func (h *Holder) parse(holder cryptobyte.String) error {
+	var present bool
+	var element cryptobyte.String
+
+	if !holder.ReadOptionalASN1(&element, &present, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) {
+		return errors.New("malformed holder: invalid baseCertificateID")
+	}
+	if present {
+		id, err := parseIssuerSerial(element)
+		if err != nil {
+			return fmt.Errorf("malformed holder: %w", err)
+		}
+		h.BaseCertificateID = id
+	}
+
+	if !holder.ReadOptionalASN1(&element, &present, cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) {
+		return errors.New("malformed holder: invalid entityName")
+	}
+	if present {
+		names, err := parseGeneralNames(element)
+		if err != nil {
+			return fmt.Errorf("malformed holder: invalid entityName: %w", err)
+		}
+		h.EntityName = names
+	}
+
+	if !holder.ReadOptionalASN1(&element, &present, cryptobyte_asn1.Tag(2).Constructed().ContextSpecific()) {
+		return errors.New("malformed holder: invalid objectDigestInfo")
+	}
+	if present {
+		info, err := parseObjectDigestInfo(element)
+		if err != nil {
+			return fmt.Errorf("malformed holder: %w", err)
+		}
+		h.ObjectDigestInfo = info
+	}
+
+	if !holder.Empty() {
+		return errors.New("malformed holder: trailing data")
+	}
+	return nil
+}
+
+func (iss *Issuer) parse(info *cryptobyte.String) error {
+	// v1Form is a bare GeneralNames SEQUENCE
+	if info.PeekASN1Tag(cryptobyte_asn1.SEQUENCE) {
+		var names cryptobyte.String
+		if !info.ReadASN1(&names, cryptobyte_asn1.SEQUENCE) {
+			return errors.New("malformed issuer: invalid v1Form")
+		}
+		parsed, err := parseGeneralNames(names)
+		if err != nil {
+			return fmt.Errorf("malformed issuer: invalid v1Form: %w", err)
+		}
+		iss.V1Form = true
+		iss.IssuerName = parsed
+		return nil
+	}
+
+	var v2Form cryptobyte.String
+	if !info.ReadASN1(&v2Form, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) {
+		return errors.New("malformed issuer: invalid v2Form")
+	}
+
+	if v2Form.PeekASN1Tag(cryptobyte_asn1.SEQUENCE) {
+		var names cryptobyte.String
+		if !v2Form.ReadASN1(&names, cryptobyte_asn1.SEQUENCE) {
+			return errors.New("malformed issuer: invalid issuerName")
+		}
+		parsed, err := parseGeneralNames(names)
+		if err != nil {
+			return fmt.Errorf("malformed issuer: invalid issuerName: %w", err)
+		}
+		iss.IssuerName = parsed
+	}
+
+	var present bool
+	var element cryptobyte.String
+	if !v2Form.ReadOptionalASN1(&element, &present, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) {
+		return errors.New("malformed issuer: invalid baseCertificateID")
+	}
+	if present {
+		id, err := parseIssuerSerial(element)
+		if err != nil {
+			return fmt.Errorf("malformed issuer: %w", err)
+		}
+		iss.BaseCertificateID = id
+	}
+
+	if !v2Form.ReadOptionalASN1(&element, &present, cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) {
+		return errors.New("malformed issuer: invalid objectDigestInfo")
+	}
+	if present {
+		info, err := parseObjectDigestInfo(element)
+		if err != nil {
+			return fmt.Errorf("malformed issuer: %w", err)
+		}
+		iss.ObjectDigestInfo = info
+	}
+
+	if !v2Form.Empty() {
+		return errors.New("malformed issuer: trailing data in v2Form")
+	}
+	return nil
+}
+
+// parseIssuerSerial parses the contents of an IMPLICIT-tagged IssuerSerial.
+func parseIssuerSerial(input cryptobyte.String) (*IssuerSerial, error) {
+	var names cryptobyte.String
+	id := &IssuerSerial{Serial: new(big.Int)}
+	if !input.ReadASN1(&names, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("invalid IssuerSerial issuer")
+	}
+	issuer, err := parseGeneralNames(names)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IssuerSerial issuer: %w", err)
+	}
+	id.Issuer = issuer
+	if !input.ReadASN1Integer(id.Serial) {
+		return nil, errors.New("invalid IssuerSerial serial")
+	}
+	if input.PeekASN1Tag(cryptobyte_asn1.BIT_STRING) {
+		var uid asn1.BitString
+		if !input.ReadASN1BitString(&uid) {
+			return nil, errors.New("invalid IssuerSerial issuerUID")
+		}
+		id.IssuerUID = uid.RightAlign()
+	}
+	if !input.Empty() {
+		return nil, errors.New("trailing data in IssuerSerial")
+	}
+	return id, nil
+}
+
+// parseObjectDigestInfo parses the contents of an IMPLICIT-tagged
+// ObjectDigestInfo.
+func parseObjectDigestInfo(input cryptobyte.String) (*ObjectDigestInfo, error) {
+	info := &ObjectDigestInfo{}
+	if !input.ReadASN1Enum(&info.DigestedObjectType) {
+		return nil, errors.New("invalid ObjectDigestInfo digestedObjectType")
+	}
+
+	if input.PeekASN1Tag(cryptobyte_asn1.OBJECT_IDENTIFIER) {
+		var id asn1.ObjectIdentifier
+		if !input.ReadASN1ObjectIdentifier(&id) {
+			return nil, errors.New("invalid ObjectDigestInfo otherObjectTypeID")
+		}
+		info.OtherObjectTypeID = id.String()
+	}
+
+	var algorithm cryptobyte.String
+	var digest asn1.BitString
+	if !input.ReadASN1Element(&algorithm, cryptobyte_asn1.SEQUENCE) ||
+		!input.ReadASN1BitString(&digest) {
+		return nil, errors.New("invalid ObjectDigestInfo digest")
+	}
+	info.DigestAlgorithm = pclasn1.ParseAlgorithmIDParams(algorithm)
+	info.ObjectDigest = digest.RightAlign()
+	return info, nil
+}
+
+// parseGeneralNames splits the contents of a GeneralNames SEQUENCE into
+// DER GeneralName elements.
+func parseGeneralNames(input cryptobyte.String) ([][]byte, error) {
+	var names [][]byte
+	for !input.Empty() {
+		var name cryptobyte.String
+		var tag cryptobyte_asn1.Tag
+		if !input.ReadAnyASN1Element(&name, &tag) {
+			return nil, errors.New("invalid GeneralName")
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Attribute returns the attribute with the given OID, or nil.
+func (ac *AttributeCertificate) Attribute(oid string) *Attribute {
+	for i := range ac.Attributes {
+		if ac.Attributes[i].OID == oid {
+			return &ac.Attributes[i]
+		}
+	}
+	return nil
+}
+
+// IssuerCertificate returns the certificate in pool that issued the
+// attribute certificate: its subject is the directoryName of the v2Form
+// issuerName and, when the attribute certificate has an
+// authorityKeyIdentifier, its subject key identifier matches. It is nil
+// when pool holds no such certificate.
+func (ac *AttributeCertificate) IssuerCertificate(pool []*x509.Certificate) *x509.Certificate {
+	var names [][]byte
+	for _, raw := range ac.Issuer.IssuerName {
+		if name, ok := DirectoryName(raw); ok {
+			names = append(names, name)
+		}
+	}
+	aki := ac.AuthorityKeyID()
+
+	for _, c := range pool {
+		if c == nil {
+			continue
+		}
+		if len(aki) > 0 && len(c.SubjectKeyId) > 0 && !bytes.Equal(aki, c.SubjectKeyId) {
+			continue
+		}
+		for _, name := range names {
+			if bytes.Equal(name, c.RawSubject) {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// AuthorityKeyID returns the keyIdentifier of the authorityKeyIdentifier
+// extension, or nil.
+func (ac *AttributeCertificate) AuthorityKeyID() []byte {
+	for _, ext := range ac.Extensions {
+		if ext.Id.String() != OIDAuthorityKeyIdentifier {
+			continue
+		}
+		input := cryptobyte.String(ext.Value)
+		var aki, keyID cryptobyte.String
+		var present bool
+		if !input.ReadASN1(&aki, cryptobyte_asn1.SEQUENCE) ||
+			!aki.ReadOptionalASN1(&keyID, &present, cryptobyte_asn1.Tag(0).ContextSpecific()) || !present {
+			return nil
+		}
+		return keyID
+	}
+	return nil
+}
+
+// SignatureAlgorithmOf returns the X.509 signature algorithm of the outer
+// signatureAlgorithm.
+func (ac *AttributeCertificate) SignatureAlgorithmOf() x509.SignatureAlgorithm {
+	var ai pkix.AlgorithmIdentifier
+	if _, err := zasn1.Unmarshal(ac.RawSignatureAlgorithm, &ai); err != nil {
+		return x509.UnknownSignatureAlgorithm
+	}
+	return x509.GetSignatureAlgorithmFromAI(ai)
+}
+
+// CheckSignature verifies the signature over the AttributeCertificateInfo
+// with the key of c. It returns x509.ErrUnsupportedAlgorithm when the
+// algorithm has no verifier.
+func (ac *AttributeCertificate) CheckSignature(c *x509.Certificate) error {
+	alg := ac.SignatureAlgorithmOf()
+	if alg == x509.UnknownSignatureAlgorithm {
+		return x509.ErrUnsupportedAlgorithm
+	}
+	return c.CheckSignature(alg, ac.RawInfo, ac.SignatureValue)
+}
+
+// DirectoryName returns the DER Name of a directoryName GeneralName.
+func DirectoryName(generalName []byte) ([]byte, bool) {
+	input := cryptobyte.String(generalName)
+	var name cryptobyte.String
+	if !input.ReadASN1(&name, cryptobyte_asn1.Tag(4).Constructed().ContextSpecific()) {
+		return nil, false
+	}
+	var element cryptobyte.String
+	if !name.ReadASN1Element(&element, cryptobyte_asn1.SEQUENCE) {
+		return nil, false
+	}
+	return element, true
+}
@@ -0,0 +1,149 @@
+package attrcert
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/zmap/zcrypto/x509"
+)
+
+const (
+	testdata = "../../tests/attrcerts"
+	certs    = "../../tests/certs"
+)
+
+func loadAttrCert(t *testing.T, name string) *AttributeCertificate {
+	t.Helper()
+	ac, err := ParseAttrCert(readTestdata(t, filepath.Join(testdata, name)))
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return ac
+}
+
+func loadCert(t *testing.T, name string) *x509.Certificate {
+	t.Helper()
+	block, _ := pem.Decode(readTestdata(t, filepath.Join(certs, name)))
+	c, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func readTestdata(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParse(t *testing.T) {
+	ac := loadAttrCert(t, "attrcert.pem")
+	root := loadCert(t, "aa-root.pem")
+	holder := loadCert(t, "holder.pem")
+
+	id := ac.Holder.BaseCertificateID
+	if id == nil || id.Serial.Cmp(holder.SerialNumber) != 0 || len(id.Issuer) != 1 {
+		t.Fatalf("holder baseCertificateID = %+v", id)
+	}
+	if name, ok := DirectoryName(id.Issuer[0]); !ok || string(name) != string(root.RawSubject) {
+		t.Errorf("holder issuer is not the root subject")
+	}
+	if ac.Issuer.V1Form || len(ac.Issuer.IssuerName) != 1 {
+		t.Errorf("issuer = %+v, want one v2Form issuerName", ac.Issuer)
+	}
+	if ac.NotBefore.Year() != 2026 || ac.NotAfter.Year() != 2027 {
+		t.Errorf("validity %v - %v", ac.NotBefore, ac.NotAfter)
+	}
+	if len(ac.Attributes) != 3 || ac.Attribute(OIDRole) == nil {
+		t.Errorf("attributes = %+v", ac.Attributes)
+	}
+	if len(ac.Extensions) != 3 || len(ac.AuthorityKeyID()) != 20 {
+		t.Errorf("%d extensions, authority key ID %x", len(ac.Extensions), ac.AuthorityKeyID())
+	}
+}
+
+func TestParse_V1Form(t *testing.T) {
+	ac := loadAttrCert(t, "attrcert-bad.pem")
+	if !ac.Issuer.V1Form || len(ac.Issuer.IssuerName) != 1 {
+		t.Errorf("issuer = %+v, want v1Form", ac.Issuer)
+	}
+	if ac.Holder.BaseCertificateID != nil || len(ac.Holder.EntityName) != 1 {
+		t.Errorf("holder = %+v, want entityName only", ac.Holder)
+	}
+}
+
+func TestCheckSignature(t *testing.T) {
+	aa := loadCert(t, "aa.pem")
+	pool := []*x509.Certificate{loadCert(t, "aa-root.pem"), aa}
+
+	ac := loadAttrCert(t, "attrcert.pem")
+	if got := ac.IssuerCertificate(pool); got != aa {
+		t.Fatalf("IssuerCertificate = %v, want the attribute authority", got)
+	}
+	if err := ac.CheckSignature(aa); err != nil {
+		t.Errorf("CheckSignature: %v", err)
+	}
+
+	bad := loadAttrCert(t, "attrcert-bad.pem")
+	if got := bad.IssuerCertificate(pool); got != aa {
+		t.Fatalf("IssuerCertificate of v1Form = %v, want the attribute authority", got)
+	}
+	if err := bad.CheckSignature(aa); err == nil {
+		t.Error("expected a tampered signature to fail")
+	}
+}
+
+func TestParseAttributes(t *testing.T) {
+	ac := loadAttrCert(t, "attrcert.pem")
+
+	role, err := ParseRole(ac.Attribute(OIDRole).Values[0])
+	if err != nil || string(role.RoleName[2:]) != "urn:example:role:admin" {
+		t.Errorf("role = %+v, %v", role, err)
+	}
+
+	group, err := ParseIetfAttrSyntax(ac.Attribute(OIDGroup).Values[0])
+	if err != nil || len(group.Values) != 2 || group.Values[0] != (IetfAttrValue{Type: "string", Value: "engineering"}) {
+		t.Errorf("group = %+v, %v", group, err)
+	}
+
+	clearance, err := ParseClearance(ac.Attribute(OIDClearance).Values[0])
+	if err != nil || clearance.PolicyID != "1.2.3.4.5" || !slices.Equal(clearance.ClassList, []string{"confidential"}) {
+		t.Errorf("clearance = %+v, %v", clearance, err)
+	}
+}
+
+func TestParseClearance_DefaultClassList(t *testing.T) {
+	// SEQUENCE { OID 1.2.3 }
+	clearance, err := ParseClearance([]byte{0x30, 0x04, 0x06, 0x02, 0x2a, 0x03})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(clearance.ClassList, []string{"unclassified"}) {
+		t.Errorf("classList = %v, want the unclassified default", clearance.ClassList)
+	}
+}
+
+func TestParseTargetInformation(t *testing.T) {
+	ac := loadAttrCert(t, "attrcert.pem")
+	for _, ext := range ac.Extensions {
+		if ext.Id.String() != OIDTargetInformation {
+			continue
+		}
+		targets, err := ParseTargetInformation(ext.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(targets) != 1 || targets[0].Type != "targetName" || string(targets[0].Name[2:]) != "app.example.test" {
+			t.Errorf("targets = %+v", targets)
+		}
+		return
+	}
+	t.Fatal("no targetInformation extension")
+}
@@ -0,0 +1,290 @@
+// Package zcrypto provides zcrypto-based attribute certificate tree
+// building.
+package zcrypto
+
+import (
+	"fmt"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/attrcert"
+	certzcrypto "github.com/cavoq/PCL/internal/cert/zcrypto"
+	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/zcrypto"
+)
+
+type AttrCertBuilder struct{}
+
+func NewAttrCertBuilder() *AttrCertBuilder {
+	return &AttrCertBuilder{}
+}
+
+func (b *AttrCertBuilder) Build(ac *attrcert.AttributeCertificate) *node.Node {
+	return buildAttrCert(ac, nil)
+}
+
+func BuildTree(ac *attrcert.AttributeCertificate) *node.Node {
+	return NewAttrCertBuilder().Build(ac)
+}
+
+// BuildTreeWithIssuers builds the tree like BuildTree, looking the
+// attribute authority certificate up in issuers to verify the signature.
+func BuildTreeWithIssuers(ac *attrcert.AttributeCertificate, issuers []*x509.Certificate) *node.Node {
+	return buildAttrCert(ac, issuers)
+}
+
+// Fields lists the top-level children an attribute certificate tree may
+// contain.
+var Fields = []string{
+	"version",
+	"holder",
+	"issuer",
+	"tbsSignatureAlgorithm",
+	"serialNumber",
+	"validity",
+	"attributes",
+	"issuerUniqueID",
+	"extensions",
+	"authorityKeyIdentifier",
+	"targetInformation",
+	"noRevAvail",
+	"signatureAlgorithm",
+	"signatureValue",
+	"issuerCertificate",
+	"signatureValid",
+}
+
+func buildAttrCert(ac *attrcert.AttributeCertificate, issuers []*x509.Certificate) *node.Node {
+	root := node.New("attrCert", nil)
+
+	// AttCertVersion v2 is encoded as 1; the tree counts from 1 like the
+	// certificate version
+	root.Children["version"] = node.New("version", ac.Version+1)
+	root.Children["holder"] = buildHolder(&ac.Holder)
+	root.Children["issuer"] = buildIssuer(&ac.Issuer)
+	root.Children["tbsSignatureAlgorithm"] = certzcrypto.BuildSignatureAlgorithm("tbsSignatureAlgorithm", ac.SignatureAlgorithmOf(), ac.Signature.OID, ac.Signature)
+
+	serialNode := node.New("serialNumber", ac.SerialNumber.Bytes())
+	serialNode.Children["value"] = node.New("value", ac.SerialNumber.String())
+	root.Children["serialNumber"] = serialNode
+
+	validity := node.New("validity", nil)
+	validity.Children["notBefore"] = node.New("notBefore", ac.NotBefore)
+	validity.Children["notAfter"] = node.New("notAfter", ac.NotAfter)
+	root.Children["validity"] = validity
+
+	root.Children["attributes"] = buildAttributes(ac.Attributes)
+
+	if len(ac.IssuerUniqueID) > 0 {
+		root.Children["issuerUniqueID"] = node.New("issuerUniqueID", ac.IssuerUniqueID)
+	}
+
+	if len(ac.Extensions) > 0 {
+		root.Children["extensions"] = zcrypto.BuildExtensions(ac.Extensions)
+		buildKnownExtensions(root, ac)
+	}
+
+	root.Children["signatureAlgorithm"] = certzcrypto.BuildSignatureAlgorithm("signatureAlgorithm", ac.SignatureAlgorithmOf(), ac.SignatureAlgorithm.OID, ac.SignatureAlgorithm)
+	if len(ac.SignatureValue) > 0 {
+		root.Children["signatureValue"] = node.New("signatureValue", ac.SignatureValue)
+	}
+
+	issuerCert := ac.IssuerCertificate(issuers)
+	if issuerCert != nil {
+		root.Children["issuerCertificate"] = certzcrypto.BuildTree(issuerCert)
+
+		// Signed by the attribute authority, not by the holder
+		if valid := zcrypto.BuildSignatureValid(ac.CheckSignature(issuerCert)); valid != nil {
+			root.Children["signatureValid"] = valid
+		}
+	}
+
+	return root
+}
+
+func buildHolder(holder *attrcert.Holder) *node.Node {
+	n := node.New("holder", nil)
+	if holder.BaseCertificateID != nil {
+		n.Children["baseCertificateID"] = buildIssuerSerial("baseCertificateID", holder.BaseCertificateID)
+	}
+	if holder.EntityName != nil {
+		n.Children["entityName"] = buildGeneralNames("entityName", holder.EntityName)
+	}
+	if holder.ObjectDigestInfo != nil {
+		n.Children["objectDigestInfo"] = buildObjectDigestInfo(holder.ObjectDigestInfo)
+	}
+	return n
+}
+
+func buildIssuer(issuer *attrcert.Issuer) *node.Node {
+	n := node.New("issuer", nil)
+	if issuer.V1Form {
+		n.Children["form"] = node.New("form", "v1Form")
+	} else {
+		n.Children["form"] = node.New("form", "v2Form")
+	}
+	if issuer.IssuerName != nil {
+		n.Children["issuerName"] = buildGeneralNames("issuerName", issuer.IssuerName)
+	}
+	if issuer.BaseCertificateID != nil {
+		n.Children["baseCertificateID"] = buildIssuerSerial("baseCertificateID", issuer.BaseCertificateID)
+	}
+	if issuer.ObjectDigestInfo != nil {
+		n.Children["objectDigestInfo"] = buildObjectDigestInfo(issuer.ObjectDigestInfo)
+	}
+	return n
+}
+
+func buildIssuerSerial(name string, id *attrcert.IssuerSerial) *node.Node {
+	n := node.New(name, nil)
+	n.Children["issuer"] = buildGeneralNames("issuer", id.Issuer)
+	n.Children["serial"] = node.New("serial", id.Serial.String())
+	if len(id.IssuerUID) > 0 {
+		n.Children["issuerUID"] = node.New("issuerUID", id.IssuerUID)
+	}
+	return n
+}
+
+var digestedObjectTypes = []string{"publicKey", "publicKeyCert", "otherObjectTypes"}
+
+func buildObjectDigestInfo(info *attrcert.ObjectDigestInfo) *node.Node {
+	n := node.New("objectDigestInfo", nil)
+	objectType := node.New("digestedObjectType", info.DigestedObjectType)
+	if info.DigestedObjectType >= 0 && info.DigestedObjectType < len(digestedObjectTypes) {
+		objectType.Children["name"] = node.New("name", digestedObjectTypes[info.DigestedObjectType])
+	}
+	n.Children["digestedObjectType"] = objectType
+	if info.OtherObjectTypeID != "" {
+		n.Children["otherObjectTypeID"] = node.New("otherObjectTypeID", info.OtherObjectTypeID)
+	}
+	algorithm := node.New("digestAlgorithm", nil)
+	algorithm.Children["oid"] = node.New("oid", info.DigestAlgorithm.OID)
+	n.Children["digestAlgorithm"] = algorithm
+	n.Children["objectDigest"] = node.New("objectDigest", fmt.Sprintf("%x", info.ObjectDigest))
+	return n
+}
+
+// buildAttributes keys each attribute by OID and, when known, by name.
+// Role, group, chargingIdentity and clearance values are decoded.
+func buildAttributes(attrs []attrcert.Attribute) *node.Node {
+	n := node.New("attributes", nil)
+	n.Children["count"] = node.New("count", len(attrs))
+
+	for _, a := range attrs {
+		an := node.New(a.OID, nil)
+		an.Children["oid"] = node.New("oid", a.OID)
+		name := attrcert.AttributeName(a.OID)
+		if name != "" {
+			an.Children["name"] = node.New("name", name)
+		}
+
+		values := node.New("values", nil)
+		for i, raw := range a.Values {
+			key := fmt.Sprintf("%d", i)
+			values.Children[key] = buildAttributeValue(key, name, raw)
+		}
+		an.Children["values"] = values
+		an.Children["count"] = node.New("count", len(a.Values))
+
+		n.Children[a.OID] = an
+		if name != "" {
+			n.Children[name] = an
+		}
+	}
+
+	return n
+}
+
+func buildAttributeValue(key, name string, raw []byte) *node.Node {
+	switch name {
+	case "role":
+		if role, err := attrcert.ParseRole(raw); err == nil {
+			n := node.New(key, nil)
+			if role.RoleAuthority != nil {
+				n.Children["roleAuthority"] = buildGeneralNames("roleAuthority", role.RoleAuthority)
+			}
+			n.Children["roleName"] = zcrypto.BuildGeneralName("roleName", role.RoleName)
+			return n
+		}
+	case "group", "chargingIdentity":
+		if attr, err := attrcert.ParseIetfAttrSyntax(raw); err == nil {
+			n := node.New(key, nil)
+			if attr.PolicyAuthority != nil {
+				n.Children["policyAuthority"] = buildGeneralNames("policyAuthority", attr.PolicyAuthority)
+			}
+			values := node.New("values", nil)
+			for i, v := range attr.Values {
+				vk := fmt.Sprintf("%d", i)
+				vn := node.New(vk, v.Value)
+				vn.Children["type"] = node.New("type", v.Type)
+				vn.Children["value"] = node.New("value", v.Value)
+				values.Children[vk] = vn
+			}
+			n.Children["values"] = values
+			return n
+		}
+	case "clearance":
+		if clearance, err := attrcert.ParseClearance(raw); err == nil {
+			n := node.New(key, nil)
+			n.Children["policyId"] = node.New("policyId", clearance.PolicyID)
+			classList := node.New("classList", nil)
+			for _, class := range clearance.ClassList {
+				classList.Children[class] = node.New(class, true)
+			}
+			n.Children["classList"] = classList
+			if len(clearance.SecurityCategories) > 0 {
+				categories := node.New("securityCategories", nil)
+				for i, c := range clearance.SecurityCategories {
+					categories.Children[fmt.Sprintf("%d", i)] = node.New(fmt.Sprintf("%d", i), c)
+				}
+				n.Children["securityCategories"] = categories
+			}
+			return n
+		}
+	}
+
+	// Undecoded or malformed values keep their DER encoding
+	return node.New(key, raw)
+}
+
+// buildKnownExtensions decodes the extensions RFC 5755 4.3 profiles.
+func buildKnownExtensions(root *node.Node, ac *attrcert.AttributeCertificate) {
+	for _, ext := range ac.Extensions {
+		switch ext.Id.String() {
+		case attrcert.OIDAuthorityKeyIdentifier:
+			if aki := ac.AuthorityKeyID(); aki != nil {
+				root.Children["authorityKeyIdentifier"] = node.New("authorityKeyIdentifier", aki)
+			}
+		case attrcert.OIDTargetInformation:
+			n := node.New("targetInformation", nil)
+			n.Children["critical"] = node.New("critical", ext.Critical)
+			if targets, err := attrcert.ParseTargetInformation(ext.Value); err == nil {
+				tn := node.New("targets", nil)
+				for i, t := range targets {
+					key := fmt.Sprintf("%d", i)
+					target := node.New(key, nil)
+					target.Children["type"] = node.New("type", t.Type)
+					if t.Name != nil {
+						target.Children["name"] = zcrypto.BuildGeneralName("name", t.Name)
+					}
+					tn.Children[key] = target
+				}
+				n.Children["targets"] = tn
+			}
+			root.Children["targetInformation"] = n
+		case attrcert.OIDNoRevAvail:
+			n := node.New("noRevAvail", true)
+			n.Children["critical"] = node.New("critical", ext.Critical)
+			root.Children["noRevAvail"] = n
+		}
+	}
+}
+
+func buildGeneralNames(name string, raws [][]byte) *node.Node {
+	n := node.New(name, nil)
+	for i, raw := range raws {
+		key := fmt.Sprintf("%d", i)
+		n.Children[key] = zcrypto.BuildGeneralName(key, raw)
+	}
+	return n
+}
@@ -0,0 +1,111 @@
+package zcrypto
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/attrcert"
+	"github.com/cavoq/PCL/internal/node/nodetest"
+)
+
+const (
+	testdata = "../../../tests/attrcerts"
+	certs    = "../../../tests/certs"
+)
+
+func loadAttrCert(t *testing.T, name string) *attrcert.AttributeCertificate {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(testdata, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ac, err := attrcert.ParseAttrCert(data)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return ac
+}
+
+func loadIssuers(t *testing.T) []*x509.Certificate {
+	t.Helper()
+	var issuers []*x509.Certificate
+	for _, name := range []string{"aa-root.pem", "aa.pem"} {
+		data, err := os.ReadFile(filepath.Join(certs, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, _ := pem.Decode(data)
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		issuers = append(issuers, c)
+	}
+	return issuers
+}
+
+func TestBuildTree(t *testing.T) {
+	tree := BuildTreeWithIssuers(loadAttrCert(t, "attrcert.pem"), loadIssuers(t))
+
+	nodetest.AssertPathValue(t, tree, "version", 2)
+	nodetest.AssertPathValue(t, tree, "holder.baseCertificateID.serial", "4098")
+	nodetest.AssertPathValue(t, tree, "holder.baseCertificateID.issuer.0.directoryName.commonName", "Test AA Root")
+	nodetest.AssertPathValue(t, tree, "issuer.form", "v2Form")
+	nodetest.AssertPathValue(t, tree, "issuer.issuerName.0.directoryName.commonName", "Test Attribute Authority")
+	nodetest.AssertPathValue(t, tree, "serialNumber.value", "10753")
+	nodetest.AssertPathValue(t, tree, "tbsSignatureAlgorithm.algorithm", "ECDSA-SHA256")
+	nodetest.AssertPathValue(t, tree, "signatureAlgorithm.algorithm", "ECDSA-SHA256")
+
+	nodetest.AssertPathValue(t, tree, "attributes.count", 3)
+	nodetest.AssertPathValue(t, tree, "attributes.role.oid", attrcert.OIDRole)
+	nodetest.AssertPathValue(t, tree, "attributes.2.5.4.72.name", "role")
+	nodetest.AssertPathValue(t, tree, "attributes.role.values.0.roleName.uniformResourceIdentifier", "urn:example:role:admin")
+	nodetest.AssertPathValue(t, tree, "attributes.group.count", 1)
+	nodetest.AssertPathValue(t, tree, "attributes.group.values.0.values.1.type", "string")
+	nodetest.AssertPathValue(t, tree, "attributes.group.values.0.values.1.value", "operations")
+	nodetest.AssertPathValue(t, tree, "attributes.clearance.values.0.policyId", "1.2.3.4.5")
+	nodetest.AssertPathValue(t, tree, "attributes.clearance.values.0.classList.confidential", true)
+
+	nodetest.AssertPathValue(t, tree, "targetInformation.critical", true)
+	nodetest.AssertPathValue(t, tree, "targetInformation.targets.0.type", "targetName")
+	nodetest.AssertPathValue(t, tree, "targetInformation.targets.0.name.dNSName", "app.example.test")
+	nodetest.AssertPathValue(t, tree, "noRevAvail", true)
+	nodetest.AssertPathValue(t, tree, "noRevAvail.critical", false)
+	nodetest.AssertPathValue(t, tree, "extensions.2.5.29.56.critical", false)
+
+	nodetest.AssertPathValue(t, tree, "issuerCertificate.subject.commonName", "Test Attribute Authority")
+	nodetest.AssertPathValue(t, tree, "signatureValid", true)
+
+	for _, path := range []string{"validity.notBefore", "validity.notAfter", "authorityKeyIdentifier", "signatureValue"} {
+		if _, ok := tree.Resolve(path); !ok {
+			t.Errorf("path %q not found", path)
+		}
+	}
+	nodetest.AssertPathNotExists(t, tree, "holder.entityName")
+	nodetest.AssertPathNotExists(t, tree, "issuerUniqueID")
+}
+
+func TestBuildTreeWithoutIssuers(t *testing.T) {
+	tree := BuildTree(loadAttrCert(t, "attrcert.pem"))
+
+	nodetest.AssertPathValue(t, tree, "version", 2)
+	nodetest.AssertPathNotExists(t, tree, "issuerCertificate")
+	nodetest.AssertPathNotExists(t, tree, "signatureValid")
+}
+
+func TestBuildTreeV1Form(t *testing.T) {
+	tree := BuildTreeWithIssuers(loadAttrCert(t, "attrcert-bad.pem"), loadIssuers(t))
+
+	nodetest.AssertPathValue(t, tree, "issuer.form", "v1Form")
+	nodetest.AssertPathValue(t, tree, "issuer.issuerName.0.directoryName.commonName", "Test Attribute Authority")
+	nodetest.AssertPathValue(t, tree, "holder.entityName.0.dNSName", "holder.example.test")
+	nodetest.AssertPathValue(t, tree, "targetInformation.critical", false)
+	nodetest.AssertPathValue(t, tree, "noRevAvail.critical", true)
+	nodetest.AssertPathValue(t, tree, "signatureValid", false)
+	nodetest.AssertPathNotExists(t, tree, "holder.baseCertificateID")
+	nodetest.AssertPathNotExists(t, tree, "authorityKeyIdentifier")
+}
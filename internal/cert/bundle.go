@@ -0,0 +1,105 @@
+package cert
+
+import (
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/source"
+)
+
+// Bundle is one lint subject in batch mode: a certificate file together with
+// any further certificates the same file carries, such as a PEM chain.
+type Bundle struct {
+	Subject *Info
+	Extra   []*Info
+}
+
+// LoadBundles loads every certificate file under path as its own bundle.
+// Files that cannot be read or parsed are skipped.
+func LoadBundles(path string) ([]Bundle, error) {
+	files, err := GetCertFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	bundles := make([]Bundle, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		infos, err := NewInfos(data, file, source.Info{Type: source.Local})
+		if err != nil {
+			continue
+		}
+		bundles = append(bundles, NewBundle(infos))
+	}
+
+	if len(bundles) == 0 && len(files) > 0 {
+		return nil, fmt.Errorf("no valid items found in %s", path)
+	}
+
+	return bundles, nil
+}
+
+// NewInfos parses every certificate of a PEM bundle, or a single DER
+// certificate, held in memory.
+func NewInfos(data []byte, name string, sourceInfo source.Info) ([]*Info, error) {
+	var infos []*Info
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		info, err := NewInfo(pem.EncodeToMemory(block), name, sourceInfo)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+
+	if len(infos) > 0 {
+		return infos, nil
+	}
+
+	info, err := NewInfo(data, name, sourceInfo)
+	if err != nil {
+		return nil, err
+	}
+	return []*Info{info}, nil
+}
+
+// NewBundle picks the subject of a set of certificates loaded together: the
+// first one that issued none of the others. The rest become extra issuers.
+func NewBundle(infos []*Info) Bundle {
+	subject := 0
+	for i, c := range infos {
+		if !issuesAny(c.Cert, infos) {
+			subject = i
+			break
+		}
+	}
+
+	extra := make([]*Info, 0, len(infos)-1)
+	extra = append(extra, infos[:subject]...)
+	extra = append(extra, infos[subject+1:]...)
+	return Bundle{Subject: infos[subject], Extra: extra}
+}
+
+func issuesAny(c *x509.Certificate, infos []*Info) bool {
+	for _, other := range infos {
+		if other.Cert != c && !IsSelfSigned(other.Cert) && other.Cert.Issuer.String() == c.Subject.String() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package cert
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cavoq/PCL/internal/source"
+)
+
+func TestNewInfos_PEMBundle(t *testing.T) {
+	data, err := os.ReadFile("../../tests/certs/chain.pem")
+	if err != nil {
+		t.Fatalf("reading chain: %v", err)
+	}
+
+	infos, err := NewInfos(data, "chain.pem", source.Info{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(infos) != 3 {
+		t.Fatalf("expected 3 certificates, got %d", len(infos))
+	}
+	for _, info := range infos {
+		if info.FilePath != "chain.pem" || info.Hash == "" {
+			t.Errorf("unexpected info: path %q hash %q", info.FilePath, info.Hash)
+		}
+	}
+
+	b := NewBundle(infos)
+	if b.Subject.Cert.IsCA {
+		t.Errorf("expected bundle subject to be the leaf, got %s", b.Subject.Cert.Subject.CommonName)
+	}
+	if len(b.Extra) != 2 {
+		t.Errorf("expected 2 extra certificates, got %d", len(b.Extra))
+	}
+}
+
+func TestLoadBundles_OnePerFile(t *testing.T) {
+	bundles, err := LoadBundles("../../tests/certs-batch")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bundles) != 3 {
+		t.Fatalf("expected 3 bundles, got %d", len(bundles))
+	}
+	for _, b := range bundles {
+		if len(b.Extra) != 0 {
+			t.Errorf("%s: expected single-certificate bundle", b.Subject.FilePath)
+		}
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"slices"
 	"time"
 
 	"github.com/cavoq/PCL/internal/aia"
@@ -30,24 +29,11 @@ func LoadCertificatesWithSource(path string, sourceInfo source.Info) ([]*Info, e
 			continue
 		}
 
-		cert, format, err := parseCertificate(data)
+		info, err := NewInfo(data, file, sourceInfo)
 		if err != nil {
 			continue
 		}
-
-		hash := sha256.Sum256(cert.Raw)
-		infoSource := sourceInfo
-		if infoSource.Type == "" {
-			infoSource.Type = source.Local
-		}
-		infoSource.Format = format
-		infos = append(infos, &Info{
-			Cert:     cert,
-			FilePath: file,
-			Hash:     hex.EncodeToString(hash[:]),
-			Source:   infoSource,
-			Format:   format,
-		})
+		infos = append(infos, info)
 	}
 
 	if len(infos) == 0 && len(files) > 0 {
@@ -57,58 +43,40 @@ func LoadCertificatesWithSource(path string, sourceInfo source.Info) ([]*Info, e
 	return infos, nil
 }
 
-func BuildChain(certs []*Info) ([]*Info, error) {
-	if len(certs) == 0 {
-		return nil, fmt.Errorf("no certificates provided")
-	}
-
-	if len(certs) == 1 {
-		certs[0].Position = 0
-		certs[0].Type = GetCertType(certs[0].Cert, 0, 1)
-		return certs, nil
+// NewInfo parses a PEM or DER certificate held in memory. The name is
+// reported as the certificate's file path in lint results.
+func NewInfo(data []byte, name string, sourceInfo source.Info) (*Info, error) {
+	cert, format, err := parseCertificate(data)
+	if err != nil {
+		return nil, err
 	}
 
-	subjectMap := make(map[string]*Info)
-	for _, c := range certs {
-		subjectMap[c.Cert.Subject.String()] = c
+	hash := sha256.Sum256(cert.Raw)
+	if sourceInfo.Type == "" {
+		sourceInfo.Type = source.Local
 	}
+	sourceInfo.Format = format
+	return &Info{
+		Cert:     cert,
+		FilePath: name,
+		Hash:     hex.EncodeToString(hash[:]),
+		Source:   sourceInfo,
+		Format:   format,
+	}, nil
+}
 
-	var longestChain []*Info
-
-	for _, leaf := range certs {
-		chain := []*Info{leaf}
-		current := leaf
-
-		for !IsSelfSigned(current.Cert) {
-
-			issuer := subjectMap[current.Cert.Issuer.String()]
-			if issuer == nil {
-				break
-			}
-
-			if slices.Contains(chain, issuer) {
-				break
-			}
-
-			chain = append(chain, issuer)
-			current = issuer
-		}
-
-		if len(chain) > len(longestChain) {
-			longestChain = chain
-		}
+// BuildChain returns the best ranked path of BuildPaths through certs,
+// validity being ranked at the current time.
+func BuildChain(certs []*Info) ([]*Info, error) {
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates provided")
 	}
 
-	if len(longestChain) == 0 {
+	paths := BuildPaths(certs, PathOptions{})
+	if len(paths) == 0 {
 		return nil, fmt.Errorf("could not build certificate chain")
 	}
-
-	for i, c := range longestChain {
-		c.Position = i
-		c.Type = GetCertType(c.Cert, i, len(longestChain))
-	}
-
-	return longestChain, nil
+	return paths[0].Chain, nil
 }
 
 // ClimbChain recursively fetches issuer certificates via CA Issuers URLs.
@@ -190,4 +190,13 @@ func TestGetCertType_ClassifiesIndependentOfPosition(t *testing.T) {
 	if got := GetCertType(leaf, 2, 3); got != "leaf" {
 		t.Fatalf("expected leaf independent of position, got %q", got)
 	}
+
+	precert := &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "Precertificate"},
+		Issuer:    pkix.Name{CommonName: "Intermediate"},
+		IsPrecert: true,
+	}
+	if got := GetCertType(precert, 0, 3); got != "leaf" {
+		t.Fatalf("expected precertificate to be a leaf, got %q", got)
+	}
 }
@@ -0,0 +1,329 @@
+package cert
+
+import (
+	"bytes"
+	"slices"
+	"time"
+
+	"github.com/zmap/zcrypto/x509"
+)
+
+// Limits on path building, so that a pool with many cross-certificates
+// cannot make the search explode.
+const (
+	maxPathDepth = 16
+	maxPaths     = 64
+)
+
+// PathOptions tune BuildPaths.
+type PathOptions struct {
+	// Now is the time validity is ranked at. The zero value means the
+	// current time.
+	Now time.Time
+
+	// Anchored reports whether a path, ordered from the target up, ends at
+	// a trust anchor. Nil treats a path as anchored if its top certificate
+	// is self-signed.
+	Anchored func(path []*x509.Certificate) bool
+}
+
+// Path is one candidate certification path, ordered from the target up,
+// together with the criteria it is ranked by.
+type Path struct {
+	Chain           []*Info
+	Anchored        bool
+	SignaturesValid bool
+	WithinValidity  bool
+}
+
+// Valid reports whether the path ends at a trust anchor, every signature
+// verifies with the issuer's key and every certificate is within its
+// validity period.
+func (p Path) Valid() bool {
+	return p.Anchored && p.SignaturesValid && p.WithinValidity
+}
+
+// BuildPaths builds every certification path through certs. Issuers are
+// matched on subject DN and, where both are present, on the authority and
+// subject key identifiers, so cross-signed and re-keyed CAs that share a
+// DN yield one path each. Paths start at each certificate that issued none
+// of the others and end at a self-signed certificate or where no further
+// issuer is known.
+//
+// Paths are ranked: anchored paths first, then paths whose signatures all
+// verify, then paths valid at opts.Now, then longer paths. Each path holds
+// its own copies of the infos, with positions and types set.
+func BuildPaths(certs []*Info, opts PathOptions) []Path {
+	g := NewGraph(certs)
+	return g.paths(g.starts(), nil, opts)
+}
+
+// PathsFrom builds every certification path that starts at leaf, taking
+// issuers from pool. Paths are ranked as by BuildPaths.
+func PathsFrom(leaf *Info, pool []*Info, opts PathOptions) []Path {
+	return NewGraph(pool).PathsFrom(leaf, nil, opts)
+}
+
+// Graph is an issuer graph over a shared pool of certificates. Leaves are
+// attached to it one at a time, so that many leaves reuse the issuer
+// matching and signature checks of the pool. A Graph is not safe for
+// concurrent use.
+//
+// It holds the deduplicated certificates and, for each, the indexes of the
+// certificates that may have issued it. Certificates are indexed by DN so
+// that adding one only matches it against certificates of related names.
+type Graph struct {
+	nodes     []*Info
+	issuers   [][]int
+	pooled    []bool
+	index     map[string]int
+	bySubject map[string][]int
+	byIssuer  map[string][]int
+	sigs      map[[2]int]bool
+}
+
+// NewGraph builds the issuer graph of pool.
+func NewGraph(pool []*Info) *Graph {
+	g := &Graph{
+		index:     make(map[string]int),
+		bySubject: make(map[string][]int),
+		byIssuer:  make(map[string][]int),
+		sigs:      make(map[[2]int]bool),
+	}
+	for _, c := range pool {
+		if i, ok := g.add(c); ok {
+			g.pooled[i] = true
+		}
+	}
+	return g
+}
+
+// PathsFrom builds every certification path that starts at leaf, taking
+// issuers from the pool of g and from extra. The certificates of extra are
+// only used for the paths of this leaf. Paths are ranked as by BuildPaths.
+func (g *Graph) PathsFrom(leaf *Info, extra []*Info, opts PathOptions) []Path {
+	start, ok := g.add(leaf)
+	if !ok {
+		return nil
+	}
+	own := map[int]bool{start: true}
+	for _, c := range extra {
+		if i, ok := g.add(c); ok {
+			own[i] = true
+		}
+	}
+	visible := func(i int) bool { return g.pooled[i] || own[i] }
+	return g.paths([]int{start}, visible, opts)
+}
+
+// paths walks from each of starts through the issuers for which visible
+// returns true, or through every issuer if visible is nil.
+func (g *Graph) paths(starts []int, visible func(int) bool, opts PathOptions) []Path {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.Anchored == nil {
+		opts.Anchored = selfSignedTop
+	}
+
+	var paths []Path
+	for _, start := range starts {
+		g.walk([]int{start}, visible, func(idx []int) bool {
+			paths = append(paths, g.path(idx, opts))
+			return len(paths) < maxPaths
+		})
+		if len(paths) >= maxPaths {
+			break
+		}
+	}
+
+	slices.SortStableFunc(paths, comparePaths)
+	return paths
+}
+
+// ValidPaths returns the valid paths of BuildPaths. If there is none, the
+// best ranked path is returned on its own so callers always get a chain to
+// lint.
+func ValidPaths(paths []Path) []Path {
+	var valid []Path
+	for _, p := range paths {
+		if p.Valid() {
+			valid = append(valid, p)
+		}
+	}
+	if len(valid) == 0 && len(paths) > 0 {
+		return paths[:1]
+	}
+	return valid
+}
+
+func comparePaths(a, b Path) int {
+	for _, c := range [][2]bool{
+		{a.Anchored, b.Anchored},
+		{a.SignaturesValid, b.SignaturesValid},
+		{a.WithinValidity, b.WithinValidity},
+	} {
+		if c[0] != c[1] {
+			if c[0] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(b.Chain) - len(a.Chain)
+}
+
+func selfSignedTop(path []*x509.Certificate) bool {
+	return len(path) > 0 && IsSelfSigned(path[len(path)-1])
+}
+
+// add inserts c unless an equal certificate is already present and links
+// it to its issuers and subjects. It returns the index of c, and false if
+// c holds no certificate.
+func (g *Graph) add(c *Info) (int, bool) {
+	if c == nil || c.Cert == nil {
+		return 0, false
+	}
+	key := certKey(c)
+	if key != "" {
+		if i, ok := g.index[key]; ok {
+			return i, true
+		}
+	}
+
+	i := len(g.nodes)
+	g.nodes = append(g.nodes, c)
+	g.issuers = append(g.issuers, nil)
+	g.pooled = append(g.pooled, false)
+	if key != "" {
+		g.index[key] = i
+	}
+
+	subject, issuer := c.Cert.Subject.String(), c.Cert.Issuer.String()
+	for _, j := range g.bySubject[issuer] {
+		if mayHaveIssued(g.nodes[j].Cert, c.Cert) {
+			g.issuers[i] = append(g.issuers[i], j)
+		}
+	}
+	for _, j := range g.byIssuer[subject] {
+		if mayHaveIssued(c.Cert, g.nodes[j].Cert) {
+			g.issuers[j] = append(g.issuers[j], i)
+		}
+	}
+	g.bySubject[subject] = append(g.bySubject[subject], i)
+	g.byIssuer[issuer] = append(g.byIssuer[issuer], i)
+	return i, true
+}
+
+// certKey identifies c for deduplication, preferring its DER encoding over
+// its hash. Certificates with neither are never treated as equal.
+func certKey(c *Info) string {
+	switch {
+	case len(c.Cert.Raw) > 0:
+		return "der:" + string(c.Cert.Raw)
+	case c.Hash != "":
+		return "hash:" + c.Hash
+	}
+	return ""
+}
+
+// mayHaveIssued matches issuer to c, whose DNs already match, by key ID
+// when c names an authority key identifier and issuer has a subject key
+// identifier.
+func mayHaveIssued(issuer, c *x509.Certificate) bool {
+	if len(c.AuthorityKeyId) > 0 && len(issuer.SubjectKeyId) > 0 {
+		return bytes.Equal(c.AuthorityKeyId, issuer.SubjectKeyId)
+	}
+	return true
+}
+
+// starts returns the certificates that issued none of the others, or all
+// certificates if every one of them issued another.
+func (g *Graph) starts() []int {
+	issuesOther := make([]bool, len(g.nodes))
+	for i := range g.nodes {
+		if IsSelfSigned(g.nodes[i].Cert) {
+			continue
+		}
+		for _, j := range g.issuers[i] {
+			issuesOther[j] = true
+		}
+	}
+
+	var starts []int
+	for i := range g.nodes {
+		if !issuesOther[i] {
+			starts = append(starts, i)
+		}
+	}
+	if len(starts) == 0 {
+		for i := range g.nodes {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+// walk extends path depth-first through visible issuers and calls emit for
+// every complete path. It stops early when emit returns false.
+func (g *Graph) walk(path []int, visible func(int) bool, emit func([]int) bool) bool {
+	top := path[len(path)-1]
+	var next []int
+	if !IsSelfSigned(g.nodes[top].Cert) && len(path) < maxPathDepth {
+		for _, j := range g.issuers[top] {
+			if !slices.Contains(path, j) && (visible == nil || visible(j)) {
+				next = append(next, j)
+			}
+		}
+	}
+	if len(next) == 0 {
+		return emit(slices.Clone(path))
+	}
+	for _, j := range next {
+		if !g.walk(append(path, j), visible, emit) {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *Graph) signed(child, issuer int) bool {
+	key := [2]int{child, issuer}
+	ok, seen := g.sigs[key]
+	if !seen {
+		ok = signedBy(g.nodes[child].Cert, g.nodes[issuer].Cert)
+		g.sigs[key] = ok
+	}
+	return ok
+}
+
+// signedBy checks the signature of c with the key of issuer. Issuer
+// constraints such as basicConstraints are left to path validation.
+func signedBy(c, issuer *x509.Certificate) bool {
+	return issuer.CheckSignature(c.SignatureAlgorithm, c.RawTBSCertificate, c.Signature) == nil
+}
+
+func (g *Graph) path(idx []int, opts PathOptions) Path {
+	p := Path{SignaturesValid: true, WithinValidity: true}
+	certs := make([]*x509.Certificate, 0, len(idx))
+	for i, n := range idx {
+		info := *g.nodes[n]
+		p.Chain = append(p.Chain, &info)
+		certs = append(certs, info.Cert)
+
+		if opts.Now.Before(info.Cert.NotBefore) || opts.Now.After(info.Cert.NotAfter) {
+			p.WithinValidity = false
+		}
+		if i+1 < len(idx) && !g.signed(n, idx[i+1]) {
+			p.SignaturesValid = false
+		}
+	}
+	top := certs[len(certs)-1]
+	if IsSelfSigned(top) && !signedBy(top, top) {
+		p.SignaturesValid = false
+	}
+	p.Anchored = opts.Anchored(certs)
+
+	RebuildChainMetadata(p.Chain)
+	return p
+}
@@ -0,0 +1,248 @@
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	stdx509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/source"
+)
+
+var graphNow = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+type testCA struct {
+	tmpl *stdx509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// issueCert signs a certificate for key with the given subject. A nil
+// issuer makes it self-signed.
+func issueCert(t *testing.T, name string, key *ecdsa.PrivateKey, issuer *testCA, isCA bool, notAfter time.Time) (*Info, *testCA) {
+	t.Helper()
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &stdx509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             graphNow.AddDate(-1, 0, 0),
+		NotAfter:              notAfter,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		tmpl.KeyUsage = stdx509.KeyUsageCertSign
+	}
+	parent, signer := tmpl, key
+	if issuer != nil {
+		parent, signer = issuer.tmpl, issuer.key
+	}
+	der, err := stdx509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Key identifiers are derived while creating the certificate; keep
+	// them so certificates issued by this one name the right AKI.
+	parsed, err := stdx509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := NewInfo(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), name, source.Info{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info, &testCA{tmpl: parsed, key: key}
+}
+
+func subjects(chain []*Info) []string {
+	names := make([]string, 0, len(chain))
+	for _, c := range chain {
+		names = append(names, c.FilePath)
+	}
+	return names
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// crossSigned returns a leaf whose intermediate key is certified twice
+// under the same DN: once by root A and once, as a cross-certificate, by
+// root B.
+func crossSigned(t *testing.T, crossNotAfter time.Time) (leaf, interA, interB, rootA, rootB *Info) {
+	t.Helper()
+	later := graphNow.AddDate(1, 0, 0)
+	rootA, caA := issueCert(t, "Root A", newKey(t), nil, true, later)
+	rootB, caB := issueCert(t, "Root B", newKey(t), nil, true, later)
+
+	interKey := newKey(t)
+	interA, ica := issueCert(t, "Intermediate", interKey, caA, true, later)
+	interB, _ = issueCert(t, "Intermediate", interKey, caB, true, crossNotAfter)
+	interA.FilePath, interB.FilePath = "Intermediate (A)", "Intermediate (B)"
+
+	leaf, _ = issueCert(t, "leaf", newKey(t), ica, false, later)
+	return leaf, interA, interB, rootA, rootB
+}
+
+func TestBuildPathsCrossSigned(t *testing.T) {
+	leaf, interA, interB, rootA, rootB := crossSigned(t, graphNow.AddDate(1, 0, 0))
+
+	paths := BuildPaths([]*Info{leaf, interA, interB, rootA, rootB}, PathOptions{Now: graphNow})
+	if len(paths) != 2 {
+		t.Fatalf("got %d paths, want 2", len(paths))
+	}
+	want := [][]string{
+		{"leaf", "Intermediate (A)", "Root A"},
+		{"leaf", "Intermediate (B)", "Root B"},
+	}
+	for i, p := range paths {
+		if !p.Valid() {
+			t.Errorf("path %d should be valid: %+v", i, p)
+		}
+		if got := subjects(p.Chain); !equal(got, want[i]) {
+			t.Errorf("path %d = %v, want %v", i, got, want[i])
+		}
+		for pos, c := range p.Chain {
+			if c.Position != pos {
+				t.Errorf("path %d: %s at position %d, want %d", i, c.FilePath, c.Position, pos)
+			}
+		}
+	}
+	if paths[1].Chain[0] == paths[0].Chain[0] {
+		t.Error("paths must not share info copies")
+	}
+}
+
+func TestBuildPathsRanksExpiredLast(t *testing.T) {
+	leaf, interA, interB, rootA, rootB := crossSigned(t, graphNow.AddDate(0, 0, -1))
+
+	paths := BuildPaths([]*Info{leaf, interB, interA, rootB, rootA}, PathOptions{Now: graphNow})
+	if len(paths) != 2 {
+		t.Fatalf("got %d paths, want 2", len(paths))
+	}
+	if got := subjects(paths[0].Chain); got[1] != "Intermediate (A)" {
+		t.Errorf("best path = %v, want the unexpired intermediate first", got)
+	}
+	if paths[1].WithinValidity || paths[1].Valid() {
+		t.Errorf("path through the expired cross-certificate should be invalid: %+v", paths[1])
+	}
+	if valid := ValidPaths(paths); len(valid) != 1 {
+		t.Errorf("ValidPaths returned %d paths, want 1", len(valid))
+	}
+}
+
+func TestBuildPathsRanksAnchoredFirst(t *testing.T) {
+	leaf, interA, interB, rootA, rootB := crossSigned(t, graphNow.AddDate(1, 0, 0))
+
+	anchoredInB := func(path []*x509.Certificate) bool {
+		return path[len(path)-1].Subject.CommonName == "Root B"
+	}
+	paths := BuildPaths([]*Info{leaf, interA, interB, rootA, rootB}, PathOptions{Now: graphNow, Anchored: anchoredInB})
+	if got := subjects(paths[0].Chain); got[2] != "Root B" {
+		t.Errorf("best path = %v, want the path anchored in Root B", got)
+	}
+	if paths[1].Anchored {
+		t.Error("path to Root A should not be anchored")
+	}
+}
+
+func TestBuildPathsKeyIdentifiers(t *testing.T) {
+	later := graphNow.AddDate(1, 0, 0)
+	_, oldCA := issueCert(t, "CA", newKey(t), nil, true, later)
+	newRoot, newCA := issueCert(t, "CA", newKey(t), nil, true, later)
+	oldRoot, _ := issueCert(t, "CA", oldCA.key, nil, true, later)
+	oldRoot.FilePath, newRoot.FilePath = "CA (old)", "CA (new)"
+	leaf, _ := issueCert(t, "leaf", newKey(t), newCA, false, later)
+
+	// Both CAs share the DN; only the AKI/SKI match picks the new key.
+	paths := PathsFrom(leaf, []*Info{oldRoot, newRoot}, PathOptions{Now: graphNow})
+	if len(paths) != 1 {
+		t.Fatalf("got %d paths, want 1: %v", len(paths), paths)
+	}
+	if got := subjects(paths[0].Chain); !equal(got, []string{"leaf", "CA (new)"}) {
+		t.Errorf("path = %v, want leaf -> CA (new)", got)
+	}
+	if !paths[0].Valid() {
+		t.Errorf("path should be valid: %+v", paths[0])
+	}
+}
+
+func TestBuildPathsBadSignature(t *testing.T) {
+	later := graphNow.AddDate(1, 0, 0)
+	root, _ := issueCert(t, "Root", newKey(t), nil, true, later)
+	// An impostor shares the root's DN and has no key identifiers in
+	// common, so it is only told apart by the signature.
+	_, impostor := issueCert(t, "Root", newKey(t), nil, true, later)
+	impostor.tmpl.SubjectKeyId = nil
+	leaf, _ := issueCert(t, "leaf", newKey(t), impostor, false, later)
+	leaf.Cert.AuthorityKeyId = nil
+
+	paths := BuildPaths([]*Info{leaf, root}, PathOptions{Now: graphNow})
+	if len(paths) != 1 {
+		t.Fatalf("got %d paths, want 1", len(paths))
+	}
+	if paths[0].SignaturesValid || paths[0].Valid() {
+		t.Errorf("path with a forged issuer should not be valid: %+v", paths[0])
+	}
+}
+
+func TestPathsFrom(t *testing.T) {
+	leaf, interA, interB, rootA, rootB := crossSigned(t, graphNow.AddDate(1, 0, 0))
+
+	paths := PathsFrom(leaf, []*Info{rootA, rootB, interA, interB}, PathOptions{Now: graphNow})
+	if len(paths) != 2 {
+		t.Fatalf("got %d paths, want 2", len(paths))
+	}
+	for _, p := range paths {
+		if p.Chain[0].FilePath != "leaf" || p.Chain[0].Type != "leaf" {
+			t.Errorf("path starts at %s (%s), want leaf", p.Chain[0].FilePath, p.Chain[0].Type)
+		}
+	}
+	if leaf.Position != 0 || interB.Position != 0 {
+		t.Error("pool certificates were modified")
+	}
+}
+
+func TestGraphPathsFromSharedPool(t *testing.T) {
+	leaf, interA, interB, rootA, rootB := crossSigned(t, graphNow.AddDate(1, 0, 0))
+
+	g := NewGraph([]*Info{rootA, rootB})
+	paths := g.PathsFrom(leaf, []*Info{interA}, PathOptions{Now: graphNow})
+	if len(paths) != 1 || len(paths[0].Chain) != 3 {
+		t.Fatalf("got %d paths, want 1 through interA", len(paths))
+	}
+
+	// interA was extra to the first leaf only
+	paths = g.PathsFrom(leaf, []*Info{interB}, PathOptions{Now: graphNow})
+	if len(paths) != 1 || paths[0].Chain[1].FilePath != interB.FilePath {
+		t.Fatalf("second leaf should only reach interB, got %d paths", len(paths))
+	}
+
+	paths = g.PathsFrom(leaf, nil, PathOptions{Now: graphNow})
+	if len(paths) != 1 || len(paths[0].Chain) != 1 {
+		t.Errorf("leaf without extras should have no issuers in the pool, got %+v", paths)
+	}
+}
@@ -13,7 +13,9 @@ import (
 	"github.com/zmap/zcrypto/x509/ct"
 
 	"github.com/cavoq/PCL/internal/asn1"
+	"github.com/cavoq/PCL/internal/crl"
 	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/oid"
 	"github.com/cavoq/PCL/internal/zcrypto"
 )
 
@@ -31,6 +33,39 @@ func BuildTree(cert *x509.Certificate) *node.Node {
 	return NewZCryptoBuilder().Build(cert)
 }
 
+// Fields lists the top-level children a certificate tree may contain.
+var Fields = []string{
+	"version",
+	"serialNumber",
+	"signatureAlgorithm",
+	"tbsSignatureAlgorithm",
+	"issuer",
+	"validity",
+	"subject",
+	"subjectPublicKeyInfo",
+	"issuerUniqueID",
+	"subjectUniqueID",
+	"extensions",
+	"signatureValue",
+	"authorityKeyIdentifier",
+	"subjectKeyIdentifier",
+	"keyUsage",
+	"extKeyUsage",
+	"basicConstraints",
+	"subjectAltName",
+	"issuerAltName",
+	"nameConstraints",
+	"certificatePolicies",
+	"cRLDistributionPoints",
+	"freshestCRL",
+	"ocspURL",
+	"caIssuersURL",
+	"cabfOrganizationIdentifier",
+	"signedCertificateTimestamps",
+	"isPrecertificate",
+	"precertificate",
+}
+
 func buildCertificate(cert *x509.Certificate) *node.Node {
 	root := node.New("certificate", nil)
 
@@ -44,9 +79,9 @@ func buildCertificate(cert *x509.Certificate) *node.Node {
 
 	root.Children["signatureAlgorithm"] = buildSignatureAlgorithm(cert)
 	root.Children["tbsSignatureAlgorithm"] = buildTBSSignatureAlgorithm(cert)
-	root.Children["issuer"] = zcrypto.BuildPkixName("issuer", cert.Issuer)
+	root.Children["issuer"] = zcrypto.BuildName("issuer", cert.Issuer, cert.RawIssuer)
 	root.Children["validity"] = buildValidity(cert)
-	root.Children["subject"] = zcrypto.BuildPkixName("subject", cert.Subject)
+	root.Children["subject"] = zcrypto.BuildName("subject", cert.Subject, cert.RawSubject)
 	root.Children["subjectPublicKeyInfo"] = buildSubjectPublicKeyInfo(cert)
 
 	if cert.IssuerUniqueId.BitLength > 0 {
@@ -81,6 +116,15 @@ func buildCertificate(cert *x509.Certificate) *node.Node {
 					}
 				}
 			}
+			if oidStr == oid.FreshestCRL {
+				freshestNode := ParseCRLDP(ext.Value)
+				if extNode, ok := root.Children["extensions"].Children["freshestCRL"]; ok {
+					// FreshestCRL has the syntax of CRL Distribution Points
+					for k, v := range freshestNode.Children {
+						extNode.Children[k] = v
+					}
+				}
+			}
 			if oidStr == "2.5.29.32" {
 				certPoliciesNode := ParseCertPolicies(ext.Value)
 				if extNode, ok := root.Children["extensions"].Children["certificatePolicies"]; ok {
@@ -93,10 +137,10 @@ func buildCertificate(cert *x509.Certificate) *node.Node {
 		}
 	}
 
-	root.Children["keyUsage"] = buildKeyUsage(cert.KeyUsage)
+	root.Children["keyUsage"] = BuildKeyUsage(cert.KeyUsage)
 
-	if len(cert.ExtKeyUsage) > 0 {
-		root.Children["extKeyUsage"] = buildExtKeyUsage(cert.ExtKeyUsage)
+	if len(cert.ExtKeyUsage) > 0 || len(cert.UnknownExtKeyUsage) > 0 {
+		root.Children["extKeyUsage"] = buildExtKeyUsage(cert)
 	}
 
 	if cert.BasicConstraintsValid {
@@ -131,7 +175,7 @@ func buildCertificate(cert *x509.Certificate) *node.Node {
 	}
 
 	if len(cert.Signature) > 0 {
-		root.Children["signatureValue"] = node.New("signatureValue", cert.Signature)
+		root.Children["signatureValue"] = buildSignatureValue(cert)
 	}
 
 	// Add OCSP URL from AIA extension
@@ -153,15 +197,30 @@ func buildCertificate(cert *x509.Certificate) *node.Node {
 		root.Children["cRLDistributionPoints"] = crlDPNode
 	}
 
+	// Add delta CRL distribution points (RFC 5280 4.2.1.15)
+	if uris := crl.FreshestCRL(cert.Extensions); len(uris) > 0 {
+		freshestNode := node.New("freshestCRL", nil)
+		for i, uri := range uris {
+			freshestNode.Children[fmt.Sprintf("%d", i)] = node.New(fmt.Sprintf("%d", i), uri)
+		}
+		root.Children["freshestCRL"] = freshestNode
+	}
+
 	// Add Signed Certificate Timestamps (SCT) from CT extension
 	if len(cert.SignedCertificateTimestampList) > 0 {
 		sctNode := node.New("signedCertificateTimestamps", nil)
 		for i, sct := range cert.SignedCertificateTimestampList {
-			sctNode.Children[fmt.Sprintf("%d", i)] = buildSCT(sct, i)
+			sctNode.Children[fmt.Sprintf("%d", i)] = BuildSCT(sct, i)
 		}
 		root.Children["signedCertificateTimestamps"] = sctNode
 	}
 
+	// CT precertificate (RFC 6962 3.1), linted as a leaf
+	root.Children["isPrecertificate"] = node.New("isPrecertificate", cert.IsPrecert)
+	if cert.IsPrecert {
+		root.Children["precertificate"] = buildPrecertificate(cert)
+	}
+
 	// Add Certificate Policies
 	if len(cert.PolicyIdentifiers) > 0 {
 		policiesNode := node.New("certificatePolicies", nil)
@@ -182,30 +241,25 @@ func buildCertificate(cert *x509.Certificate) *node.Node {
 }
 
 func buildSignatureAlgorithm(cert *x509.Certificate) *node.Node {
-	n := node.New("signatureAlgorithm", nil)
-	n.Children["algorithm"] = node.New("algorithm", cert.SignatureAlgorithm.String())
-	if len(cert.SignatureAlgorithmOID) > 0 {
-		n.Children["oid"] = node.New("oid", cert.SignatureAlgorithmOID.String())
-	}
-	params := ParseCertSignatureAlgorithmParams(cert.Raw)
-	// Add raw DER bytes for byte-for-byte encoding validation (Mozilla requirements)
-	if len(params.RawDER) > 0 {
-		n.Children["rawDER"] = node.New("rawDER", params.RawDER)
-	}
-	paramsNode := buildAlgorithmIDParams(params)
-	if paramsNode != nil {
-		n.Children["parameters"] = paramsNode
-	}
-	return n
+	return BuildSignatureAlgorithm("signatureAlgorithm", cert.SignatureAlgorithm, cert.SignatureAlgorithmOID.String(), ParseCertSignatureAlgorithmParams(cert.Raw))
 }
 
 func buildTBSSignatureAlgorithm(cert *x509.Certificate) *node.Node {
-	n := node.New("tbsSignatureAlgorithm", nil)
-	n.Children["algorithm"] = node.New("algorithm", cert.SignatureAlgorithm.String())
-	if len(cert.SignatureAlgorithmOID) > 0 {
-		n.Children["oid"] = node.New("oid", cert.SignatureAlgorithmOID.String())
+	return BuildSignatureAlgorithm("tbsSignatureAlgorithm", cert.SignatureAlgorithm, cert.SignatureAlgorithmOID.String(), ParseTBSCertSignatureParams(cert.RawTBSCertificate))
+}
+
+// BuildSignatureAlgorithm renders a signature AlgorithmIdentifier. It is
+// shared with other signed objects, such as certificate requests, so their
+// algorithm nodes match those of certificates.
+func BuildSignatureAlgorithm(name string, algorithm x509.SignatureAlgorithm, algorithmOID string, params asn1.ParamsState) *node.Node {
+	n := node.New(name, nil)
+	n.Children["algorithm"] = node.New("algorithm", algorithm.String())
+	if algorithmOID != "" {
+		n.Children["oid"] = node.New("oid", algorithmOID)
+		if alg, ok := oid.LookupPQC(algorithmOID); ok {
+			addPQCAlgorithm(n, alg)
+		}
 	}
-	params := ParseTBSCertSignatureParams(cert.RawTBSCertificate)
 	// Add raw DER bytes for byte-for-byte encoding validation (Mozilla requirements)
 	if len(params.RawDER) > 0 {
 		n.Children["rawDER"] = node.New("rawDER", params.RawDER)
@@ -316,14 +370,25 @@ func buildValidity(cert *x509.Certificate) *node.Node {
 }
 
 func buildSubjectPublicKeyInfo(cert *x509.Certificate) *node.Node {
+	return BuildSubjectPublicKeyInfo(cert.PublicKeyAlgorithm, cert.RawSubjectPublicKeyInfo, cert.PublicKey)
+}
+
+// BuildSubjectPublicKeyInfo renders a SubjectPublicKeyInfo from its DER
+// encoding and the key zcrypto parsed from it, which is nil for algorithms
+// zcrypto does not know.
+func BuildSubjectPublicKeyInfo(algorithm x509.PublicKeyAlgorithm, rawSPKI []byte, publicKey any) *node.Node {
 	n := node.New("subjectPublicKeyInfo", nil)
 
+	params := ParseSubjectPublicKeyInfoParams(rawSPKI)
 	algo := node.New("algorithm", nil)
-	algo.Children["algorithm"] = node.New("algorithm", cert.PublicKeyAlgorithm.String())
-	if len(cert.PublicKeyAlgorithmOID) > 0 {
-		algo.Children["oid"] = node.New("oid", cert.PublicKeyAlgorithmOID.String())
+	algo.Children["algorithm"] = node.New("algorithm", algorithm.String())
+	pqc, isPQC := oid.LookupPQC(params.OID)
+	if params.OID != "" {
+		algo.Children["oid"] = node.New("oid", params.OID)
+		if isPQC {
+			addPQCAlgorithm(algo, pqc)
+		}
 	}
-	params := ParseSubjectPublicKeyInfoParams(cert.RawSubjectPublicKeyInfo)
 	// Add raw DER bytes for byte-for-byte encoding validation (Mozilla requirements)
 	if len(params.RawDER) > 0 {
 		algo.Children["rawDER"] = node.New("rawDER", params.RawDER)
@@ -334,23 +399,33 @@ func buildSubjectPublicKeyInfo(cert *x509.Certificate) *node.Node {
 	}
 	n.Children["algorithm"] = algo
 
-	if cert.PublicKey != nil {
-		switch key := cert.PublicKey.(type) {
+	// zcrypto leaves PublicKey nil for algorithms it does not know.
+	if isPQC {
+		if key := ParseSubjectPublicKey(rawSPKI); key != nil {
+			n.Children["publicKey"] = buildPQCKey(key, pqc)
+		}
+	}
+
+	if publicKey != nil {
+		switch key := publicKey.(type) {
 		case *rsa.PublicKey:
 			n.Children["publicKey"] = buildRSAKey(key)
 		case *ecdsa.PublicKey:
 			n.Children["publicKey"] = buildECDSAKey(key)
+		case *x509.AugmentedECDSA:
+			n.Children["publicKey"] = buildECDSAKey(key.Pub)
 		case ed25519.PublicKey:
 			n.Children["publicKey"] = buildEd25519Key(key)
 		default:
-			n.Children["publicKey"] = node.New("publicKey", cert.PublicKey)
+			n.Children["publicKey"] = node.New("publicKey", publicKey)
 		}
 	}
 
 	return n
 }
 
-func buildKeyUsage(ku x509.KeyUsage) *node.Node {
+// BuildKeyUsage renders the key usage bits, one child per bit that is set.
+func BuildKeyUsage(ku x509.KeyUsage) *node.Node {
 	n := node.New("keyUsage", int(ku))
 
 	if ku&x509.KeyUsageDigitalSignature != 0 {
@@ -386,7 +461,8 @@ func buildKeyUsage(ku x509.KeyUsage) *node.Node {
 	return n
 }
 
-func buildExtKeyUsage(ekus []x509.ExtKeyUsage) *node.Node {
+// BuildExtKeyUsage renders the known extended key usages by name.
+func BuildExtKeyUsage(ekus []x509.ExtKeyUsage) *node.Node {
 	n := node.New("extKeyUsage", nil)
 
 	for _, eku := range ekus {
@@ -411,6 +487,35 @@ func buildExtKeyUsage(ekus []x509.ExtKeyUsage) *node.Node {
 	return n
 }
 
+// buildExtKeyUsage adds the purposes zcrypto does not know, keyed by OID as
+// in a CSR tree. The CT Precertificate Signing purpose is also named.
+func buildExtKeyUsage(cert *x509.Certificate) *node.Node {
+	n := BuildExtKeyUsage(cert.ExtKeyUsage)
+	for _, u := range cert.UnknownExtKeyUsage {
+		o := u.String()
+		n.Children[o] = node.New(o, true)
+		if o == oid.PrecertSigning {
+			n.Children["precertSigning"] = node.New("precertSigning", true)
+		}
+	}
+	return n
+}
+
+// buildPrecertificate describes the poison extension. The evaluator adds
+// the issuer and final certificate checks, which need other certificates.
+func buildPrecertificate(cert *x509.Certificate) *node.Node {
+	n := node.New("precertificate", nil)
+	for _, ext := range cert.Extensions {
+		if ext.Id.String() == oid.PrecertPoison {
+			poison := node.New("poison", nil)
+			poison.Children["critical"] = node.New("critical", ext.Critical)
+			n.Children["poison"] = poison
+			break
+		}
+	}
+	return n
+}
+
 func buildBasicConstraints(cert *x509.Certificate) *node.Node {
 	n := node.New("basicConstraints", nil)
 	n.Children["cA"] = node.New("cA", cert.IsCA)
@@ -530,7 +635,43 @@ func buildEd25519Key(key ed25519.PublicKey) *node.Node {
 	return n
 }
 
-func buildSCT(sct interface{}, index int) *node.Node {
+func buildPQCKey(key []byte, alg oid.PQCAlgorithm) *node.Node {
+	n := node.New("publicKey", nil)
+	n.Children["keySize"] = node.New("keySize", len(key)*8)
+	n.Children["length"] = node.New("length", len(key))
+	n.Children["lengthValid"] = node.New("lengthValid", alg.ValidPublicKeyLength(len(key)))
+	return n
+}
+
+// addPQCAlgorithm names a post-quantum or composite algorithm identifier,
+// which zcrypto reports as unknown, and exposes its parameter set.
+func addPQCAlgorithm(n *node.Node, alg oid.PQCAlgorithm) {
+	n.Children["algorithm"] = node.New("algorithm", alg.Name)
+	n.Children["family"] = node.New("family", alg.Family)
+	n.Children["parameterSet"] = node.New("parameterSet", alg.Name)
+	n.Children["securityCategory"] = node.New("securityCategory", alg.SecurityCategory)
+	if len(alg.Components) > 0 {
+		components := node.New("components", nil)
+		for i, c := range alg.Components {
+			components.Children[fmt.Sprintf("%d", i)] = node.New(fmt.Sprintf("%d", i), c)
+		}
+		n.Children["components"] = components
+	}
+}
+
+// buildSignatureValue adds the signature length for post-quantum algorithms,
+// whose signatures have a fixed size per parameter set.
+func buildSignatureValue(cert *x509.Certificate) *node.Node {
+	n := node.New("signatureValue", cert.Signature)
+	if alg, ok := oid.LookupPQC(cert.SignatureAlgorithmOID.String()); ok {
+		n.Children["length"] = node.New("length", len(cert.Signature))
+		n.Children["lengthValid"] = node.New("lengthValid", alg.ValidSignatureLength(len(cert.Signature)))
+	}
+	return n
+}
+
+// BuildSCT renders one signed certificate timestamp as list element index.
+func BuildSCT(sct interface{}, index int) *node.Node {
 	n := node.New(fmt.Sprintf("%d", index), nil)
 	n.Children["present"] = node.New("present", true)
 
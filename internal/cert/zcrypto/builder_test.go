@@ -1,39 +1,22 @@
 package zcrypto
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	stdx509 "crypto/x509"
+	"encoding/pem"
+	"math/big"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/node/nodetest"
+	"github.com/cavoq/PCL/internal/oid"
 )
 
-func assertPathExists(t *testing.T, root *node.Node, path string) {
-	t.Helper()
-	if _, ok := root.Resolve(path); !ok {
-		t.Errorf("expected path %q to exist", path)
-	}
-}
-
-func assertPathNotExists(t *testing.T, root *node.Node, path string) {
-	t.Helper()
-	if _, ok := root.Resolve(path); ok {
-		t.Errorf("expected path %q to not exist", path)
-	}
-}
-
-func assertPathValue(t *testing.T, root *node.Node, path string, want any) {
-	t.Helper()
-	n, ok := root.Resolve(path)
-	if !ok {
-		t.Errorf("path %q not found", path)
-		return
-	}
-	if n.Value != want {
-		t.Errorf("path %q: expected %v (%T), got %v (%T)", path, want, want, n.Value, n.Value)
-	}
-}
-
 func loadCert(t *testing.T, name string) *node.Node {
 	t.Helper()
 	loader := NewLoader()
@@ -55,12 +38,12 @@ func TestBuilder_RootName(t *testing.T) {
 
 func TestBuilder_Version(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
-	assertPathValue(t, root, "certificate.version", 3)
+	nodetest.AssertPathValue(t, root, "certificate.version", 3)
 }
 
 func TestBuilder_SerialNumber(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
-	assertPathExists(t, root, "certificate.serialNumber")
+	nodetest.AssertPathExists(t, root, "certificate.serialNumber")
 
 	n, _ := root.Resolve("certificate.serialNumber")
 	if n.Value == nil || n.Value == "" {
@@ -71,41 +54,41 @@ func TestBuilder_SerialNumber(t *testing.T) {
 func TestBuilder_SignatureAlgorithm(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
 
-	assertPathExists(t, root, "certificate.signatureAlgorithm")
-	assertPathExists(t, root, "certificate.signatureAlgorithm.algorithm")
-	assertPathExists(t, root, "certificate.signatureAlgorithm.oid")
+	nodetest.AssertPathExists(t, root, "certificate.signatureAlgorithm")
+	nodetest.AssertPathExists(t, root, "certificate.signatureAlgorithm.algorithm")
+	nodetest.AssertPathExists(t, root, "certificate.signatureAlgorithm.oid")
 
-	assertPathValue(t, root, "certificate.signatureAlgorithm.algorithm", "SHA256-RSA")
+	nodetest.AssertPathValue(t, root, "certificate.signatureAlgorithm.algorithm", "SHA256-RSA")
 }
 
 func TestBuilder_Issuer(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
 
-	assertPathExists(t, root, "certificate.issuer")
-	assertPathValue(t, root, "certificate.issuer.commonName", "BSI Intermediate CA")
-	assertPathValue(t, root, "certificate.issuer.countryName", "DE")
-	assertPathValue(t, root, "certificate.issuer.organizationName", "ExampleOrg")
-	assertPathValue(t, root, "certificate.issuer.organizationalUnitName", "Intermediate")
-	assertPathValue(t, root, "certificate.issuer.localityName", "Berlin")
-	assertPathValue(t, root, "certificate.issuer.stateOrProvinceName", "Berlin")
+	nodetest.AssertPathExists(t, root, "certificate.issuer")
+	nodetest.AssertPathValue(t, root, "certificate.issuer.commonName", "BSI Intermediate CA")
+	nodetest.AssertPathValue(t, root, "certificate.issuer.countryName", "DE")
+	nodetest.AssertPathValue(t, root, "certificate.issuer.organizationName", "ExampleOrg")
+	nodetest.AssertPathValue(t, root, "certificate.issuer.organizationalUnitName", "Intermediate")
+	nodetest.AssertPathValue(t, root, "certificate.issuer.localityName", "Berlin")
+	nodetest.AssertPathValue(t, root, "certificate.issuer.stateOrProvinceName", "Berlin")
 }
 
 func TestBuilder_Subject(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
 
-	assertPathExists(t, root, "certificate.subject")
-	assertPathValue(t, root, "certificate.subject.commonName", "leaf.example.test")
-	assertPathValue(t, root, "certificate.subject.countryName", "DE")
-	assertPathValue(t, root, "certificate.subject.organizationName", "ExampleOrg")
-	assertPathValue(t, root, "certificate.subject.organizationalUnitName", "Leaf")
+	nodetest.AssertPathExists(t, root, "certificate.subject")
+	nodetest.AssertPathValue(t, root, "certificate.subject.commonName", "leaf.example.test")
+	nodetest.AssertPathValue(t, root, "certificate.subject.countryName", "DE")
+	nodetest.AssertPathValue(t, root, "certificate.subject.organizationName", "ExampleOrg")
+	nodetest.AssertPathValue(t, root, "certificate.subject.organizationalUnitName", "Leaf")
 }
 
 func TestBuilder_Validity(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
 
-	assertPathExists(t, root, "certificate.validity")
-	assertPathExists(t, root, "certificate.validity.notBefore")
-	assertPathExists(t, root, "certificate.validity.notAfter")
+	nodetest.AssertPathExists(t, root, "certificate.validity")
+	nodetest.AssertPathExists(t, root, "certificate.validity.notBefore")
+	nodetest.AssertPathExists(t, root, "certificate.validity.notAfter")
 
 	notBefore, _ := root.Resolve("certificate.validity.notBefore")
 	notAfter, _ := root.Resolve("certificate.validity.notAfter")
@@ -121,73 +104,94 @@ func TestBuilder_Validity(t *testing.T) {
 func TestBuilder_SubjectPublicKeyInfo_RSA(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
 
-	assertPathExists(t, root, "certificate.subjectPublicKeyInfo")
-	assertPathExists(t, root, "certificate.subjectPublicKeyInfo.algorithm")
-	assertPathValue(t, root, "certificate.subjectPublicKeyInfo.algorithm.algorithm", "RSA")
+	nodetest.AssertPathExists(t, root, "certificate.subjectPublicKeyInfo")
+	nodetest.AssertPathExists(t, root, "certificate.subjectPublicKeyInfo.algorithm")
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.algorithm.algorithm", "RSA")
 
-	assertPathExists(t, root, "certificate.subjectPublicKeyInfo.publicKey")
-	assertPathValue(t, root, "certificate.subjectPublicKeyInfo.publicKey.keySize", 2048)
-	assertPathValue(t, root, "certificate.subjectPublicKeyInfo.publicKey.exponent", 65537)
+	nodetest.AssertPathExists(t, root, "certificate.subjectPublicKeyInfo.publicKey")
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.publicKey.keySize", 2048)
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.publicKey.exponent", 65537)
 }
 
 func TestBuilder_SubjectPublicKeyInfo_RSA4096(t *testing.T) {
 	root := loadCert(t, "intermediate.pem")
 
-	assertPathValue(t, root, "certificate.subjectPublicKeyInfo.publicKey.keySize", 4096)
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.publicKey.keySize", 4096)
+}
+
+func TestBuilder_SubjectPublicKeyInfo_ECDSA(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &stdx509.Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour)}
+	der, err := stdx509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := NewLoader().Load(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	if err != nil {
+		t.Fatalf("failed to load cert: %v", err)
+	}
+	root := NewZCryptoBuilder().Build(cert)
+
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.algorithm.algorithm", "ECDSA")
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.publicKey.keySize", 384)
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.publicKey.curve", "P-384")
 }
 
 func TestBuilder_KeyUsage_LeafCert(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
 
-	assertPathExists(t, root, "certificate.keyUsage")
-	assertPathValue(t, root, "certificate.keyUsage.digitalSignature", true)
-	assertPathValue(t, root, "certificate.keyUsage.keyEncipherment", true)
+	nodetest.AssertPathExists(t, root, "certificate.keyUsage")
+	nodetest.AssertPathValue(t, root, "certificate.keyUsage.digitalSignature", true)
+	nodetest.AssertPathValue(t, root, "certificate.keyUsage.keyEncipherment", true)
 
-	assertPathNotExists(t, root, "certificate.keyUsage.keyCertSign")
-	assertPathNotExists(t, root, "certificate.keyUsage.cRLSign")
+	nodetest.AssertPathNotExists(t, root, "certificate.keyUsage.keyCertSign")
+	nodetest.AssertPathNotExists(t, root, "certificate.keyUsage.cRLSign")
 }
 
 func TestBuilder_KeyUsage_CACert(t *testing.T) {
 	root := loadCert(t, "intermediate.pem")
 
-	assertPathExists(t, root, "certificate.keyUsage")
-	assertPathValue(t, root, "certificate.keyUsage.keyCertSign", true)
-	assertPathValue(t, root, "certificate.keyUsage.cRLSign", true)
+	nodetest.AssertPathExists(t, root, "certificate.keyUsage")
+	nodetest.AssertPathValue(t, root, "certificate.keyUsage.keyCertSign", true)
+	nodetest.AssertPathValue(t, root, "certificate.keyUsage.cRLSign", true)
 }
 
 func TestBuilder_ExtKeyUsage(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
 
-	assertPathExists(t, root, "certificate.extKeyUsage")
-	assertPathValue(t, root, "certificate.extKeyUsage.serverAuth", true)
+	nodetest.AssertPathExists(t, root, "certificate.extKeyUsage")
+	nodetest.AssertPathValue(t, root, "certificate.extKeyUsage.serverAuth", true)
 }
 
 func TestBuilder_BasicConstraints_LeafCert(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
 
-	assertPathExists(t, root, "certificate.basicConstraints")
-	assertPathValue(t, root, "certificate.basicConstraints.cA", false)
+	nodetest.AssertPathExists(t, root, "certificate.basicConstraints")
+	nodetest.AssertPathValue(t, root, "certificate.basicConstraints.cA", false)
 }
 
 func TestBuilder_BasicConstraints_CACert(t *testing.T) {
 	root := loadCert(t, "intermediate.pem")
 
-	assertPathExists(t, root, "certificate.basicConstraints")
-	assertPathValue(t, root, "certificate.basicConstraints.cA", true)
-	assertPathExists(t, root, "certificate.basicConstraints.pathLenConstraint")
+	nodetest.AssertPathExists(t, root, "certificate.basicConstraints")
+	nodetest.AssertPathValue(t, root, "certificate.basicConstraints.cA", true)
+	nodetest.AssertPathExists(t, root, "certificate.basicConstraints.pathLenConstraint")
 }
 
 func TestBuilder_SubjectAltName(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
 
-	assertPathExists(t, root, "certificate.subjectAltName")
-	assertPathExists(t, root, "certificate.subjectAltName.dNSName")
-	assertPathValue(t, root, "certificate.subjectAltName.dNSName.0", "leaf.example.test")
+	nodetest.AssertPathExists(t, root, "certificate.subjectAltName")
+	nodetest.AssertPathExists(t, root, "certificate.subjectAltName.dNSName")
+	nodetest.AssertPathValue(t, root, "certificate.subjectAltName.dNSName.0", "leaf.example.test")
 }
 
 func TestBuilder_SubjectKeyIdentifier(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
-	assertPathExists(t, root, "certificate.subjectKeyIdentifier")
+	nodetest.AssertPathExists(t, root, "certificate.subjectKeyIdentifier")
 
 	n, _ := root.Resolve("certificate.subjectKeyIdentifier")
 	if n.Value == nil {
@@ -197,7 +201,7 @@ func TestBuilder_SubjectKeyIdentifier(t *testing.T) {
 
 func TestBuilder_AuthorityKeyIdentifier(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
-	assertPathExists(t, root, "certificate.authorityKeyIdentifier")
+	nodetest.AssertPathExists(t, root, "certificate.authorityKeyIdentifier")
 
 	n, _ := root.Resolve("certificate.authorityKeyIdentifier")
 	if n.Value == nil {
@@ -208,7 +212,7 @@ func TestBuilder_AuthorityKeyIdentifier(t *testing.T) {
 func TestBuilder_Extensions(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
 
-	assertPathExists(t, root, "certificate.extensions")
+	nodetest.AssertPathExists(t, root, "certificate.extensions")
 
 	extensions, _ := root.Resolve("certificate.extensions")
 	if len(extensions.Children) == 0 {
@@ -216,10 +220,57 @@ func TestBuilder_Extensions(t *testing.T) {
 	}
 }
 
+func TestBuilder_MLDSA(t *testing.T) {
+	root := loadCert(t, "mldsa-root.pem")
+
+	for _, path := range []string{"certificate.signatureAlgorithm", "certificate.tbsSignatureAlgorithm", "certificate.subjectPublicKeyInfo.algorithm"} {
+		nodetest.AssertPathValue(t, root, path+".algorithm", "ML-DSA-65")
+		nodetest.AssertPathValue(t, root, path+".oid", "2.16.840.1.101.3.4.3.18")
+		nodetest.AssertPathValue(t, root, path+".family", "ML-DSA")
+		nodetest.AssertPathValue(t, root, path+".parameterSet", "ML-DSA-65")
+		nodetest.AssertPathValue(t, root, path+".securityCategory", 3)
+		nodetest.AssertPathNotExists(t, root, path+".parameters")
+	}
+
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.publicKey.length", 1952)
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.publicKey.keySize", 1952*8)
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.publicKey.lengthValid", true)
+	nodetest.AssertPathValue(t, root, "certificate.signatureValue.length", 3309)
+	nodetest.AssertPathValue(t, root, "certificate.signatureValue.lengthValid", true)
+}
+
+func TestBuilder_MLKEM(t *testing.T) {
+	root := loadCert(t, "mlkem-leaf.pem")
+
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.algorithm.algorithm", "ML-KEM-768")
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.algorithm.family", "ML-KEM")
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.algorithm.parameters.null", true)
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.publicKey.length", 1184)
+	nodetest.AssertPathValue(t, root, "certificate.subjectPublicKeyInfo.publicKey.lengthValid", true)
+}
+
+func TestAddPQCAlgorithm_Composite(t *testing.T) {
+	alg, ok := oid.LookupPQC("1.3.6.1.5.5.7.6.45")
+	if !ok {
+		t.Fatal("composite OID not recognised")
+	}
+	n := node.New("signatureAlgorithm", nil)
+	addPQCAlgorithm(n, alg)
+
+	nodetest.AssertPathValue(t, n, "signatureAlgorithm.algorithm", "MLDSA65-ECDSA-P256-SHA512")
+	nodetest.AssertPathValue(t, n, "signatureAlgorithm.family", "Composite ML-DSA")
+	nodetest.AssertPathValue(t, n, "signatureAlgorithm.components.0", "ML-DSA-65")
+	nodetest.AssertPathValue(t, n, "signatureAlgorithm.components.1", "ECDSA-P256")
+
+	if !alg.ValidPublicKeyLength(1952+65) || alg.ValidPublicKeyLength(1952) {
+		t.Error("composite key must be longer than its ML-DSA component")
+	}
+}
+
 func TestBuilder_SignatureValue(t *testing.T) {
 	root := loadCert(t, "leaf.pem")
 
-	assertPathExists(t, root, "certificate.signatureValue")
+	nodetest.AssertPathExists(t, root, "certificate.signatureValue")
 
 	n, _ := root.Resolve("certificate.signatureValue")
 	sig, ok := n.Value.([]byte)
@@ -246,7 +297,7 @@ func TestBuilder_BuildTree(t *testing.T) {
 
 func TestBuilder_NoSubjectAltName(t *testing.T) {
 	root := loadCert(t, "intermediate.pem")
-	assertPathNotExists(t, root, "certificate.subjectAltName")
+	nodetest.AssertPathNotExists(t, root, "certificate.subjectAltName")
 }
 
 func TestBuilder_ExtensionDetails(t *testing.T) {
@@ -359,6 +410,33 @@ func TestBuilder_AIAStructure(t *testing.T) {
 	}
 }
 
+func TestBuilder_FreshestCRL(t *testing.T) {
+	data, err := os.ReadFile("../../../tests/certs/delta-held-leaf.pem")
+	if err != nil {
+		t.Fatalf("failed to read cert: %v", err)
+	}
+	loader := NewLoader()
+	cert, err := loader.Load(data)
+	if err != nil {
+		t.Fatalf("failed to load cert: %v", err)
+	}
+
+	node := BuildTree(cert)
+
+	uri, ok := node.Resolve("freshestCRL.0")
+	if !ok || uri.Value != "http://crl.example.test/delta-root-delta.crl" {
+		t.Errorf("expected freshestCRL URI, got %v", uri)
+	}
+
+	// The extension has the syntax of CRL Distribution Points
+	if _, ok := node.Resolve("extensions.freshestCRL.distributionPoints.0"); !ok {
+		t.Error("parsed freshestCRL distributionPoints not found")
+	}
+	if _, ok := node.Resolve("extensions.2.5.29.46"); !ok {
+		t.Error("freshestCRL OID not found")
+	}
+}
+
 func TestBuilder_CRLDPStructure(t *testing.T) {
 	data, err := os.ReadFile("../../../tests/certs/leaf.pem")
 	if err != nil {
@@ -454,16 +532,16 @@ func TestBuilder_NameConstraints(t *testing.T) {
 	root := loadCert(t, "nc_ca.pem")
 
 	// Check nameConstraints node exists
-	assertPathExists(t, root, "certificate.nameConstraints")
+	nodetest.AssertPathExists(t, root, "certificate.nameConstraints")
 
 	// Check critical flag
-	assertPathValue(t, root, "certificate.nameConstraints.critical", true)
+	nodetest.AssertPathValue(t, root, "certificate.nameConstraints.critical", true)
 
 	// Check permittedSubtrees exists
-	assertPathExists(t, root, "certificate.nameConstraints.permittedSubtrees")
+	nodetest.AssertPathExists(t, root, "certificate.nameConstraints.permittedSubtrees")
 
 	// Check permittedSubtrees.dNSName exists
-	assertPathExists(t, root, "certificate.nameConstraints.permittedSubtrees.dNSName")
+	nodetest.AssertPathExists(t, root, "certificate.nameConstraints.permittedSubtrees.dNSName")
 
 	// Check first DNS constraint value
 	dns0, ok := root.Resolve("certificate.nameConstraints.permittedSubtrees.dNSName.0")
@@ -476,9 +554,20 @@ func TestBuilder_NameConstraints(t *testing.T) {
 	t.Logf("DNS constraint 0 value: %v", dns0.Children["value"].Value)
 
 	// Check permittedSubtrees.iPAddress exists
-	assertPathExists(t, root, "certificate.nameConstraints.permittedSubtrees.iPAddress")
+	nodetest.AssertPathExists(t, root, "certificate.nameConstraints.permittedSubtrees.iPAddress")
 
 	// Check min/max are NOT present (BR requirement)
-	assertPathNotExists(t, root, "certificate.nameConstraints.permittedSubtrees.dNSName.0.min")
-	assertPathNotExists(t, root, "certificate.nameConstraints.permittedSubtrees.dNSName.0.max")
+	nodetest.AssertPathNotExists(t, root, "certificate.nameConstraints.permittedSubtrees.dNSName.0.min")
+	nodetest.AssertPathNotExists(t, root, "certificate.nameConstraints.permittedSubtrees.dNSName.0.max")
+}
+
+func TestBuilder_FieldsCoverTree(t *testing.T) {
+	for _, name := range []string{"leaf.pem", "intermediate.pem", "nc_ca.pem"} {
+		root := loadCert(t, name)
+		for child := range root.Children {
+			if !slices.Contains(Fields, child) {
+				t.Errorf("%s: child %q missing from Fields", name, child)
+			}
+		}
+	}
 }
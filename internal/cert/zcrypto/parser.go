@@ -403,4 +403,26 @@ func ParseRawCertificateTimes(rawCert []byte) (notBefore, notAfter time.Time, no
 	}
 
 	return notBefore, notAfter, notBeforeTag, notAfterTag, nil
-}
\ No newline at end of file
+}
+
+// ParseSubjectPublicKey returns the contents of the subjectPublicKey BIT
+// STRING of a SubjectPublicKeyInfo, or nil if it cannot be parsed.
+func ParseSubjectPublicKey(rawSubjectPublicKeyInfo []byte) []byte {
+	input := cryptobyte.String(rawSubjectPublicKeyInfo)
+
+	var spki cryptobyte.String
+	if !input.ReadASN1(&spki, cryptobyte_asn1.SEQUENCE) {
+		return nil
+	}
+
+	// Skip algorithm AlgorithmIdentifier
+	if !spki.SkipASN1(cryptobyte_asn1.SEQUENCE) {
+		return nil
+	}
+
+	var key stdasn1.BitString
+	if !spki.ReadASN1BitString(&key) {
+		return nil
+	}
+	return key.RightAlign()
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/cavoq/PCL/internal/cert"
@@ -68,19 +69,11 @@ func GetCRLs(path string) ([]*Info, error) {
 			continue
 		}
 
-		crl, format, err := parseCRL(data)
+		info, err := NewInfo(data, file, source.Info{Type: source.Local})
 		if err != nil {
 			continue
 		}
-
-		hash := sha256.Sum256(crl.Raw)
-		infos = append(infos, &Info{
-			CRL:      crl,
-			FilePath: file,
-			Hash:     hex.EncodeToString(hash[:]),
-			Source:   source.Info{Type: source.Local, Format: format},
-			Format:   format,
-		})
+		infos = append(infos, info)
 	}
 
 	if len(infos) == 0 && len(files) > 0 {
@@ -90,6 +83,25 @@ func GetCRLs(path string) ([]*Info, error) {
 	return infos, nil
 }
 
+// NewInfo parses a PEM or DER CRL held in memory. The name is reported as
+// the CRL's file path in lint results.
+func NewInfo(data []byte, name string, sourceInfo source.Info) (*Info, error) {
+	crl, format, err := parseCRL(data)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(crl.Raw)
+	sourceInfo.Format = format
+	return &Info{
+		CRL:      crl,
+		FilePath: name,
+		Hash:     hex.EncodeToString(hash[:]),
+		Source:   sourceInfo,
+		Format:   format,
+	}, nil
+}
+
 func FetchCRL(url string, timeout time.Duration) (*Info, error) {
 	if url == "" {
 		return nil, fmt.Errorf("CRL URL is required")
@@ -146,11 +158,13 @@ func FetchForChain(chain []*cert.Info, timeout time.Duration, w io.Writer) []*In
 	var results []*Info
 
 	for _, c := range chain {
-		if c.Cert == nil || len(c.Cert.CRLDistributionPoints) == 0 {
+		if c.Cert == nil {
 			continue
 		}
 
-		for _, url := range c.Cert.CRLDistributionPoints {
+		// Delta CRLs are found through the freshestCRL extension
+		urls := slices.Concat(c.Cert.CRLDistributionPoints, FreshestCRL(c.Cert.Extensions))
+		for _, url := range urls {
 			fetchResult, err := FetchCRL(url, timeout)
 			if err != nil {
 				if w != nil {
@@ -0,0 +1,187 @@
+package crl
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
+	"github.com/zmap/zcrypto/x509/pkix"
+
+	"github.com/cavoq/PCL/internal/oid"
+)
+
+// ReasonRemoveFromCRL is the CRLReason a delta CRL lists a certificate
+// with when it is no longer revoked, such as a released hold (RFC 5280
+// 5.2.4).
+const ReasonRemoveFromCRL = 8
+
+// BaseCRLNumber returns the BaseCRLNumber of the deltaCRLIndicator
+// extension, the number of the complete CRL a delta CRL updates.
+func BaseCRLNumber(crl *x509.RevocationList) (*big.Int, bool) {
+	if crl == nil {
+		return nil, false
+	}
+	for _, ext := range crl.Extensions {
+		if ext.Id.String() != oid.DeltaCRLIndicator {
+			continue
+		}
+		number := new(big.Int)
+		if rest, err := asn1.Unmarshal(ext.Value, &number); err != nil || len(rest) > 0 {
+			return nil, false
+		}
+		return number, true
+	}
+	return nil, false
+}
+
+// SameScope reports whether two CRLs have the same issuer and the same
+// issuingDistributionPoint, so that one can be a delta of the other.
+func SameScope(a, b *x509.RevocationList) bool {
+	if a == nil || b == nil || !bytes.Equal(a.RawIssuer, b.RawIssuer) {
+		return false
+	}
+	return bytes.Equal(extensionValue(a.Extensions, oid.IssuingDistributionPoint), extensionValue(b.Extensions, oid.IssuingDistributionPoint))
+}
+
+// BaseFor returns the complete CRL among crls that delta updates: the one
+// with the highest CRL number of the same scope that is at least the
+// BaseCRLNumber of delta and lower than the number of delta itself.
+func BaseFor(delta *x509.RevocationList, crls []*Info) *Info {
+	baseNumber, ok := BaseCRLNumber(delta)
+	if !ok {
+		return nil
+	}
+
+	var base *Info
+	for _, c := range crls {
+		if c.CRL == nil || c.CRL.Number == nil || HasDeltaIndicator(c.CRL) || !SameScope(c.CRL, delta) {
+			continue
+		}
+		if c.CRL.Number.Cmp(baseNumber) < 0 || (delta.Number != nil && c.CRL.Number.Cmp(delta.Number) >= 0) {
+			continue
+		}
+		if base == nil || c.CRL.Number.Cmp(base.CRL.Number) > 0 {
+			base = c
+		}
+	}
+	return base
+}
+
+// DeltaFor returns the newest delta CRL among crls that updates the
+// complete CRL base.
+func DeltaFor(base *x509.RevocationList, crls []*Info) *Info {
+	if base == nil || base.Number == nil || HasDeltaIndicator(base) {
+		return nil
+	}
+
+	var delta *Info
+	for _, c := range crls {
+		if c.CRL == nil || c.CRL.Number == nil || !SameScope(c.CRL, base) {
+			continue
+		}
+		baseNumber, ok := BaseCRLNumber(c.CRL)
+		if !ok || baseNumber.Cmp(base.Number) > 0 || c.CRL.Number.Cmp(base.Number) <= 0 {
+			continue
+		}
+		if delta == nil || c.CRL.Number.Cmp(delta.CRL.Number) > 0 {
+			delta = c
+		}
+	}
+	return delta
+}
+
+// Merge returns the revoked certificates of a complete CRL updated with a
+// delta CRL: entries of the delta replace those of the base, and entries
+// the delta lists with removeFromCRL are dropped. A nil delta returns the
+// entries of base; a nil base those the delta revokes.
+func Merge(base, delta *x509.RevocationList) []x509.RevokedCertificate {
+	if base == nil {
+		base = &x509.RevocationList{}
+	}
+	if delta == nil {
+		return base.RevokedCertificates
+	}
+
+	updated := make(map[string]x509.RevokedCertificate, len(delta.RevokedCertificates))
+	for _, rc := range delta.RevokedCertificates {
+		if rc.SerialNumber != nil {
+			updated[rc.SerialNumber.String()] = rc
+		}
+	}
+
+	merged := make([]x509.RevokedCertificate, 0, len(base.RevokedCertificates)+len(delta.RevokedCertificates))
+	for _, rc := range base.RevokedCertificates {
+		if rc.SerialNumber != nil {
+			if _, ok := updated[rc.SerialNumber.String()]; ok {
+				continue
+			}
+		}
+		merged = append(merged, rc)
+	}
+	for _, rc := range delta.RevokedCertificates {
+		if !isRemoveFromCRL(rc) {
+			merged = append(merged, rc)
+		}
+	}
+	return merged
+}
+
+// MergedWithComplete reports whether DeltaFor pairs delta, or a newer
+// delta CRL of its scope, with a complete CRL among crls, so that its
+// entries are already consulted through that complete CRL.
+func MergedWithComplete(delta *x509.RevocationList, crls []*Info) bool {
+	if delta == nil || delta.Number == nil {
+		return false
+	}
+	for _, c := range crls {
+		if c.CRL == nil || HasDeltaIndicator(c.CRL) || !SameScope(c.CRL, delta) {
+			continue
+		}
+		if d := DeltaFor(c.CRL, crls); d != nil && d.CRL.Number.Cmp(delta.Number) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func isRemoveFromCRL(rc x509.RevokedCertificate) bool {
+	return rc.ReasonCode != nil && *rc.ReasonCode == ReasonRemoveFromCRL
+}
+
+// FreshestCRL returns the URIs of the freshestCRL extension among exts,
+// which points certificates and complete CRLs to their delta CRLs (RFC
+// 5280 4.2.1.15, 5.2.6).
+func FreshestCRL(exts []pkix.Extension) []string {
+	value := extensionValue(exts, oid.FreshestCRL)
+	if value == nil {
+		return nil
+	}
+	return distributionPointURIs(value)
+}
+
+func distributionPointURIs(value []byte) []string {
+	var points []distributionPoint
+	if rest, err := asn1.Unmarshal(value, &points); err != nil || len(rest) > 0 {
+		return nil
+	}
+
+	var uris []string
+	for _, dp := range points {
+		for _, name := range dp.DistributionPoint.FullName {
+			if name.Class == asn1.ClassContextSpecific && name.Tag == 6 {
+				uris = append(uris, string(name.Bytes))
+			}
+		}
+	}
+	return uris
+}
+
+func extensionValue(exts []pkix.Extension, id string) []byte {
+	for _, ext := range exts {
+		if ext.Id.String() == id {
+			return ext.Value
+		}
+	}
+	return nil
+}
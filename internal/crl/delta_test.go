@@ -0,0 +1,188 @@
+package crl
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
+	"github.com/zmap/zcrypto/x509/pkix"
+)
+
+var (
+	deltaIndicatorOID = asn1.ObjectIdentifier{2, 5, 29, 27}
+	idpOID            = asn1.ObjectIdentifier{2, 5, 29, 28}
+	freshestCRLOID    = asn1.ObjectIdentifier{2, 5, 29, 46}
+)
+
+func completeCRL(t *testing.T, number int64, serials ...int64) *Info {
+	t.Helper()
+	rl := &x509.RevocationList{
+		RawIssuer: []byte("issuer"),
+		Number:    big.NewInt(number),
+	}
+	for _, s := range serials {
+		rl.RevokedCertificates = append(rl.RevokedCertificates, x509.RevokedCertificate{SerialNumber: big.NewInt(s)})
+	}
+	return &Info{CRL: rl}
+}
+
+func deltaCRL(t *testing.T, number, base int64, revoked ...x509.RevokedCertificate) *Info {
+	t.Helper()
+	value, err := asn1.Marshal(big.NewInt(base))
+	if err != nil {
+		t.Fatalf("failed to marshal BaseCRLNumber: %v", err)
+	}
+	return &Info{CRL: &x509.RevocationList{
+		RawIssuer:           []byte("issuer"),
+		Number:              big.NewInt(number),
+		Extensions:          []pkix.Extension{{Id: deltaIndicatorOID, Critical: true, Value: value}},
+		RevokedCertificates: revoked,
+	}}
+}
+
+func revokedWithReason(serial int64, reason int) x509.RevokedCertificate {
+	return x509.RevokedCertificate{SerialNumber: big.NewInt(serial), ReasonCode: &reason}
+}
+
+func TestBaseCRLNumber(t *testing.T) {
+	delta := deltaCRL(t, 11, 10)
+	got, ok := BaseCRLNumber(delta.CRL)
+	if !ok || got.Int64() != 10 {
+		t.Errorf("expected BaseCRLNumber 10, got %v (%v)", got, ok)
+	}
+
+	if _, ok := BaseCRLNumber(completeCRL(t, 10).CRL); ok {
+		t.Error("complete CRL should have no BaseCRLNumber")
+	}
+	if _, ok := BaseCRLNumber(nil); ok {
+		t.Error("nil CRL should have no BaseCRLNumber")
+	}
+}
+
+func TestSameScope(t *testing.T) {
+	base := completeCRL(t, 10)
+	delta := deltaCRL(t, 11, 10)
+	if !SameScope(base.CRL, delta.CRL) {
+		t.Error("same issuer without IDP should be same scope")
+	}
+
+	other := deltaCRL(t, 11, 10)
+	other.CRL.RawIssuer = []byte("other")
+	if SameScope(base.CRL, other.CRL) {
+		t.Error("different issuer should not be same scope")
+	}
+
+	partitioned := deltaCRL(t, 11, 10)
+	partitioned.CRL.Extensions = append(partitioned.CRL.Extensions, pkix.Extension{
+		Id:    idpOID,
+		Value: []byte{0x30, 0x03, 0x81, 0x01, 0xff},
+	})
+	if SameScope(base.CRL, partitioned.CRL) {
+		t.Error("different issuingDistributionPoint should not be same scope")
+	}
+}
+
+func TestBaseFor(t *testing.T) {
+	old := completeCRL(t, 9)
+	base := completeCRL(t, 10)
+	newer := completeCRL(t, 12)
+	delta := deltaCRL(t, 11, 9)
+	crls := []*Info{old, base, newer, delta}
+
+	if got := BaseFor(delta.CRL, crls); got != base {
+		t.Errorf("expected CRL 10 as base, got %v", got)
+	}
+	if got := BaseFor(deltaCRL(t, 11, 11).CRL, crls); got != nil {
+		t.Errorf("expected no base for BaseCRLNumber 11, got %v", got)
+	}
+	if got := BaseFor(base.CRL, crls); got != nil {
+		t.Error("complete CRL should have no base")
+	}
+}
+
+func TestDeltaFor(t *testing.T) {
+	base := completeCRL(t, 10)
+	older := deltaCRL(t, 11, 10)
+	newest := deltaCRL(t, 12, 9)
+	ahead := deltaCRL(t, 13, 11)
+	crls := []*Info{base, older, newest, ahead}
+
+	if got := DeltaFor(base.CRL, crls); got != newest {
+		t.Errorf("expected delta 12, got %v", got)
+	}
+	if got := DeltaFor(newest.CRL, crls); got != nil {
+		t.Error("delta CRL should have no delta")
+	}
+	if got := DeltaFor(completeCRL(t, 20).CRL, crls); got != nil {
+		t.Errorf("expected no delta for CRL 20, got %v", got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := completeCRL(t, 10, 1, 2, 3)
+	delta := deltaCRL(t, 11, 10,
+		revokedWithReason(2, ReasonRemoveFromCRL),
+		revokedWithReason(3, 1),
+		revokedWithReason(4, 1),
+	)
+
+	var got []int64
+	for _, rc := range Merge(base.CRL, delta.CRL) {
+		got = append(got, rc.SerialNumber.Int64())
+	}
+	if want := []int64{1, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected serials %v, got %v", want, got)
+	}
+}
+
+func TestMerge_NilInputs(t *testing.T) {
+	base := completeCRL(t, 10, 1)
+	if got := Merge(base.CRL, nil); len(got) != 1 {
+		t.Errorf("expected base entries, got %d", len(got))
+	}
+
+	delta := deltaCRL(t, 11, 10, revokedWithReason(2, ReasonRemoveFromCRL), revokedWithReason(3, 1))
+	got := Merge(nil, delta.CRL)
+	if len(got) != 1 || got[0].SerialNumber.Int64() != 3 {
+		t.Errorf("expected only serial 3, got %v", got)
+	}
+}
+
+func TestMergedWithComplete(t *testing.T) {
+	delta := deltaCRL(t, 11, 10)
+	if MergedWithComplete(delta.CRL, []*Info{delta}) {
+		t.Error("delta alone should not be merged")
+	}
+	if !MergedWithComplete(delta.CRL, []*Info{completeCRL(t, 10), delta}) {
+		t.Error("expected delta to be merged with complete CRL 10")
+	}
+	if !MergedWithComplete(delta.CRL, []*Info{completeCRL(t, 10), delta, deltaCRL(t, 12, 10)}) {
+		t.Error("expected delta to be superseded by delta 12")
+	}
+
+	ahead := deltaCRL(t, 13, 11)
+	if MergedWithComplete(ahead.CRL, []*Info{completeCRL(t, 10), ahead}) {
+		t.Error("delta with a newer base should not be merged with complete CRL 10")
+	}
+}
+
+func TestFreshestCRL(t *testing.T) {
+	// SEQUENCE { DistributionPoint { [0] { [0] { [6] "http://a" } } } }
+	value := []byte{
+		0x30, 0x10, 0x30, 0x0e, 0xa0, 0x0c, 0xa0, 0x0a,
+		0x86, 0x08, 'h', 't', 't', 'p', ':', '/', '/', 'a',
+	}
+	exts := []pkix.Extension{{Id: freshestCRLOID, Value: value}}
+	if got := FreshestCRL(exts); !reflect.DeepEqual(got, []string{"http://a"}) {
+		t.Errorf("expected [http://a], got %v", got)
+	}
+
+	if got := FreshestCRL(nil); got != nil {
+		t.Errorf("expected nil without extension, got %v", got)
+	}
+	if got := FreshestCRL([]pkix.Extension{{Id: freshestCRLOID, Value: []byte{0x01}}}); got != nil {
+		t.Errorf("expected nil for malformed extension, got %v", got)
+	}
+}
@@ -0,0 +1,234 @@
+package crl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/oid"
+)
+
+// ReasonFlags bit names (RFC 5280 4.2.1.13)
+var reasonFlagNames = []string{
+	"unused", "keyCompromise", "cACompromise", "affiliationChanged", "superseded",
+	"cessationOfOperation", "certificateHold", "privilegeWithdrawn", "aACompromise",
+}
+
+// IssuingDistributionPoint is the decoded issuingDistributionPoint
+// extension, which limits a CRL to a partition of the certificates of its
+// issuer (RFC 5280 5.2.5).
+//
+//	IssuingDistributionPoint ::= SEQUENCE {
+//	    distributionPoint          [0] DistributionPointName OPTIONAL,
+//	    onlyContainsUserCerts      [1] BOOLEAN DEFAULT FALSE,
+//	    onlyContainsCACerts        [2] BOOLEAN DEFAULT FALSE,
+//	    onlySomeReasons            [3] ReasonFlags OPTIONAL,
+//	    indirectCRL                [4] BOOLEAN DEFAULT FALSE,
+//	    onlyContainsAttributeCerts [5] BOOLEAN DEFAULT FALSE }
+type IssuingDistributionPoint struct {
+	FullName                   [][]byte // DER GeneralNames
+	RelativeName               []byte   // DER nameRelativeToCRLIssuer
+	OnlyContainsUserCerts      bool
+	OnlyContainsCACerts        bool
+	OnlySomeReasons            []string
+	IndirectCRL                bool
+	OnlyContainsAttributeCerts bool
+}
+
+// distributionPoint mirrors DistributionPoint (RFC 5280 4.2.1.13).
+type distributionPoint struct {
+	DistributionPoint distributionPointName `asn1:"optional,tag:0"`
+	Reason            asn1.BitString        `asn1:"optional,tag:1"`
+	CRLIssuer         asn1.RawValue         `asn1:"optional,tag:2"`
+}
+
+type distributionPointName struct {
+	FullName     []asn1.RawValue `asn1:"optional,tag:0"`
+	RelativeName asn1.RawValue   `asn1:"optional,tag:1"`
+}
+
+type issuingDistributionPoint struct {
+	DistributionPoint          distributionPointName `asn1:"optional,tag:0"`
+	OnlyContainsUserCerts      bool                  `asn1:"optional,tag:1"`
+	OnlyContainsCACerts        bool                  `asn1:"optional,tag:2"`
+	OnlySomeReasons            asn1.BitString        `asn1:"optional,tag:3"`
+	IndirectCRL                bool                  `asn1:"optional,tag:4"`
+	OnlyContainsAttributeCerts bool                  `asn1:"optional,tag:5"`
+}
+
+// ParseIssuingDistributionPoint decodes an issuingDistributionPoint
+// extension value.
+func ParseIssuingDistributionPoint(value []byte) (*IssuingDistributionPoint, error) {
+	var raw issuingDistributionPoint
+	rest, err := asn1.Unmarshal(value, &raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("trailing data after issuingDistributionPoint")
+	}
+
+	idp := &IssuingDistributionPoint{
+		RelativeName:               raw.DistributionPoint.RelativeName.FullBytes,
+		OnlyContainsUserCerts:      raw.OnlyContainsUserCerts,
+		OnlyContainsCACerts:        raw.OnlyContainsCACerts,
+		IndirectCRL:                raw.IndirectCRL,
+		OnlyContainsAttributeCerts: raw.OnlyContainsAttributeCerts,
+	}
+	for _, name := range raw.DistributionPoint.FullName {
+		idp.FullName = append(idp.FullName, name.FullBytes)
+	}
+	for i := 0; i < raw.OnlySomeReasons.BitLength; i++ {
+		if raw.OnlySomeReasons.At(i) != 0 && i < len(reasonFlagNames) {
+			idp.OnlySomeReasons = append(idp.OnlySomeReasons, reasonFlagNames[i])
+		}
+	}
+	return idp, nil
+}
+
+// IssuingDistributionPointOf returns the decoded issuingDistributionPoint
+// of crl, or nil if it has none.
+func IssuingDistributionPointOf(crl *x509.RevocationList) (*IssuingDistributionPoint, error) {
+	if crl == nil {
+		return nil, nil
+	}
+	value := extensionValue(crl.Extensions, oid.IssuingDistributionPoint)
+	if value == nil {
+		return nil, nil
+	}
+	return ParseIssuingDistributionPoint(value)
+}
+
+// HasDistributionPoint reports whether the issuingDistributionPoint names
+// the distribution point the CRL is published at.
+func (idp *IssuingDistributionPoint) HasDistributionPoint() bool {
+	return len(idp.FullName) > 0 || len(idp.RelativeName) > 0
+}
+
+// Covers reports whether cert is within the scope of crl (RFC 5280 6.3.3
+// (b)(2)): a CRL from the certificate's issuer without an
+// issuingDistributionPoint covers every certificate, while a partitioned
+// CRL must admit the certificate type and, when it names its distribution
+// point, match a distribution point or cRLIssuer of the certificate's
+// cRLDistributionPoints. A malformed issuingDistributionPoint or
+// cRLDistributionPoints is an error, since the scope cannot be decided.
+// Covers ignores onlySomeReasons; see CoversAllReasons.
+func Covers(crl *x509.RevocationList, cert *x509.Certificate) (bool, error) {
+	if crl == nil || cert == nil || crl.Issuer.String() != cert.Issuer.String() {
+		return false, nil
+	}
+
+	idp, err := IssuingDistributionPointOf(crl)
+	if err != nil {
+		return false, fmt.Errorf("malformed issuingDistributionPoint: %w", err)
+	}
+	if idp == nil {
+		return true, nil
+	}
+
+	isCA := cert.BasicConstraintsValid && cert.IsCA
+	switch {
+	case idp.OnlyContainsAttributeCerts:
+		return false, nil
+	case idp.OnlyContainsUserCerts && isCA:
+		return false, nil
+	case idp.OnlyContainsCACerts && !isCA:
+		return false, nil
+	}
+
+	if !idp.HasDistributionPoint() {
+		return true, nil
+	}
+	return matchesDistributionPoint(idp, cert)
+}
+
+// CoversAllReasons reports whether a CRL with this issuingDistributionPoint
+// lists revocations for every reason. A nil idp or one without
+// onlySomeReasons covers all reasons.
+func (idp *IssuingDistributionPoint) CoversAllReasons() bool {
+	if idp == nil || len(idp.OnlySomeReasons) == 0 {
+		return true
+	}
+	return CoverAllReasons(idp.OnlySomeReasons)
+}
+
+// CoverAllReasons reports whether reasons names every revocation reason of
+// ReasonFlags, so that CRLs partitioned by onlySomeReasons together provide
+// complete coverage (RFC 5280 6.3.3 (j)).
+func CoverAllReasons(reasons []string) bool {
+	for _, name := range reasonFlagNames[1:] {
+		if !slices.Contains(reasons, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesDistributionPoint reports whether a distribution point of the
+// certificate's cRLDistributionPoints names the distribution point of idp.
+func matchesDistributionPoint(idp *IssuingDistributionPoint, cert *x509.Certificate) (bool, error) {
+	value := extensionValue(cert.Extensions, oid.CRLDistributionPoints)
+	if value == nil {
+		return false, nil
+	}
+	var points []distributionPoint
+	if rest, err := asn1.Unmarshal(value, &points); err != nil {
+		return false, fmt.Errorf("malformed cRLDistributionPoints: %w", err)
+	} else if len(rest) > 0 {
+		return false, errors.New("malformed cRLDistributionPoints: trailing data")
+	}
+
+	for _, dp := range points {
+		name := dp.DistributionPoint
+		switch {
+		case len(name.FullName) > 0:
+			for _, n := range name.FullName {
+				if containsName(idp.FullName, n.FullBytes) {
+					return true, nil
+				}
+			}
+		case len(name.RelativeName.FullBytes) > 0:
+			if bytes.Equal(idp.RelativeName, name.RelativeName.FullBytes) {
+				return true, nil
+			}
+		case len(dp.CRLIssuer.Bytes) > 0:
+			// Without a distribution point name the CRL is found through
+			// the cRLIssuer names
+			for _, n := range generalNames(dp.CRLIssuer.Bytes) {
+				if containsName(idp.FullName, n) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+func containsName(names [][]byte, name []byte) bool {
+	for _, n := range names {
+		if bytes.Equal(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// generalNames splits the contents of a GeneralNames into the DER of each
+// GeneralName.
+func generalNames(contents []byte) [][]byte {
+	var names [][]byte
+	for len(contents) > 0 {
+		var name asn1.RawValue
+		rest, err := asn1.Unmarshal(contents, &name)
+		if err != nil {
+			return names
+		}
+		names = append(names, name.FullBytes)
+		contents = rest
+	}
+	return names
+}
@@ -0,0 +1,215 @@
+package crl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
+	"github.com/zmap/zcrypto/x509/pkix"
+)
+
+var crlDistributionPointsOID = asn1.ObjectIdentifier{2, 5, 29, 31}
+
+type testDistributionPointName struct {
+	FullName     []asn1.RawValue `asn1:"optional,tag:0"`
+	RelativeName asn1.RawValue   `asn1:"optional,tag:1"`
+}
+
+type testIssuingDistributionPoint struct {
+	DistributionPoint          testDistributionPointName `asn1:"optional,tag:0"`
+	OnlyContainsUserCerts      bool                      `asn1:"optional,tag:1"`
+	OnlyContainsCACerts        bool                      `asn1:"optional,tag:2"`
+	OnlySomeReasons            asn1.BitString            `asn1:"optional,tag:3"`
+	IndirectCRL                bool                      `asn1:"optional,tag:4"`
+	OnlyContainsAttributeCerts bool                      `asn1:"optional,tag:5"`
+}
+
+type testDistributionPoint struct {
+	DistributionPoint testDistributionPointName `asn1:"optional,tag:0"`
+	CRLIssuer         []asn1.RawValue           `asn1:"optional,tag:2"`
+}
+
+func uriName(uri string) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 6, Bytes: []byte(uri)}
+}
+
+func marshal(t *testing.T, v any) []byte {
+	t.Helper()
+	der, err := asn1.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	return der
+}
+
+func partitionedCRL(t *testing.T, idp testIssuingDistributionPoint) *x509.RevocationList {
+	t.Helper()
+	return &x509.RevocationList{
+		Issuer: pkix.Name{CommonName: "Test CA"},
+		Extensions: []pkix.Extension{{
+			Id:       idpOID,
+			Critical: true,
+			Value:    marshal(t, idp),
+		}},
+	}
+}
+
+func testCert(t *testing.T, isCA bool, points ...testDistributionPoint) *x509.Certificate {
+	t.Helper()
+	cert := &x509.Certificate{
+		Issuer:                pkix.Name{CommonName: "Test CA"},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if len(points) > 0 {
+		cert.Extensions = []pkix.Extension{{Id: crlDistributionPointsOID, Value: marshal(t, points)}}
+	}
+	return cert
+}
+
+func TestParseIssuingDistributionPoint(t *testing.T) {
+	value := marshal(t, testIssuingDistributionPoint{
+		DistributionPoint:     testDistributionPointName{FullName: []asn1.RawValue{uriName("http://a")}},
+		OnlyContainsUserCerts: true,
+		// keyCompromise and cACompromise
+		OnlySomeReasons: asn1.BitString{Bytes: []byte{0x60}, BitLength: 3},
+		IndirectCRL:     true,
+	})
+
+	idp, err := ParseIssuingDistributionPoint(value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(idp.FullName) != 1 || string(idp.FullName[0][2:]) != "http://a" {
+		t.Errorf("expected fullName http://a, got %x", idp.FullName)
+	}
+	if !idp.OnlyContainsUserCerts || idp.OnlyContainsCACerts || !idp.IndirectCRL || idp.OnlyContainsAttributeCerts {
+		t.Errorf("unexpected flags: %+v", idp)
+	}
+	if want := []string{"keyCompromise", "cACompromise"}; !reflect.DeepEqual(idp.OnlySomeReasons, want) {
+		t.Errorf("expected onlySomeReasons %v, got %v", want, idp.OnlySomeReasons)
+	}
+}
+
+func TestParseIssuingDistributionPoint_RelativeName(t *testing.T) {
+	rdn := marshal(t, []pkix.AttributeTypeAndValue{{Type: asn1.ObjectIdentifier{2, 5, 4, 3}, Value: "users"}})
+	relative := asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 1, IsCompound: true, Bytes: rdn[2:]}
+
+	idp, err := ParseIssuingDistributionPoint(marshal(t, testIssuingDistributionPoint{
+		DistributionPoint: testDistributionPointName{RelativeName: relative},
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(idp.RelativeName) == 0 || !idp.HasDistributionPoint() {
+		t.Error("expected nameRelativeToCRLIssuer")
+	}
+}
+
+func TestParseIssuingDistributionPoint_Malformed(t *testing.T) {
+	if _, err := ParseIssuingDistributionPoint([]byte{0x30, 0x03, 0x81}); err == nil {
+		t.Error("expected error for truncated extension")
+	}
+	if _, err := ParseIssuingDistributionPoint([]byte{0x30, 0x00, 0x00}); err == nil {
+		t.Error("expected error for trailing data")
+	}
+}
+
+func TestIsIndirect(t *testing.T) {
+	if !IsIndirect(partitionedCRL(t, testIssuingDistributionPoint{IndirectCRL: true})) {
+		t.Error("expected indirect CRL")
+	}
+	if IsIndirect(partitionedCRL(t, testIssuingDistributionPoint{OnlyContainsCACerts: true})) {
+		t.Error("expected CRL not to be indirect")
+	}
+	if IsIndirect(&x509.RevocationList{}) {
+		t.Error("CRL without issuingDistributionPoint is not indirect")
+	}
+}
+
+func TestCovers(t *testing.T) {
+	users := testDistributionPointName{FullName: []asn1.RawValue{uriName("http://crl/users.crl")}}
+	cas := testDistributionPointName{FullName: []asn1.RawValue{uriName("http://crl/cas.crl")}}
+	issuerName := asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 4, IsCompound: true,
+		Bytes: marshal(t, pkix.Name{CommonName: "CRL Issuer"}.ToRDNSequence())}
+
+	tests := []struct {
+		name    string
+		crl     *x509.RevocationList
+		cert    *x509.Certificate
+		want    bool
+		wantErr bool
+	}{
+		{"no issuingDistributionPoint", &x509.RevocationList{Issuer: pkix.Name{CommonName: "Test CA"}}, testCert(t, false), true, false},
+		{"other issuer", &x509.RevocationList{Issuer: pkix.Name{CommonName: "Other CA"}}, testCert(t, false), false, false},
+		{"user certs only, user cert", partitionedCRL(t, testIssuingDistributionPoint{OnlyContainsUserCerts: true}), testCert(t, false), true, false},
+		{"user certs only, CA cert", partitionedCRL(t, testIssuingDistributionPoint{OnlyContainsUserCerts: true}), testCert(t, true), false, false},
+		{"CA certs only, CA cert", partitionedCRL(t, testIssuingDistributionPoint{OnlyContainsCACerts: true}), testCert(t, true), true, false},
+		{"CA certs only, user cert", partitionedCRL(t, testIssuingDistributionPoint{OnlyContainsCACerts: true}), testCert(t, false), false, false},
+		{"attribute certs only", partitionedCRL(t, testIssuingDistributionPoint{OnlyContainsAttributeCerts: true}), testCert(t, false), false, false},
+		{"reasons only", partitionedCRL(t, testIssuingDistributionPoint{OnlySomeReasons: asn1.BitString{Bytes: []byte{0x40}, BitLength: 2}}), testCert(t, false), true, false},
+		{
+			"distribution point matches",
+			partitionedCRL(t, testIssuingDistributionPoint{DistributionPoint: users}),
+			testCert(t, false, testDistributionPoint{DistributionPoint: cas}, testDistributionPoint{DistributionPoint: users}),
+			true,
+			false,
+		},
+		{
+			"distribution point differs",
+			partitionedCRL(t, testIssuingDistributionPoint{DistributionPoint: users}),
+			testCert(t, false, testDistributionPoint{DistributionPoint: cas}),
+			false,
+			false,
+		},
+		{
+			"certificate without cRLDistributionPoints",
+			partitionedCRL(t, testIssuingDistributionPoint{DistributionPoint: users}),
+			testCert(t, false),
+			false,
+			false,
+		},
+		{
+			"cRLIssuer matches",
+			partitionedCRL(t, testIssuingDistributionPoint{DistributionPoint: testDistributionPointName{FullName: []asn1.RawValue{issuerName}}}),
+			testCert(t, false, testDistributionPoint{CRLIssuer: []asn1.RawValue{issuerName}}),
+			true,
+			false,
+		},
+		{
+			"malformed issuingDistributionPoint",
+			&x509.RevocationList{Issuer: pkix.Name{CommonName: "Test CA"}, Extensions: []pkix.Extension{{Id: idpOID, Value: []byte{0x01}}}},
+			testCert(t, false),
+			false,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Covers(tt.crl, tt.cert)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCoversAllReasons(t *testing.T) {
+	if !(*IssuingDistributionPoint)(nil).CoversAllReasons() {
+		t.Error("nil issuingDistributionPoint should cover all reasons")
+	}
+	if !(&IssuingDistributionPoint{OnlyContainsUserCerts: true}).CoversAllReasons() {
+		t.Error("issuingDistributionPoint without onlySomeReasons should cover all reasons")
+	}
+	if (&IssuingDistributionPoint{OnlySomeReasons: []string{"keyCompromise"}}).CoversAllReasons() {
+		t.Error("keyCompromise alone should not cover all reasons")
+	}
+	if !(&IssuingDistributionPoint{OnlySomeReasons: reasonFlagNames[1:]}).CoversAllReasons() {
+		t.Error("every reason should cover all reasons")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/zmap/zcrypto/x509"
 
 	"github.com/cavoq/PCL/internal/asn1"
+	"github.com/cavoq/PCL/internal/crl"
 	"github.com/cavoq/PCL/internal/node"
 	"github.com/cavoq/PCL/internal/zcrypto"
 )
@@ -25,6 +26,24 @@ func BuildTree(crl *x509.RevocationList) *node.Node {
 	return NewCRLBuilder().Build(crl)
 }
 
+// Fields lists the top-level children a CRL tree may contain.
+var Fields = []string{
+	"issuer",
+	"thisUpdate",
+	"nextUpdate",
+	"signatureAlgorithm",
+	"tbsSignatureAlgorithm",
+	"crlNumber",
+	"baseCRLNumber",
+	"freshestCRL",
+	"issuingDistributionPoint",
+	"authorityKeyIdentifier",
+	"revokedCertificates",
+	"extensions",
+	"signatureValue",
+	"isCACRL",
+}
+
 // BuildTreeWithChain builds CRL node tree with CA status determined from issuer chain.
 // isCACRL is set to true if the CRL issuer is a CA certificate (Root or Intermediate).
 func BuildTreeWithChain(crl *x509.RevocationList, issuerCerts []*x509.Certificate) *node.Node {
@@ -48,38 +67,93 @@ func BuildTreeWithChain(crl *x509.RevocationList, issuerCerts []*x509.Certificat
 	return n
 }
 
-func buildCRL(crl *x509.RevocationList) *node.Node {
+func buildCRL(revocationList *x509.RevocationList) *node.Node {
 	root := node.New("crl", nil)
 
-	root.Children["issuer"] = zcrypto.BuildPkixName("issuer", crl.Issuer)
-	root.Children["thisUpdate"] = node.New("thisUpdate", crl.ThisUpdate)
-	root.Children["nextUpdate"] = node.New("nextUpdate", crl.NextUpdate)
-	root.Children["signatureAlgorithm"] = buildSignatureAlgorithm(crl)
-	root.Children["tbsSignatureAlgorithm"] = buildTBSSignatureAlgorithm(crl)
+	root.Children["issuer"] = zcrypto.BuildName("issuer", revocationList.Issuer, revocationList.RawIssuer)
+	root.Children["thisUpdate"] = node.New("thisUpdate", revocationList.ThisUpdate)
+	root.Children["nextUpdate"] = node.New("nextUpdate", revocationList.NextUpdate)
+	root.Children["signatureAlgorithm"] = buildSignatureAlgorithm(revocationList)
+	root.Children["tbsSignatureAlgorithm"] = buildTBSSignatureAlgorithm(revocationList)
+
+	if revocationList.Number != nil {
+		root.Children["crlNumber"] = node.New("crlNumber", revocationList.Number.String())
+	}
 
-	if crl.Number != nil {
-		root.Children["crlNumber"] = node.New("crlNumber", crl.Number.String())
+	// Delta CRLs name the complete CRL they update (RFC 5280 5.2.4)
+	if baseNumber, ok := crl.BaseCRLNumber(revocationList); ok {
+		root.Children["baseCRLNumber"] = node.New("baseCRLNumber", baseNumber.String())
 	}
 
-	if len(crl.AuthorityKeyId) > 0 {
-		root.Children["authorityKeyIdentifier"] = node.New("authorityKeyIdentifier", crl.AuthorityKeyId)
+	if uris := crl.FreshestCRL(revocationList.Extensions); len(uris) > 0 {
+		freshestNode := node.New("freshestCRL", nil)
+		for i, uri := range uris {
+			freshestNode.Children[fmt.Sprintf("%d", i)] = node.New(fmt.Sprintf("%d", i), uri)
+		}
+		root.Children["freshestCRL"] = freshestNode
 	}
 
-	if len(crl.RevokedCertificates) > 0 {
-		root.Children["revokedCertificates"] = buildRevokedCertificates(crl.RevokedCertificates)
+	if idp, err := crl.IssuingDistributionPointOf(revocationList); err == nil && idp != nil {
+		root.Children["issuingDistributionPoint"] = buildIssuingDistributionPoint(idp)
 	}
 
-	if len(crl.Extensions) > 0 {
-		root.Children["extensions"] = zcrypto.BuildExtensions(crl.Extensions)
+	if len(revocationList.AuthorityKeyId) > 0 {
+		root.Children["authorityKeyIdentifier"] = node.New("authorityKeyIdentifier", revocationList.AuthorityKeyId)
 	}
 
-	if len(crl.Signature) > 0 {
-		root.Children["signatureValue"] = node.New("signatureValue", crl.Signature)
+	if len(revocationList.RevokedCertificates) > 0 {
+		root.Children["revokedCertificates"] = buildRevokedCertificates(revocationList.RevokedCertificates)
+	}
+
+	if len(revocationList.Extensions) > 0 {
+		root.Children["extensions"] = zcrypto.BuildExtensions(revocationList.Extensions)
+	}
+
+	if len(revocationList.Signature) > 0 {
+		root.Children["signatureValue"] = node.New("signatureValue", revocationList.Signature)
 	}
 
 	return root
 }
 
+// buildIssuingDistributionPoint renders the scope of a partitioned CRL
+// (RFC 5280 5.2.5).
+func buildIssuingDistributionPoint(idp *crl.IssuingDistributionPoint) *node.Node {
+	n := node.New("issuingDistributionPoint", nil)
+
+	if idp.HasDistributionPoint() {
+		dp := node.New("distributionPoint", nil)
+		if len(idp.FullName) > 0 {
+			fullName := node.New("fullName", nil)
+			for i, raw := range idp.FullName {
+				key := fmt.Sprintf("%d", i)
+				fullName.Children[key] = zcrypto.BuildGeneralName(key, raw)
+			}
+			dp.Children["fullName"] = fullName
+		}
+		if len(idp.RelativeName) > 0 {
+			dp.Children["nameRelativeToCRLIssuer"] = node.New("nameRelativeToCRLIssuer", idp.RelativeName)
+		}
+		n.Children["distributionPoint"] = dp
+	}
+
+	n.Children["onlyContainsUserCerts"] = node.New("onlyContainsUserCerts", idp.OnlyContainsUserCerts)
+	n.Children["onlyContainsCACerts"] = node.New("onlyContainsCACerts", idp.OnlyContainsCACerts)
+	n.Children["indirectCRL"] = node.New("indirectCRL", idp.IndirectCRL)
+	n.Children["onlyContainsAttributeCerts"] = node.New("onlyContainsAttributeCerts", idp.OnlyContainsAttributeCerts)
+
+	if len(idp.OnlySomeReasons) > 0 {
+		reasons := node.New("onlySomeReasons", nil)
+		for i, reason := range idp.OnlySomeReasons {
+			key := fmt.Sprintf("%d", i)
+			reasons.Children[key] = node.New(key, reason)
+		}
+		n.Children["onlySomeReasons"] = reasons
+	}
+
+	return n
+}
+
 func buildSignatureAlgorithm(crl *x509.RevocationList) *node.Node {
 	params := ParseCRLSignatureAlgorithmParams(crl.Raw)
 	n := node.New("signatureAlgorithm", nil)
@@ -4,18 +4,26 @@ import (
 	"encoding/pem"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/zmap/zcrypto/x509"
 )
 
+// crls holds the CRLs shared with the integration tests.
+const crls = "../../../tests/crls"
+
 func loadTestCRL(t *testing.T, name string) *x509.RevocationList {
 	t.Helper()
+	return loadCRLFile(t, filepath.Join("..", "testdata", name))
+}
+
+func loadCRLFile(t *testing.T, path string) *x509.RevocationList {
+	t.Helper()
 
-	path := filepath.Join("..", "testdata", name)
 	data, err := os.ReadFile(path)
 	if err != nil {
-		t.Fatalf("failed to read test CRL %s: %v", name, err)
+		t.Fatalf("failed to read test CRL %s: %v", path, err)
 	}
 
 	block, _ := pem.Decode(data)
@@ -25,7 +33,7 @@ func loadTestCRL(t *testing.T, name string) *x509.RevocationList {
 
 	crl, err := x509.ParseRevocationList(data)
 	if err != nil {
-		t.Fatalf("failed to parse test CRL %s: %v", name, err)
+		t.Fatalf("failed to parse test CRL %s: %v", path, err)
 	}
 
 	return crl
@@ -165,3 +173,84 @@ func TestBuildTree_EmptyCRL(t *testing.T) {
 		t.Error("empty CRL should not have revokedCertificates node")
 	}
 }
+
+func TestBuildTree_DeltaCRL(t *testing.T) {
+	tree := BuildTree(loadCRLFile(t, filepath.Join(crls, "delta", "delta.crl")))
+
+	baseNumber, ok := tree.Resolve("baseCRLNumber")
+	if !ok || baseNumber.Value != "10" {
+		t.Errorf("expected baseCRLNumber 10, got %v", baseNumber)
+	}
+
+	if _, ok := tree.Resolve("freshestCRL"); ok {
+		t.Error("delta CRL should not have freshestCRL node")
+	}
+}
+
+func TestBuildTree_FreshestCRL(t *testing.T) {
+	tree := BuildTree(loadCRLFile(t, filepath.Join(crls, "delta", "base.crl")))
+
+	uri, ok := tree.Resolve("freshestCRL.0")
+	if !ok || uri.Value != "http://crl.example.test/delta-root-delta.crl" {
+		t.Errorf("expected freshestCRL URI, got %v", uri)
+	}
+
+	if _, ok := tree.Resolve("baseCRLNumber"); ok {
+		t.Error("complete CRL should not have baseCRLNumber node")
+	}
+}
+
+func TestBuildTree_IssuingDistributionPoint(t *testing.T) {
+	tree := BuildTree(loadCRLFile(t, filepath.Join(crls, "partitioned", "users.crl")))
+
+	uri, ok := tree.Resolve("issuingDistributionPoint.distributionPoint.fullName.0.uniformResourceIdentifier")
+	if !ok || uri.Value != "http://crl.example.test/partitioned-users.crl" {
+		t.Errorf("expected distribution point URI, got %v", uri)
+	}
+
+	for field, want := range map[string]bool{
+		"onlyContainsUserCerts":      true,
+		"onlyContainsCACerts":        false,
+		"indirectCRL":                false,
+		"onlyContainsAttributeCerts": false,
+	} {
+		n, ok := tree.Resolve("issuingDistributionPoint." + field)
+		if !ok || n.Value != want {
+			t.Errorf("expected %s %v, got %v", field, want, n)
+		}
+	}
+
+	if _, ok := tree.Resolve("issuingDistributionPoint.onlySomeReasons"); ok {
+		t.Error("onlySomeReasons should be absent")
+	}
+
+	ext, ok := tree.Resolve("extensions.issuingDistributionPoint.critical")
+	if !ok || ext.Value != true {
+		t.Errorf("expected critical issuingDistributionPoint extension, got %v", ext)
+	}
+}
+
+func TestBuildTree_NoIssuingDistributionPoint(t *testing.T) {
+	tree := BuildTree(loadTestCRL(t, "test.crl"))
+	if _, ok := tree.Resolve("issuingDistributionPoint"); ok {
+		t.Error("CRL without the extension should not have issuingDistributionPoint node")
+	}
+}
+
+func TestBuildTree_FieldsCoverTree(t *testing.T) {
+	paths := []string{
+		filepath.Join("..", "testdata", "test.crl"),
+		filepath.Join("..", "testdata", "test_with_revoked.crl"),
+		filepath.Join(crls, "delta", "base.crl"),
+		filepath.Join(crls, "delta", "delta.crl"),
+		filepath.Join(crls, "partitioned", "users.crl"),
+	}
+	for _, path := range paths {
+		tree := BuildTreeWithChain(loadCRLFile(t, path), nil)
+		for child := range tree.Children {
+			if !slices.Contains(Fields, child) {
+				t.Errorf("%s: child %q missing from Fields", path, child)
+			}
+		}
+	}
+}
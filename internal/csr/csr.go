@@ -0,0 +1,97 @@
+// Package csr provides PKCS#10 certificate signing request data types.
+package csr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/zmap/zcrypto/x509"
+
+	fileio "github.com/cavoq/PCL/internal/io"
+	"github.com/cavoq/PCL/internal/source"
+)
+
+var extensions = []string{".csr", ".req", ".pem", ".der"}
+
+type Info struct {
+	CSR      *x509.CertificateRequest
+	FilePath string
+	Hash     string
+	Source   source.Info
+	Format   source.Format
+}
+
+func ParseCSR(data []byte) (*x509.CertificateRequest, error) {
+	csr, _, err := parseCSR(data)
+	return csr, err
+}
+
+func parseCSR(data []byte) (*x509.CertificateRequest, source.Format, error) {
+	block, _ := pem.Decode(data)
+	if block != nil && (block.Type == "CERTIFICATE REQUEST" || block.Type == "NEW CERTIFICATE REQUEST") {
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse PEM CSR: %w", err)
+		}
+		return csr, source.FormatPEM, nil
+	}
+
+	csr, err := x509.ParseCertificateRequest(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse PEM or DER CSR: %w", err)
+	}
+	return csr, source.FormatDER, nil
+}
+
+func GetCSRFiles(path string) ([]string, error) {
+	return fileio.GetFilesWithExtensions(path, extensions...)
+}
+
+func GetCSRs(path string) ([]*Info, error) {
+	files, err := GetCSRFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*Info, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		info, err := NewInfo(data, file, source.Info{Type: source.Local})
+		if err != nil {
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	if len(infos) == 0 && len(files) > 0 {
+		return nil, fmt.Errorf("no valid items found in %s", path)
+	}
+
+	return infos, nil
+}
+
+// NewInfo parses a PEM or DER CSR held in memory. The name is reported as
+// the CSR's file path in lint results.
+func NewInfo(data []byte, name string, sourceInfo source.Info) (*Info, error) {
+	csr, format, err := parseCSR(data)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(csr.Raw)
+	sourceInfo.Format = format
+	return &Info{
+		CSR:      csr,
+		FilePath: name,
+		Hash:     hex.EncodeToString(hash[:]),
+		Source:   sourceInfo,
+		Format:   format,
+	}, nil
+}
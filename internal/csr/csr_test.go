@@ -0,0 +1,77 @@
+package csr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cavoq/PCL/internal/loader"
+	"github.com/cavoq/PCL/internal/source"
+)
+
+const testdata = "../../tests/csrs"
+
+func TestParseCSR_PEM(t *testing.T) {
+	csr, err := loader.Load(filepath.Join(testdata, "csr.pem"), ParseCSR)
+	if err != nil {
+		t.Fatalf("failed to load PEM CSR: %v", err)
+	}
+	if csr.Subject.CommonName != "csr.example.test" {
+		t.Errorf("expected subject CN 'csr.example.test', got %q", csr.Subject.CommonName)
+	}
+}
+
+func TestParseCSR_DER(t *testing.T) {
+	csr, err := loader.Load(filepath.Join(testdata, "csr.der"), ParseCSR)
+	if err != nil {
+		t.Fatalf("failed to load DER CSR: %v", err)
+	}
+	if len(csr.DNSNames) != 2 {
+		t.Errorf("expected 2 requested DNS names, got %d", len(csr.DNSNames))
+	}
+}
+
+func TestParseCSR_Invalid(t *testing.T) {
+	if _, err := ParseCSR([]byte("not a CSR")); err == nil {
+		t.Fatal("expected error for invalid CSR data")
+	}
+}
+
+func TestGetCSRs(t *testing.T) {
+	infos, err := GetCSRs(testdata)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(infos) != 3 {
+		t.Fatalf("expected 3 CSRs, got %d", len(infos))
+	}
+	for _, info := range infos {
+		if info.Hash == "" || info.Source.Type != source.Local {
+			t.Errorf("%s: hash %q, source %q", info.FilePath, info.Hash, info.Source.Type)
+		}
+	}
+}
+
+func TestGetCSRs_NoValidItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.csr")
+	if err := os.WriteFile(path, []byte("not a CSR"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetCSRs(path); err == nil {
+		t.Fatal("expected error when no file parses")
+	}
+}
+
+func TestNewInfo(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(testdata, "csr.der"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := NewInfo(data, "request", source.Info{Type: source.Memory})
+	if err != nil {
+		t.Fatalf("NewInfo: %v", err)
+	}
+	if info.FilePath != "request" || info.Format != source.FormatDER || info.Source.Format != source.FormatDER {
+		t.Errorf("unexpected info: %+v", info)
+	}
+}
@@ -0,0 +1,213 @@
+// Package zcrypto provides zcrypto-based CSR parsing.
+package zcrypto
+
+import (
+	"fmt"
+
+	"github.com/zmap/zcrypto/x509"
+
+	certzcrypto "github.com/cavoq/PCL/internal/cert/zcrypto"
+	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/oid"
+	"github.com/cavoq/PCL/internal/zcrypto"
+)
+
+type CSRBuilder struct{}
+
+func NewCSRBuilder() *CSRBuilder {
+	return &CSRBuilder{}
+}
+
+func (b *CSRBuilder) Build(csr *x509.CertificateRequest) *node.Node {
+	return buildCSR(csr)
+}
+
+func BuildTree(csr *x509.CertificateRequest) *node.Node {
+	return NewCSRBuilder().Build(csr)
+}
+
+// Fields lists the top-level children a CSR tree may contain.
+var Fields = []string{
+	"version",
+	"subject",
+	"subjectPublicKeyInfo",
+	"attributes",
+	"challengePassword",
+	"unstructuredName",
+	"extensions",
+	"keyUsage",
+	"extKeyUsage",
+	"basicConstraints",
+	"subjectAltName",
+	"signatureAlgorithm",
+	"signatureValue",
+	"signatureValid",
+}
+
+// PKCS#9 attribute OIDs (RFC 2985)
+const (
+	oidChallengePassword = "1.2.840.113549.1.9.7"
+	oidUnstructuredName  = "1.2.840.113549.1.9.2"
+	oidExtensionRequest  = "1.2.840.113549.1.9.14"
+)
+
+var attributeNames = map[string]string{
+	oidChallengePassword: "challengePassword",
+	oidUnstructuredName:  "unstructuredName",
+	oidExtensionRequest:  "extensionRequest",
+}
+
+func buildCSR(csr *x509.CertificateRequest) *node.Node {
+	root := node.New("csr", nil)
+
+	root.Children["version"] = node.New("version", csr.Version)
+	root.Children["subject"] = zcrypto.BuildName("subject", csr.Subject, csr.RawSubject)
+	root.Children["subjectPublicKeyInfo"] = certzcrypto.BuildSubjectPublicKeyInfo(csr.PublicKeyAlgorithm, csr.RawSubjectPublicKeyInfo, csr.PublicKey)
+
+	if attrs := ParseAttributes(csr.RawTBSCertificateRequest); len(attrs) > 0 {
+		root.Children["attributes"] = buildAttributes(attrs)
+		for _, a := range attrs {
+			switch a.OID {
+			case oidChallengePassword, oidUnstructuredName:
+				if len(a.Values) > 0 {
+					name := attributeNames[a.OID]
+					root.Children[name] = zcrypto.BuildValue(name, a.Values[0])
+				}
+			}
+		}
+	}
+
+	// Requested extensions (extensionRequest attribute)
+	if len(csr.Extensions) > 0 {
+		root.Children["extensions"] = zcrypto.BuildExtensions(csr.Extensions)
+		buildRequestedExtensions(root, csr)
+	}
+
+	// Signature: the request is self-signed as proof of possession
+	params := certzcrypto.ParseCertSignatureAlgorithmParams(csr.Raw)
+	root.Children["signatureAlgorithm"] = certzcrypto.BuildSignatureAlgorithm("signatureAlgorithm", csr.SignatureAlgorithm, params.OID, params)
+	if len(csr.Signature) > 0 {
+		root.Children["signatureValue"] = buildSignatureValue(csr.Signature, params.OID)
+	}
+
+	// Proof of possession: verified with the requested public key
+	if valid := zcrypto.BuildSignatureValid(csr.CheckSignature()); valid != nil {
+		root.Children["signatureValid"] = valid
+	}
+
+	return root
+}
+
+func buildAttributes(attrs []Attribute) *node.Node {
+	n := node.New("attributes", nil)
+
+	for i, a := range attrs {
+		an := node.New(fmt.Sprintf("%d", i), nil)
+		an.Children["oid"] = node.New("oid", a.OID)
+		if name, ok := attributeNames[a.OID]; ok {
+			an.Children["type"] = node.New("type", name)
+		}
+
+		values := node.New("values", nil)
+		for j, v := range a.Values {
+			key := fmt.Sprintf("%d", j)
+			values.Children[key] = zcrypto.BuildValue(key, v)
+		}
+		an.Children["values"] = values
+
+		n.Children[an.Name] = an
+	}
+
+	return n
+}
+
+// buildRequestedExtensions decodes the requested extensions policies most
+// often check into the same nodes a certificate tree has.
+func buildRequestedExtensions(root *node.Node, csr *x509.CertificateRequest) {
+	for _, ext := range csr.Extensions {
+		switch ext.Id.String() {
+		case "2.5.29.15":
+			if ku, ok := ParseKeyUsage(ext.Value); ok {
+				root.Children["keyUsage"] = certzcrypto.BuildKeyUsage(ku)
+			}
+		case "2.5.29.37":
+			if oids, ok := ParseExtKeyUsage(ext.Value); ok {
+				root.Children["extKeyUsage"] = buildExtKeyUsage(oids)
+			}
+		case "2.5.29.19":
+			if bc, ok := ParseBasicConstraints(ext.Value); ok {
+				n := node.New("basicConstraints", nil)
+				n.Children["cA"] = node.New("cA", bc.IsCA)
+				if bc.MaxPathLen >= 0 {
+					n.Children["pathLenConstraint"] = node.New("pathLenConstraint", bc.MaxPathLen)
+				}
+				root.Children["basicConstraints"] = n
+			}
+		}
+	}
+
+	if len(csr.DNSNames) > 0 || len(csr.EmailAddresses) > 0 || len(csr.IPAddresses) > 0 {
+		root.Children["subjectAltName"] = buildSubjectAltName(csr)
+	}
+}
+
+// buildExtKeyUsage names the known purposes like a certificate tree and keys
+// any other purpose by its OID.
+func buildExtKeyUsage(oids []string) *node.Node {
+	var known []x509.ExtKeyUsage
+	var other []string
+	for _, o := range oids {
+		if eku, ok := oid.ExtKeyUsageFromOID(o); ok {
+			known = append(known, eku)
+		} else {
+			other = append(other, o)
+		}
+	}
+
+	n := certzcrypto.BuildExtKeyUsage(known)
+	for _, o := range other {
+		n.Children[o] = node.New(o, true)
+	}
+	return n
+}
+
+func buildSubjectAltName(csr *x509.CertificateRequest) *node.Node {
+	n := node.New("subjectAltName", nil)
+
+	if len(csr.DNSNames) > 0 {
+		dnsNode := node.New("dNSName", nil)
+		for i, dns := range csr.DNSNames {
+			dnsNode.Children[fmt.Sprintf("%d", i)] = node.New(fmt.Sprintf("%d", i), dns)
+		}
+		n.Children["dNSName"] = dnsNode
+	}
+
+	if len(csr.EmailAddresses) > 0 {
+		emailNode := node.New("rfc822Name", nil)
+		for i, email := range csr.EmailAddresses {
+			emailNode.Children[fmt.Sprintf("%d", i)] = node.New(fmt.Sprintf("%d", i), email)
+		}
+		n.Children["rfc822Name"] = emailNode
+	}
+
+	if len(csr.IPAddresses) > 0 {
+		ipNode := node.New("iPAddress", nil)
+		for i, ip := range csr.IPAddresses {
+			ipNode.Children[fmt.Sprintf("%d", i)] = node.New(fmt.Sprintf("%d", i), ip.String())
+		}
+		n.Children["iPAddress"] = ipNode
+	}
+
+	return n
+}
+
+// buildSignatureValue adds the signature length for post-quantum algorithms,
+// as the certificate tree does.
+func buildSignatureValue(signature []byte, algorithmOID string) *node.Node {
+	n := node.New("signatureValue", signature)
+	if alg, ok := oid.LookupPQC(algorithmOID); ok {
+		n.Children["length"] = node.New("length", len(signature))
+		n.Children["lengthValid"] = node.New("lengthValid", alg.ValidSignatureLength(len(signature)))
+	}
+	return n
+}
@@ -0,0 +1,121 @@
+package zcrypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	stdx509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/node/nodetest"
+)
+
+const testdata = "../../../tests/csrs"
+
+func loadCSR(t *testing.T, name string) *x509.CertificateRequest {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(testdata, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatalf("no PEM block in %s", name)
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return csr
+}
+
+func TestBuildTree(t *testing.T) {
+	tree := BuildTree(loadCSR(t, "csr.pem"))
+
+	nodetest.AssertPathValue(t, tree, "version", 0)
+	nodetest.AssertPathValue(t, tree, "subject.commonName", "csr.example.test")
+	nodetest.AssertPathValue(t, tree, "subject.countryName.stringType", "PrintableString")
+	nodetest.AssertPathValue(t, tree, "subjectPublicKeyInfo.algorithm.algorithm", "ECDSA")
+	nodetest.AssertPathValue(t, tree, "subjectPublicKeyInfo.publicKey.curve", "P-256")
+	nodetest.AssertPathValue(t, tree, "signatureAlgorithm.algorithm", "ECDSA-SHA256")
+	nodetest.AssertPathValue(t, tree, "signatureAlgorithm.oid", "1.2.840.10045.4.3.2")
+	nodetest.AssertPathValue(t, tree, "signatureValid", true)
+
+	nodetest.AssertPathValue(t, tree, "challengePassword", "s3cretPassw0rd")
+	nodetest.AssertPathValue(t, tree, "challengePassword.stringType", "PrintableString")
+	nodetest.AssertPathValue(t, tree, "attributes.0.type", "challengePassword")
+	nodetest.AssertPathValue(t, tree, "attributes.1.type", "extensionRequest")
+
+	nodetest.AssertPathValue(t, tree, "extensions.keyUsage.critical", true)
+	nodetest.AssertPathValue(t, tree, "keyUsage.digitalSignature", true)
+	nodetest.AssertPathValue(t, tree, "keyUsage.keyEncipherment", true)
+	nodetest.AssertPathNotExists(t, tree, "keyUsage.keyCertSign")
+	nodetest.AssertPathValue(t, tree, "extKeyUsage.serverAuth", true)
+	nodetest.AssertPathValue(t, tree, "extKeyUsage.clientAuth", true)
+	nodetest.AssertPathValue(t, tree, "basicConstraints.cA", false)
+	nodetest.AssertPathNotExists(t, tree, "basicConstraints.pathLenConstraint")
+	nodetest.AssertPathValue(t, tree, "subjectAltName.dNSName.1", "www.csr.example.test")
+}
+
+func TestBuildTreeBadCSR(t *testing.T) {
+	tree := BuildTree(loadCSR(t, "csr-bad.pem"))
+
+	nodetest.AssertPathValue(t, tree, "challengePassword", "pässwort")
+	nodetest.AssertPathValue(t, tree, "challengePassword.stringType", "UTF8String")
+	nodetest.AssertPathValue(t, tree, "keyUsage.keyCertSign", true)
+	nodetest.AssertPathValue(t, tree, "basicConstraints.cA", true)
+	nodetest.AssertPathValue(t, tree, "extensions.basicConstraints.critical", true)
+	nodetest.AssertPathNotExists(t, tree, "extKeyUsage")
+}
+
+func TestBuildTreeMinimal(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := stdx509.CreateCertificateRequest(rand.Reader, &stdx509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "minimal"},
+		ExtraExtensions: []pkix.Extension{
+			{Id: asn1.ObjectIdentifier{2, 5, 29, 37}, Value: mustMarshal(t, []asn1.ObjectIdentifier{{1, 3, 6, 1, 4, 1, 99999, 1}})},
+		},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := BuildTree(csr)
+	nodetest.AssertPathValue(t, tree, "extKeyUsage.1.3.6.1.4.1.99999.1", true)
+	nodetest.AssertPathNotExists(t, tree, "challengePassword")
+	nodetest.AssertPathNotExists(t, tree, "keyUsage")
+	nodetest.AssertPathNotExists(t, tree, "subjectAltName")
+
+	// A corrupted signature no longer verifies
+	csr.Signature[len(csr.Signature)-1] ^= 0xff
+	nodetest.AssertPathValue(t, BuildTree(csr), "signatureValid", false)
+}
+
+func TestParseAttributesMalformed(t *testing.T) {
+	if attrs := ParseAttributes([]byte{0x30, 0x03, 0x02, 0x01, 0x00}); attrs != nil {
+		t.Errorf("expected no attributes, got %v", attrs)
+	}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := asn1.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,125 @@
+package zcrypto
+
+import (
+	"encoding/asn1"
+
+	"github.com/zmap/zcrypto/x509"
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// Attribute is one attribute of a CertificationRequestInfo with its values.
+type Attribute struct {
+	OID    string
+	Values []asn1.RawValue
+}
+
+// ParseAttributes parses the attributes of a CertificationRequestInfo.
+// zcrypto only keeps attributes whose values are AttributeTypeAndValue
+// sets, which drops challengePassword and unstructuredName.
+//
+// ASN.1 structure (RFC 2986 4.1):
+//
+//	CertificationRequestInfo ::= SEQUENCE {
+//	    version       INTEGER { v1(0) },
+//	    subject       Name,
+//	    subjectPKInfo SubjectPublicKeyInfo,
+//	    attributes    [0] IMPLICIT SET OF Attribute }
+//	Attribute ::= SEQUENCE {
+//	    type   OBJECT IDENTIFIER,
+//	    values SET OF ANY }
+func ParseAttributes(rawTBSCertificateRequest []byte) []Attribute {
+	input := cryptobyte.String(rawTBSCertificateRequest)
+
+	var info cryptobyte.String
+	if !input.ReadASN1(&info, cryptobyte_asn1.SEQUENCE) {
+		return nil
+	}
+
+	// Skip version, subject and subjectPKInfo
+	if !info.SkipASN1(cryptobyte_asn1.INTEGER) ||
+		!info.SkipASN1(cryptobyte_asn1.SEQUENCE) ||
+		!info.SkipASN1(cryptobyte_asn1.SEQUENCE) {
+		return nil
+	}
+
+	var attrs cryptobyte.String
+	if !info.ReadASN1(&attrs, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) {
+		return nil
+	}
+
+	var out []Attribute
+	for !attrs.Empty() {
+		var attr, values cryptobyte.String
+		var oid asn1.ObjectIdentifier
+		if !attrs.ReadASN1(&attr, cryptobyte_asn1.SEQUENCE) ||
+			!attr.ReadASN1ObjectIdentifier(&oid) ||
+			!attr.ReadASN1(&values, cryptobyte_asn1.SET) {
+			break
+		}
+
+		a := Attribute{OID: oid.String()}
+		for !values.Empty() {
+			var element cryptobyte.String
+			var tag cryptobyte_asn1.Tag
+			if !values.ReadAnyASN1Element(&element, &tag) {
+				break
+			}
+			var v asn1.RawValue
+			if _, err := asn1.Unmarshal(element, &v); err != nil {
+				break
+			}
+			a.Values = append(a.Values, v)
+		}
+		out = append(out, a)
+	}
+
+	return out
+}
+
+// ParseKeyUsage decodes a requested keyUsage extension value.
+func ParseKeyUsage(value []byte) (x509.KeyUsage, bool) {
+	var bits asn1.BitString
+	if rest, err := asn1.Unmarshal(value, &bits); err != nil || len(rest) > 0 {
+		return 0, false
+	}
+
+	var usage x509.KeyUsage
+	for i := 0; i < 9; i++ {
+		if bits.At(i) != 0 {
+			usage |= 1 << uint(i)
+		}
+	}
+	return usage, true
+}
+
+// ParseExtKeyUsage decodes a requested extKeyUsage extension value into its
+// purpose OIDs.
+func ParseExtKeyUsage(value []byte) ([]string, bool) {
+	var oids []asn1.ObjectIdentifier
+	if rest, err := asn1.Unmarshal(value, &oids); err != nil || len(rest) > 0 {
+		return nil, false
+	}
+
+	out := make([]string, 0, len(oids))
+	for _, oid := range oids {
+		out = append(out, oid.String())
+	}
+	return out, true
+}
+
+// BasicConstraints is a decoded basicConstraints extension. MaxPathLen is
+// -1 when pathLenConstraint is absent.
+type BasicConstraints struct {
+	IsCA       bool `asn1:"optional"`
+	MaxPathLen int  `asn1:"optional,default:-1"`
+}
+
+// ParseBasicConstraints decodes a requested basicConstraints extension value.
+func ParseBasicConstraints(value []byte) (BasicConstraints, bool) {
+	var bc BasicConstraints
+	if rest, err := asn1.Unmarshal(value, &bc); err != nil || len(rest) > 0 {
+		return BasicConstraints{}, false
+	}
+	return bc, true
+}
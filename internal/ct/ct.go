@@ -0,0 +1,180 @@
+// Package ct verifies Certificate Transparency signed certificate timestamps
+// embedded in certificates (RFC 6962).
+package ct
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	zct "github.com/zmap/zcrypto/x509/ct"
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// DER contents of the embedded SCT list extension OID,
+// 1.3.6.1.4.1.11129.2.4.2 (RFC 6962 3.3), the precertificate poison
+// extension OID, 1.3.6.1.4.1.11129.2.4.3 (RFC 6962 3.1), and the
+// authorityKeyIdentifier OID, 2.5.29.35.
+var (
+	oidSCTList = []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0xd6, 0x79, 0x02, 0x04, 0x02}
+	oidPoison  = []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0xd6, 0x79, 0x02, 0x04, 0x03}
+	oidAKI     = []byte{0x55, 0x1d, 0x23}
+)
+
+// PrecertTBS reconstructs the TBSCertificate the log signed for a
+// certificate with embedded SCTs: the certificate's TBSCertificate without
+// the SCT list extension (RFC 6962 3.2).
+func PrecertTBS(rawTBS []byte) ([]byte, error) {
+	input := cryptobyte.String(rawTBS)
+	var tbs cryptobyte.String
+	if !input.ReadASN1(&tbs, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("malformed TBSCertificate")
+	}
+
+	var out cryptobyte.Builder
+	var found bool
+	out.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		for !tbs.Empty() {
+			var field cryptobyte.String
+			var tag cryptobyte_asn1.Tag
+			if !tbs.ReadAnyASN1Element(&field, &tag) {
+				b.SetError(errors.New("malformed TBSCertificate"))
+				return
+			}
+			if tag != cryptobyte_asn1.Tag(3).Constructed().ContextSpecific() {
+				b.AddBytes(field)
+				continue
+			}
+			exts, err := withoutExtension(field, oidSCTList)
+			if err != nil {
+				b.SetError(err)
+				return
+			}
+			found = exts != nil
+			if len(exts) > 0 {
+				b.AddASN1(tag, func(b *cryptobyte.Builder) {
+					b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+						b.AddBytes(exts)
+					})
+				})
+			}
+		}
+	})
+
+	der, err := out.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("no SCT list extension")
+	}
+	return der, nil
+}
+
+// withoutExtension returns the encoded extensions of an [3] Extensions
+// field other than the one with the given OID, or nil if there is no such
+// extension.
+func withoutExtension(field cryptobyte.String, oid []byte) ([]byte, error) {
+	var explicit, exts cryptobyte.String
+	if !field.ReadASN1(&explicit, cryptobyte_asn1.Tag(3).Constructed().ContextSpecific()) ||
+		!explicit.ReadASN1(&exts, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("malformed extensions")
+	}
+
+	kept := []byte{}
+	found := false
+	for !exts.Empty() {
+		var ext cryptobyte.String
+		if !exts.ReadASN1Element(&ext, cryptobyte_asn1.SEQUENCE) {
+			return nil, errors.New("malformed extension")
+		}
+		inner := ext
+		var body cryptobyte.String
+		var id cryptobyte.String
+		if !inner.ReadASN1(&body, cryptobyte_asn1.SEQUENCE) || !body.ReadASN1(&id, cryptobyte_asn1.OBJECT_IDENTIFIER) {
+			return nil, errors.New("malformed extension")
+		}
+		if string(id) == string(oid) {
+			found = true
+			continue
+		}
+		kept = append(kept, ext...)
+	}
+	if !found {
+		return nil, nil
+	}
+	return kept, nil
+}
+
+// SignedData returns the data a log signs for an SCT over a precertificate
+// (RFC 6962 3.2, digitally-signed struct with entry_type precert_entry).
+func SignedData(sct *zct.SignedCertificateTimestamp, issuerSPKI, precertTBS []byte) []byte {
+	var b cryptobyte.Builder
+	b.AddUint8(uint8(sct.SCTVersion))
+	b.AddUint8(0) // signature_type: certificate_timestamp
+	b.AddUint64(sct.Timestamp)
+	b.AddUint16(1) // entry_type: precert_entry
+	issuerKeyHash := sha256.Sum256(issuerSPKI)
+	b.AddBytes(issuerKeyHash[:])
+	b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(precertTBS) })
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(sct.Extensions) })
+	return b.BytesOrPanic()
+}
+
+// X509SignedData returns the data a log signs for an SCT over a final
+// certificate, as delivered in the TLS extension or an OCSP response
+// (RFC 6962 3.2, digitally-signed struct with entry_type x509_entry).
+func X509SignedData(sct *zct.SignedCertificateTimestamp, certDER []byte) []byte {
+	var b cryptobyte.Builder
+	b.AddUint8(uint8(sct.SCTVersion))
+	b.AddUint8(0) // signature_type: certificate_timestamp
+	b.AddUint64(sct.Timestamp)
+	b.AddUint16(0) // entry_type: x509_entry
+	b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(certDER) })
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(sct.Extensions) })
+	return b.BytesOrPanic()
+}
+
+// Verify checks the signature of an SCT with the log's DER
+// SubjectPublicKeyInfo. Logs sign with ECDSA P-256 or RSA, both over
+// SHA-256 (RFC 6962 2.1.4).
+func Verify(sct *zct.SignedCertificateTimestamp, logKey, signed []byte) error {
+	if sct.SCTVersion != zct.V1 {
+		return fmt.Errorf("unsupported SCT version %d", sct.SCTVersion)
+	}
+	if sct.Signature.HashAlgorithm != zct.SHA256 {
+		return fmt.Errorf("unsupported SCT hash algorithm %s", sct.Signature.HashAlgorithm)
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(logKey)
+	if err != nil {
+		return fmt.Errorf("invalid log key: %w", err)
+	}
+	digest := sha256.Sum256(signed)
+
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		if sct.Signature.SignatureAlgorithm != zct.ECDSA {
+			return fmt.Errorf("signature algorithm %s does not match the ECDSA log key", sct.Signature.SignatureAlgorithm)
+		}
+		if !ecdsa.VerifyASN1(key, digest[:], sct.Signature.Signature) {
+			return errors.New("signature verification failed")
+		}
+		return nil
+	case *rsa.PublicKey:
+		if sct.Signature.SignatureAlgorithm != zct.RSA {
+			return fmt.Errorf("signature algorithm %s does not match the RSA log key", sct.Signature.SignatureAlgorithm)
+		}
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sct.Signature.Signature); err != nil {
+			return errors.New("signature verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported log key type %T", pub)
+	}
+}
@@ -0,0 +1,193 @@
+package ct
+
+import (
+	"bytes"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/data"
+)
+
+const (
+	certs = "../../tests/certs"
+	lists = "../../tests/scts"
+	logs  = "../../tests/data"
+)
+
+func loadCert(t *testing.T, name string) *x509.Certificate {
+	t.Helper()
+	block, _ := pem.Decode(readFile(t, filepath.Join(certs, name)))
+	if block == nil {
+		t.Fatalf("no PEM block in %s", name)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return cert
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func loadLogs(t *testing.T) *data.Loader {
+	t.Helper()
+	l := &data.Loader{}
+	if err := l.LoadCTLogs(filepath.Join(logs, "ct_log_list.json")); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestPrecertTBS(t *testing.T) {
+	leaf := loadCert(t, "sct-leaf.pem")
+
+	precert, err := PrecertTBS(leaf.RawTBSCertificate)
+	if err != nil {
+		t.Fatalf("PrecertTBS: %v", err)
+	}
+	if len(precert) >= len(leaf.RawTBSCertificate) {
+		t.Errorf("precertificate TBS is %d bytes, want fewer than %d", len(precert), len(leaf.RawTBSCertificate))
+	}
+	if bytes.Contains(precert, oidSCTList) {
+		t.Error("SCT list extension still present")
+	}
+
+	root := loadCert(t, "sct-root.pem")
+	if _, err := PrecertTBS(root.RawTBSCertificate); err == nil {
+		t.Error("expected error for a certificate without SCTs")
+	}
+	if _, err := PrecertTBS([]byte{0x30, 0x05}); err == nil {
+		t.Error("expected error for a truncated TBSCertificate")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	logs := loadLogs(t)
+	root := loadCert(t, "sct-root.pem")
+
+	tests := []struct {
+		file  string
+		valid []bool
+	}{
+		{"sct-leaf.pem", []bool{true, true}},
+		{"sct-bad-leaf.pem", []bool{true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			leaf := loadCert(t, tt.file)
+			precert, err := PrecertTBS(leaf.RawTBSCertificate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range tt.valid {
+				sct := leaf.SignedCertificateTimestampList[i]
+				log := logs.CTLog(sct.LogID)
+				if log == nil {
+					t.Fatalf("SCT %d: log not in the list", i)
+				}
+				err := Verify(sct, log.Key, SignedData(sct, root.RawSubjectPublicKeyInfo, precert))
+				if got := err == nil; got != want {
+					t.Errorf("SCT %d (%s): valid = %v (%v), want %v", i, log.Description, got, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyWrongIssuer(t *testing.T) {
+	logs := loadLogs(t)
+	leaf := loadCert(t, "sct-leaf.pem")
+	precert, err := PrecertTBS(leaf.RawTBSCertificate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sct := leaf.SignedCertificateTimestampList[0]
+	signed := SignedData(sct, leaf.RawSubjectPublicKeyInfo, precert)
+	if err := Verify(sct, logs.CTLog(sct.LogID).Key, signed); err == nil {
+		t.Error("expected failure when the issuer key hash does not match")
+	}
+}
+
+func TestParseSCTList(t *testing.T) {
+	scts, err := ParseSCTList(readFile(t, filepath.Join(lists, "ct-tls-leaf.sct")))
+	if err != nil {
+		t.Fatalf("ParseSCTList: %v", err)
+	}
+	if len(scts) != 2 {
+		t.Fatalf("got %d SCTs, want 2", len(scts))
+	}
+
+	for _, raw := range [][]byte{nil, {0x00, 0x00}, {0x00, 0x05, 0x00}, append(readFile(t, filepath.Join(lists, "ct-tls-leaf.sct")), 0x00)} {
+		if _, err := ParseSCTList(raw); err == nil {
+			t.Errorf("expected error for %x", raw)
+		}
+	}
+}
+
+func TestVerifyX509Entry(t *testing.T) {
+	logs := loadLogs(t)
+	leaf := loadCert(t, "ct-tls-leaf.pem")
+	scts, err := ParseSCTList(readFile(t, filepath.Join(lists, "ct-tls-leaf.sct")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, sct := range scts {
+		log := logs.CTLog(sct.LogID)
+		if log == nil {
+			t.Fatalf("SCT %d: log not in the list", i)
+		}
+		if err := Verify(sct, log.Key, X509SignedData(sct, leaf.Raw)); err != nil {
+			t.Errorf("SCT %d (%s): %v", i, log.Description, err)
+		}
+		if err := Verify(sct, log.Key, X509SignedData(sct, loadCert(t, "ct-final.pem").Raw)); err == nil {
+			t.Errorf("SCT %d: expected failure for another certificate", i)
+		}
+	}
+}
+
+func TestTBSDifferences(t *testing.T) {
+	final := loadCert(t, "ct-final.pem")
+	signing := loadCert(t, "ct-precert-signing.pem")
+
+	tests := []struct {
+		name          string
+		precert       string
+		signingIssuer []byte
+		want          []string
+	}{
+		{"matching", "ct-precert.pem", nil, nil},
+		{"precert signing certificate", "ct-precert-delegated.pem", signing.RawIssuer, nil},
+		{"precert signing certificate ignored", "ct-precert-delegated.pem", nil, []string{"issuer", "extensions"}},
+		{"different subject", "ct-precert-bad.pem", nil, []string{"subject", "extensions"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			precert := loadCert(t, tt.precert)
+			got, err := TBSDifferences(precert.RawTBSCertificate, final.RawTBSCertificate, tt.signingIssuer)
+			if err != nil {
+				t.Fatalf("TBSDifferences: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := TBSDifferences([]byte{0x30, 0x00}, final.RawTBSCertificate, nil); err == nil {
+		t.Error("expected error for a malformed TBSCertificate")
+	}
+}
@@ -0,0 +1,43 @@
+package ct
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	zct "github.com/zmap/zcrypto/x509/ct"
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// ParseSCTList decodes a TLS-encoded SignedCertificateTimestampList, the
+// form SCTs take in the TLS extension and, wrapped in an OCTET STRING, in
+// certificate and OCSP extensions (RFC 6962 3.3).
+//
+//	opaque SerializedSCT<1..2^16-1>;
+//	struct {
+//	    SerializedSCT sct_list <1..2^16-1>;
+//	} SignedCertificateTimestampList;
+func ParseSCTList(raw []byte) ([]*zct.SignedCertificateTimestamp, error) {
+	input := cryptobyte.String(raw)
+	var list cryptobyte.String
+	if !input.ReadUint16LengthPrefixed(&list) || !input.Empty() {
+		return nil, errors.New("malformed SCT list")
+	}
+	if list.Empty() {
+		return nil, errors.New("empty SCT list")
+	}
+
+	var scts []*zct.SignedCertificateTimestamp
+	for !list.Empty() {
+		var serialized cryptobyte.String
+		if !list.ReadUint16LengthPrefixed(&serialized) {
+			return nil, errors.New("malformed SCT list")
+		}
+		sct, err := zct.DeserializeSCT(bytes.NewReader(serialized))
+		if err != nil {
+			return nil, fmt.Errorf("SCT %d: %w", len(scts), err)
+		}
+		scts = append(scts, sct)
+	}
+	return scts, nil
+}
@@ -0,0 +1,154 @@
+package ct
+
+import (
+	"bytes"
+	"errors"
+
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// tbsFieldNames are the TBSCertificate fields in encoding order (RFC 5280
+// 4.1).
+var tbsFieldNames = []string{
+	"version",
+	"serialNumber",
+	"signature",
+	"issuer",
+	"validity",
+	"subject",
+	"subjectPublicKeyInfo",
+	"issuerUniqueID",
+	"subjectUniqueID",
+	"extensions",
+}
+
+const (
+	tbsIssuer     = 3
+	tbsExtensions = 9
+)
+
+// TBSDifferences compares the TBSCertificate of a precertificate with that
+// of the final certificate issued from it and returns the names of the
+// fields that differ. The poison extension and the SCT list are not
+// compared, and the remaining extensions must appear in the same order
+// (RFC 6962 3.1).
+//
+// signingIssuer is the issuer of the Precertificate Signing Certificate
+// when one signed the precertificate, nil otherwise. The final
+// certificate's issuer is then compared with it, and authorityKeyIdentifier
+// is not compared.
+func TBSDifferences(precertTBS, finalTBS, signingIssuer []byte) ([]string, error) {
+	skip := [][]byte{oidPoison, oidSCTList}
+	if signingIssuer != nil {
+		skip = append(skip, oidAKI)
+	}
+
+	precert, err := splitTBS(precertTBS, skip)
+	if err != nil {
+		return nil, err
+	}
+	final, err := splitTBS(finalTBS, skip)
+	if err != nil {
+		return nil, err
+	}
+	if signingIssuer != nil {
+		precert[tbsIssuer] = signingIssuer
+	}
+
+	var diffs []string
+	for i, name := range tbsFieldNames {
+		if !bytes.Equal(precert[i], final[i]) {
+			diffs = append(diffs, name)
+		}
+	}
+	return diffs, nil
+}
+
+// splitTBS returns the encoded fields of a TBSCertificate, indexed like
+// tbsFieldNames. Absent optional fields are nil; the extensions field holds
+// the concatenated extensions other than those with an OID in skip.
+func splitTBS(rawTBS []byte, skip [][]byte) ([][]byte, error) {
+	errMalformed := errors.New("malformed TBSCertificate")
+
+	input := cryptobyte.String(rawTBS)
+	var tbs cryptobyte.String
+	if !input.ReadASN1(&tbs, cryptobyte_asn1.SEQUENCE) {
+		return nil, errMalformed
+	}
+
+	fields := make([][]byte, len(tbsFieldNames))
+	var field cryptobyte.String
+
+	versionTag := cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()
+	if tbs.PeekASN1Tag(versionTag) {
+		if !tbs.ReadASN1Element(&field, versionTag) {
+			return nil, errMalformed
+		}
+		fields[0] = field
+	}
+
+	required := []cryptobyte_asn1.Tag{
+		cryptobyte_asn1.INTEGER,
+		cryptobyte_asn1.SEQUENCE,
+		cryptobyte_asn1.SEQUENCE,
+		cryptobyte_asn1.SEQUENCE,
+		cryptobyte_asn1.SEQUENCE,
+		cryptobyte_asn1.SEQUENCE,
+	}
+	for i, tag := range required {
+		if !tbs.ReadASN1Element(&field, tag) {
+			return nil, errMalformed
+		}
+		fields[i+1] = field
+	}
+
+	for i, tag := range []cryptobyte_asn1.Tag{
+		cryptobyte_asn1.Tag(1).ContextSpecific(),
+		cryptobyte_asn1.Tag(2).ContextSpecific(),
+	} {
+		if tbs.PeekASN1Tag(tag) {
+			if !tbs.ReadASN1Element(&field, tag) {
+				return nil, errMalformed
+			}
+			fields[7+i] = field
+		}
+	}
+
+	extensionsTag := cryptobyte_asn1.Tag(3).Constructed().ContextSpecific()
+	if tbs.PeekASN1Tag(extensionsTag) {
+		var explicit, exts cryptobyte.String
+		if !tbs.ReadASN1(&explicit, extensionsTag) || !explicit.ReadASN1(&exts, cryptobyte_asn1.SEQUENCE) {
+			return nil, errors.New("malformed extensions")
+		}
+		kept := []byte{}
+		for !exts.Empty() {
+			var ext, body, id cryptobyte.String
+			if !exts.ReadASN1Element(&ext, cryptobyte_asn1.SEQUENCE) {
+				return nil, errors.New("malformed extension")
+			}
+			inner := ext
+			if !inner.ReadASN1(&body, cryptobyte_asn1.SEQUENCE) || !body.ReadASN1(&id, cryptobyte_asn1.OBJECT_IDENTIFIER) {
+				return nil, errors.New("malformed extension")
+			}
+			if !containsOID(skip, id) {
+				kept = append(kept, ext...)
+			}
+		}
+		fields[tbsExtensions] = kept
+	}
+
+	if !tbs.Empty() {
+		return nil, errMalformed
+	}
+	return fields, nil
+}
+
+func containsOID(oids [][]byte, id []byte) bool {
+	for _, oid := range oids {
+		if bytes.Equal(oid, id) {
+			return true
+		}
+	}
+	return false
+}
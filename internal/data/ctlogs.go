@@ -0,0 +1,154 @@
+package data
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CTLogListFile is the name of the CT log list in the data directory.
+const CTLogListFile = "ct_log_list.json"
+
+// CTLogListURL is the Chrome CT log list, downloaded by UpdateData.
+const CTLogListURL = "https://www.gstatic.com/ct/log_list/v3/log_list.json"
+
+// CTLog is a Certificate Transparency log from the log list.
+type CTLog struct {
+	Description string
+	Operator    string
+	Key         []byte // DER SubjectPublicKeyInfo
+	URL         string
+	State       string // usable, qualified, readonly, retired, rejected or pending
+}
+
+// CTLogList holds the CT logs keyed by log ID, the SHA-256 of the log key.
+type CTLogList struct {
+	Logs map[[sha256.Size]byte]*CTLog
+
+	// Metadata
+	LoadedAt   time.Time
+	SourceFile string
+}
+
+// logListV3 is the v3 log list schema shared by the Chrome and Apple lists.
+type logListV3 struct {
+	Operators []struct {
+		Name      string         `json:"name"`
+		Logs      []logListV3Log `json:"logs"`
+		TiledLogs []logListV3Log `json:"tiled_logs"`
+	} `json:"operators"`
+}
+
+type logListV3Log struct {
+	Description   string                     `json:"description"`
+	Key           string                     `json:"key"`
+	URL           string                     `json:"url"`
+	SubmissionURL string                     `json:"submission_url"`
+	State         map[string]json.RawMessage `json:"state"`
+}
+
+// LoadCTLogs loads the CT log list from file, or from CTLogListFile in the
+// data directory when filename is empty.
+func (l *Loader) LoadCTLogs(filename string) error {
+	l.ctLogsMutex.Lock()
+	defer l.ctLogsMutex.Unlock()
+
+	filePath := filename
+	if filePath == "" {
+		if l.dataDir == "" {
+			return fmt.Errorf("no CT log list specified and no data directory found")
+		}
+		filePath = filepath.Join(l.dataDir, CTLogListFile)
+	}
+
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return fmt.Errorf("CT log list not found: %s", filePath)
+	}
+
+	logs, err := parseCTLogListFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse CT log list: %w", err)
+	}
+
+	logs.SourceFile = filePath
+	logs.LoadedAt = time.Now()
+	l.ctLogs = logs
+
+	return nil
+}
+
+func parseCTLogListFile(filePath string) (*CTLogList, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var list logListV3
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+
+	logs := &CTLogList{Logs: make(map[[sha256.Size]byte]*CTLog)}
+	for _, op := range list.Operators {
+		for _, entry := range append(op.Logs, op.TiledLogs...) {
+			key, err := base64.StdEncoding.DecodeString(entry.Key)
+			if err != nil {
+				return nil, fmt.Errorf("log %q: invalid key: %w", entry.Description, err)
+			}
+			log := &CTLog{
+				Description: entry.Description,
+				Operator:    op.Name,
+				Key:         key,
+				URL:         entry.URL,
+			}
+			if log.URL == "" {
+				log.URL = entry.SubmissionURL
+			}
+			for state := range entry.State {
+				log.State = state
+			}
+			logs.Logs[sha256.Sum256(key)] = log
+		}
+	}
+
+	return logs, nil
+}
+
+// CTLogsLoaded reports whether a CT log list is loaded.
+func (l *Loader) CTLogsLoaded() bool {
+	l.ctLogsMutex.RLock()
+	defer l.ctLogsMutex.RUnlock()
+	return l.ctLogs != nil
+}
+
+// CTLog returns the log with the given log ID, or nil if the log is not in
+// the list or no list is loaded.
+func (l *Loader) CTLog(logID [sha256.Size]byte) *CTLog {
+	l.ctLogsMutex.RLock()
+	defer l.ctLogsMutex.RUnlock()
+
+	if l.ctLogs == nil {
+		return nil
+	}
+	return l.ctLogs.Logs[logID]
+}
+
+// CTLogStats returns the number of logs and log operators in the loaded list.
+func (l *Loader) CTLogStats() (logs, operators int, loaded bool) {
+	l.ctLogsMutex.RLock()
+	defer l.ctLogsMutex.RUnlock()
+
+	if l.ctLogs == nil {
+		return 0, 0, false
+	}
+
+	ops := make(map[string]bool)
+	for _, log := range l.ctLogs.Logs {
+		ops[log.Operator] = true
+	}
+	return len(l.ctLogs.Logs), len(ops), true
+}
@@ -0,0 +1,74 @@
+package data
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCTLogs(t *testing.T) {
+	keyA := []byte("log key A")
+	keyB := []byte("log key B")
+	content := `{
+  "version": "1.0",
+  "operators": [
+    {
+      "name": "Operator One",
+      "logs": [
+        {"description": "Log A", "key": "` + base64.StdEncoding.EncodeToString(keyA) + `", "url": "https://a.example/", "state": {"usable": {"timestamp": "2025-01-01T00:00:00Z"}}}
+      ],
+      "tiled_logs": [
+        {"description": "Log B", "key": "` + base64.StdEncoding.EncodeToString(keyB) + `", "submission_url": "https://b.example/", "state": {"retired": {"timestamp": "2025-01-01T00:00:00Z"}}}
+      ]
+    },
+    {"name": "Operator Two", "logs": []}
+  ]
+}`
+	path := filepath.Join(t.TempDir(), CTLogListFile)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := &Loader{}
+	if l.CTLogsLoaded() || l.CTLog(sha256.Sum256(keyA)) != nil {
+		t.Error("no log should be known before a list is loaded")
+	}
+	if err := l.LoadCTLogs(path); err != nil {
+		t.Fatalf("LoadCTLogs: %v", err)
+	}
+
+	a := l.CTLog(sha256.Sum256(keyA))
+	if a == nil || a.Description != "Log A" || a.Operator != "Operator One" || a.State != "usable" || a.URL != "https://a.example/" {
+		t.Errorf("Log A = %+v", a)
+	}
+	b := l.CTLog(sha256.Sum256(keyB))
+	if b == nil || b.State != "retired" || b.URL != "https://b.example/" {
+		t.Errorf("tiled Log B = %+v", b)
+	}
+	if l.CTLog(sha256.Sum256([]byte("other"))) != nil {
+		t.Error("unlisted log found")
+	}
+	if logs, operators, loaded := l.CTLogStats(); logs != 2 || operators != 1 || !loaded {
+		t.Errorf("CTLogStats = %d, %d, %v; want 2, 1, true", logs, operators, loaded)
+	}
+}
+
+func TestLoadCTLogsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"not json":    "not json",
+		"invalid key": `{"operators": [{"name": "x", "logs": [{"description": "bad", "key": "%%%"}]}]}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), CTLogListFile)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := (&Loader{}).LoadCTLogs(path); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
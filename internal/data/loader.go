@@ -3,6 +3,8 @@
 // Currently supports:
 //   - Public Suffix List (PSL) from publicsuffix.org
 //   - IANA Root Zone Database TLD list
+//   - Known weak key fingerprints (weak_keys.txt, user supplied)
+//   - Certificate Transparency log list (ct_log_list.json)
 //
 // Data files can be updated via:
 //   pcl --update-data
@@ -42,6 +44,12 @@ type Loader struct {
 	psl      *PSL
 	pslMutex sync.RWMutex
 
+	weakKeys      *WeakKeys
+	weakKeysMutex sync.RWMutex
+
+	ctLogs      *CTLogList
+	ctLogsMutex sync.RWMutex
+
 	// Default data directory
 	dataDir string
 }
@@ -265,15 +273,33 @@ func DownloadPSL(url string, destPath string) error {
 	if url == "" {
 		url = "https://publicsuffix.org/list/public_suffix_list.dat"
 	}
+	if err := downloadFile(url, destPath); err != nil {
+		return fmt.Errorf("failed to download PSL: %w", err)
+	}
+	return nil
+}
+
+// DownloadCTLogList downloads the CT log list, by default from CTLogListURL.
+// Any list in the v3 schema, such as Apple's, can be used instead.
+func DownloadCTLogList(url string, destPath string) error {
+	if url == "" {
+		url = CTLogListURL
+	}
+	if err := downloadFile(url, destPath); err != nil {
+		return fmt.Errorf("failed to download CT log list: %w", err)
+	}
+	return nil
+}
 
+func downloadFile(url string, destPath string) error {
 	resp, err := http.Get(url)
 	if err != nil {
-		return fmt.Errorf("failed to download PSL: %w", err)
+		return err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download PSL: HTTP %d", resp.StatusCode)
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
 
 	// Ensure destination directory exists
@@ -327,5 +353,19 @@ func UpdateData(dataDir string) error {
 	icann, private, _ := DefaultLoader.Stats()
 	fmt.Printf("Successfully loaded PSL: %d ICANN domains, %d private domains\n", icann, private)
 
+	// Download CT log list
+	ctPath := filepath.Join(dataDir, CTLogListFile)
+	fmt.Printf("Downloading CT log list to: %s\n", ctPath)
+	if err := DownloadCTLogList("", ctPath); err != nil {
+		return fmt.Errorf("failed to update CT log list: %w", err)
+	}
+
+	if err := DefaultLoader.LoadCTLogs(ctPath); err != nil {
+		return fmt.Errorf("failed to load downloaded CT log list: %w", err)
+	}
+
+	logs, operators, _ := DefaultLoader.CTLogStats()
+	fmt.Printf("Successfully loaded CT log list: %d logs from %d operators\n", logs, operators)
+
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,139 @@
+package data
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // the openssl-blacklist format is defined over SHA-1
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// WeakKeysFile is the name of the weak key list in the data directory.
+const WeakKeysFile = "weak_keys.txt"
+
+// WeakKeys holds fingerprints of publicly known weak keys.
+//
+// The file lists one fingerprint per line; blank lines and lines starting
+// with # are ignored. Two formats are recognised by length:
+//   - 64 hex digits: SHA-256 of the DER SubjectPublicKeyInfo
+//   - 20 hex digits: Debian openssl-blacklist entry, the last 80 bits of
+//     SHA-1("Modulus=<upper-case hex modulus>\n") of an RSA key
+type WeakKeys struct {
+	SPKISHA256 map[string]bool
+	DebianRSA  map[string]bool
+
+	// Metadata
+	LoadedAt   time.Time
+	SourceFile string
+}
+
+// LoadWeakKeys loads the weak key list from file, or from WeakKeysFile in
+// the data directory when filename is empty.
+func (l *Loader) LoadWeakKeys(filename string) error {
+	l.weakKeysMutex.Lock()
+	defer l.weakKeysMutex.Unlock()
+
+	filePath := filename
+	if filePath == "" {
+		if l.dataDir == "" {
+			return fmt.Errorf("no weak key file specified and no data directory found")
+		}
+		filePath = filepath.Join(l.dataDir, WeakKeysFile)
+	}
+
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return fmt.Errorf("weak key file not found: %s", filePath)
+	}
+
+	wk, err := parseWeakKeysFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse weak key file: %w", err)
+	}
+
+	wk.SourceFile = filePath
+	wk.LoadedAt = time.Now()
+	l.weakKeys = wk
+
+	return nil
+}
+
+func parseWeakKeysFile(filePath string) (*WeakKeys, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	wk := &WeakKeys{
+		SPKISHA256: make(map[string]bool),
+		DebianRSA:  make(map[string]bool),
+	}
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := hex.DecodeString(line); err != nil {
+			return nil, fmt.Errorf("line %d: not a hex fingerprint", lineNo)
+		}
+
+		switch len(line) {
+		case 64:
+			wk.SPKISHA256[line] = true
+		case 20:
+			wk.DebianRSA[line] = true
+		default:
+			return nil, fmt.Errorf("line %d: fingerprint has %d hex digits, want 64 or 20", lineNo, len(line))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return wk, nil
+}
+
+// WeakKeysLoaded reports whether a weak key list is loaded.
+func (l *Loader) WeakKeysLoaded() bool {
+	l.weakKeysMutex.RLock()
+	defer l.weakKeysMutex.RUnlock()
+	return l.weakKeys != nil
+}
+
+// IsWeakKey checks a key against the loaded weak key list. spki is the DER
+// SubjectPublicKeyInfo; modulus is the RSA modulus, or nil for other keys.
+// Returns false if no list is loaded.
+func (l *Loader) IsWeakKey(spki []byte, modulus *big.Int) bool {
+	l.weakKeysMutex.RLock()
+	defer l.weakKeysMutex.RUnlock()
+
+	if l.weakKeys == nil {
+		return false
+	}
+
+	sum := sha256.Sum256(spki)
+	if l.weakKeys.SPKISHA256[hex.EncodeToString(sum[:])] {
+		return true
+	}
+	if modulus != nil {
+		return l.weakKeys.DebianRSA[DebianFingerprint(modulus)]
+	}
+	return false
+}
+
+// DebianFingerprint returns the openssl-blacklist fingerprint of an RSA
+// modulus.
+func DebianFingerprint(modulus *big.Int) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("Modulus=%X\n", modulus))) //nolint:gosec // fingerprint, not a signature
+	return hex.EncodeToString(sum[:])[20:]
+}
@@ -0,0 +1,74 @@
+package data
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDebianFingerprint(t *testing.T) {
+	got := DebianFingerprint(big.NewInt(0xC0FFEE))
+	if want := "6149ed99475722f13e18"; got != want {
+		t.Errorf("DebianFingerprint = %s, want %s", got, want)
+	}
+}
+
+func TestLoadWeakKeys(t *testing.T) {
+	spki := []byte("not really a SubjectPublicKeyInfo")
+	sum := sha256.Sum256(spki)
+	modulus := big.NewInt(0xC0FFEE)
+
+	content := "# weak keys\n\n" +
+		hex.EncodeToString(sum[:]) + "\n" +
+		"6149ED99475722F13E18\n"
+	path := filepath.Join(t.TempDir(), WeakKeysFile)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := &Loader{}
+	if l.IsWeakKey(spki, nil) {
+		t.Error("no key should be weak before a list is loaded")
+	}
+	if err := l.LoadWeakKeys(path); err != nil {
+		t.Fatalf("LoadWeakKeys: %v", err)
+	}
+	if !l.WeakKeysLoaded() {
+		t.Fatal("WeakKeysLoaded = false after loading")
+	}
+
+	if !l.IsWeakKey(spki, nil) {
+		t.Error("SPKI fingerprint not matched")
+	}
+	if !l.IsWeakKey([]byte("other"), modulus) {
+		t.Error("Debian modulus fingerprint not matched (case-insensitive)")
+	}
+	if l.IsWeakKey([]byte("other"), big.NewInt(65537)) {
+		t.Error("unlisted key reported as weak")
+	}
+}
+
+func TestLoadWeakKeysInvalid(t *testing.T) {
+	tests := map[string]string{
+		"not hex":      "zz\n",
+		"wrong length": "abcdef\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), WeakKeysFile)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := (&Loader{}).LoadWeakKeys(path); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	if err := (&Loader{}).LoadWeakKeys(""); err == nil {
+		t.Error("expected error without a file or data directory")
+	}
+}
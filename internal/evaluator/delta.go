@@ -0,0 +1,55 @@
+package evaluator
+
+import (
+	"bytes"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/crl"
+	"github.com/cavoq/PCL/internal/node"
+)
+
+// addBaseCRL completes the tree of a delta CRL with the complete CRL among
+// crls that it updates (RFC 5280 5.2.4). When none qualifies, the newest
+// complete CRL of the same issuer is described instead, so that rules can
+// report why the pair does not match.
+func addBaseCRL(n *node.Node, delta *x509.RevocationList, crls []*crl.Info) {
+	baseNumber, ok := crl.BaseCRLNumber(delta)
+	if !ok {
+		return
+	}
+
+	base := crl.BaseFor(delta, crls)
+	if base == nil {
+		base = newestCompleteCRL(delta, crls)
+	}
+	if base == nil {
+		return
+	}
+
+	b := node.New("baseCRL", nil)
+	if base.CRL.Number != nil {
+		b.Children["crlNumber"] = node.New("crlNumber", base.CRL.Number.String())
+	}
+	b.Children["numberValid"] = node.New("numberValid", base.CRL.Number != nil &&
+		baseNumber.Cmp(base.CRL.Number) <= 0 &&
+		(delta.Number == nil || delta.Number.Cmp(base.CRL.Number) > 0))
+	b.Children["scopeMatches"] = node.New("scopeMatches", crl.SameScope(base.CRL, delta))
+	b.Children["mergedCount"] = node.New("mergedCount", len(crl.Merge(base.CRL, delta)))
+	n.Children["baseCRL"] = b
+}
+
+// newestCompleteCRL returns the complete CRL among crls with the issuer of
+// delta and the highest CRL number.
+func newestCompleteCRL(delta *x509.RevocationList, crls []*crl.Info) *crl.Info {
+	var newest *crl.Info
+	for _, c := range crls {
+		if c.CRL == nil || crl.HasDeltaIndicator(c.CRL) || !bytes.Equal(c.CRL.RawIssuer, delta.RawIssuer) {
+			continue
+		}
+		if newest == nil || (c.CRL.Number != nil && (newest.CRL.Number == nil || c.CRL.Number.Cmp(newest.CRL.Number) > 0)) {
+			newest = c
+		}
+	}
+	return newest
+}
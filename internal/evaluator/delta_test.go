@@ -0,0 +1,74 @@
+package evaluator
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
+	"github.com/zmap/zcrypto/x509/pkix"
+
+	"github.com/cavoq/PCL/internal/crl"
+	"github.com/cavoq/PCL/internal/node"
+)
+
+func testDeltaCRL(t *testing.T, number, base int64) *x509.RevocationList {
+	t.Helper()
+	value, err := asn1.Marshal(big.NewInt(base))
+	if err != nil {
+		t.Fatalf("failed to marshal BaseCRLNumber: %v", err)
+	}
+	return &x509.RevocationList{
+		RawIssuer:  []byte("issuer"),
+		Number:     big.NewInt(number),
+		Extensions: []pkix.Extension{{Id: asn1.ObjectIdentifier{2, 5, 29, 27}, Critical: true, Value: value}},
+	}
+}
+
+func TestAddBaseCRL(t *testing.T) {
+	base := &crl.Info{CRL: &x509.RevocationList{RawIssuer: []byte("issuer"), Number: big.NewInt(10)}}
+
+	tests := []struct {
+		name        string
+		delta       *x509.RevocationList
+		numberValid bool
+	}{
+		{"matching base", testDeltaCRL(t, 11, 10), true},
+		{"base too old", testDeltaCRL(t, 12, 11), false},
+		{"delta not newer", testDeltaCRL(t, 10, 9), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node.New("crl", nil)
+			addBaseCRL(n, tt.delta, []*crl.Info{base, {CRL: tt.delta}})
+
+			number, ok := n.Resolve("baseCRL.crlNumber")
+			if !ok || number.Value != "10" {
+				t.Fatalf("expected baseCRL.crlNumber 10, got %v", number)
+			}
+			valid, _ := n.Resolve("baseCRL.numberValid")
+			if valid.Value != tt.numberValid {
+				t.Errorf("expected numberValid %v, got %v", tt.numberValid, valid.Value)
+			}
+			scope, _ := n.Resolve("baseCRL.scopeMatches")
+			if scope.Value != true {
+				t.Error("expected scopeMatches true")
+			}
+		})
+	}
+}
+
+func TestAddBaseCRL_NoBase(t *testing.T) {
+	n := node.New("crl", nil)
+	delta := testDeltaCRL(t, 11, 10)
+	addBaseCRL(n, delta, []*crl.Info{{CRL: delta}})
+	if _, ok := n.Resolve("baseCRL"); ok {
+		t.Error("baseCRL should be absent without a complete CRL")
+	}
+
+	addBaseCRL(n, &x509.RevocationList{Number: big.NewInt(10)}, nil)
+	if _, ok := n.Resolve("baseCRL"); ok {
+		t.Error("baseCRL should be absent for a complete CRL")
+	}
+}
@@ -2,68 +2,167 @@
 package evaluator
 
 import (
+	"slices"
+	"time"
+
+	"github.com/cavoq/PCL/internal/attrcert"
+	attrcertzcrypto "github.com/cavoq/PCL/internal/attrcert/zcrypto"
 	"github.com/cavoq/PCL/internal/cert"
 	certzcrypto "github.com/cavoq/PCL/internal/cert/zcrypto"
 	"github.com/cavoq/PCL/internal/crl"
 	crlzcrypto "github.com/cavoq/PCL/internal/crl/zcrypto"
+	"github.com/cavoq/PCL/internal/csr"
+	csrzcrypto "github.com/cavoq/PCL/internal/csr/zcrypto"
+	"github.com/cavoq/PCL/internal/data"
 	"github.com/cavoq/PCL/internal/node"
 	"github.com/cavoq/PCL/internal/ocsp"
 	ocspzcrypto "github.com/cavoq/PCL/internal/ocsp/zcrypto"
 	"github.com/cavoq/PCL/internal/operator"
+	"github.com/cavoq/PCL/internal/pathval"
 	"github.com/cavoq/PCL/internal/policy"
+	"github.com/cavoq/PCL/internal/sct"
+	sctzcrypto "github.com/cavoq/PCL/internal/sct/zcrypto"
 	"github.com/cavoq/PCL/internal/source"
+	"github.com/cavoq/PCL/internal/trust"
+	"github.com/cavoq/PCL/internal/tst"
+	tstzcrypto "github.com/cavoq/PCL/internal/tst/zcrypto"
 	"github.com/cavoq/PCL/internal/zcrypto"
 	"github.com/zmap/zcrypto/x509"
 )
 
 // Context contains all data needed for policy evaluation.
 type Context struct {
-	Policies []policy.Policy
-	Registry *operator.Registry
-	CRLs     []*crl.Info
-	OCSPs    []*ocsp.Info
-	Chain    []*cert.Info
+	Policies  []policy.Policy
+	Registry  *operator.Registry
+	CRLs      []*crl.Info
+	OCSPs     []*ocsp.Info
+	CSRs      []*csr.Info
+	TSTs      []*tst.Info
+	SCTs      []*sct.Info
+	AttrCerts []*attrcert.Info
+	Chain     []*cert.Info
+
+	// FinalCerts are the final certificates precertificates are compared
+	// with, matched by serial number.
+	FinalCerts []*cert.Info
+
+	// Trust holds the trust anchors chains are resolved against. Without a
+	// store the self-signed top of a chain is its anchor.
+	Trust *trust.Store
+
+	// Now is the time rules are evaluated at. The zero value means the
+	// current time.
+	Now time.Time
+
+	// Jobs bounds the number of certificate/policy evaluations that run in
+	// parallel. Values below 2 evaluate sequentially.
+	Jobs int
 }
 
-func Chain(ctx Context) []policy.Result {
-	var results []policy.Result
-
-	for _, c := range ctx.Chain {
-		tree := certzcrypto.BuildTree(c.Cert)
+// Subject is a chain linted by Chains. Skip holds hashes of chain
+// certificates that are not linted again; they still provide chain context
+// to the other certificates. Path, if set, labels the results as those of
+// one of several certification paths.
+type Subject struct {
+	Chain []*cert.Info
+	Skip  map[string]bool
+	Path  int
+}
 
-		if c.Source.Format != "" && c.Source.Type != source.Local {
-			tree.Children["downloadFormat"] = node.New("downloadFormat", c.Source.Format)
-			tree.Children["downloadURL"] = node.New("downloadURL", c.Source.URL)
-		}
+func Chain(ctx Context) []policy.Result {
+	return Chains(ctx, []Subject{{Chain: ctx.Chain}})
+}
 
-		if len(ctx.CRLs) > 0 {
-			for _, crlInfo := range ctx.CRLs {
-				if crlInfo.CRL != nil {
-					crlNode := crlzcrypto.BuildTree(crlInfo.CRL)
-					if crlNode != nil {
-						tree.Children["crl"] = crlNode
-					}
-					break
-				}
+// Chains lints the certificates of several chains. Trees are built and
+// policies evaluated on up to ctx.Jobs goroutines; results are returned in
+// chain, certificate and policy order regardless of scheduling.
+func Chains(ctx Context, subjects []Subject) []policy.Result {
+	type target struct {
+		cert  *cert.Info
+		chain []*cert.Info
+		path  int
+	}
+	var targets []target
+	for _, s := range subjects {
+		for _, c := range s.Chain {
+			if !s.Skip[c.Hash] {
+				targets = append(targets, target{cert: c, chain: s.Chain, path: s.Path})
 			}
 		}
+	}
 
+	type prepared struct {
+		tree     *node.Node
+		evalCtx  *operator.EvaluationContext
+		policies []policy.Policy
+	}
+	preps := make([]prepared, len(targets))
+	forEach(ctx.Jobs, len(targets), func(i int) {
+		c := targets[i].cert
+		tree := buildChainTree(ctx, c, targets[i].chain)
 		evalOpts := []operator.ContextOption{
 			operator.WithCRLs(ctx.CRLs),
 			operator.WithOCSPs(ctx.OCSPs),
+			operator.WithNow(ctx.Now),
+			operator.WithTrustStore(ctx.Trust),
+		}
+		preps[i] = prepared{
+			tree:     tree,
+			evalCtx:  operator.NewEvaluationContext(tree, c, targets[i].chain, evalOpts...),
+			policies: policy.ByCertificate(ctx.Policies, c.Cert),
 		}
-		evalCtx := operator.NewEvaluationContext(tree, c, ctx.Chain, evalOpts...)
+	})
 
-		filteredPolicies := policy.ByCertificate(ctx.Policies, c.Cert)
-		for _, p := range filteredPolicies {
-			res := policy.Evaluate(p, tree, ctx.Registry, evalCtx)
-			results = append(results, res)
+	type task struct {
+		prep   int
+		policy int
+	}
+	var tasks []task
+	for i, prep := range preps {
+		for j := range prep.policies {
+			tasks = append(tasks, task{prep: i, policy: j})
 		}
 	}
 
+	results := make([]policy.Result, len(tasks))
+	forEach(ctx.Jobs, len(tasks), func(i int) {
+		prep := preps[tasks[i].prep]
+		results[i] = policy.Evaluate(prep.policies[tasks[i].policy], prep.tree, ctx.Registry, prep.evalCtx)
+		results[i].Path = targets[tasks[i].prep].path
+	})
+
 	return results
 }
 
+func buildChainTree(ctx Context, c *cert.Info, chain []*cert.Info) *node.Node {
+	tree := certzcrypto.BuildTree(c.Cert)
+
+	path, anchor := pathval.FromChain(chain, c.Position, ctx.Trust)
+	tree.Children["chain"] = trust.BuildTree(path, anchor)
+	tree.Children["pathValidation"] = pathval.BuildTree(pathval.Validate(path, anchor.Cert, pathval.Options{Now: ctx.Now}))
+
+	if pre, ok := tree.Children["precertificate"]; ok {
+		addPrecertificate(pre, c, chain, ctx.FinalCerts)
+	}
+
+	if c.Source.Format != "" && c.Source.Type != source.Local {
+		tree.Children["downloadFormat"] = node.New("downloadFormat", c.Source.Format)
+		tree.Children["downloadURL"] = node.New("downloadURL", c.Source.URL)
+	}
+
+	for _, crlInfo := range ctx.CRLs {
+		if crlInfo.CRL != nil {
+			crlNode := crlzcrypto.BuildTree(crlInfo.CRL)
+			if crlNode != nil {
+				tree.Children["crl"] = crlNode
+			}
+			break
+		}
+	}
+
+	return tree
+}
+
 func OCSP(ctx Context) []policy.Result {
 	var results []policy.Result
 
@@ -84,7 +183,7 @@ func OCSP(ctx Context) []policy.Result {
 		}
 
 		tree := ocspNode
-		evalOpts := []operator.ContextOption{operator.WithOCSPs(ctx.OCSPs)}
+		evalOpts := []operator.ContextOption{operator.WithOCSPs(ctx.OCSPs), operator.WithNow(ctx.Now), operator.WithTrustStore(ctx.Trust)}
 		evalCtx := operator.NewEvaluationContext(tree, ocspCertInfo, ctx.Chain, evalOpts...)
 
 		filteredPolicies := policy.ByInput(ctx.Policies, policy.InputOCSP)
@@ -94,7 +193,7 @@ func OCSP(ctx Context) []policy.Result {
 		}
 
 		if ocspInfo.Response.Certificate != nil {
-			results = append(results, ocspSigningCert(ctx.Policies, ctx.Registry, ctx.OCSPs, ocspInfo, ctx.Chain)...)
+			results = append(results, ocspSigningCert(ctx, ocspInfo)...)
 		}
 	}
 
@@ -115,6 +214,7 @@ func CRL(ctx Context) []policy.Result {
 		if crlNode == nil {
 			continue
 		}
+		addBaseCRL(crlNode, crlInfo.CRL, ctx.CRLs)
 
 		crlCertInfo := &cert.Info{
 			FilePath: crlInfo.FilePath,
@@ -123,7 +223,7 @@ func CRL(ctx Context) []policy.Result {
 		}
 
 		tree := crlNode
-		evalOpts := []operator.ContextOption{operator.WithCRLs(ctx.CRLs)}
+		evalOpts := []operator.ContextOption{operator.WithCRLs(ctx.CRLs), operator.WithNow(ctx.Now), operator.WithTrustStore(ctx.Trust)}
 		evalCtx := operator.NewEvaluationContext(tree, crlCertInfo, ctx.Chain, evalOpts...)
 
 		filteredPolicies := policy.ByCRL(ctx.Policies, crlInfo.CRL)
@@ -136,6 +236,144 @@ func CRL(ctx Context) []policy.Result {
 	return results
 }
 
+// CSR lints certificate signing requests against the policies that apply
+// to CSR input.
+func CSR(ctx Context) []policy.Result {
+	var results []policy.Result
+
+	filteredPolicies := policy.ByInput(ctx.Policies, policy.InputCSR)
+	for _, csrInfo := range ctx.CSRs {
+		if csrInfo.CSR == nil {
+			continue
+		}
+
+		tree := csrzcrypto.BuildTree(csrInfo.CSR)
+
+		csrCertInfo := &cert.Info{
+			FilePath: csrInfo.FilePath,
+			Type:     "csr",
+			Source:   csrInfo.Source,
+		}
+
+		evalOpts := []operator.ContextOption{operator.WithNow(ctx.Now), operator.WithTrustStore(ctx.Trust)}
+		evalCtx := operator.NewEvaluationContext(tree, csrCertInfo, nil, evalOpts...)
+
+		for _, p := range filteredPolicies {
+			res := policy.Evaluate(p, tree, ctx.Registry, evalCtx)
+			results = append(results, res)
+		}
+	}
+
+	return results
+}
+
+// TST lints RFC 3161 time-stamp tokens against the policies that apply to
+// them. The chain supplies the signer certificate when a token does not
+// carry it; an embedded signer certificate is also linted as a certificate.
+func TST(ctx Context) []policy.Result {
+	var results []policy.Result
+
+	issuerCerts := ExtractCertsFromInfo(ctx.Chain)
+	for _, tstInfo := range ctx.TSTs {
+		if tstInfo.Token == nil {
+			continue
+		}
+
+		tree := tstzcrypto.BuildTreeWithChain(tstInfo.Token, issuerCerts)
+
+		tstCertInfo := &cert.Info{
+			FilePath: tstInfo.FilePath,
+			Type:     "tst",
+			Source:   tstInfo.Source,
+		}
+
+		evalOpts := []operator.ContextOption{operator.WithNow(ctx.Now), operator.WithTrustStore(ctx.Trust)}
+		evalCtx := operator.NewEvaluationContext(tree, tstCertInfo, ctx.Chain, evalOpts...)
+
+		filteredPolicies := policy.ByTST(ctx.Policies, tstInfo.Token)
+		for _, p := range filteredPolicies {
+			res := policy.Evaluate(p, tree, ctx.Registry, evalCtx)
+			results = append(results, res)
+		}
+
+		if signer := tstInfo.Token.SignerCertificate(nil); signer != nil {
+			results = append(results, tsaSigningCert(ctx, tstInfo, signer)...)
+		}
+	}
+
+	return results
+}
+
+// SCT lints SCT lists delivered outside a certificate against the policies
+// that apply to them. The SCTs are verified over the first certificate of
+// the chain, the one they were delivered with, using the loaded CT log
+// list.
+func SCT(ctx Context) []policy.Result {
+	var results []policy.Result
+
+	var leaf *x509.Certificate
+	if len(ctx.Chain) > 0 {
+		leaf = ctx.Chain[0].Cert
+	}
+	for _, sctInfo := range ctx.SCTs {
+		if sctInfo.List == nil {
+			continue
+		}
+
+		tree := sctzcrypto.BuildTreeWithCert(sctInfo.List, leaf, data.DefaultLoader)
+
+		sctCertInfo := &cert.Info{
+			FilePath: sctInfo.FilePath,
+			Type:     "sct",
+			Source:   sctInfo.Source,
+		}
+
+		evalOpts := []operator.ContextOption{operator.WithNow(ctx.Now), operator.WithTrustStore(ctx.Trust)}
+		evalCtx := operator.NewEvaluationContext(tree, sctCertInfo, ctx.Chain, evalOpts...)
+
+		filteredPolicies := policy.BySCT(ctx.Policies, sctInfo.List)
+		for _, p := range filteredPolicies {
+			res := policy.Evaluate(p, tree, ctx.Registry, evalCtx)
+			results = append(results, res)
+		}
+	}
+
+	return results
+}
+
+// AttrCert lints RFC 5755 attribute certificates against the policies that
+// apply to them. The issuing attribute authority is looked up in the chain
+// to verify the signature.
+func AttrCert(ctx Context) []policy.Result {
+	var results []policy.Result
+
+	issuerCerts := ExtractCertsFromInfo(ctx.Chain)
+	filteredPolicies := policy.ByInput(ctx.Policies, policy.InputAttrCert)
+	for _, acInfo := range ctx.AttrCerts {
+		if acInfo.AttrCert == nil {
+			continue
+		}
+
+		tree := attrcertzcrypto.BuildTreeWithIssuers(acInfo.AttrCert, issuerCerts)
+
+		acCertInfo := &cert.Info{
+			FilePath: acInfo.FilePath,
+			Type:     "attrCert",
+			Source:   acInfo.Source,
+		}
+
+		evalOpts := []operator.ContextOption{operator.WithNow(ctx.Now), operator.WithTrustStore(ctx.Trust)}
+		evalCtx := operator.NewEvaluationContext(tree, acCertInfo, ctx.Chain, evalOpts...)
+
+		for _, p := range filteredPolicies {
+			res := policy.Evaluate(p, tree, ctx.Registry, evalCtx)
+			results = append(results, res)
+		}
+	}
+
+	return results
+}
+
 func CRLOnly(policies []policy.Policy, registry *operator.Registry, crls []*crl.Info, issuers []*cert.Info) []policy.Result {
 	return CRL(Context{
 		Policies: policies,
@@ -153,7 +391,7 @@ func OCSPOnly(policies []policy.Policy, registry *operator.Registry, ocsps []*oc
 	})
 }
 
-func ocspSigningCert(policies []policy.Policy, registry *operator.Registry, ocsps []*ocsp.Info, ocspInfo *ocsp.Info, chain []*cert.Info) []policy.Result {
+func ocspSigningCert(ctx Context, ocspInfo *ocsp.Info) []policy.Result {
 	zcryptoSignerCert, err := zcrypto.FromStdCert(ocspInfo.Response.Certificate)
 	if err != nil || zcryptoSignerCert == nil {
 		return nil
@@ -167,13 +405,34 @@ func ocspSigningCert(policies []policy.Policy, registry *operator.Registry, ocsp
 		Source:   source.Info{Type: source.Extracted, Description: "extracted from OCSP response"},
 	}
 
-	evalOpts := []operator.ContextOption{operator.WithOCSPs(ocsps)}
-	evalCtx := operator.NewEvaluationContext(ocspSignerTree, ocspSignerInfo, chain, evalOpts...)
+	evalOpts := []operator.ContextOption{operator.WithOCSPs(ctx.OCSPs), operator.WithNow(ctx.Now), operator.WithTrustStore(ctx.Trust)}
+	evalCtx := operator.NewEvaluationContext(ocspSignerTree, ocspSignerInfo, ctx.Chain, evalOpts...)
 
 	var results []policy.Result
-	signerPolicies := policy.ByCertificate(policies, zcryptoSignerCert)
+	signerPolicies := policy.ByCertificate(ctx.Policies, zcryptoSignerCert)
 	for _, p := range signerPolicies {
-		res := policy.Evaluate(p, ocspSignerTree, registry, evalCtx)
+		res := policy.Evaluate(p, ocspSignerTree, ctx.Registry, evalCtx)
+		results = append(results, res)
+	}
+
+	return results
+}
+
+func tsaSigningCert(ctx Context, tstInfo *tst.Info, signer *x509.Certificate) []policy.Result {
+	signerTree := certzcrypto.BuildTree(signer)
+	signerInfo := &cert.Info{
+		Cert:     signer,
+		FilePath: tstInfo.FilePath + " (signing cert)",
+		Type:     "tsaSigning",
+		Source:   source.Info{Type: source.Extracted, Description: "extracted from time-stamp token"},
+	}
+
+	evalOpts := []operator.ContextOption{operator.WithNow(ctx.Now), operator.WithTrustStore(ctx.Trust)}
+	evalCtx := operator.NewEvaluationContext(signerTree, signerInfo, ctx.Chain, evalOpts...)
+
+	var results []policy.Result
+	for _, p := range policy.ByCertificate(ctx.Policies, signer) {
+		res := policy.Evaluate(p, signerTree, ctx.Registry, evalCtx)
 		results = append(results, res)
 	}
 
@@ -190,3 +449,17 @@ func ExtractCertsFromInfo(infos []*cert.Info) []*x509.Certificate {
 	}
 	return certs
 }
+
+// TargetFields lists, per tree root, the top-level fields that the trees
+// evaluated by this package may contain.
+func TargetFields() map[string][]string {
+	return map[string][]string{
+		"certificate": slices.Concat(certzcrypto.Fields, []string{"downloadFormat", "downloadURL", "crl", "chain", "pathValidation"}),
+		"crl":         slices.Concat(crlzcrypto.Fields, []string{"baseCRL"}),
+		"ocsp":        ocspzcrypto.Fields,
+		"csr":         csrzcrypto.Fields,
+		"tst":         tstzcrypto.Fields,
+		"sct":         sctzcrypto.Fields,
+		"attrCert":    attrcertzcrypto.Fields,
+	}
+}
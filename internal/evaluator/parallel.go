@@ -0,0 +1,33 @@
+package evaluator
+
+import "sync"
+
+// forEach calls fn for every index in [0, n) on at most jobs goroutines.
+// Callers store results by index so that output order does not depend on
+// scheduling. With jobs below 2, fn runs sequentially on the caller's
+// goroutine.
+func forEach(jobs, n int, fn func(i int)) {
+	if jobs < 2 || n < 2 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(jobs, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
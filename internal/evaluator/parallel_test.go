@@ -0,0 +1,32 @@
+package evaluator
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestForEachVisitsEveryIndexOnce(t *testing.T) {
+	for _, jobs := range []int{0, 1, 4, 64} {
+		const n = 100
+		var calls atomic.Int32
+		seen := make([]int32, n)
+		forEach(jobs, n, func(i int) {
+			calls.Add(1)
+			atomic.AddInt32(&seen[i], 1)
+		})
+		if calls.Load() != n {
+			t.Errorf("jobs=%d: got %d calls, want %d", jobs, calls.Load(), n)
+		}
+		for i, c := range seen {
+			if c != 1 {
+				t.Errorf("jobs=%d: index %d visited %d times", jobs, i, c)
+			}
+		}
+	}
+}
+
+func TestForEachNoItems(t *testing.T) {
+	forEach(4, 0, func(int) {
+		t.Fatal("fn called for empty range")
+	})
+}
@@ -0,0 +1,75 @@
+package evaluator
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/cert"
+	"github.com/cavoq/PCL/internal/ct"
+	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/oid"
+)
+
+// addPrecertificate completes the precertificate node of a tree with the
+// checks that need other certificates: whether the issuer is a
+// Precertificate Signing Certificate, and how the TBSCertificate compares
+// with that of the final certificate with the same issuer and serial number
+// among finals (RFC 6962 3.1).
+func addPrecertificate(n *node.Node, c *cert.Info, chain []*cert.Info, finals []*cert.Info) {
+	var signingIssuer []byte
+	if pos := c.Position; pos+1 < len(chain) && chain[pos+1].Cert != nil {
+		issuer := chain[pos+1].Cert
+		signing := isPrecertSigningCert(issuer)
+		n.Children["issuedByPrecertSigningCert"] = node.New("issuedByPrecertSigningCert", signing)
+		if signing {
+			signingIssuer = issuer.RawIssuer
+		}
+	}
+
+	final := finalCertificate(c.Cert, signingIssuer, finals)
+	if final == nil {
+		return
+	}
+
+	f := node.New("finalCertificate", nil)
+	f.Children["serialNumber"] = node.New("serialNumber", final.SerialNumber.String())
+	if diffs, err := ct.TBSDifferences(c.Cert.RawTBSCertificate, final.RawTBSCertificate, signingIssuer); err == nil {
+		f.Children["tbsMatches"] = node.New("tbsMatches", len(diffs) == 0)
+		differences := node.New("differences", nil)
+		for i, d := range diffs {
+			differences.Children[fmt.Sprintf("%d", i)] = node.New(fmt.Sprintf("%d", i), d)
+		}
+		f.Children["differences"] = differences
+	}
+	n.Children["finalCertificate"] = f
+}
+
+func isPrecertSigningCert(c *x509.Certificate) bool {
+	return slices.ContainsFunc(c.UnknownExtKeyUsage, func(eku asn1.ObjectIdentifier) bool {
+		return eku.String() == oid.PrecertSigning
+	})
+}
+
+// finalCertificate returns the certificate among finals that is not itself
+// a precertificate and has the serial number of precert and its issuer: the
+// issuer of precert, or signingIssuer, the issuer of the Precertificate
+// Signing Certificate, when one signed precert.
+func finalCertificate(precert *x509.Certificate, signingIssuer []byte, finals []*cert.Info) *x509.Certificate {
+	issuer := precert.RawIssuer
+	if signingIssuer != nil {
+		issuer = signingIssuer
+	}
+	for _, f := range finals {
+		if f.Cert == nil || f.Cert.IsPrecert || !bytes.Equal(f.Cert.RawIssuer, issuer) {
+			continue
+		}
+		if f.Cert.SerialNumber.Cmp(precert.SerialNumber) == 0 {
+			return f.Cert
+		}
+	}
+	return nil
+}
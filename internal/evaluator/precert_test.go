@@ -0,0 +1,35 @@
+package evaluator
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/cert"
+)
+
+func TestFinalCertificate(t *testing.T) {
+	precert := &x509.Certificate{SerialNumber: big.NewInt(5), RawIssuer: []byte("ca"), IsPrecert: true}
+	otherCA := &x509.Certificate{SerialNumber: big.NewInt(5), RawIssuer: []byte("other")}
+	sameCA := &x509.Certificate{SerialNumber: big.NewInt(5), RawIssuer: []byte("ca")}
+	otherSerial := &x509.Certificate{SerialNumber: big.NewInt(6), RawIssuer: []byte("ca")}
+	finals := []*cert.Info{{Cert: precert}, {Cert: otherCA}, {Cert: otherSerial}, {Cert: sameCA}}
+
+	if got := finalCertificate(precert, nil, finals); got != sameCA {
+		t.Errorf("expected the final certificate of the same issuer, got %v", got)
+	}
+	if got := finalCertificate(precert, nil, finals[:3]); got != nil {
+		t.Errorf("expected no final certificate from another issuer, got %v", got)
+	}
+
+	// Signed by a Precertificate Signing Certificate, the final certificate
+	// is issued by the CA that issued the signing certificate
+	delegated := &x509.Certificate{SerialNumber: big.NewInt(5), RawIssuer: []byte("signing"), IsPrecert: true}
+	if got := finalCertificate(delegated, []byte("ca"), finals); got != sameCA {
+		t.Errorf("expected the final certificate of the signing certificate's issuer, got %v", got)
+	}
+	if got := finalCertificate(delegated, nil, finals); got != nil {
+		t.Errorf("expected no final certificate issued by the signing certificate, got %v", got)
+	}
+}
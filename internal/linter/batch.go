@@ -0,0 +1,61 @@
+package linter
+
+import (
+	"fmt"
+
+	"github.com/cavoq/PCL/internal/cert"
+	"github.com/cavoq/PCL/internal/evaluator"
+	"github.com/cavoq/PCL/internal/operator"
+	"github.com/cavoq/PCL/internal/policy"
+)
+
+// processBatch lints every certificate file under --cert as its own subject.
+func processBatch(cfg Config, policies []policy.Policy, reg *operator.Registry, in Inputs) ([]policy.Result, error) {
+	if len(cfg.CertURLs) > 0 {
+		return nil, fmt.Errorf("--batch lints --cert files and cannot be combined with --cert-url")
+	}
+	if cfg.AutoValidate {
+		return nil, fmt.Errorf("--batch cannot be combined with --auto-validate")
+	}
+
+	bundles, err := cert.LoadBundles(cfg.CertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificates: %w", err)
+	}
+	if len(bundles) == 0 {
+		return nil, fmt.Errorf("no leaf certificates provided")
+	}
+
+	return evaluateBatch(policies, reg, bundles, in), nil
+}
+
+// evaluateBatch lints each bundle as its own subject. Every chain starts at
+// the bundle's subject and draws issuers from the bundle and the shared
+// issuer pool. The issuer graph and its signature cache are built once from
+// the pool, and each subject is attached to it. An issuer is linted once,
+// with the first chain that reaches it. CRLs and OCSP responses are linted
+// once, against the issuer pool. SCT lists belong to no single subject and
+// are linted without verification.
+func evaluateBatch(policies []policy.Policy, reg *operator.Registry, bundles []cert.Bundle, in Inputs) []policy.Result {
+	linted := make(map[string]bool)
+	graph := cert.NewGraph(in.Issuers)
+	subjects := make([]evaluator.Subject, 0, len(bundles))
+	for _, b := range bundles {
+		paths := graph.PathsFrom(b.Subject, b.Extra, in.pathOptions())
+		subjects = append(subjects, in.subjects(paths, linted)...)
+	}
+
+	results := evaluator.Chains(in.context(policies, reg, nil), subjects)
+
+	if len(in.CRLs) > 0 {
+		results = append(results, evaluator.CRL(in.context(policies, reg, in.Issuers))...)
+	}
+	if len(in.OCSPs) > 0 {
+		results = append(results, evaluator.OCSP(in.context(policies, reg, nil))...)
+	}
+	if len(in.SCTs) > 0 {
+		results = append(results, evaluator.SCT(in.context(policies, reg, nil))...)
+	}
+
+	return results
+}
@@ -14,10 +14,29 @@ type Config struct {
 	IssuerURLs  []string
 	CRLPath     string
 	OCSPPath    string
+	CSRPath     string
+	TSTPath     string
 	OCSPTimeout time.Duration
 	OutputFmt   string
 	Verbosity   int
 	ShowMeta    bool
+	FailOn      string    // Minimum failed-rule severity that yields a non-zero exit: error, warning, or notice
+	Batch       bool      // Lint each --cert file as its own subject, sharing the issuer pool
+	Jobs        int       // Parallel evaluation workers (default: number of CPUs)
+	At          time.Time // Evaluation time for date and validity checks (default: now)
+	AllPaths    bool      // Lint every valid certification path instead of the best ranked one
+
+	// Certificate Transparency inputs
+	SCTPath       string // TLS-encoded or OCSP-stapled SCT lists, verified over the leaf certificate
+	FinalCertPath string // Final certificates that precertificates are compared with
+
+	// Attribute certificates (RFC 5755), verified against the issuers
+	AttrCertPath string
+
+	// Trust store options. With either set, only these certificates anchor
+	// a chain; otherwise a self-signed chain root is the anchor.
+	TrustAnchorPaths []string // Trust anchor certificate files or directories (PEM bundles or DER)
+	SystemRoots      bool     // Add the system CA bundle to the trust anchors
 
 	// Auto-validate mode options
 	AutoValidate  bool // Enable automatic PKI resource fetching (OCSP, CRL, chain climbing)
@@ -38,4 +57,7 @@ type Config struct {
 	PSLFile string // Path to Public Suffix List file (optional)
 	UsePSL  bool   // Enable PSL loading (default: true if file exists)
 	DataDir string // Directory for external data files (optional)
+
+	WeakKeysFile  string // Path to known weak key list (optional, default: weak_keys.txt in the data directory)
+	CTLogListFile string // Path to CT log list JSON (optional, default: ct_log_list.json in the data directory)
 }
@@ -0,0 +1,97 @@
+package linter
+
+import (
+	"fmt"
+
+	"github.com/cavoq/PCL/internal/policy"
+	"github.com/cavoq/PCL/internal/rule"
+)
+
+// Exit codes reported by the pcl command.
+const (
+	ExitOK       = 0 // no failed rule at or above the fail-on threshold
+	ExitFail     = 1 // at least one error-severity rule failed
+	ExitWarnings = 2 // only warning or notice rules failed at or above the threshold
+	ExitError    = 3 // operational error such as a bad policy or unreadable input
+)
+
+// Fail-on thresholds accepted by Config.FailOn.
+const (
+	FailOnError   = "error"
+	FailOnWarning = "warning"
+	FailOnNotice  = "notice"
+)
+
+// VerdictError is returned by Run when linting completed but produced
+// failures at or above the configured threshold.
+type VerdictError struct {
+	Code   int
+	Failed int
+}
+
+func (e *VerdictError) Error() string {
+	if e.Code == ExitFail {
+		return fmt.Sprintf("lint failed: %d rule(s) failed", e.Failed)
+	}
+	return fmt.Sprintf("lint produced warnings: %d rule(s) failed", e.Failed)
+}
+
+// severityRank orders rule severities; failures rank at or above the
+// fail-on threshold to affect the exit code. Rules without a recognized
+// severity rank as notices.
+func severityRank(severity string) int {
+	switch severity {
+	case "error":
+		return 3
+	case "warning":
+		return 2
+	default:
+		return 1
+	}
+}
+
+func failOnRank(failOn string) (int, error) {
+	switch failOn {
+	case FailOnError:
+		return 3, nil
+	case FailOnWarning:
+		return 2, nil
+	case FailOnNotice:
+		return 1, nil
+	default:
+		return 0, fmt.Errorf("invalid --fail-on value %q (want error, warning, or notice)", failOn)
+	}
+}
+
+// countVerdict derives the exit code and the number of failed rules whose
+// severity ranks at or above threshold.
+func countVerdict(results []policy.Result, threshold int) (int, int) {
+	code := ExitOK
+	failed := 0
+	for _, pr := range results {
+		for _, rr := range pr.Results {
+			if rr.Verdict != rule.VerdictFail || severityRank(rr.Severity) < threshold {
+				continue
+			}
+			failed++
+			if rr.Severity == "error" {
+				code = ExitFail
+			} else if code == ExitOK {
+				code = ExitWarnings
+			}
+		}
+	}
+	return code, failed
+}
+
+func verdictError(results []policy.Result, failOn string) error {
+	threshold, err := failOnRank(failOn)
+	if err != nil {
+		return err
+	}
+	code, failed := countVerdict(results, threshold)
+	if code == ExitOK {
+		return nil
+	}
+	return &VerdictError{Code: code, Failed: failed}
+}
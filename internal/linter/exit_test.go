@@ -0,0 +1,97 @@
+package linter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cavoq/PCL/internal/policy"
+	"github.com/cavoq/PCL/internal/rule"
+)
+
+func resultsWith(rules ...rule.Result) []policy.Result {
+	return []policy.Result{{PolicyID: "p", Results: rules}}
+}
+
+func TestExitCode(t *testing.T) {
+	errFail := rule.Result{RuleID: "e", Verdict: rule.VerdictFail, Severity: "error"}
+	warnFail := rule.Result{RuleID: "w", Verdict: rule.VerdictFail, Severity: "warning"}
+	infoFail := rule.Result{RuleID: "i", Verdict: rule.VerdictFail, Severity: "info"}
+	pass := rule.Result{RuleID: "p", Verdict: rule.VerdictPass, Severity: "error"}
+	skip := rule.Result{RuleID: "s", Verdict: rule.VerdictSkip, Severity: "error"}
+
+	tests := []struct {
+		name    string
+		results []policy.Result
+		failOn  string
+		want    int
+	}{
+		{"no results", nil, FailOnError, ExitOK},
+		{"passes and skips", resultsWith(pass, skip), FailOnNotice, ExitOK},
+		{"error failure", resultsWith(pass, errFail), FailOnError, ExitFail},
+		{"warning below error threshold", resultsWith(warnFail), FailOnError, ExitOK},
+		{"warning at warning threshold", resultsWith(warnFail), FailOnWarning, ExitWarnings},
+		{"error wins over warning", resultsWith(warnFail, errFail), FailOnWarning, ExitFail},
+		{"info below warning threshold", resultsWith(infoFail), FailOnWarning, ExitOK},
+		{"info at notice threshold", resultsWith(infoFail), FailOnNotice, ExitWarnings},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExitOK
+			err := verdictError(tt.results, tt.failOn)
+			var verdictErr *VerdictError
+			if errors.As(err, &verdictErr) {
+				got = verdictErr.Code
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("exit code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitCodeInvalidThreshold(t *testing.T) {
+	for _, threshold := range []string{"critical", "info"} {
+		err := verdictError(nil, threshold)
+		if err == nil {
+			t.Fatalf("expected error for invalid threshold %q", threshold)
+		}
+		var verdictErr *VerdictError
+		if errors.As(err, &verdictErr) {
+			t.Errorf("invalid threshold %q should be an operational error, got %v", threshold, verdictErr)
+		}
+	}
+}
+
+func TestVerdictError(t *testing.T) {
+	results := resultsWith(
+		rule.Result{Verdict: rule.VerdictFail, Severity: "error"},
+		rule.Result{Verdict: rule.VerdictFail, Severity: "warning"},
+	)
+
+	err := verdictError(results, FailOnWarning)
+	var verdictErr *VerdictError
+	if !errors.As(err, &verdictErr) {
+		t.Fatalf("expected VerdictError, got %v", err)
+	}
+	if verdictErr.Code != ExitFail || verdictErr.Failed != 2 {
+		t.Errorf("VerdictError = %+v, want code 1 with 2 failures", verdictErr)
+	}
+
+	if err := verdictError(resultsWith(), FailOnError); err != nil {
+		t.Errorf("expected nil error for clean results, got %v", err)
+	}
+}
+
+func TestRunRejectsInvalidFailOn(t *testing.T) {
+	err := Run(Config{PolicyPaths: []string{"unused.yaml"}, FailOn: "fatal"}, nil)
+	if err == nil {
+		t.Fatal("expected error for invalid --fail-on")
+	}
+	var verdictErr *VerdictError
+	if errors.As(err, &verdictErr) {
+		t.Fatal("invalid --fail-on must be an operational error")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/cavoq/PCL/internal/cert"
+	"github.com/cavoq/PCL/internal/trust"
 )
 
 // loadCertificates loads leaf certificates from paths and URLs specified in config.
@@ -67,3 +68,24 @@ func loadIssuers(cfg Config, existingCleanup func()) ([]*cert.Info, func(), erro
 
 	return issuers, cleanup, nil
 }
+
+// loadTrustStore loads the trust anchors specified in config. It returns a
+// nil store when no trust anchors were requested.
+func loadTrustStore(cfg Config) (*trust.Store, error) {
+	if len(cfg.TrustAnchorPaths) == 0 && !cfg.SystemRoots {
+		return nil, nil
+	}
+
+	store := trust.NewStore()
+	for _, path := range cfg.TrustAnchorPaths {
+		if err := store.AddPath(path, trust.SourceTrustAnchors); err != nil {
+			return nil, fmt.Errorf("failed to load trust anchors from %s: %w", path, err)
+		}
+	}
+	if cfg.SystemRoots {
+		if err := store.AddSystemRoots(); err != nil {
+			return nil, fmt.Errorf("failed to load system roots: %w", err)
+		}
+	}
+	return store, nil
+}
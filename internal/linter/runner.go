@@ -4,19 +4,30 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"runtime"
+	"slices"
 	"time"
 
+	"github.com/cavoq/PCL/internal/attrcert"
 	"github.com/cavoq/PCL/internal/cert"
 	"github.com/cavoq/PCL/internal/crl"
+	"github.com/cavoq/PCL/internal/csr"
 	"github.com/cavoq/PCL/internal/evaluator"
 	"github.com/cavoq/PCL/internal/ocsp"
 	"github.com/cavoq/PCL/internal/operator"
 	"github.com/cavoq/PCL/internal/output"
 	"github.com/cavoq/PCL/internal/policy"
+	"github.com/cavoq/PCL/internal/sct"
+	"github.com/cavoq/PCL/internal/trust"
+	"github.com/cavoq/PCL/internal/tst"
+	"github.com/zmap/zcrypto/x509"
 )
 
 func Run(cfg Config, w io.Writer) error {
 	applyDefaults(&cfg)
+	if _, err := failOnRank(cfg.FailOn); err != nil {
+		return err
+	}
 
 	// Load policies
 	policies, err := loadPolicies(cfg.PolicyPaths)
@@ -28,6 +39,11 @@ func Run(cfg Config, w io.Writer) error {
 	var results []policy.Result
 	var cleanup func()
 
+	store, err := loadTrustStore(cfg)
+	if err != nil {
+		return err
+	}
+
 	// Load CRLs if provided
 	crls, err := loadCRLs(cfg.CRLPath)
 	if err != nil {
@@ -40,6 +56,36 @@ func Run(cfg Config, w io.Writer) error {
 		return err
 	}
 
+	// Load CSRs if provided
+	csrs, err := loadCSRs(cfg.CSRPath)
+	if err != nil {
+		return err
+	}
+
+	// Load time-stamp tokens if provided
+	tsts, err := loadTSTs(cfg.TSTPath)
+	if err != nil {
+		return err
+	}
+
+	// Load attribute certificates if provided
+	attrCerts, err := loadAttrCerts(cfg.AttrCertPath)
+	if err != nil {
+		return err
+	}
+
+	// Load SCT lists if provided
+	scts, err := loadSCTs(cfg.SCTPath)
+	if err != nil {
+		return err
+	}
+
+	// Load final certificates for precertificate comparison if provided
+	finals, err := loadFinalCerts(cfg.FinalCertPath)
+	if err != nil {
+		return err
+	}
+
 	// Process certificates if provided
 	hasCert := cfg.CertPath != "" || len(cfg.CertURLs) > 0
 	hasIssuer := len(cfg.IssuerPaths) > 0 || len(cfg.IssuerURLs) > 0
@@ -53,14 +99,40 @@ func Run(cfg Config, w io.Writer) error {
 		cleanup = issuerCleanup
 	}
 
+	in := cfg.inputs(store, issuers, crls, ocsps)
+	in.SCTs, in.FinalCerts = scts, finals
+
+	switch {
+	case hasCert && cfg.Batch:
+		results, err = processBatch(cfg, policies, reg, in)
+		if err != nil {
+			if cleanup != nil {
+				cleanup()
+			}
+			return err
+		}
+	case hasCert:
+		results, cleanup, err = processCertificates(cfg, policies, reg, in, cleanup, w)
+		if err != nil {
+			if cleanup != nil {
+				cleanup()
+			}
+			return err
+		}
+	default:
+		in.CSRs, in.TSTs, in.AttrCerts = csrs, tsts, attrCerts
+		results, err = Evaluate(policies, reg, in)
+		if err != nil {
+			return err
+		}
+	}
+
+	// CSRs, time-stamp tokens and attribute certificates are linted on
+	// their own, alongside any certificates
 	if hasCert {
-		results, cleanup = processCertificates(cfg, policies, reg, crls, ocsps, issuers, cleanup, w)
-	} else if len(crls) > 0 {
-		results = evaluator.CRLOnly(policies, reg, crls, issuers)
-	} else if len(ocsps) > 0 {
-		results = evaluator.OCSPOnly(policies, reg, ocsps)
-	} else {
-		return fmt.Errorf("no certificates, CRLs, or OCSP responses provided")
+		in := cfg.inputs(store, issuers, nil, nil)
+		in.CSRs, in.TSTs, in.AttrCerts = csrs, tsts, attrCerts
+		results = append(results, standalone(policies, reg, in)...)
 	}
 
 	// Run cleanup at the end
@@ -69,7 +141,10 @@ func Run(cfg Config, w io.Writer) error {
 	}
 
 	// Output results
-	return outputResults(cfg, results, w)
+	if err := outputResults(cfg, results, w); err != nil {
+		return err
+	}
+	return verdictError(results, cfg.FailOn)
 }
 
 func loadPolicies(paths []string) ([]policy.Policy, error) {
@@ -119,6 +194,61 @@ func loadOCSPs(path string) ([]*ocsp.Info, error) {
 	return ocsps, nil
 }
 
+func loadCSRs(path string) ([]*csr.Info, error) {
+	if path == "" {
+		return nil, nil
+	}
+	csrs, err := csr.GetCSRs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CSRs: %w", err)
+	}
+	return csrs, nil
+}
+
+func loadTSTs(path string) ([]*tst.Info, error) {
+	if path == "" {
+		return nil, nil
+	}
+	tsts, err := tst.GetTSTs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time-stamp tokens: %w", err)
+	}
+	return tsts, nil
+}
+
+func loadAttrCerts(path string) ([]*attrcert.Info, error) {
+	if path == "" {
+		return nil, nil
+	}
+	attrCerts, err := attrcert.GetAttrCerts(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load attribute certificates: %w", err)
+	}
+	return attrCerts, nil
+}
+
+func loadSCTs(path string) ([]*sct.Info, error) {
+	if path == "" {
+		return nil, nil
+	}
+	scts, err := sct.GetSCTs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SCT lists: %w", err)
+	}
+	return scts, nil
+}
+
+func loadFinalCerts(path string) ([]*cert.Info, error) {
+	if path == "" {
+		return nil, nil
+	}
+	finals, err := cert.LoadCertificates(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load final certificates: %w", err)
+	}
+	return finals, nil
+}
+
 func loadIssuersIfProvided(cfg Config, hasIssuer bool) ([]*cert.Info, func(), error) {
 	if !hasIssuer {
 		return nil, nil, nil
@@ -126,12 +256,12 @@ func loadIssuersIfProvided(cfg Config, hasIssuer bool) ([]*cert.Info, func(), er
 	return loadIssuers(cfg, nil)
 }
 
-func processCertificates(cfg Config, policies []policy.Policy, reg *operator.Registry, crls []*crl.Info, ocsps []*ocsp.Info, issuers []*cert.Info, existingCleanup func(), w io.Writer) ([]policy.Result, func()) {
+func processCertificates(cfg Config, policies []policy.Policy, reg *operator.Registry, in Inputs, existingCleanup func(), w io.Writer) ([]policy.Result, func(), error) {
 	// Load leaf certificates
 	var cleanup func() //nolint:prealloc // overwritten by loadCertificates
 	certs, certCleanup, err := loadCertificates(cfg)
 	if err != nil {
-		return nil, existingCleanup
+		return nil, existingCleanup, err
 	}
 
 	// Combine cleanup functions
@@ -148,9 +278,9 @@ func processCertificates(cfg Config, policies []policy.Policy, reg *operator.Reg
 	}
 
 	// Build chain
-	allCerts := append(certs, issuers...)
+	allCerts := append(certs, in.Issuers...)
 	if len(allCerts) == 0 {
-		return nil, cleanup
+		return nil, cleanup, nil
 	}
 
 	// Auto-validate: climb chain via CA Issuers URLs
@@ -164,16 +294,17 @@ func processCertificates(cfg Config, policies []policy.Policy, reg *operator.Reg
 			miniChain = cert.ClimbChain(miniChain, cfg.CertTimeout, cfg.MaxChainDepth, w)
 			climbedCerts = append(climbedCerts, miniChain...)
 		}
-		allCerts = append(climbedCerts, issuers...)
+		allCerts = append(climbedCerts, in.Issuers...)
 	}
 
-	chain, err := cert.BuildChain(allCerts)
-	if err != nil {
-		_, _ = fmt.Fprintf(w, "Warning: failed to build chain: %v\n", err)
-		return nil, cleanup
+	paths := cert.BuildPaths(allCerts, in.pathOptions())
+	if len(paths) == 0 {
+		return nil, cleanup, fmt.Errorf("failed to build chain: could not build certificate chain")
 	}
+	chain := paths[0].Chain
 
 	nonceOpts := buildNonceOptions(cfg)
+	crls, ocsps := in.CRLs, in.OCSPs
 
 	// Auto-validate: fetch CRLs
 	if cfg.AutoValidate && !cfg.NoAutoCRL {
@@ -195,24 +326,183 @@ func processCertificates(cfg Config, policies []policy.Policy, reg *operator.Reg
 		ocsps = append(ocsps, autoOCSPs...)
 	}
 
-	evalCtx := evaluator.Context{
+	in.CRLs, in.OCSPs = crls, ocsps
+	return evaluatePaths(policies, reg, paths, in), cleanup, nil
+}
+
+// Inputs holds lint subjects that have already been loaded into memory.
+type Inputs struct {
+	Certs   []*cert.Info
+	Issuers []*cert.Info
+	CRLs    []*crl.Info
+	OCSPs   []*ocsp.Info
+	CSRs    []*csr.Info
+	TSTs    []*tst.Info
+	SCTs    []*sct.Info
+
+	// AttrCerts are RFC 5755 attribute certificates, verified against the
+	// attribute authority among Issuers.
+	AttrCerts []*attrcert.Info
+
+	// FinalCerts are compared with the precertificates among Certs.
+	FinalCerts []*cert.Info
+
+	// Jobs bounds parallel certificate and policy evaluation; values below
+	// 2 evaluate sequentially.
+	Jobs int
+
+	// At is the time rules are evaluated at. The zero value means the
+	// current time.
+	At time.Time
+
+	// Trust holds the trust anchors chains are resolved against. Nil means
+	// the self-signed top of a chain is its anchor.
+	Trust *trust.Store
+
+	// AllPaths lints every valid certification path through the
+	// certificates instead of only the best ranked one.
+	AllPaths bool
+}
+
+// inputs bundles loaded inputs with the evaluation settings of cfg.
+func (cfg Config) inputs(store *trust.Store, issuers []*cert.Info, crls []*crl.Info, ocsps []*ocsp.Info) Inputs {
+	return Inputs{Issuers: issuers, CRLs: crls, OCSPs: ocsps, Jobs: cfg.Jobs, At: cfg.At, Trust: store, AllPaths: cfg.AllPaths}
+}
+
+// pathOptions ranks certification paths at the evaluation time and by the
+// trust anchors of in.
+func (in Inputs) pathOptions() cert.PathOptions {
+	return cert.PathOptions{
+		Now: in.At,
+		Anchored: func(path []*x509.Certificate) bool {
+			_, anchor := in.Trust.Resolve(path)
+			return anchor.Cert != nil
+		},
+	}
+}
+
+// subjects returns the chains to lint from ranked paths: the best path, or
+// with AllPaths every valid one, labelled by its 1-based number. The first
+// certificate of each path is always linted; an issuer already linted with
+// an earlier path is not linted again.
+func (in Inputs) subjects(paths []cert.Path, linted map[string]bool) []evaluator.Subject {
+	if !in.AllPaths {
+		return []evaluator.Subject{{Chain: paths[0].Chain, Skip: skipLinted(paths[0].Chain, linted)}}
+	}
+
+	valid := cert.ValidPaths(paths)
+	subjects := make([]evaluator.Subject, 0, len(valid))
+	for i, p := range valid {
+		subjects = append(subjects, evaluator.Subject{Chain: p.Chain, Skip: skipLinted(p.Chain, linted), Path: i + 1})
+	}
+	return subjects
+}
+
+// skipLinted marks the issuers of chain as linted and returns those that
+// were linted before.
+func skipLinted(chain []*cert.Info, linted map[string]bool) map[string]bool {
+	skip := make(map[string]bool)
+	for _, c := range chain[1:] {
+		if linted[c.Hash] {
+			skip[c.Hash] = true
+		}
+		linted[c.Hash] = true
+	}
+	return skip
+}
+
+// context returns the evaluator context for in with the given chain.
+func (in Inputs) context(policies []policy.Policy, reg *operator.Registry, chain []*cert.Info) evaluator.Context {
+	return evaluator.Context{
 		Policies: policies,
 		Registry: reg,
-		CRLs:     crls,
-		OCSPs:    ocsps,
+		CRLs:     in.CRLs,
+		OCSPs:    in.OCSPs,
+		CSRs:     in.CSRs,
+		TSTs:     in.TSTs,
+		SCTs:     in.SCTs,
 		Chain:    chain,
+
+		AttrCerts: in.AttrCerts,
+
+		FinalCerts: in.FinalCerts,
+		Trust:      in.Trust,
+		Now:        in.At,
+		Jobs:       in.Jobs,
 	}
-	results := evaluator.Chain(evalCtx)
+}
 
-	if len(ocsps) > 0 {
+// Evaluate lints already-loaded inputs against policies. Unlike Run it
+// performs no file, network or output I/O. Certificates are linted along
+// their certification paths, with CRLs, OCSP responses and SCT lists
+// checked against the best ranked path. Without certificates, CRLs are
+// linted against the issuers, or else OCSP responses on their own, and SCT
+// lists are linted unverified in either case. CSRs, time-stamp tokens and
+// attribute certificates are always linted on their own, after the other
+// inputs.
+func Evaluate(policies []policy.Policy, reg *operator.Registry, in Inputs) ([]policy.Result, error) {
+	var results []policy.Result
+	switch {
+	case len(in.Certs) > 0:
+		paths := cert.BuildPaths(slices.Concat(in.Certs, in.Issuers), in.pathOptions())
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("failed to build chain: could not build certificate chain")
+		}
+		results = evaluatePaths(policies, reg, paths, in)
+	case len(in.CRLs) > 0:
+		results = evaluator.CRL(in.context(policies, reg, in.Issuers))
+	case len(in.OCSPs) > 0:
+		results = evaluator.OCSP(in.context(policies, reg, nil))
+	case len(in.SCTs) == 0 && len(in.CSRs) == 0 && len(in.TSTs) == 0 && len(in.AttrCerts) == 0:
+		return nil, fmt.Errorf("no certificates, CRLs, OCSP responses, SCT lists, CSRs, time-stamp tokens, or attribute certificates provided")
+	}
+
+	// With certificates, SCT lists are linted with the chain
+	if len(in.Certs) == 0 && len(in.SCTs) > 0 {
+		results = append(results, evaluator.SCT(in.context(policies, reg, nil))...)
+	}
+
+	return append(results, standalone(policies, reg, in)...), nil
+}
+
+// standalone lints the inputs evaluated on their own: CSRs, time-stamp
+// tokens, which find a signer certificate they do not carry among the
+// issuers, and attribute certificates, which find their attribute
+// authority there.
+func standalone(policies []policy.Policy, reg *operator.Registry, in Inputs) []policy.Result {
+	var results []policy.Result
+	if len(in.CSRs) > 0 {
+		results = append(results, evaluator.CSR(in.context(policies, reg, nil))...)
+	}
+	if len(in.TSTs) > 0 {
+		results = append(results, evaluator.TST(in.context(policies, reg, in.Issuers))...)
+	}
+	if len(in.AttrCerts) > 0 {
+		results = append(results, evaluator.AttrCert(in.context(policies, reg, in.Issuers))...)
+	}
+	return results
+}
+
+// evaluatePaths lints the certification paths selected by in.subjects.
+// CRLs, OCSP responses and SCT lists are linted once, with the best ranked
+// path.
+func evaluatePaths(policies []policy.Policy, reg *operator.Registry, paths []cert.Path, in Inputs) []policy.Result {
+	evalCtx := in.context(policies, reg, paths[0].Chain)
+	results := evaluator.Chains(evalCtx, in.subjects(paths, make(map[string]bool)))
+
+	if len(in.OCSPs) > 0 {
 		results = append(results, evaluator.OCSP(evalCtx)...)
 	}
 
-	if len(crls) > 0 {
+	if len(in.CRLs) > 0 {
 		results = append(results, evaluator.CRL(evalCtx)...)
 	}
 
-	return results, cleanup
+	if len(in.SCTs) > 0 {
+		results = append(results, evaluator.SCT(evalCtx)...)
+	}
+
+	return results
 }
 
 func outputResults(cfg Config, results []policy.Result, w io.Writer) error {
@@ -240,6 +530,12 @@ func applyDefaults(cfg *Config) {
 	if cfg.OutputFmt == "" {
 		cfg.OutputFmt = "text"
 	}
+	if cfg.FailOn == "" {
+		cfg.FailOn = FailOnError
+	}
+	if cfg.Jobs <= 0 {
+		cfg.Jobs = runtime.NumCPU()
+	}
 
 	// Auto-validate defaults
 	if cfg.AutoValidate {
@@ -0,0 +1,46 @@
+package linter
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cavoq/PCL/internal/evaluator"
+	"github.com/cavoq/PCL/internal/operator"
+	"github.com/cavoq/PCL/internal/policy"
+)
+
+// ValidatePolicies checks policy files and directories with a
+// policy.Validator and writes the issues found to w. It returns a
+// VerdictError with ExitFail when any issue is an error; warnings alone
+// do not fail validation.
+func ValidatePolicies(paths []string, w io.Writer) error {
+	v := policy.Validator{
+		Registry: operator.DefaultRegistry(),
+		Fields:   evaluator.TargetFields(),
+	}
+
+	var issues []policy.Issue
+	for _, path := range paths {
+		found, err := v.ValidatePath(path)
+		if err != nil {
+			return fmt.Errorf("validating %s: %w", path, err)
+		}
+		issues = append(issues, found...)
+	}
+
+	errs, warnings := 0, 0
+	for _, issue := range issues {
+		if issue.Severity == policy.IssueError {
+			errs++
+		} else {
+			warnings++
+		}
+		fmt.Fprintln(w, issue)
+	}
+	fmt.Fprintf(w, "%d error(s), %d warning(s)\n", errs, warnings)
+
+	if errs > 0 {
+		return &VerdictError{Code: ExitFail, Failed: errs}
+	}
+	return nil
+}
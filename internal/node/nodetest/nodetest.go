@@ -0,0 +1,35 @@
+// Package nodetest provides assertions on node trees for tests of the tree
+// builders.
+package nodetest
+
+import (
+	"testing"
+
+	"github.com/cavoq/PCL/internal/node"
+)
+
+func AssertPathExists(t *testing.T, root *node.Node, path string) {
+	t.Helper()
+	if _, ok := root.Resolve(path); !ok {
+		t.Errorf("expected path %q to exist", path)
+	}
+}
+
+func AssertPathNotExists(t *testing.T, root *node.Node, path string) {
+	t.Helper()
+	if _, ok := root.Resolve(path); ok {
+		t.Errorf("expected path %q to not exist", path)
+	}
+}
+
+func AssertPathValue(t *testing.T, root *node.Node, path string, want any) {
+	t.Helper()
+	n, ok := root.Resolve(path)
+	if !ok {
+		t.Errorf("path %q not found", path)
+		return
+	}
+	if n.Value != want {
+		t.Errorf("path %q: expected %v (%T), got %v (%T)", path, want, want, n.Value, n.Value)
+	}
+}
@@ -66,19 +66,11 @@ func GetOCSPs(path string) ([]*Info, error) {
 			continue
 		}
 
-		resp, format, err := parseOCSP(data)
+		info, err := NewInfo(data, file, source.Info{Type: source.Local})
 		if err != nil {
 			continue
 		}
-
-		hash := sha256.Sum256(resp.Raw)
-		infos = append(infos, &Info{
-			Response: resp,
-			FilePath: file,
-			Hash:     hex.EncodeToString(hash[:]),
-			Source:   source.Info{Type: source.Local, Format: format},
-			Format:   format,
-		})
+		infos = append(infos, info)
 	}
 
 	if len(infos) == 0 && len(files) > 0 {
@@ -87,6 +79,25 @@ func GetOCSPs(path string) ([]*Info, error) {
 	return infos, nil
 }
 
+// NewInfo parses a PEM or DER OCSP response held in memory. The name is
+// reported as the response's file path in lint results.
+func NewInfo(data []byte, name string, sourceInfo source.Info) (*Info, error) {
+	resp, format, err := parseOCSP(data)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(resp.Raw)
+	sourceInfo.Format = format
+	return &Info{
+		Response: resp,
+		FilePath: name,
+		Hash:     hex.EncodeToString(hash[:]),
+		Source:   sourceInfo,
+		Format:   format,
+	}, nil
+}
+
 func infoFromDownloadedResponse(resp *ocsp.Response, requestInfo *RequestInfo, url string) *Info {
 	if resp == nil {
 		return nil
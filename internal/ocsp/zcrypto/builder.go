@@ -27,6 +27,23 @@ func BuildTree(resp *ocsp.Response) *node.Node {
 	return NewOCSPBuilder().Build(resp)
 }
 
+// Fields lists the top-level children an OCSP response tree may contain.
+var Fields = []string{
+	"status",
+	"serialNumber",
+	"producedAt",
+	"thisUpdate",
+	"nextUpdate",
+	"revokedAt",
+	"revocationReason",
+	"signatureAlgorithm",
+	"tbsSignatureAlgorithm",
+	"responderID",
+	"issuerHash",
+	"extensions",
+	"nonce",
+}
+
 func buildOCSP(resp *ocsp.Response) *node.Node {
 	root := node.New("ocsp", nil)
 
@@ -5,6 +5,7 @@ import "github.com/zmap/zcrypto/x509"
 
 const (
 	// Extended Key Usage OIDs (RFC 5280)
+	AnyExtKeyUsage  = "2.5.29.37.0"
 	ServerAuth      = "1.3.6.1.5.5.7.3.1"
 	ClientAuth      = "1.3.6.1.5.5.7.3.2"
 	CodeSigning     = "1.3.6.1.5.5.7.3.3"
@@ -12,9 +13,15 @@ const (
 	TimeStamping    = "1.3.6.1.5.5.7.3.8"
 	OCSPSigning     = "1.3.6.1.5.5.7.3.9"
 
+	// Certificate Transparency OIDs (RFC 6962 3.1)
+	PrecertSigning = "1.3.6.1.4.1.11129.2.4.4"
+	PrecertPoison  = "1.3.6.1.4.1.11129.2.4.3"
+
 	// CRL Extension OIDs (RFC 5280)
 	DeltaCRLIndicator        = "2.5.29.27"
-	IssuingDistributionPoint = "2.5.29.29"
+	IssuingDistributionPoint = "2.5.29.28"
+	FreshestCRL              = "2.5.29.46"
+	CRLDistributionPoints    = "2.5.29.31"
 )
 
 // NormalizeOID converts a friendly name to its OID string.
@@ -33,6 +40,8 @@ func NormalizeOID(nameOrOID string) string {
 		return TimeStamping
 	case "ocspSigning":
 		return OCSPSigning
+	case "precertSigning":
+		return PrecertSigning
 	case "deltaCRLIndicator":
 		return DeltaCRLIndicator
 	case "issuingDistributionPoint":
@@ -61,3 +70,25 @@ func ExtKeyUsageToOID(eku x509.ExtKeyUsage) string {
 		return ""
 	}
 }
+
+// ExtKeyUsageFromOID returns the x509.ExtKeyUsage value for an OID string.
+func ExtKeyUsageFromOID(oid string) (x509.ExtKeyUsage, bool) {
+	switch oid {
+	case AnyExtKeyUsage:
+		return x509.ExtKeyUsageAny, true
+	case ServerAuth:
+		return x509.ExtKeyUsageServerAuth, true
+	case ClientAuth:
+		return x509.ExtKeyUsageClientAuth, true
+	case CodeSigning:
+		return x509.ExtKeyUsageCodeSigning, true
+	case EmailProtection:
+		return x509.ExtKeyUsageEmailProtection, true
+	case TimeStamping:
+		return x509.ExtKeyUsageTimeStamping, true
+	case OCSPSigning:
+		return x509.ExtKeyUsageOcspSigning, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,153 @@
+package oid
+
+// Post-quantum algorithm families.
+const (
+	FamilyMLDSA     = "ML-DSA"
+	FamilyHashMLDSA = "HashML-DSA"
+	FamilySLHDSA    = "SLH-DSA"
+	FamilyMLKEM     = "ML-KEM"
+	FamilyComposite = "Composite ML-DSA"
+)
+
+// PQCAlgorithm describes a post-quantum or composite algorithm identifier.
+// Sizes are in octets. For composites they are the sizes of the ML-DSA
+// component, which is encoded first.
+type PQCAlgorithm struct {
+	OID              string
+	Name             string
+	Family           string
+	SecurityCategory int
+	PublicKeySize    int
+	SignatureSize    int // 0 for KEMs
+	Components       []string
+}
+
+// IsComposite reports whether the algorithm combines ML-DSA with a
+// traditional algorithm.
+func (a PQCAlgorithm) IsComposite() bool {
+	return a.Family == FamilyComposite
+}
+
+// ValidPublicKeyLength reports whether an encoded public key of n octets
+// fits the parameter set. Composite keys carry a traditional key after the
+// ML-DSA key, so they must be longer than it.
+func (a PQCAlgorithm) ValidPublicKeyLength(n int) bool {
+	if a.IsComposite() {
+		return n > a.PublicKeySize
+	}
+	return n == a.PublicKeySize
+}
+
+// ValidSignatureLength reports whether a signature of n octets fits the
+// parameter set. Always false for KEMs.
+func (a PQCAlgorithm) ValidSignatureLength(n int) bool {
+	if a.SignatureSize == 0 {
+		return false
+	}
+	if a.IsComposite() {
+		return n > a.SignatureSize
+	}
+	return n == a.SignatureSize
+}
+
+var (
+	mldsa44 = PQCAlgorithm{OID: "2.16.840.1.101.3.4.3.17", Name: "ML-DSA-44", Family: FamilyMLDSA, SecurityCategory: 2, PublicKeySize: 1312, SignatureSize: 2420}
+	mldsa65 = PQCAlgorithm{OID: "2.16.840.1.101.3.4.3.18", Name: "ML-DSA-65", Family: FamilyMLDSA, SecurityCategory: 3, PublicKeySize: 1952, SignatureSize: 3309}
+	mldsa87 = PQCAlgorithm{OID: "2.16.840.1.101.3.4.3.19", Name: "ML-DSA-87", Family: FamilyMLDSA, SecurityCategory: 5, PublicKeySize: 2592, SignatureSize: 4627}
+)
+
+// hashMLDSA returns the pre-hash variant of an ML-DSA parameter set.
+func hashMLDSA(oid string, base PQCAlgorithm) PQCAlgorithm {
+	a := base
+	a.OID = oid
+	a.Name = "Hash" + base.Name + "-with-SHA512"
+	a.Family = FamilyHashMLDSA
+	return a
+}
+
+// composite returns a composite ML-DSA algorithm whose sizes are those of
+// its ML-DSA component.
+func composite(oid, name string, mldsa PQCAlgorithm, traditional string) PQCAlgorithm {
+	return PQCAlgorithm{
+		OID:              oid,
+		Name:             name,
+		Family:           FamilyComposite,
+		SecurityCategory: mldsa.SecurityCategory,
+		PublicKeySize:    mldsa.PublicKeySize,
+		SignatureSize:    mldsa.SignatureSize,
+		Components:       []string{mldsa.Name, traditional},
+	}
+}
+
+func slhdsa(oid, name string, category, publicKeySize, signatureSize int) PQCAlgorithm {
+	return PQCAlgorithm{OID: oid, Name: name, Family: FamilySLHDSA, SecurityCategory: category, PublicKeySize: publicKeySize, SignatureSize: signatureSize}
+}
+
+func mlkem(oid, name string, category, publicKeySize int) PQCAlgorithm {
+	return PQCAlgorithm{OID: oid, Name: name, Family: FamilyMLKEM, SecurityCategory: category, PublicKeySize: publicKeySize}
+}
+
+// pqcAlgorithms lists the algorithms of FIPS 203, 204 and 205 as profiled
+// by draft-ietf-lamps-dilithium-certificates, draft-ietf-lamps-x509-slhdsa
+// and draft-ietf-lamps-kyber-certificates, and the composite ML-DSA
+// algorithms of draft-ietf-lamps-pq-composite-sigs. The composite OIDs are
+// still draft assignments.
+var pqcAlgorithms = []PQCAlgorithm{
+	mldsa44,
+	mldsa65,
+	mldsa87,
+	hashMLDSA("2.16.840.1.101.3.4.3.32", mldsa44),
+	hashMLDSA("2.16.840.1.101.3.4.3.33", mldsa65),
+	hashMLDSA("2.16.840.1.101.3.4.3.34", mldsa87),
+
+	slhdsa("2.16.840.1.101.3.4.3.20", "SLH-DSA-SHA2-128s", 1, 32, 7856),
+	slhdsa("2.16.840.1.101.3.4.3.21", "SLH-DSA-SHA2-128f", 1, 32, 17088),
+	slhdsa("2.16.840.1.101.3.4.3.22", "SLH-DSA-SHA2-192s", 3, 48, 16224),
+	slhdsa("2.16.840.1.101.3.4.3.23", "SLH-DSA-SHA2-192f", 3, 48, 35664),
+	slhdsa("2.16.840.1.101.3.4.3.24", "SLH-DSA-SHA2-256s", 5, 64, 29792),
+	slhdsa("2.16.840.1.101.3.4.3.25", "SLH-DSA-SHA2-256f", 5, 64, 49856),
+	slhdsa("2.16.840.1.101.3.4.3.26", "SLH-DSA-SHAKE-128s", 1, 32, 7856),
+	slhdsa("2.16.840.1.101.3.4.3.27", "SLH-DSA-SHAKE-128f", 1, 32, 17088),
+	slhdsa("2.16.840.1.101.3.4.3.28", "SLH-DSA-SHAKE-192s", 3, 48, 16224),
+	slhdsa("2.16.840.1.101.3.4.3.29", "SLH-DSA-SHAKE-192f", 3, 48, 35664),
+	slhdsa("2.16.840.1.101.3.4.3.30", "SLH-DSA-SHAKE-256s", 5, 64, 29792),
+	slhdsa("2.16.840.1.101.3.4.3.31", "SLH-DSA-SHAKE-256f", 5, 64, 49856),
+
+	mlkem("2.16.840.1.101.3.4.4.1", "ML-KEM-512", 1, 800),
+	mlkem("2.16.840.1.101.3.4.4.2", "ML-KEM-768", 3, 1184),
+	mlkem("2.16.840.1.101.3.4.4.3", "ML-KEM-1024", 5, 1568),
+
+	composite("1.3.6.1.5.5.7.6.37", "MLDSA44-RSA2048-PSS-SHA256", mldsa44, "RSA2048-PSS"),
+	composite("1.3.6.1.5.5.7.6.38", "MLDSA44-RSA2048-PKCS15-SHA256", mldsa44, "RSA2048-PKCS15"),
+	composite("1.3.6.1.5.5.7.6.39", "MLDSA44-Ed25519-SHA512", mldsa44, "Ed25519"),
+	composite("1.3.6.1.5.5.7.6.40", "MLDSA44-ECDSA-P256-SHA256", mldsa44, "ECDSA-P256"),
+	composite("1.3.6.1.5.5.7.6.41", "MLDSA65-RSA3072-PSS-SHA512", mldsa65, "RSA3072-PSS"),
+	composite("1.3.6.1.5.5.7.6.42", "MLDSA65-RSA3072-PKCS15-SHA512", mldsa65, "RSA3072-PKCS15"),
+	composite("1.3.6.1.5.5.7.6.43", "MLDSA65-RSA4096-PSS-SHA512", mldsa65, "RSA4096-PSS"),
+	composite("1.3.6.1.5.5.7.6.44", "MLDSA65-RSA4096-PKCS15-SHA512", mldsa65, "RSA4096-PKCS15"),
+	composite("1.3.6.1.5.5.7.6.45", "MLDSA65-ECDSA-P256-SHA512", mldsa65, "ECDSA-P256"),
+	composite("1.3.6.1.5.5.7.6.46", "MLDSA65-ECDSA-P384-SHA512", mldsa65, "ECDSA-P384"),
+	composite("1.3.6.1.5.5.7.6.47", "MLDSA65-ECDSA-brainpoolP256r1-SHA512", mldsa65, "ECDSA-brainpoolP256r1"),
+	composite("1.3.6.1.5.5.7.6.48", "MLDSA65-Ed25519-SHA512", mldsa65, "Ed25519"),
+	composite("1.3.6.1.5.5.7.6.49", "MLDSA87-ECDSA-P384-SHA512", mldsa87, "ECDSA-P384"),
+	composite("1.3.6.1.5.5.7.6.50", "MLDSA87-ECDSA-brainpoolP384r1-SHA512", mldsa87, "ECDSA-brainpoolP384r1"),
+	composite("1.3.6.1.5.5.7.6.51", "MLDSA87-Ed448-SHAKE256", mldsa87, "Ed448"),
+	composite("1.3.6.1.5.5.7.6.52", "MLDSA87-RSA3072-PSS-SHA512", mldsa87, "RSA3072-PSS"),
+	composite("1.3.6.1.5.5.7.6.53", "MLDSA87-RSA4096-PSS-SHA512", mldsa87, "RSA4096-PSS"),
+	composite("1.3.6.1.5.5.7.6.54", "MLDSA87-ECDSA-P521-SHA512", mldsa87, "ECDSA-P521"),
+}
+
+var pqcByOID = func() map[string]PQCAlgorithm {
+	m := make(map[string]PQCAlgorithm, len(pqcAlgorithms))
+	for _, a := range pqcAlgorithms {
+		m[a.OID] = a
+	}
+	return m
+}()
+
+// LookupPQC returns the post-quantum or composite algorithm with the given
+// OID.
+func LookupPQC(oid string) (PQCAlgorithm, bool) {
+	a, ok := pqcByOID[oid]
+	return a, ok
+}
@@ -2,10 +2,18 @@ package operator
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/zmap/zcrypto/x509"
 
 	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/oid"
 )
 
+// SignatureValid checks the certificate signature with the key of its
+// issuer, or its own key for roots. Signature algorithms without a verifier,
+// such as ML-DSA, are reported as ErrUnsupported.
 type SignatureValid struct{}
 
 func (SignatureValid) Name() string { return "signatureValid" }
@@ -19,8 +27,7 @@ func (SignatureValid) Evaluate(_ *node.Node, ctx *EvaluationContext, _ []any) (b
 	position := ctx.Cert.Position
 
 	if ctx.Cert.Type == "root" {
-		err := cert.CheckSignatureFrom(cert)
-		return err == nil, nil
+		return signatureResult(cert, cert.CheckSignatureFrom(cert))
 	}
 
 	if position+1 >= len(ctx.Chain) {
@@ -32,7 +39,17 @@ func (SignatureValid) Evaluate(_ *node.Node, ctx *EvaluationContext, _ []any) (b
 		return false, nil
 	}
 
-	err := cert.CheckSignatureFrom(issuer.Cert)
+	return signatureResult(cert, cert.CheckSignatureFrom(issuer.Cert))
+}
+
+func signatureResult(cert *x509.Certificate, err error) (bool, error) {
+	if errors.Is(err, x509.ErrUnsupportedAlgorithm) {
+		name := cert.SignatureAlgorithmOID.String()
+		if alg, ok := oid.LookupPQC(name); ok {
+			name = alg.Name
+		}
+		return false, fmt.Errorf("%w: no verifier for signature algorithm %s", ErrUnsupported, name)
+	}
 	return err == nil, nil
 }
 
@@ -1,8 +1,13 @@
 package operator
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
+
 	"github.com/cavoq/PCL/internal/cert"
 	"github.com/cavoq/PCL/internal/node"
 )
@@ -119,3 +124,20 @@ func TestAKIMatchesSKINoAKI(t *testing.T) {
 		t.Error("nil cert should return false")
 	}
 }
+
+func TestSignatureValidUnsupportedAlgorithm(t *testing.T) {
+	mldsa := &x509.Certificate{
+		Version:               3,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SignatureAlgorithmOID: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 18},
+	}
+	ctx := &EvaluationContext{Cert: &cert.Info{Type: "root", Cert: mldsa}}
+	_, err := SignatureValid{}.Evaluate(nil, ctx, nil)
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "ML-DSA-65") {
+		t.Errorf("error should name the algorithm: %v", err)
+	}
+}
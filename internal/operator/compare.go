@@ -21,6 +21,10 @@ func (Gte) Evaluate(n *node.Node, ctx *EvaluationContext, operands []any) (bool,
 	return compareNumbers(n.Value, operands[0], func(a, b float64) bool { return a >= b })
 }
 
+func (Gte) Explain(n *node.Node, _ *EvaluationContext, operands []any) Explanation {
+	return comparisonExplanation(n, operands, ">=")
+}
+
 type Gt struct{}
 
 func (Gt) Name() string { return "gt" }
@@ -35,6 +39,10 @@ func (Gt) Evaluate(n *node.Node, ctx *EvaluationContext, operands []any) (bool,
 	return compareNumbers(n.Value, operands[0], func(a, b float64) bool { return a > b })
 }
 
+func (Gt) Explain(n *node.Node, _ *EvaluationContext, operands []any) Explanation {
+	return comparisonExplanation(n, operands, ">")
+}
+
 type Lte struct{}
 
 func (Lte) Name() string { return "lte" }
@@ -49,6 +57,10 @@ func (Lte) Evaluate(n *node.Node, ctx *EvaluationContext, operands []any) (bool,
 	return compareNumbers(n.Value, operands[0], func(a, b float64) bool { return a <= b })
 }
 
+func (Lte) Explain(n *node.Node, _ *EvaluationContext, operands []any) Explanation {
+	return comparisonExplanation(n, operands, "<=")
+}
+
 type Lt struct{}
 
 func (Lt) Name() string { return "lt" }
@@ -63,6 +75,10 @@ func (Lt) Evaluate(n *node.Node, ctx *EvaluationContext, operands []any) (bool,
 	return compareNumbers(n.Value, operands[0], func(a, b float64) bool { return a < b })
 }
 
+func (Lt) Explain(n *node.Node, _ *EvaluationContext, operands []any) Explanation {
+	return comparisonExplanation(n, operands, "<")
+}
+
 func compareNumbers(val, operand any, cmp func(a, b float64) bool) (bool, error) {
 	a, ok := ToFloat64(val)
 	if !ok {
@@ -136,3 +152,11 @@ func (Odd) Evaluate(n *node.Node, _ *EvaluationContext, _ []any) (bool, error) {
 		return false, nil
 	}
 }
+
+func comparisonExplanation(n *node.Node, operands []any, symbol string) Explanation {
+	e := defaultExplanation(n, nil)
+	if len(operands) == 1 {
+		e.Expected = fmt.Sprintf("%s %v", symbol, operands[0])
+	}
+	return e
+}
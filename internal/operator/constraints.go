@@ -1,6 +1,8 @@
 package operator
 
 import (
+	"fmt"
+
 	"github.com/cavoq/PCL/internal/node"
 )
 
@@ -84,6 +86,18 @@ func (ValidityPeriodDays) Evaluate(n *node.Node, ctx *EvaluationContext, operand
 	return days >= minDays && days <= maxDays, nil
 }
 
+func (ValidityPeriodDays) Explain(_ *node.Node, ctx *EvaluationContext, operands []any) Explanation {
+	e := Explanation{Path: "certificate.validity", Detail: "validity period in days"}
+	if ctx.HasCert() {
+		days := ctx.Cert.Cert.NotAfter.Sub(ctx.Cert.Cert.NotBefore).Hours() / 24
+		e.Actual = float64(int64(days*100)) / 100
+	}
+	if len(operands) >= 2 {
+		e.Expected = fmt.Sprintf(">= %v and <= %v", operands[0], operands[1])
+	}
+	return e
+}
+
 type SANRequiredIfEmptySubject struct{}
 
 func (SANRequiredIfEmptySubject) Name() string { return "sanRequiredIfEmptySubject" }
@@ -7,6 +7,7 @@ import (
 	"github.com/cavoq/PCL/internal/crl"
 	"github.com/cavoq/PCL/internal/node"
 	"github.com/cavoq/PCL/internal/ocsp"
+	"github.com/cavoq/PCL/internal/trust"
 )
 
 type EvaluationContext struct {
@@ -16,6 +17,7 @@ type EvaluationContext struct {
 	Chain []*cert.Info
 	CRLs  []*crl.Info
 	OCSPs []*ocsp.Info
+	Trust *trust.Store
 }
 
 func (ctx *EvaluationContext) HasCert() bool {
@@ -76,6 +78,24 @@ func WithOCSPs(ocsps []*ocsp.Info) ContextOption {
 	}
 }
 
+// WithNow sets the time that date and validity checks are evaluated at. A
+// zero time keeps the current time.
+func WithNow(now time.Time) ContextOption {
+	return func(ctx *EvaluationContext) {
+		if !now.IsZero() {
+			ctx.Now = now
+		}
+	}
+}
+
+// WithTrustStore sets the trust anchors chains are resolved against. A nil
+// store treats a self-signed chain root as the anchor.
+func WithTrustStore(store *trust.Store) ContextOption {
+	return func(ctx *EvaluationContext) {
+		ctx.Trust = store
+	}
+}
+
 func NewEvaluationContext(root *node.Node, c *cert.Info, chain []*cert.Info, opts ...ContextOption) *EvaluationContext {
 	ctx := &EvaluationContext{
 		Root:  root,
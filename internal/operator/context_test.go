@@ -124,6 +124,24 @@ func TestNewEvaluationContext_NowIsRecent(t *testing.T) {
 	}
 }
 
+func TestWithNow_SetsEvaluationTime(t *testing.T) {
+	at := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	ctx := NewEvaluationContext(nil, nil, nil, WithNow(at))
+
+	if !ctx.Now.Equal(at) {
+		t.Errorf("Now = %v, want %v", ctx.Now, at)
+	}
+}
+
+func TestWithNow_ZeroKeepsCurrentTime(t *testing.T) {
+	before := time.Now()
+	ctx := NewEvaluationContext(nil, nil, nil, WithNow(time.Time{}))
+
+	if ctx.Now.Before(before) {
+		t.Error("zero time should keep the current time")
+	}
+}
+
 func TestWithCRLs_NilSlice(t *testing.T) {
 	root := node.New("root", nil)
 	ctx := NewEvaluationContext(root, nil, nil, WithCRLs(nil))
@@ -1,6 +1,9 @@
 package operator
 
 import (
+	"fmt"
+
+	"github.com/cavoq/PCL/internal/crl"
 	"github.com/cavoq/PCL/internal/node"
 	"github.com/zmap/zcrypto/x509"
 )
@@ -111,28 +114,121 @@ func (NotRevoked) Evaluate(_ *node.Node, ctx *EvaluationContext, _ []any) (bool,
 
 	cert := ctx.Cert.Cert
 	certSerial := cert.SerialNumber.String()
-	certIssuer := cert.Issuer.String()
 
 	if !ctx.HasCRLs() {
 		return true, nil // No CRLs = not revoked
 	}
 
 	for _, crlInfo := range ctx.CRLs {
-		if crlInfo.CRL == nil {
+		// Skips CRLs of other issuers and partitioned CRLs whose scope
+		// excludes the certificate
+		covers, err := crl.Covers(crlInfo.CRL, cert)
+		if err != nil {
+			return false, fmt.Errorf("CRL %s: %w", crlInfo.FilePath, err)
+		}
+		if !covers {
 			continue
 		}
-		crl := crlInfo.CRL
 
-		if crl.Issuer.String() != certIssuer {
+		for _, revoked := range crlInfo.CRL.RevokedCertificates {
+			if revoked.SerialNumber != nil && revoked.SerialNumber.String() == certSerial {
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// NotRevokedWithDelta checks the certificate against each complete CRL of
+// its issuer merged with the newest delta CRL that updates it (RFC 5280
+// 5.2.4), so that a hold released with removeFromCRL no longer counts as a
+// revocation. A delta CRL is consulted on its own when no loaded complete
+// CRL is merged with it or a newer delta, such as when its BaseCRLNumber is
+// ahead of every loaded complete CRL. Like notRevoked, it ignores CRLs whose
+// scope excludes the certificate and fails with an error when a CRL's scope
+// cannot be decoded.
+type NotRevokedWithDelta struct{}
+
+func (NotRevokedWithDelta) Name() string { return "notRevokedWithDelta" }
+
+func (NotRevokedWithDelta) Evaluate(_ *node.Node, ctx *EvaluationContext, _ []any) (bool, error) {
+	if ctx == nil || ctx.Cert == nil || ctx.Cert.Cert == nil {
+		return false, nil
+	}
+
+	cert := ctx.Cert.Cert
+	certSerial := cert.SerialNumber.String()
+
+	if !ctx.HasCRLs() {
+		return true, nil // No CRLs = not revoked
+	}
+
+	for _, crlInfo := range ctx.CRLs {
+		covers, err := crl.Covers(crlInfo.CRL, cert)
+		if err != nil {
+			return false, fmt.Errorf("CRL %s: %w", crlInfo.FilePath, err)
+		}
+		if !covers {
 			continue
 		}
 
-		for _, revoked := range crl.RevokedCertificates {
-			if revoked.SerialNumber != nil && revoked.SerialNumber.String() == certSerial {
+		var revoked []x509.RevokedCertificate
+		if crl.HasDeltaIndicator(crlInfo.CRL) {
+			if crl.MergedWithComplete(crlInfo.CRL, ctx.CRLs) {
+				continue // consulted through its complete CRL
+			}
+			revoked = crl.Merge(nil, crlInfo.CRL)
+		} else {
+			var delta *x509.RevocationList
+			if d := crl.DeltaFor(crlInfo.CRL, ctx.CRLs); d != nil {
+				delta = d.CRL
+			}
+			revoked = crl.Merge(crlInfo.CRL, delta)
+		}
+
+		for _, rc := range revoked {
+			if rc.SerialNumber != nil && rc.SerialNumber.String() == certSerial {
 				return false, nil
 			}
 		}
 	}
 
 	return true, nil
-}
\ No newline at end of file
+}
+
+// CRLCoversCertificate checks that the CRLs of the certificate's issuer
+// have the certificate in their scope for every revocation reason: a CRL's
+// issuingDistributionPoint, if any, must admit the certificate type and name
+// one of the certificate's CRL distribution points, and CRLs limited by
+// onlySomeReasons only count once together they cover all reasons (RFC 5280
+// 5.2.5, 6.3.3).
+type CRLCoversCertificate struct{}
+
+func (CRLCoversCertificate) Name() string { return "crlCoversCertificate" }
+
+func (CRLCoversCertificate) Evaluate(_ *node.Node, ctx *EvaluationContext, _ []any) (bool, error) {
+	if ctx == nil || ctx.Cert == nil || ctx.Cert.Cert == nil || !ctx.HasCRLs() {
+		return false, nil
+	}
+
+	var reasons []string
+	for _, crlInfo := range ctx.CRLs {
+		covers, err := crl.Covers(crlInfo.CRL, ctx.Cert.Cert)
+		if err != nil {
+			return false, fmt.Errorf("CRL %s: %w", crlInfo.FilePath, err)
+		}
+		if !covers {
+			continue
+		}
+
+		// Covers has already decoded the issuingDistributionPoint
+		idp, _ := crl.IssuingDistributionPointOf(crlInfo.CRL)
+		if idp.CoversAllReasons() {
+			return true, nil
+		}
+		reasons = append(reasons, idp.OnlySomeReasons...)
+	}
+
+	return crl.CoverAllReasons(reasons), nil
+}
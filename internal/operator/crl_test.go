@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/zmap/zcrypto/encoding/asn1"
 	"github.com/zmap/zcrypto/x509"
 	"github.com/zmap/zcrypto/x509/pkix"
 
@@ -443,3 +444,256 @@ func TestNotRevokedNilCRLInInfo(t *testing.T) {
 		t.Error("should skip nil CRL and check others")
 	}
 }
+
+func TestNotRevokedWithDeltaName(t *testing.T) {
+	op := NotRevokedWithDelta{}
+	if op.Name() != "notRevokedWithDelta" {
+		t.Error("wrong name")
+	}
+}
+
+func TestNotRevokedWithDeltaNilContext(t *testing.T) {
+	op := NotRevokedWithDelta{}
+	got, err := op.Evaluate(nil, nil, nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got {
+		t.Error("nil context should return false")
+	}
+}
+
+// deltaTestCRLs returns a delta CRL 11 over base 10, preceded by a complete
+// CRL numbered baseNumber unless it is 0.
+func deltaTestCRLs(t *testing.T, issuer pkix.Name, baseNumber int64) []*crl.Info {
+	t.Helper()
+	deltaBase, err := asn1.Marshal(big.NewInt(10))
+	if err != nil {
+		t.Fatalf("failed to marshal BaseCRLNumber: %v", err)
+	}
+	held, removed, compromised := 6, crl.ReasonRemoveFromCRL, 1
+
+	delta := &crl.Info{CRL: &x509.RevocationList{
+		Issuer:    issuer,
+		RawIssuer: []byte(issuer.String()),
+		Number:    big.NewInt(11),
+		Extensions: []pkix.Extension{{
+			Id:       asn1.ObjectIdentifier{2, 5, 29, 27},
+			Critical: true,
+			Value:    deltaBase,
+		}},
+		RevokedCertificates: []x509.RevokedCertificate{
+			{SerialNumber: big.NewInt(123), ReasonCode: &removed},
+			{SerialNumber: big.NewInt(456), ReasonCode: &compromised},
+		},
+	}}
+	if baseNumber == 0 {
+		return []*crl.Info{delta}
+	}
+
+	base := &crl.Info{CRL: &x509.RevocationList{
+		Issuer:    issuer,
+		RawIssuer: []byte(issuer.String()),
+		Number:    big.NewInt(baseNumber),
+		RevokedCertificates: []x509.RevokedCertificate{
+			{SerialNumber: big.NewInt(123), ReasonCode: &held},
+			{SerialNumber: big.NewInt(789)},
+		},
+	}}
+	return []*crl.Info{base, delta}
+}
+
+func TestNotRevokedWithDelta(t *testing.T) {
+	issuer := pkix.Name{CommonName: "Test CA"}
+	tests := []struct {
+		name       string
+		serial     int64
+		baseNumber int64
+		want       bool
+	}{
+		{"hold removed by delta", 123, 10, true},
+		{"revoked by delta", 456, 10, false},
+		{"revoked by base", 789, 10, false},
+		{"not listed", 1000, 10, true},
+		{"delta without base revokes", 456, 0, false},
+		{"delta without base skips removeFromCRL", 123, 0, true},
+		{"delta ahead of stale base revokes", 456, 9, false},
+		{"stale base keeps its hold", 123, 9, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &EvaluationContext{
+				Cert: &cert.Info{
+					Cert: &x509.Certificate{
+						SerialNumber: big.NewInt(tt.serial),
+						Issuer:       issuer,
+					},
+				},
+				CRLs: deltaTestCRLs(t, issuer, tt.baseNumber),
+			}
+			got, err := NotRevokedWithDelta{}.Evaluate(nil, ctx, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNotRevokedIgnoresDeltaRemoval(t *testing.T) {
+	issuer := pkix.Name{CommonName: "Test CA"}
+	ctx := &EvaluationContext{
+		Cert: &cert.Info{
+			Cert: &x509.Certificate{
+				SerialNumber: big.NewInt(123),
+				Issuer:       issuer,
+			},
+		},
+		CRLs: deltaTestCRLs(t, issuer, 10),
+	}
+	got, err := NotRevoked{}.Evaluate(nil, ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got {
+		t.Error("notRevoked should still see the hold on the complete CRL")
+	}
+}
+
+func usersOnlyCRL(serial *big.Int) *crl.Info {
+	// issuingDistributionPoint { onlyContainsUserCerts TRUE }
+	return &crl.Info{CRL: &x509.RevocationList{
+		Issuer: pkix.Name{CommonName: "Test CA"},
+		Extensions: []pkix.Extension{{
+			Id:       asn1.ObjectIdentifier{2, 5, 29, 28},
+			Critical: true,
+			Value:    []byte{0x30, 0x03, 0x81, 0x01, 0xff},
+		}},
+		RevokedCertificates: []x509.RevokedCertificate{{SerialNumber: serial}},
+	}}
+}
+
+func TestNotRevokedIgnoresOutOfScopeCRL(t *testing.T) {
+	serial := big.NewInt(123)
+	ctx := &EvaluationContext{
+		Cert: &cert.Info{
+			Cert: &x509.Certificate{
+				SerialNumber:          serial,
+				Issuer:                pkix.Name{CommonName: "Test CA"},
+				BasicConstraintsValid: true,
+				IsCA:                  true,
+			},
+		},
+		CRLs: []*crl.Info{usersOnlyCRL(serial)},
+	}
+	for _, op := range []Operator{NotRevoked{}, NotRevokedWithDelta{}} {
+		got, err := op.Evaluate(nil, ctx, nil)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", op.Name(), err)
+		}
+		if !got {
+			t.Errorf("%s: user-only CRL should not revoke a CA certificate", op.Name())
+		}
+	}
+
+	ctx.Cert.Cert.IsCA = false
+	got, err := NotRevoked{}.Evaluate(nil, ctx, nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got {
+		t.Error("user-only CRL should revoke a user certificate")
+	}
+}
+
+func reasonsOnlyCRL(first, second byte) *crl.Info {
+	// issuingDistributionPoint { onlySomeReasons } with nine ReasonFlags bits
+	return &crl.Info{CRL: &x509.RevocationList{
+		Issuer: pkix.Name{CommonName: "Test CA"},
+		Extensions: []pkix.Extension{{
+			Id:       asn1.ObjectIdentifier{2, 5, 29, 28},
+			Critical: true,
+			Value:    []byte{0x30, 0x05, 0x83, 0x03, 0x07, first, second},
+		}},
+	}}
+}
+
+func TestNotRevokedMalformedIssuingDistributionPoint(t *testing.T) {
+	serial := big.NewInt(123)
+	ctx := &EvaluationContext{
+		Cert: &cert.Info{
+			Cert: &x509.Certificate{SerialNumber: serial, Issuer: pkix.Name{CommonName: "Test CA"}},
+		},
+		CRLs: []*crl.Info{{CRL: &x509.RevocationList{
+			Issuer:              pkix.Name{CommonName: "Test CA"},
+			Extensions:          []pkix.Extension{{Id: asn1.ObjectIdentifier{2, 5, 29, 28}, Value: []byte{0x01}}},
+			RevokedCertificates: []x509.RevokedCertificate{{SerialNumber: serial}},
+		}}},
+	}
+	for _, op := range []Operator{NotRevoked{}, NotRevokedWithDelta{}, CRLCoversCertificate{}} {
+		got, err := op.Evaluate(nil, ctx, nil)
+		if err == nil {
+			t.Errorf("%s: expected error for malformed issuingDistributionPoint", op.Name())
+		}
+		if got {
+			t.Errorf("%s: expected false for malformed issuingDistributionPoint", op.Name())
+		}
+	}
+}
+
+func TestCRLCoversCertificateName(t *testing.T) {
+	op := CRLCoversCertificate{}
+	if op.Name() != "crlCoversCertificate" {
+		t.Error("wrong name")
+	}
+}
+
+func TestCRLCoversCertificate(t *testing.T) {
+	issuer := pkix.Name{CommonName: "Test CA"}
+	tests := []struct {
+		name string
+		isCA bool
+		crls []*crl.Info
+		want bool
+	}{
+		{"no CRLs", false, nil, false},
+		{"full CRL", false, []*crl.Info{{CRL: &x509.RevocationList{Issuer: issuer}}}, true},
+		{"other issuer", false, []*crl.Info{{CRL: &x509.RevocationList{Issuer: pkix.Name{CommonName: "Other CA"}}}}, false},
+		{"user-only CRL, user cert", false, []*crl.Info{usersOnlyCRL(big.NewInt(1))}, true},
+		{"user-only CRL, CA cert", true, []*crl.Info{usersOnlyCRL(big.NewInt(1))}, false},
+		{"nil CRL", false, []*crl.Info{{CRL: nil}}, false},
+		{"some reasons only", false, []*crl.Info{reasonsOnlyCRL(0x7f, 0x00)}, false},
+		{"reasons partitioned across CRLs", false, []*crl.Info{reasonsOnlyCRL(0x7f, 0x00), reasonsOnlyCRL(0x00, 0x80)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &EvaluationContext{
+				Cert: &cert.Info{
+					Cert: &x509.Certificate{
+						SerialNumber:          big.NewInt(123),
+						Issuer:                issuer,
+						BasicConstraintsValid: true,
+						IsCA:                  tt.isCA,
+					},
+				},
+				CRLs: tt.crls,
+			}
+			got, err := CRLCoversCertificate{}.Evaluate(nil, ctx, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	got, err := CRLCoversCertificate{}.Evaluate(nil, nil, nil)
+	if err != nil || got {
+		t.Error("nil context should return false")
+	}
+}
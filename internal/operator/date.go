@@ -29,6 +29,10 @@ func (Before) Evaluate(n *node.Node, ctx *EvaluationContext, operands []any) (bo
 	return nodeTime.Before(compareTime), nil
 }
 
+func (Before) Explain(n *node.Node, ctx *EvaluationContext, operands []any) Explanation {
+	return timeExplanation(n, ctx, operands, "before")
+}
+
 type After struct{}
 
 func (After) Name() string { return "after" }
@@ -51,6 +55,10 @@ func (After) Evaluate(n *node.Node, ctx *EvaluationContext, operands []any) (boo
 	return nodeTime.After(compareTime), nil
 }
 
+func (After) Explain(n *node.Node, ctx *EvaluationContext, operands []any) Explanation {
+	return timeExplanation(n, ctx, operands, "after")
+}
+
 func getCompareTime(operands []any, ctx *EvaluationContext) (time.Time, error) {
 	if len(operands) == 0 {
 		if ctx != nil {
@@ -278,3 +286,11 @@ func (DateDiff) Evaluate(n *node.Node, _ *EvaluationContext, operands []any) (bo
 
 	return true, nil
 }
+
+func timeExplanation(n *node.Node, ctx *EvaluationContext, operands []any, relation string) Explanation {
+	e := defaultExplanation(n, nil)
+	if compareTime, err := getCompareTime(operands, ctx); err == nil {
+		e.Expected = relation + " " + compareTime.UTC().Format(time.RFC3339)
+	}
+	return e
+}
@@ -37,71 +37,15 @@ func (Every) Evaluate(n *node.Node, ctx *EvaluationContext, operands []any) (boo
 		return false, nil
 	}
 
-	// Parse operands
-	if len(operands) == 0 {
-		return false, fmt.Errorf("every operator requires operands")
-	}
-
-	var subPath string
-	var innerOp string
-	var innerOperands []any
-	var skipMissing bool
-
-	if m, ok := operands[0].(map[string]any); ok {
-		if p, ok := m["path"].(string); ok {
-			subPath = p
-		}
-		// Use "operator" for inner operator (consistent naming)
-		if op, ok := m["operator"].(string); ok {
-			innerOp = op
-		}
-		// Also support legacy "check" for backwards compatibility
-		if c, ok := m["check"].(string); ok && innerOp == "" {
-			innerOp = c
-		}
-		if v, ok := m["operands"]; ok {
-			switch val := v.(type) {
-			case []any:
-				innerOperands = val
-			case map[string]any:
-				innerOperands = []any{val}
-			default:
-				innerOperands = []any{val}
-			}
-		}
-		// Also support legacy "values" for backwards compatibility
-		if vs, ok := m["values"]; ok && len(innerOperands) == 0 {
-			switch val := vs.(type) {
-			case []any:
-				innerOperands = val
-			default:
-				innerOperands = []any{val}
-			}
-		}
-		if s, ok := m["skipMissing"].(bool); ok {
-			skipMissing = s
-		}
-	} else if len(operands) >= 2 {
-		// Alternative: parse as [path, operator, operands...]
-		if p, ok := operands[0].(string); ok {
-			subPath = p
-		}
-		if op, ok := operands[1].(string); ok {
-			innerOp = op
-		}
-		if len(operands) > 2 {
-			innerOperands = operands[2:]
-		}
-	}
-
-	if innerOp == "" {
-		return false, fmt.Errorf("every operator requires 'operator' operand")
+	args, err := parseEveryOperands(operands)
+	if err != nil {
+		return false, err
 	}
 
 	registry := DefaultRegistry()
-	op, err := registry.Get(innerOp)
+	op, err := registry.Get(args.operator)
 	if err != nil {
-		return false, fmt.Errorf("every: unknown operator '%s'", innerOp)
+		return false, fmt.Errorf("every: unknown operator '%s'", args.operator)
 	}
 
 	// If node has no children, trivially true
@@ -116,12 +60,12 @@ func (Every) Evaluate(n *node.Node, ctx *EvaluationContext, operands []any) (boo
 		}
 
 		var targetNode *node.Node
-		if subPath == "" {
+		if args.path == "" {
 			targetNode = child
 		} else {
-			targetNode = resolvePath(child, subPath)
+			targetNode = resolvePath(child, args.path)
 			if targetNode == nil {
-				if skipMissing {
+				if args.skipMissing {
 					continue
 				}
 				return false, nil
@@ -134,7 +78,7 @@ func (Every) Evaluate(n *node.Node, ctx *EvaluationContext, operands []any) (boo
 				if subChild == nil {
 					continue
 				}
-				result, err := op.Evaluate(subChild, ctx, innerOperands)
+				result, err := op.Evaluate(subChild, ctx, args.operands)
 				if err != nil {
 					return false, err
 				}
@@ -143,7 +87,7 @@ func (Every) Evaluate(n *node.Node, ctx *EvaluationContext, operands []any) (boo
 				}
 			}
 		} else {
-			result, err := op.Evaluate(targetNode, ctx, innerOperands)
+			result, err := op.Evaluate(targetNode, ctx, args.operands)
 			if err != nil {
 				return false, err
 			}
@@ -156,6 +100,75 @@ func (Every) Evaluate(n *node.Node, ctx *EvaluationContext, operands []any) (boo
 	return true, nil
 }
 
+// everyArgs holds the parsed operands of the every operator.
+type everyArgs struct {
+	path        string
+	operator    string
+	operands    []any
+	skipMissing bool
+}
+
+// parseEveryOperands accepts either the map form or the positional
+// [path, operator, operands...] form.
+func parseEveryOperands(operands []any) (everyArgs, error) {
+	var args everyArgs
+	if len(operands) == 0 {
+		return args, fmt.Errorf("every operator requires operands")
+	}
+
+	if m, ok := operands[0].(map[string]any); ok {
+		if p, ok := m["path"].(string); ok {
+			args.path = p
+		}
+		// Use "operator" for inner operator (consistent naming)
+		if op, ok := m["operator"].(string); ok {
+			args.operator = op
+		}
+		// Also support legacy "check" for backwards compatibility
+		if c, ok := m["check"].(string); ok && args.operator == "" {
+			args.operator = c
+		}
+		if v, ok := m["operands"]; ok {
+			switch val := v.(type) {
+			case []any:
+				args.operands = val
+			case map[string]any:
+				args.operands = []any{val}
+			default:
+				args.operands = []any{val}
+			}
+		}
+		// Also support legacy "values" for backwards compatibility
+		if vs, ok := m["values"]; ok && len(args.operands) == 0 {
+			switch val := vs.(type) {
+			case []any:
+				args.operands = val
+			default:
+				args.operands = []any{val}
+			}
+		}
+		if s, ok := m["skipMissing"].(bool); ok {
+			args.skipMissing = s
+		}
+	} else if len(operands) >= 2 {
+		// Alternative: parse as [path, operator, operands...]
+		if p, ok := operands[0].(string); ok {
+			args.path = p
+		}
+		if op, ok := operands[1].(string); ok {
+			args.operator = op
+		}
+		if len(operands) > 2 {
+			args.operands = operands[2:]
+		}
+	}
+
+	if args.operator == "" {
+		return args, fmt.Errorf("every operator requires 'operator' operand")
+	}
+	return args, nil
+}
+
 // resolvePath resolves a dot-separated path from a node.
 // Handles OID-style keys that contain dots (e.g., "2.5.29.21").
 // Supports `*` wildcard to match all children at that level.
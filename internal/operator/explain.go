@@ -0,0 +1,138 @@
+package operator
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/cavoq/PCL/internal/node"
+)
+
+// maxDisplayLen bounds the length of string values shown in explanations.
+const maxDisplayLen = 128
+
+// Explanation describes the values behind a failed operator check: the path
+// that was checked, the value observed there and what the operator expected.
+type Explanation struct {
+	Path     string `json:"path,omitempty" yaml:"path,omitempty"`
+	Actual   any    `json:"actual" yaml:"actual"`
+	Expected any    `json:"expected" yaml:"expected"`
+	Detail   string `json:"detail,omitempty" yaml:"detail,omitempty"`
+}
+
+// Explainer is implemented by operators that can describe a failed check
+// more precisely than the target node value and the raw operands, for
+// example when the compared quantity is derived from the node.
+type Explainer interface {
+	Explain(n *node.Node, ctx *EvaluationContext, operands []any) Explanation
+}
+
+// Explain builds the explanation for a failed check of op on the node at
+// path. Operators that do not implement Explainer report the node value and
+// their operands.
+func Explain(op Operator, path string, n *node.Node, ctx *EvaluationContext, operands []any) Explanation {
+	var e Explanation
+	if ex, ok := op.(Explainer); ok {
+		e = ex.Explain(n, ctx, operands)
+	} else {
+		e = defaultExplanation(n, operands)
+	}
+	if e.Path == "" {
+		e.Path = path
+	}
+	e.Actual = displayValue(e.Actual)
+	e.Expected = displayValue(e.Expected)
+	return e
+}
+
+// String renders the explanation as a one-line message.
+func (e Explanation) String() string {
+	var b strings.Builder
+	if e.Path != "" {
+		b.WriteString(e.Path)
+		b.WriteString(": ")
+	}
+	if e.Actual == nil {
+		b.WriteString("not present")
+	} else {
+		fmt.Fprintf(&b, "got %v", e.Actual)
+	}
+	if e.Expected != nil {
+		fmt.Fprintf(&b, ", expected %v", e.Expected)
+	}
+	if e.Detail != "" {
+		fmt.Fprintf(&b, " (%s)", e.Detail)
+	}
+	return b.String()
+}
+
+func defaultExplanation(n *node.Node, operands []any) Explanation {
+	e := Explanation{}
+	if n != nil {
+		e.Actual = n.Value
+		if n.Value == nil && len(n.Children) > 0 {
+			e.Actual = fmt.Sprintf("<%d children>", len(n.Children))
+		}
+	}
+	switch len(operands) {
+	case 0:
+	case 1:
+		e.Expected = operands[0]
+	default:
+		e.Expected = operands
+	}
+	return e
+}
+
+// displayValue converts node values into types that render and serialize
+// readably in text, JSON and YAML output.
+func displayValue(v any) any {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return truncate(t)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return t
+	case time.Time:
+		return t.UTC().Format(time.RFC3339)
+	case []byte:
+		return truncate(hex.EncodeToString(t))
+	case *big.Int:
+		if t == nil {
+			return nil
+		}
+		return t.String()
+	case []any:
+		out := make([]any, len(t))
+		for i, item := range t {
+			out[i] = displayValue(item)
+		}
+		return out
+	case []string:
+		out := make([]any, len(t))
+		for i, item := range t {
+			out[i] = truncate(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, item := range t {
+			out[k] = displayValue(item)
+		}
+		return out
+	case fmt.Stringer:
+		return truncate(t.String())
+	default:
+		return truncate(fmt.Sprintf("%v", t))
+	}
+}
+
+func truncate(s string) string {
+	if len(s) <= maxDisplayLen {
+		return s
+	}
+	return s[:maxDisplayLen] + "..."
+}
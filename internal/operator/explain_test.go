@@ -0,0 +1,70 @@
+package operator
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/cavoq/PCL/internal/node"
+)
+
+func TestExplainDefault(t *testing.T) {
+	n := node.New("version", 1)
+	e := Explain(Eq{}, "certificate.version", n, nil, []any{3})
+
+	if e.Path != "certificate.version" || e.Actual != 1 || e.Expected != 3 {
+		t.Errorf("unexpected explanation: %+v", e)
+	}
+	if got := e.String(); got != "certificate.version: got 1, expected 3" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestExplainMissingNode(t *testing.T) {
+	e := Explain(Present{}, "certificate.subjectAltName", nil, nil, nil)
+	if got := e.String(); got != "certificate.subjectAltName: not present, expected present" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestExplainComparison(t *testing.T) {
+	n := node.New("keySize", 1024)
+	e := Explain(Gte{}, "certificate.subjectPublicKeyInfo.publicKey.keySize", n, nil, []any{2048})
+	if e.Expected != ">= 2048" {
+		t.Errorf("Expected = %v, want >= 2048", e.Expected)
+	}
+}
+
+func TestExplainLength(t *testing.T) {
+	n := node.New("commonName", "a-very-long-common-name")
+	e := Explain(MaxLength{}, "certificate.subject.commonName", n, nil, []any{10})
+	if e.Actual != 23 {
+		t.Errorf("Actual = %v, want 23", e.Actual)
+	}
+}
+
+func TestExplainDisplayValues(t *testing.T) {
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name string
+		in   any
+		want any
+	}{
+		{"time", ts, "2025-01-02T03:04:05Z"},
+		{"bytes", []byte{0xde, 0xad}, "dead"},
+		{"big int", big.NewInt(65537), "65537"},
+		{"bool", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := displayValue(tt.in); got != tt.want {
+				t.Errorf("displayValue(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	long := make([]byte, 200)
+	if s, _ := displayValue(string(long)).(string); len(s) != maxDisplayLen+3 {
+		t.Errorf("long values should be truncated, got length %d", len(s))
+	}
+}
@@ -0,0 +1,344 @@
+package operator
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/data"
+	"github.com/cavoq/PCL/internal/node"
+)
+
+// subjectPublicKey returns the parsed public key of the certificate under
+// evaluation, with zcrypto's ECDSA wrapper removed.
+func subjectPublicKey(ctx *EvaluationContext) any {
+	if !ctx.HasCert() {
+		return nil
+	}
+	if key, ok := ctx.Cert.Cert.PublicKey.(*x509.AugmentedECDSA); ok {
+		return key.Pub
+	}
+	return ctx.Cert.Cert.PublicKey
+}
+
+func rsaKey(ctx *EvaluationContext) *rsa.PublicKey {
+	key, _ := subjectPublicKey(ctx).(*rsa.PublicKey)
+	return key
+}
+
+func ecdsaKey(ctx *EvaluationContext) *ecdsa.PublicKey {
+	key, _ := subjectPublicKey(ctx).(*ecdsa.PublicKey)
+	return key
+}
+
+// numberRange reads an inclusive [min] or [min, max] operand range.
+func numberRange(operands []any) (lo, hi float64, hasMax bool, err error) {
+	if len(operands) == 0 || len(operands) > 2 {
+		return 0, 0, false, fmt.Errorf("expected min or min and max")
+	}
+	lo, ok := ToFloat64(operands[0])
+	if !ok {
+		return 0, 0, false, fmt.Errorf("min must be a number, got %T", operands[0])
+	}
+	if len(operands) == 2 {
+		if hi, ok = ToFloat64(operands[1]); !ok {
+			return 0, 0, false, fmt.Errorf("max must be a number, got %T", operands[1])
+		}
+		hasMax = true
+	}
+	return lo, hi, hasMax, nil
+}
+
+func rangeExpectation(operands []any, what string) string {
+	switch len(operands) {
+	case 1:
+		return fmt.Sprintf("%s >= %v", what, operands[0])
+	case 2:
+		return fmt.Sprintf("%s >= %v and <= %v", what, operands[0], operands[1])
+	}
+	return what
+}
+
+// RSAKeySize checks the RSA modulus length in bits against an inclusive
+// range and that it is a whole number of octets (BR 6.1.5).
+// Operands: [min] or [min, max]. Fails for non-RSA keys.
+type RSAKeySize struct{}
+
+func (RSAKeySize) Name() string { return "rsaKeySize" }
+
+func (RSAKeySize) Evaluate(_ *node.Node, ctx *EvaluationContext, operands []any) (bool, error) {
+	lo, hi, hasMax, err := numberRange(operands)
+	if err != nil {
+		return false, err
+	}
+	key := rsaKey(ctx)
+	if key == nil {
+		return false, nil
+	}
+
+	bits := key.N.BitLen()
+	if bits%8 != 0 {
+		return false, nil
+	}
+	return float64(bits) >= lo && (!hasMax || float64(bits) <= hi), nil
+}
+
+func (RSAKeySize) Explain(_ *node.Node, ctx *EvaluationContext, operands []any) Explanation {
+	e := Explanation{
+		Path:     "certificate.subjectPublicKeyInfo.publicKey.keySize",
+		Expected: rangeExpectation(operands, "RSA modulus bits") + ", divisible by 8",
+	}
+	if key := rsaKey(ctx); key != nil {
+		e.Actual = key.N.BitLen()
+	} else {
+		e.Detail = "not an RSA key"
+	}
+	return e
+}
+
+// RSAExponent checks that the RSA public exponent is odd and within an
+// inclusive range. Operands: [min] or [min, max]. Fails for non-RSA keys.
+type RSAExponent struct{}
+
+func (RSAExponent) Name() string { return "rsaExponent" }
+
+func (RSAExponent) Evaluate(_ *node.Node, ctx *EvaluationContext, operands []any) (bool, error) {
+	lo, hi, hasMax, err := numberRange(operands)
+	if err != nil {
+		return false, err
+	}
+	key := rsaKey(ctx)
+	if key == nil {
+		return false, nil
+	}
+
+	if key.E%2 == 0 {
+		return false, nil
+	}
+	e := float64(key.E)
+	return e >= lo && (!hasMax || e <= hi), nil
+}
+
+func (RSAExponent) Explain(_ *node.Node, ctx *EvaluationContext, operands []any) Explanation {
+	e := Explanation{
+		Path:     "certificate.subjectPublicKeyInfo.publicKey.exponent",
+		Expected: "odd, " + rangeExpectation(operands, "exponent"),
+	}
+	if key := rsaKey(ctx); key != nil {
+		e.Actual = key.E
+	} else {
+		e.Detail = "not an RSA key"
+	}
+	return e
+}
+
+// curveAliases maps the SEC, ANSI X9.62 and OID names of the NIST curves to
+// the names Go uses.
+var curveAliases = map[string]string{
+	"secp224r1":           "P-224",
+	"1.3.132.0.33":        "P-224",
+	"secp256r1":           "P-256",
+	"prime256v1":          "P-256",
+	"1.2.840.10045.3.1.7": "P-256",
+	"secp384r1":           "P-384",
+	"1.3.132.0.34":        "P-384",
+	"secp521r1":           "P-521",
+	"1.3.132.0.35":        "P-521",
+}
+
+func canonicalCurve(name string) string {
+	if c, ok := curveAliases[strings.ToLower(name)]; ok {
+		return c
+	}
+	return strings.ToUpper(name)
+}
+
+// ECCurveIn checks that an EC key is on one of the listed named curves.
+// Curves may be given as P-256, secp256r1, prime256v1 or by OID.
+// Fails for non-EC keys.
+type ECCurveIn struct{}
+
+func (ECCurveIn) Name() string { return "ecCurveIn" }
+
+func (ECCurveIn) Evaluate(_ *node.Node, ctx *EvaluationContext, operands []any) (bool, error) {
+	if len(operands) == 0 {
+		return false, fmt.Errorf("expected at least one curve")
+	}
+	key := ecdsaKey(ctx)
+	if key == nil {
+		return false, nil
+	}
+
+	curve := key.Curve.Params().Name
+	for _, op := range operands {
+		name, ok := op.(string)
+		if !ok {
+			return false, fmt.Errorf("curve must be a string, got %T", op)
+		}
+		if canonicalCurve(name) == curve {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (ECCurveIn) Explain(_ *node.Node, ctx *EvaluationContext, operands []any) Explanation {
+	e := Explanation{
+		Path:     "certificate.subjectPublicKeyInfo.publicKey.curve",
+		Expected: fmt.Sprintf("one of %v", operands),
+	}
+	if key := ecdsaKey(ctx); key != nil {
+		e.Actual = key.Curve.Params().Name
+	} else {
+		e.Detail = "not an EC key"
+	}
+	return e
+}
+
+// ECPointOnCurve checks that an EC public key is an uncompressed point on
+// its curve other than the point at infinity (RFC 5480 2.2, SEC 1 3.2.2).
+// Fails for non-EC keys.
+type ECPointOnCurve struct{}
+
+func (ECPointOnCurve) Name() string { return "ecPointOnCurve" }
+
+func (ECPointOnCurve) Evaluate(_ *node.Node, ctx *EvaluationContext, _ []any) (bool, error) {
+	key := ecdsaKey(ctx)
+	if key == nil {
+		return false, nil
+	}
+
+	// zcrypto keeps the encoded point; require the uncompressed form.
+	if aug, ok := ctx.Cert.Cert.PublicKey.(*x509.AugmentedECDSA); ok {
+		raw := aug.Raw.RightAlign()
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(raw) != 1+2*size || raw[0] != 0x04 {
+			return false, nil
+		}
+	}
+	return pointOnCurve(key), nil
+}
+
+func pointOnCurve(key *ecdsa.PublicKey) bool {
+	if key.X == nil || key.Y == nil || (key.X.Sign() == 0 && key.Y.Sign() == 0) {
+		return false
+	}
+	return key.Curve.IsOnCurve(key.X, key.Y) //nolint:staticcheck // checks a parsed public key, not a secret
+}
+
+// rocaPrimes and rocaGenerator define the fingerprint of RSA moduli
+// generated by the Infineon RSALib (ROCA, CVE-2017-15361): for each prime
+// p, N mod p lies in the subgroup generated by 65537.
+var (
+	rocaPrimes = []int64{
+		3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53, 59, 61, 67, 71,
+		73, 79, 83, 89, 97, 101, 103, 107, 109, 113, 127, 131, 137, 139, 149,
+		151, 157, 163, 167,
+	}
+	rocaGenerator = big.NewInt(65537)
+)
+
+// isROCAModulus reports whether an RSA modulus has the ROCA fingerprint.
+func isROCAModulus(n *big.Int) bool {
+	var r, g big.Int
+	for _, p := range rocaPrimes {
+		bp := big.NewInt(p)
+		residue := r.Mod(n, bp).Int64()
+		if residue == 0 {
+			return false
+		}
+		// Walk the cyclic subgroup generated by 65537 mod p.
+		gen := g.Mod(rocaGenerator, bp).Int64()
+		found := false
+		for x := int64(1); ; {
+			if x == residue {
+				found = true
+				break
+			}
+			if x = x * gen % p; x == 1 {
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// WeakKeyFingerprint checks that the subject public key is not a known weak
+// key. Operands select the checks and default to both:
+//   - list: the weak key list loaded through the data package
+//     (SubjectPublicKeyInfo SHA-256 and Debian openssl-blacklist entries)
+//   - roca: the ROCA RSA modulus fingerprint
+//
+// The list check returns an error when no weak key list is loaded.
+type WeakKeyFingerprint struct{}
+
+func (WeakKeyFingerprint) Name() string { return "weakKeyFingerprint" }
+
+func (WeakKeyFingerprint) Evaluate(_ *node.Node, ctx *EvaluationContext, operands []any) (bool, error) {
+	checks, err := weakKeyChecks(operands)
+	if err != nil {
+		return false, err
+	}
+	if !ctx.HasCert() {
+		return false, nil
+	}
+	reason, err := weakKeyReason(ctx, checks)
+	if err != nil {
+		return false, err
+	}
+	return reason == "", nil
+}
+
+func (WeakKeyFingerprint) Explain(_ *node.Node, ctx *EvaluationContext, operands []any) Explanation {
+	e := Explanation{Path: "certificate.subjectPublicKeyInfo", Expected: "no known weak key fingerprint"}
+	if checks, err := weakKeyChecks(operands); err == nil && ctx.HasCert() {
+		if reason, err := weakKeyReason(ctx, checks); err == nil {
+			e.Actual = reason
+		}
+	}
+	return e
+}
+
+func weakKeyChecks(operands []any) ([]string, error) {
+	if len(operands) == 0 {
+		return []string{"list", "roca"}, nil
+	}
+	checks := make([]string, 0, len(operands))
+	for _, op := range operands {
+		s, ok := op.(string)
+		if !ok || (s != "list" && s != "roca") {
+			return nil, fmt.Errorf("unknown weak key check %v, want list or roca", op)
+		}
+		checks = append(checks, s)
+	}
+	return checks, nil
+}
+
+// weakKeyReason returns which check matched the key, or "" if none did.
+func weakKeyReason(ctx *EvaluationContext, checks []string) (string, error) {
+	var modulus *big.Int
+	if key := rsaKey(ctx); key != nil {
+		modulus = key.N
+	}
+
+	if slices.Contains(checks, "list") {
+		if !data.DefaultLoader.WeakKeysLoaded() {
+			return "", fmt.Errorf("weak key list not loaded (see --weak-keys-file)")
+		}
+		if data.DefaultLoader.IsWeakKey(ctx.Cert.Cert.RawSubjectPublicKeyInfo, modulus) {
+			return "listed weak key", nil
+		}
+	}
+	if slices.Contains(checks, "roca") && modulus != nil && isROCAModulus(modulus) {
+		return "ROCA fingerprint", nil
+	}
+	return "", nil
+}
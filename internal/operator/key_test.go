@@ -0,0 +1,219 @@
+package operator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/cert"
+	"github.com/cavoq/PCL/internal/data"
+)
+
+func keyContext(pub any) *EvaluationContext {
+	return &EvaluationContext{Cert: &cert.Info{Cert: &x509.Certificate{
+		PublicKey:               pub,
+		RawSubjectPublicKeyInfo: []byte("spki"),
+	}}}
+}
+
+func rsaContext(bits, e int) *EvaluationContext {
+	n := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+	return keyContext(&rsa.PublicKey{N: n.Add(n, big.NewInt(1)), E: e})
+}
+
+func ecContext(t *testing.T, curve elliptic.Curve) (*EvaluationContext, *ecdsa.PublicKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := elliptic.Marshal(curve, key.X, key.Y) //nolint:staticcheck // uncompressed point encoding
+	aug := &x509.AugmentedECDSA{Pub: &key.PublicKey, Raw: asn1.BitString{Bytes: raw, BitLength: len(raw) * 8}}
+	return keyContext(aug), &key.PublicKey
+}
+
+func TestRSAKeySize(t *testing.T) {
+	ecCtx, _ := ecContext(t, elliptic.P256())
+	tests := []struct {
+		name     string
+		ctx      *EvaluationContext
+		operands []any
+		want     bool
+	}{
+		{"2048 meets minimum", rsaContext(2048, 65537), []any{2048}, true},
+		{"1024 below minimum", rsaContext(1024, 65537), []any{2048}, false},
+		{"4096 within range", rsaContext(4096, 65537), []any{2048, 8192}, true},
+		{"above maximum", rsaContext(4096, 65537), []any{2048, 3072}, false},
+		{"not divisible by 8", rsaContext(2049, 65537), []any{2048}, false},
+		{"EC key", ecCtx, []any{2048}, false},
+		{"no certificate", nil, []any{2048}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RSAKeySize{}.Evaluate(nil, tt.ctx, tt.operands)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (RSAKeySize{}).Evaluate(nil, rsaContext(2048, 3), []any{"big"}); err == nil {
+		t.Error("expected error for a non-numeric operand")
+	}
+}
+
+func TestRSAExponent(t *testing.T) {
+	tests := []struct {
+		name     string
+		e        int
+		operands []any
+		want     bool
+	}{
+		{"65537", 65537, []any{65537}, true},
+		{"3 below minimum", 3, []any{65537}, false},
+		{"3 allowed", 3, []any{3}, true},
+		{"even", 65536, []any{3}, false},
+		{"within range", 65537, []any{3, 1 << 20}, true},
+		{"above maximum", 1<<20 + 1, []any{3, 65537}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RSAExponent{}.Evaluate(nil, rsaContext(2048, tt.e), tt.operands)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestECCurveIn(t *testing.T) {
+	p256, _ := ecContext(t, elliptic.P256())
+	p384, _ := ecContext(t, elliptic.P384())
+	tests := []struct {
+		name     string
+		ctx      *EvaluationContext
+		operands []any
+		want     bool
+	}{
+		{"Go name", p256, []any{"P-256"}, true},
+		{"SEC name", p256, []any{"secp256r1"}, true},
+		{"X9.62 name", p256, []any{"prime256v1"}, true},
+		{"OID", p384, []any{"1.3.132.0.34"}, true},
+		{"not listed", p384, []any{"P-256", "P-521"}, false},
+		{"RSA key", rsaContext(2048, 65537), []any{"P-256"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ECCurveIn{}.Evaluate(nil, tt.ctx, tt.operands)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestECPointOnCurve(t *testing.T) {
+	ctx, key := ecContext(t, elliptic.P256())
+	if ok, _ := (ECPointOnCurve{}).Evaluate(nil, ctx, nil); !ok {
+		t.Error("generated key should be on the curve")
+	}
+
+	offCurve := &ecdsa.PublicKey{Curve: elliptic.P256(), X: key.X, Y: new(big.Int).Add(key.Y, big.NewInt(1))}
+	if ok, _ := (ECPointOnCurve{}).Evaluate(nil, keyContext(offCurve), nil); ok {
+		t.Error("point off the curve should fail")
+	}
+
+	compressed := elliptic.MarshalCompressed(elliptic.P256(), key.X, key.Y)
+	aug := &x509.AugmentedECDSA{Pub: key, Raw: asn1.BitString{Bytes: compressed, BitLength: len(compressed) * 8}}
+	if ok, _ := (ECPointOnCurve{}).Evaluate(nil, keyContext(aug), nil); ok {
+		t.Error("compressed point encoding should fail")
+	}
+
+	if ok, _ := (ECPointOnCurve{}).Evaluate(nil, rsaContext(2048, 65537), nil); ok {
+		t.Error("RSA key should fail")
+	}
+}
+
+// rocaModulus returns a number with the ROCA residues: a power of 65537
+// modulo the product of the fingerprint primes.
+func rocaModulus() *big.Int {
+	m := big.NewInt(1)
+	for _, p := range rocaPrimes {
+		m.Mul(m, big.NewInt(p))
+	}
+	n := new(big.Int).Exp(rocaGenerator, big.NewInt(1234), m)
+	return n.Add(n, new(big.Int).Mul(m, big.NewInt(987654321)))
+}
+
+func TestIsROCAModulus(t *testing.T) {
+	if !isROCAModulus(rocaModulus()) {
+		t.Error("fingerprinted modulus not detected")
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isROCAModulus(key.N) {
+		t.Error("random modulus detected as ROCA")
+	}
+}
+
+func TestWeakKeyFingerprint(t *testing.T) {
+	roca := keyContext(&rsa.PublicKey{N: rocaModulus(), E: 65537})
+	if ok, err := (WeakKeyFingerprint{}).Evaluate(nil, roca, []any{"roca"}); err != nil || ok {
+		t.Errorf("ROCA key: got %v, %v, want fail", ok, err)
+	}
+	if ok, err := (WeakKeyFingerprint{}).Evaluate(nil, rsaContext(2048, 65537), []any{"roca"}); err != nil || !ok {
+		t.Errorf("strong key: got %v, %v, want pass", ok, err)
+	}
+	if _, err := (WeakKeyFingerprint{}).Evaluate(nil, roca, []any{"debian"}); err == nil {
+		t.Error("expected error for an unknown check")
+	}
+}
+
+func TestWeakKeyFingerprintList(t *testing.T) {
+	spki := []byte("spki")
+	sum := sha256.Sum256(spki)
+	path := filepath.Join(t.TempDir(), data.WeakKeysFile)
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(sum[:])+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := data.DefaultLoader
+	data.DefaultLoader = &data.Loader{}
+	defer func() { data.DefaultLoader = saved }()
+
+	ctx := rsaContext(2048, 65537)
+	if _, err := (WeakKeyFingerprint{}).Evaluate(nil, ctx, []any{"list"}); err == nil {
+		t.Error("expected error before a weak key list is loaded")
+	}
+	if err := data.DefaultLoader.LoadWeakKeys(path); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := (WeakKeyFingerprint{}).Evaluate(nil, ctx, nil); err != nil || ok {
+		t.Errorf("listed key: got %v, %v, want fail", ok, err)
+	}
+	e := WeakKeyFingerprint{}.Explain(nil, ctx, nil)
+	if e.Actual != "listed weak key" {
+		t.Errorf("Actual = %v, want listed weak key", e.Actual)
+	}
+}
@@ -1,6 +1,7 @@
 package operator
 
 import (
+	"fmt"
 	"reflect"
 
 	"github.com/cavoq/PCL/internal/node"
@@ -28,6 +29,10 @@ func (MaxLength) Evaluate(n *node.Node, _ *EvaluationContext, operands []any) (b
 	return length <= maxLen, nil
 }
 
+func (MaxLength) Explain(n *node.Node, _ *EvaluationContext, operands []any) Explanation {
+	return lengthExplanation(n, operands, "<=")
+}
+
 type MinLength struct{}
 
 func (MinLength) Name() string { return "minLength" }
@@ -50,6 +55,10 @@ func (MinLength) Evaluate(n *node.Node, _ *EvaluationContext, operands []any) (b
 	return length >= minLen, nil
 }
 
+func (MinLength) Explain(n *node.Node, _ *EvaluationContext, operands []any) Explanation {
+	return lengthExplanation(n, operands, ">=")
+}
+
 func getLength(n *node.Node) int {
 	if n.Value == nil {
 		return len(n.Children)
@@ -70,3 +79,16 @@ func getLength(n *node.Node) int {
 		}
 	}
 }
+
+func lengthExplanation(n *node.Node, operands []any, symbol string) Explanation {
+	e := Explanation{Detail: "length"}
+	if n != nil {
+		if length := getLength(n); length >= 0 {
+			e.Actual = length
+		}
+	}
+	if len(operands) == 1 {
+		e.Expected = fmt.Sprintf("%s %v", symbol, operands[0])
+	}
+	return e
+}
@@ -0,0 +1,261 @@
+package operator
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+)
+
+// OperandKind is the type of value an operator expects in an operand position.
+type OperandKind string
+
+const (
+	OperandAny    OperandKind = "any"
+	OperandNumber OperandKind = "number"
+	OperandString OperandKind = "string"
+	OperandRegex  OperandKind = "regex"
+	OperandTime   OperandKind = "time"
+	OperandCIDR   OperandKind = "cidr"
+	OperandHex    OperandKind = "hex"
+	OperandMap    OperandKind = "map"
+)
+
+// Unbounded marks an OperandSpec without an upper operand count.
+const Unbounded = -1
+
+// OperandSpec describes the operands an operator accepts. Kinds lists the
+// expected kind per position; the last kind applies to any further operands.
+type OperandSpec struct {
+	Min   int
+	Max   int
+	Kinds []OperandKind
+}
+
+var (
+	noOperands     = OperandSpec{}
+	oneNumber      = OperandSpec{Min: 1, Max: 1, Kinds: []OperandKind{OperandNumber}}
+	oneRegex       = OperandSpec{Min: 1, Max: 1, Kinds: []OperandKind{OperandRegex}}
+	oneAny         = OperandSpec{Min: 1, Max: 1}
+	someAny        = OperandSpec{Min: 1, Max: Unbounded}
+	optionalTime   = OperandSpec{Min: 0, Max: 1, Kinds: []OperandKind{OperandTime}}
+	someStrings    = OperandSpec{Min: 1, Max: Unbounded, Kinds: []OperandKind{OperandString}}
+	someCIDRs      = OperandSpec{Min: 1, Max: Unbounded, Kinds: []OperandKind{OperandCIDR}}
+	componentLen   = OperandSpec{Min: 1, Max: 2, Kinds: []OperandKind{OperandNumber, OperandString}}
+	componentRegex = OperandSpec{Min: 1, Max: 2, Kinds: []OperandKind{OperandRegex, OperandString}}
+)
+
+// operandSpecs lists the operand shape of every built-in operator.
+var operandSpecs = map[string]OperandSpec{
+	"eq":                           oneAny,
+	"neq":                          oneAny,
+	"present":                      noOperands,
+	"absent":                       noOperands,
+	"gte":                          oneNumber,
+	"gt":                           oneNumber,
+	"lte":                          oneNumber,
+	"lt":                           oneNumber,
+	"in":                           someAny,
+	"notIn":                        someAny,
+	"contains":                     someAny,
+	"before":                       optionalTime,
+	"after":                        optionalTime,
+	"matches":                      someStrings,
+	"positive":                     noOperands,
+	"odd":                          noOperands,
+	"maxLength":                    oneNumber,
+	"minLength":                    oneNumber,
+	"isCritical":                   noOperands,
+	"notCritical":                  noOperands,
+	"isEmpty":                      noOperands,
+	"notEmpty":                     noOperands,
+	"regex":                        oneRegex,
+	"notRegex":                     oneRegex,
+	"signatureValid":               noOperands,
+	"issuedBy":                     noOperands,
+	"akiMatchesSki":                noOperands,
+	"pathLenValid":                 noOperands,
+	"validityDays":                 {Min: 2, Max: 2, Kinds: []OperandKind{OperandNumber}},
+	"validityOrderCorrect":         noOperands,
+	"signatureAlgorithmMatchesTBS": noOperands,
+	"noUnknownCriticalExtensions":  noOperands,
+	"sanRequiredIfEmptySubject":    noOperands,
+	"keyUsageCA":                   noOperands,
+	"keyUsageLeaf":                 noOperands,
+	"ekuContains":                  someStrings,
+	"ekuNotContains":               someStrings,
+	"ekuServerAuth":                noOperands,
+	"ekuClientAuth":                noOperands,
+	"noUniqueIdentifiers":          noOperands,
+	"serialNumberUnique":           noOperands,
+	"crlValid":                     noOperands,
+	"crlNotExpired":                noOperands,
+	"crlSignedBy":                  noOperands,
+	"notRevoked":                   noOperands,
+	"notRevokedWithDelta":          noOperands,
+	"crlCoversCertificate":         noOperands,
+	"ocspValid":                    noOperands,
+	"notRevokedOCSP":               noOperands,
+	"ocspGood":                     noOperands,
+	"every":                        {Min: 1, Max: Unbounded},
+	"dateDiff":                     {Min: 1, Max: 1, Kinds: []OperandKind{OperandMap}},
+	"nameConstraintsValid":         noOperands,
+	"certificatePolicyValid":       {Min: 0, Max: Unbounded, Kinds: []OperandKind{OperandString}},
+	"pathValid":                    {Min: 0, Max: Unbounded, Kinds: []OperandKind{OperandString}},
+	"chainsToTrustAnchor":          {Min: 0, Max: Unbounded, Kinds: []OperandKind{OperandString}},
+	"isNull":                       noOperands,
+	"componentMaxLength":           componentLen,
+	"componentMinLength":           componentLen,
+	"componentRegex":               componentRegex,
+	"componentNotRegex":            componentRegex,
+	"anyComponentMatches":          oneRegex,
+	"noComponentMatches":           oneRegex,
+	"componentInCIDR":              someCIDRs,
+	"componentNotInCIDR":           someCIDRs,
+	"tldRegistered":                noOperands,
+	"tldNotRegistered":             noOperands,
+	"isPublicSuffix":               noOperands,
+	"isNotPublicSuffix":            noOperands,
+	"componentTLDRegistered":       noOperands,
+	"componentTLDNotRegistered":    noOperands,
+	"componentIsPublicSuffix":      noOperands,
+	"componentNotPublicSuffix":     noOperands,
+	"utf8NoBom":                    noOperands,
+	"containsBom":                  noOperands,
+	"noDuplicateAttributes":        noOperands,
+	"uniqueValues":                 noOperands,
+	"uniqueChildren":               noOperands,
+	"utctimeHasZulu":               noOperands,
+	"utctimeHasSeconds":            noOperands,
+	"generalizedTimeHasZulu":       noOperands,
+	"generalizedTimeNoFraction":    noOperands,
+	"isUTCTime":                    noOperands,
+	"isGeneralizedTime":            noOperands,
+	"isIA5String":                  noOperands,
+	"isPrintableString":            noOperands,
+	"isUTF8String":                 noOperands,
+	"validIA5String":               noOperands,
+	"validPrintableString":         noOperands,
+	"derEqualsHex":                 {Min: 1, Max: Unbounded, Kinds: []OperandKind{OperandHex}},
+	"rsaKeySize":                   {Min: 1, Max: 2, Kinds: []OperandKind{OperandNumber}},
+	"rsaExponent":                  {Min: 1, Max: 2, Kinds: []OperandKind{OperandNumber}},
+	"ecCurveIn":                    someStrings,
+	"ecPointOnCurve":               noOperands,
+	"weakKeyFingerprint":           {Min: 0, Max: 2, Kinds: []OperandKind{OperandString}},
+	"sctValid":                     {Min: 0, Max: 2, Kinds: []OperandKind{OperandNumber}},
+	"sctLogKnown":                  {Min: 0, Max: Unbounded, Kinds: []OperandKind{OperandString}},
+}
+
+// OperandSpecFor returns the operand shape of a built-in operator.
+func OperandSpecFor(name string) (OperandSpec, bool) {
+	spec, ok := operandSpecs[name]
+	return spec, ok
+}
+
+// Validate checks the number and kinds of operands against the spec.
+func (s OperandSpec) Validate(operands []any) error {
+	n := len(operands)
+	switch {
+	case s.Max == 0 && n > 0:
+		return fmt.Errorf("takes no operands, got %d", n)
+	case n < s.Min && s.Min == s.Max:
+		return fmt.Errorf("requires exactly %d operand(s), got %d", s.Min, n)
+	case n < s.Min:
+		return fmt.Errorf("requires at least %d operand(s), got %d", s.Min, n)
+	case s.Max != Unbounded && n > s.Max:
+		return fmt.Errorf("accepts at most %d operand(s), got %d", s.Max, n)
+	}
+
+	for i, v := range operands {
+		if err := checkOperandKind(s.kindAt(i), v); err != nil {
+			return fmt.Errorf("operand %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (s OperandSpec) kindAt(i int) OperandKind {
+	if len(s.Kinds) == 0 {
+		return OperandAny
+	}
+	if i < len(s.Kinds) {
+		return s.Kinds[i]
+	}
+	return s.Kinds[len(s.Kinds)-1]
+}
+
+func checkOperandKind(kind OperandKind, v any) error {
+	switch kind {
+	case OperandNumber:
+		if _, ok := ToFloat64(v); !ok {
+			return fmt.Errorf("expected a number, got %T", v)
+		}
+	case OperandString:
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("expected a string, got %T", v)
+		}
+	case OperandRegex:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expected a regex pattern, got %T", v)
+		}
+		if _, err := getCompiledRegex(s); err != nil {
+			return err
+		}
+	case OperandTime:
+		if s, ok := v.(string); ok && s == "now" {
+			return nil
+		}
+		if _, err := toTime(v); err != nil {
+			return err
+		}
+	case OperandCIDR:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expected a CIDR string, got %T", v)
+		}
+		if _, _, err := net.ParseCIDR(s); err != nil {
+			return fmt.Errorf("invalid CIDR %q", s)
+		}
+	case OperandHex:
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expected a hex string, got %T", v)
+		}
+		if _, err := hex.DecodeString(s); err != nil {
+			return fmt.Errorf("invalid hex string %q", s)
+		}
+	case OperandMap:
+		if _, ok := v.(map[string]any); !ok {
+			return fmt.Errorf("expected a mapping, got %T", v)
+		}
+	}
+	return nil
+}
+
+// ValidateOperands checks operands for the named operator. Operators without
+// a known operand spec are not checked. Operands of the every operator are
+// validated against its inner operator, which must be registered in reg.
+func ValidateOperands(reg *Registry, name string, operands []any) error {
+	spec, ok := OperandSpecFor(name)
+	if !ok {
+		return nil
+	}
+	if err := spec.Validate(operands); err != nil {
+		return err
+	}
+	if name != (Every{}).Name() {
+		return nil
+	}
+
+	args, err := parseEveryOperands(operands)
+	if err != nil {
+		return err
+	}
+	if _, err := reg.Get(args.operator); err != nil {
+		return fmt.Errorf("unknown inner operator %q", args.operator)
+	}
+	if err := ValidateOperands(reg, args.operator, args.operands); err != nil {
+		return fmt.Errorf("inner operator %s: %w", args.operator, err)
+	}
+	return nil
+}
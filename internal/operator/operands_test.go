@@ -0,0 +1,59 @@
+package operator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOperandSpecsCoverAllOperators(t *testing.T) {
+	for _, op := range All {
+		if _, ok := OperandSpecFor(op.Name()); !ok {
+			t.Errorf("no operand spec for %s", op.Name())
+		}
+	}
+}
+
+func TestValidateOperands(t *testing.T) {
+	reg := DefaultRegistry()
+	tests := []struct {
+		name     string
+		operator string
+		operands []any
+		wantErr  string
+	}{
+		{"eq one operand", "eq", []any{3}, ""},
+		{"eq missing operand", "eq", nil, "requires exactly 1 operand(s), got 0"},
+		{"gte string", "gte", []any{"2048"}, "expected a number"},
+		{"present with operand", "present", []any{true}, "takes no operands"},
+		{"in many", "in", []any{1, 2, 3}, ""},
+		{"before now", "before", []any{"now"}, ""},
+		{"before date", "before", []any{"2025-01-01"}, ""},
+		{"before garbage", "before", []any{"tomorrow"}, "cannot parse time string"},
+		{"regex invalid", "regex", []any{"[a-"}, "invalid regex pattern"},
+		{"validityDays one", "validityDays", []any{1}, "requires exactly 2"},
+		{"cidr invalid", "componentInCIDR", []any{"10.0.0.0/33"}, "invalid CIDR"},
+		{"hex invalid", "derEqualsHex", []any{"zz"}, "invalid hex string"},
+		{"component delimiter", "componentMaxLength", []any{63, "."}, ""},
+		{"dateDiff list", "dateDiff", []any{1}, "expected a mapping"},
+		{"every map", "every", []any{map[string]any{"operator": "eq", "operands": []any{1}}}, ""},
+		{"every positional", "every", []any{"value", "in", 1, 2}, ""},
+		{"every unknown inner", "every", []any{map[string]any{"operator": "eqq"}}, `unknown inner operator "eqq"`},
+		{"every bad inner operands", "every", []any{map[string]any{"operator": "gte"}}, "inner operator gte: requires exactly 1"},
+		{"custom operator unchecked", "custom", []any{1, 2, 3}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOperands(reg, tt.operator, tt.operands)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,7 +1,16 @@
 // Package operator provides rule operators for evaluating certificate field values.
 package operator
 
-import "github.com/cavoq/PCL/internal/node"
+import (
+	"errors"
+
+	"github.com/cavoq/PCL/internal/node"
+)
+
+// ErrUnsupported is wrapped by operator errors for inputs the operator
+// cannot check, such as signatures made with an algorithm that has no
+// verifier. Rules report these as skipped rather than failed.
+var ErrUnsupported = errors.New("unsupported")
 
 type Operator interface {
 	Name() string
@@ -54,6 +63,8 @@ var All = []Operator{
 	CRLNotExpired{},
 	CRLSignedBy{},
 	NotRevoked{},
+	NotRevokedWithDelta{},
+	CRLCoversCertificate{},
 	OCSPValid{},
 	NotRevokedOCSP{},
 	OCSPGood{},
@@ -62,6 +73,8 @@ var All = []Operator{
 	DateDiff{},
 	NameConstraintsValid{},
 	CertificatePolicyValid{},
+	PathValid{},
+	ChainsToTrustAnchor{},
 	IsNull{},
 	// Generic component validation operators (useful for DNS labels, path segments, etc.)
 	ComponentMaxLength{},
@@ -105,4 +118,13 @@ var All = []Operator{
 	ValidPrintableString{},
 	// DER encoding validation (Mozilla byte-for-byte requirements)
 	DEREqualsHex{},
+	// Key strength operators
+	RSAKeySize{},
+	RSAExponent{},
+	ECCurveIn{},
+	ECPointOnCurve{},
+	WeakKeyFingerprint{},
+	// Certificate Transparency operators
+	SCTValid{},
+	SCTLogKnown{},
 }
@@ -0,0 +1,69 @@
+package operator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/cert"
+	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/pathval"
+	"github.com/cavoq/PCL/internal/trust"
+)
+
+// PathValid runs RFC 5280 §6.1 path validation from the current certificate
+// to its trust anchor, at the evaluation time. The anchor comes from the
+// trust store or, without one, is the self-signed top of the chain.
+// Operands, if any, are the acceptable certificate policy OIDs. With
+// operands an explicit policy is required, so the path is only valid if
+// one of them survives policy processing.
+type PathValid struct{}
+
+func (PathValid) Name() string { return "pathValid" }
+
+func (PathValid) Evaluate(_ *node.Node, ctx *EvaluationContext, operands []any) (bool, error) {
+	if !ctx.HasCert() {
+		return false, nil
+	}
+	return validatePath(ctx, operands).Valid, nil
+}
+
+func (PathValid) Explain(_ *node.Node, ctx *EvaluationContext, operands []any) Explanation {
+	e := Explanation{Path: "certificate.pathValidation", Expected: "valid certification path"}
+	if len(operands) > 0 {
+		e.Expected = fmt.Sprintf("valid certification path with a policy in %v", operands)
+	}
+	if ctx.HasCert() {
+		if res := validatePath(ctx, operands); !res.Valid {
+			e.Actual = "invalid"
+			e.Detail = strings.Join(res.Failures, "; ")
+		} else {
+			e.Actual = "valid"
+		}
+	}
+	return e
+}
+
+func validatePath(ctx *EvaluationContext, operands []any) pathval.Result {
+	opts := pathval.Options{Now: ctx.Now}
+	for _, op := range operands {
+		if s, ok := op.(string); ok {
+			opts.InitialPolicies = append(opts.InitialPolicies, s)
+		}
+	}
+	opts.InitialExplicitPolicy = len(opts.InitialPolicies) > 0
+
+	path, anchor := trustPath(ctx)
+	return pathval.Validate(path, anchor.Cert, opts)
+}
+
+// trustPath returns the path from the current certificate to its trust
+// anchor. Without a chain the certificate stands alone.
+func trustPath(ctx *EvaluationContext) ([]*x509.Certificate, trust.Anchor) {
+	chain := ctx.Chain
+	if len(chain) == 0 {
+		chain = []*cert.Info{ctx.Cert}
+	}
+	return pathval.FromChain(chain, ctx.Cert.Position, ctx.Trust)
+}
@@ -0,0 +1,78 @@
+package operator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zmap/zcrypto/x509"
+	"github.com/zmap/zcrypto/x509/pkix"
+
+	"github.com/cavoq/PCL/internal/cert"
+)
+
+func TestPathValidName(t *testing.T) {
+	op := PathValid{}
+	if op.Name() != "pathValid" {
+		t.Error("wrong name")
+	}
+}
+
+func TestPathValidNilContext(t *testing.T) {
+	op := PathValid{}
+	got, err := op.Evaluate(nil, nil, nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got {
+		t.Error("nil context should return false")
+	}
+}
+
+func TestPathValidNoTrustAnchor(t *testing.T) {
+	op := PathValid{}
+	leaf := &x509.Certificate{
+		Subject: pkix.Name{CommonName: "leaf"},
+		Issuer:  pkix.Name{CommonName: "ca"},
+	}
+	ctx := &EvaluationContext{Cert: &cert.Info{Cert: leaf}}
+
+	got, err := op.Evaluate(nil, ctx, nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got {
+		t.Error("path without trust anchor should not be valid")
+	}
+
+	e := op.Explain(nil, ctx, nil)
+	if e.Actual != "invalid" || !strings.Contains(e.Detail, "no trust anchor") {
+		t.Errorf("unexpected explanation: %+v", e)
+	}
+}
+
+func TestPathValidTrustAnchorOnly(t *testing.T) {
+	op := PathValid{}
+	root := &x509.Certificate{
+		Subject: pkix.Name{CommonName: "root"},
+		Issuer:  pkix.Name{CommonName: "root"},
+	}
+	ctx := &EvaluationContext{
+		Cert:  &cert.Info{Cert: root},
+		Chain: []*cert.Info{{Cert: root}},
+	}
+
+	got, err := op.Evaluate(nil, ctx, nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !got {
+		t.Error("trust anchor should validate as an empty path")
+	}
+}
+
+func TestPathValidExplainPolicies(t *testing.T) {
+	e := PathValid{}.Explain(nil, nil, []any{"2.23.140.1.2.1"})
+	if s, _ := e.Expected.(string); !strings.Contains(s, "2.23.140.1.2.1") {
+		t.Errorf("expected should list acceptable policies, got %q", e.Expected)
+	}
+}
@@ -14,6 +14,10 @@ func (Present) Evaluate(n *node.Node, _ *EvaluationContext, _ []any) (bool, erro
 	return n != nil, nil
 }
 
+func (Present) Explain(_ *node.Node, _ *EvaluationContext, _ []any) Explanation {
+	return Explanation{Expected: "present"}
+}
+
 type Absent struct{}
 
 func (Absent) Name() string { return "absent" }
@@ -22,6 +26,15 @@ func (Absent) Evaluate(n *node.Node, _ *EvaluationContext, _ []any) (bool, error
 	return n == nil, nil
 }
 
+func (Absent) Explain(n *node.Node, _ *EvaluationContext, _ []any) Explanation {
+	e := defaultExplanation(n, nil)
+	if e.Actual == nil {
+		e.Actual = "present"
+	}
+	e.Expected = "absent"
+	return e
+}
+
 type IsEmpty struct{}
 
 func (IsEmpty) Name() string { return "isEmpty" }
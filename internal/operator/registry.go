@@ -1,6 +1,9 @@
 package operator
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+)
 
 type Registry struct {
 	ops map[string]Operator
@@ -33,3 +36,13 @@ func (r *Registry) Get(name string) (Operator, error) {
 	}
 	return op, nil
 }
+
+// Names returns the names of all registered operators in sorted order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.ops))
+	for name := range r.ops {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -34,3 +34,14 @@ func TestRegistryRegisterAndGet(t *testing.T) {
 		t.Fatalf("unexpected operator name: %s", op.Name())
 	}
 }
+
+func TestRegistryNames(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(Present{})
+	reg.Register(Eq{})
+
+	names := reg.Names()
+	if len(names) != 2 || names[0] != "eq" || names[1] != "present" {
+		t.Fatalf("Names() = %v, want [eq present]", names)
+	}
+}
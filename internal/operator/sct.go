@@ -0,0 +1,237 @@
+package operator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/ct"
+	"github.com/cavoq/PCL/internal/data"
+	"github.com/cavoq/PCL/internal/node"
+)
+
+// sctCheck is the outcome of checking one embedded SCT.
+type sctCheck struct {
+	LogID [32]byte
+	Log   *data.CTLog
+	Valid bool
+	Issue string
+}
+
+// checkSCTs looks up the log of every embedded SCT and, when verify is set,
+// checks its signature over the reconstructed precertificate. The issuer is
+// the next certificate in the chain.
+func checkSCTs(ctx *EvaluationContext, verify bool) ([]sctCheck, error) {
+	if !data.DefaultLoader.CTLogsLoaded() {
+		return nil, fmt.Errorf("CT log list not loaded (run pcl update-data)")
+	}
+	cert := ctx.Cert.Cert
+
+	var issuer *x509.Certificate
+	if pos := ctx.Cert.Position; pos+1 < len(ctx.Chain) && ctx.Chain[pos+1] != nil {
+		issuer = ctx.Chain[pos+1].Cert
+	}
+	var precert []byte
+	var precertErr error
+	if verify {
+		precert, precertErr = ct.PrecertTBS(cert.RawTBSCertificate)
+	}
+
+	checks := make([]sctCheck, 0, len(cert.SignedCertificateTimestampList))
+	for i, sct := range cert.SignedCertificateTimestampList {
+		c := sctCheck{LogID: sct.LogID, Log: data.DefaultLoader.CTLog(sct.LogID)}
+		switch {
+		case c.Log == nil:
+			c.Issue = fmt.Sprintf("SCT %d: log %s not in the log list", i, sct.LogID.Base64String())
+		case !verify:
+		case issuer == nil:
+			c.Issue = fmt.Sprintf("SCT %d: no issuer to reconstruct the precertificate", i)
+		case precertErr != nil:
+			c.Issue = fmt.Sprintf("SCT %d: %v", i, precertErr)
+		default:
+			signed := ct.SignedData(sct, issuer.RawSubjectPublicKeyInfo, precert)
+			if err := ct.Verify(sct, c.Log.Key, signed); err != nil {
+				c.Issue = fmt.Sprintf("SCT %d from %s: %v", i, c.Log.Description, err)
+			} else {
+				c.Valid = true
+			}
+		}
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
+
+// validSCTCounts returns the number of distinct logs with a valid SCT and
+// of distinct operators among them. Further SCTs from a log already counted
+// add nothing, as CT policies require SCTs from separate logs.
+func validSCTCounts(checks []sctCheck) (valid, operators int) {
+	logs := make(map[[32]byte]bool)
+	seen := make(map[string]bool)
+	for _, c := range checks {
+		if !c.Valid || logs[c.LogID] {
+			continue
+		}
+		logs[c.LogID] = true
+		valid++
+		if !seen[c.Log.Operator] {
+			seen[c.Log.Operator] = true
+			operators++
+		}
+	}
+	return valid, operators
+}
+
+func sctIssues(checks []sctCheck) string {
+	var issues []string
+	for _, c := range checks {
+		if c.Issue != "" {
+			issues = append(issues, c.Issue)
+		}
+	}
+	return strings.Join(issues, "; ")
+}
+
+// SCTValid verifies the signatures of the embedded SCTs against the keys in
+// the CT log list. Operands: [min] or [min, minOperators], both defaulting
+// to 1; valid SCTs must come from at least min distinct logs, run by at
+// least minOperators distinct operators. SCTs from unknown logs do not
+// count.
+//
+// Returns an error when no CT log list is loaded.
+type SCTValid struct{}
+
+func (SCTValid) Name() string { return "sctValid" }
+
+func (SCTValid) Evaluate(_ *node.Node, ctx *EvaluationContext, operands []any) (bool, error) {
+	minValid, minOperators, err := sctMinimums(operands)
+	if err != nil {
+		return false, err
+	}
+	if !ctx.HasCert() {
+		return false, nil
+	}
+	checks, err := checkSCTs(ctx, true)
+	if err != nil {
+		return false, err
+	}
+	valid, operators := validSCTCounts(checks)
+	return valid >= minValid && operators >= minOperators, nil
+}
+
+func (SCTValid) Explain(_ *node.Node, ctx *EvaluationContext, operands []any) Explanation {
+	e := Explanation{Path: "certificate.signedCertificateTimestamps"}
+	minValid, minOperators, err := sctMinimums(operands)
+	if err != nil {
+		return e
+	}
+	e.Expected = fmt.Sprintf(">= %d logs with valid SCTs from >= %d log operators", minValid, minOperators)
+	if !ctx.HasCert() {
+		return e
+	}
+	if checks, err := checkSCTs(ctx, true); err == nil {
+		valid, operators := validSCTCounts(checks)
+		e.Actual = fmt.Sprintf("%d logs with valid SCTs from %d log operators", valid, operators)
+		e.Detail = sctIssues(checks)
+	}
+	return e
+}
+
+func sctMinimums(operands []any) (minValid, minOperators int, err error) {
+	minValid, minOperators = 1, 1
+	if len(operands) > 2 {
+		return 0, 0, fmt.Errorf("expected min or min and minOperators")
+	}
+	for i, op := range operands {
+		n, ok := ToFloat64(op)
+		if !ok || n < 0 {
+			return 0, 0, fmt.Errorf("operand %d must be a non-negative number, got %v", i+1, op)
+		}
+		if i == 0 {
+			minValid = int(n)
+		} else {
+			minOperators = int(n)
+		}
+	}
+	return minValid, minOperators, nil
+}
+
+// SCTLogKnown checks that the certificate has embedded SCTs and that every
+// one is from a log in the CT log list. Optional operands restrict the
+// accepted log states (usable, qualified, readonly, retired, rejected,
+// pending).
+//
+// Returns an error when no CT log list is loaded.
+type SCTLogKnown struct{}
+
+func (SCTLogKnown) Name() string { return "sctLogKnown" }
+
+func (SCTLogKnown) Evaluate(_ *node.Node, ctx *EvaluationContext, operands []any) (bool, error) {
+	states, err := logStates(operands)
+	if err != nil {
+		return false, err
+	}
+	if !ctx.HasCert() {
+		return false, nil
+	}
+	checks, err := checkSCTs(ctx, false)
+	if err != nil {
+		return false, err
+	}
+	if len(checks) == 0 {
+		return false, nil
+	}
+	for _, c := range checks {
+		if c.Log == nil || (len(states) > 0 && !slices.Contains(states, c.Log.State)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (SCTLogKnown) Explain(_ *node.Node, ctx *EvaluationContext, operands []any) Explanation {
+	e := Explanation{Path: "certificate.signedCertificateTimestamps", Expected: "every SCT from a known log"}
+	states, err := logStates(operands)
+	if err != nil {
+		return e
+	}
+	if len(states) > 0 {
+		e.Expected = fmt.Sprintf("every SCT from a known log in state %v", states)
+	}
+	if !ctx.HasCert() {
+		return e
+	}
+	checks, err := checkSCTs(ctx, false)
+	if err != nil {
+		return e
+	}
+	if len(checks) == 0 {
+		e.Actual = "no embedded SCTs"
+		return e
+	}
+	var issues []string
+	for i, c := range checks {
+		switch {
+		case c.Log == nil:
+			issues = append(issues, c.Issue)
+		case len(states) > 0 && !slices.Contains(states, c.Log.State):
+			issues = append(issues, fmt.Sprintf("SCT %d: log %s is %s", i, c.Log.Description, c.Log.State))
+		}
+	}
+	e.Actual = fmt.Sprintf("%d SCTs", len(checks))
+	e.Detail = strings.Join(issues, "; ")
+	return e
+}
+
+func logStates(operands []any) ([]string, error) {
+	states := make([]string, 0, len(operands))
+	for _, op := range operands {
+		s, ok := op.(string)
+		if !ok {
+			return nil, fmt.Errorf("log state must be a string, got %T", op)
+		}
+		states = append(states, s)
+	}
+	return states, nil
+}
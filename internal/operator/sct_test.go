@@ -0,0 +1,141 @@
+package operator
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zmap/zcrypto/x509"
+	zct "github.com/zmap/zcrypto/x509/ct"
+
+	"github.com/cavoq/PCL/internal/cert"
+	"github.com/cavoq/PCL/internal/data"
+)
+
+var testLogKey = []byte("test log key")
+
+func loadTestCTLogs(t *testing.T) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), data.CTLogListFile)
+	content := `{"operators": [{"name": "Test Operator", "logs": [
+  {"description": "Test Log", "key": "` + base64.StdEncoding.EncodeToString(testLogKey) + `", "state": {"usable": {}}}
+]}]}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := data.DefaultLoader
+	data.DefaultLoader = &data.Loader{}
+	t.Cleanup(func() { data.DefaultLoader = saved })
+
+	if err := data.DefaultLoader.LoadCTLogs(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sctContext(logKeys ...[]byte) *EvaluationContext {
+	c := &x509.Certificate{}
+	for _, key := range logKeys {
+		c.SignedCertificateTimestampList = append(c.SignedCertificateTimestampList,
+			&zct.SignedCertificateTimestamp{LogID: sha256.Sum256(key)})
+	}
+	return &EvaluationContext{Cert: &cert.Info{Cert: c}}
+}
+
+func TestSCTNoLogList(t *testing.T) {
+	saved := data.DefaultLoader
+	data.DefaultLoader = &data.Loader{}
+	defer func() { data.DefaultLoader = saved }()
+
+	ctx := sctContext(testLogKey)
+	if _, err := (SCTValid{}).Evaluate(nil, ctx, nil); err == nil {
+		t.Error("sctValid: expected error before a CT log list is loaded")
+	}
+	if _, err := (SCTLogKnown{}).Evaluate(nil, ctx, nil); err == nil {
+		t.Error("sctLogKnown: expected error before a CT log list is loaded")
+	}
+}
+
+func TestSCTLogKnown(t *testing.T) {
+	loadTestCTLogs(t)
+
+	tests := []struct {
+		name     string
+		ctx      *EvaluationContext
+		operands []any
+		want     bool
+	}{
+		{"known log", sctContext(testLogKey), nil, true},
+		{"known log in state", sctContext(testLogKey), []any{"usable", "qualified"}, true},
+		{"known log in other state", sctContext(testLogKey), []any{"retired"}, false},
+		{"unknown log", sctContext(testLogKey, []byte("other")), nil, false},
+		{"no SCTs", sctContext(), nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SCTLogKnown{}.Evaluate(nil, tt.ctx, tt.operands)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (SCTLogKnown{}).Evaluate(nil, sctContext(testLogKey), []any{1}); err == nil {
+		t.Error("expected error for a non-string state")
+	}
+	e := SCTLogKnown{}.Explain(nil, sctContext(testLogKey, []byte("other")), nil)
+	if !strings.Contains(e.Detail, "SCT 1: log") {
+		t.Errorf("Detail = %q, want the unknown log", e.Detail)
+	}
+}
+
+func TestSCTValid(t *testing.T) {
+	loadTestCTLogs(t)
+
+	// Without an issuer in the chain the precertificate cannot be rebuilt,
+	// so no SCT counts as valid.
+	ctx := sctContext(testLogKey)
+	if ok, err := (SCTValid{}).Evaluate(nil, ctx, nil); err != nil || ok {
+		t.Errorf("no issuer: got %v, %v, want fail", ok, err)
+	}
+	if ok, err := (SCTValid{}).Evaluate(nil, ctx, []any{0, 0}); err != nil || !ok {
+		t.Errorf("zero minimums: got %v, %v, want pass", ok, err)
+	}
+
+	e := SCTValid{}.Explain(nil, ctx, []any{2, 2})
+	if e.Expected != ">= 2 logs with valid SCTs from >= 2 log operators" {
+		t.Errorf("Expected = %q", e.Expected)
+	}
+	if e.Actual != "0 logs with valid SCTs from 0 log operators" || !strings.Contains(e.Detail, "no issuer") {
+		t.Errorf("Actual = %q, Detail = %q", e.Actual, e.Detail)
+	}
+
+	for _, operands := range [][]any{{-1}, {"two"}, {1, 1, 1}} {
+		if _, err := (SCTValid{}).Evaluate(nil, ctx, operands); err == nil {
+			t.Errorf("expected error for operands %v", operands)
+		}
+	}
+}
+
+func TestValidSCTCounts(t *testing.T) {
+	one := &data.CTLog{Operator: "One"}
+	oneB := &data.CTLog{Operator: "One"}
+	two := &data.CTLog{Operator: "Two"}
+	checks := []sctCheck{
+		{LogID: [32]byte{1}, Log: one, Valid: true},
+		{LogID: [32]byte{1}, Log: one, Valid: true},
+		{LogID: [32]byte{2}, Log: oneB, Valid: true},
+		{LogID: [32]byte{3}, Log: two, Valid: false},
+		{Log: nil},
+	}
+	if valid, operators := validSCTCounts(checks); valid != 2 || operators != 1 {
+		t.Errorf("got %d logs from %d operators, want 2 from 1", valid, operators)
+	}
+}
@@ -7,7 +7,9 @@ import (
 )
 
 // NoDuplicateAttributes checks that subject DN does not contain
-// duplicate AttributeTypeAndValue instances per CABF BR 7.1.4.1
+// duplicate AttributeTypeAndValue instances per CABF BR 7.1.4.1. On a name
+// node with an attributes list every attribute is counted; otherwise the
+// children of the node are.
 type NoDuplicateAttributes struct{}
 
 // singleInstanceOIDs maps OID string → attribute name for attributes that must
@@ -58,6 +60,10 @@ func (NoDuplicateAttributes) Evaluate(n *node.Node, _ *EvaluationContext, _ []an
 		return false, nil
 	}
 
+	if attrs, ok := n.Children["attributes"]; ok {
+		n = attrs
+	}
+
 	foundOIDs := make(map[string]int)
 
 	for childName, child := range n.Children {
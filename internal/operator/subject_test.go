@@ -1,6 +1,7 @@
 package operator
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/cavoq/PCL/internal/node"
@@ -147,6 +148,22 @@ func TestNoDuplicateAttributes(t *testing.T) {
 			}(),
 			want: true,
 		},
+		{
+			name: "duplicate commonName in attributes list returns false",
+			node: func() *node.Node {
+				n := node.New("subject", nil)
+				n.Children["commonName"] = node.New("commonName", "example.org")
+				attrs := node.New("attributes", nil)
+				for i, v := range []string{"example.com", "example.org"} {
+					a := node.New(fmt.Sprintf("%d", i), v)
+					a.Children["oid"] = node.New("oid", "2.5.4.3")
+					attrs.Children[a.Name] = a
+				}
+				n.Children["attributes"] = attrs
+				return n
+			}(),
+			want: false,
+		},
 		{
 			name: "child without OID node is skipped",
 			node: func() *node.Node {
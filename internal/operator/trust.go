@@ -0,0 +1,63 @@
+package operator
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/trust"
+)
+
+var anchorSources = []string{trust.SourceTrustAnchors, trust.SourceSystemRoots, trust.SourceSelfSigned}
+
+// ChainsToTrustAnchor checks that the chain of the current certificate ends
+// at a trust anchor. Without operands the anchor must come from the trust
+// store (--trust-anchors or --system-roots); a self-signed chain root only
+// counts if "self-signed" is listed. Operands restrict the accepted anchor
+// sources.
+type ChainsToTrustAnchor struct{}
+
+func (ChainsToTrustAnchor) Name() string { return "chainsToTrustAnchor" }
+
+func (ChainsToTrustAnchor) Evaluate(_ *node.Node, ctx *EvaluationContext, operands []any) (bool, error) {
+	accepted, err := acceptedAnchorSources(operands)
+	if err != nil {
+		return false, err
+	}
+	if !ctx.HasCert() {
+		return false, nil
+	}
+	_, anchor := trustPath(ctx)
+	return anchor.Cert != nil && slices.Contains(accepted, anchor.Source), nil
+}
+
+func (ChainsToTrustAnchor) Explain(_ *node.Node, ctx *EvaluationContext, operands []any) Explanation {
+	accepted, _ := acceptedAnchorSources(operands)
+	e := Explanation{
+		Path:     "certificate.chain.anchoredIn",
+		Expected: fmt.Sprintf("chain anchored in %v", accepted),
+		Actual:   "no trust anchor",
+	}
+	if ctx.HasCert() {
+		if _, anchor := trustPath(ctx); anchor.Cert != nil {
+			e.Actual = anchor.Source
+			e.Detail = "anchor " + anchor.Cert.Subject.String()
+		}
+	}
+	return e
+}
+
+func acceptedAnchorSources(operands []any) ([]string, error) {
+	if len(operands) == 0 {
+		return []string{trust.SourceTrustAnchors, trust.SourceSystemRoots}, nil
+	}
+	accepted := make([]string, 0, len(operands))
+	for _, op := range operands {
+		s, ok := op.(string)
+		if !ok || !slices.Contains(anchorSources, s) {
+			return nil, fmt.Errorf("chainsToTrustAnchor: unknown anchor source %v (want one of %v)", op, anchorSources)
+		}
+		accepted = append(accepted, s)
+	}
+	return accepted, nil
+}
@@ -0,0 +1,101 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/zmap/zcrypto/x509"
+	"github.com/zmap/zcrypto/x509/pkix"
+
+	"github.com/cavoq/PCL/internal/cert"
+	"github.com/cavoq/PCL/internal/trust"
+)
+
+func TestChainsToTrustAnchorName(t *testing.T) {
+	op := ChainsToTrustAnchor{}
+	if op.Name() != "chainsToTrustAnchor" {
+		t.Error("wrong name")
+	}
+}
+
+func TestChainsToTrustAnchorNilContext(t *testing.T) {
+	op := ChainsToTrustAnchor{}
+	got, err := op.Evaluate(nil, nil, nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got {
+		t.Error("nil context should return false")
+	}
+}
+
+func TestChainsToTrustAnchorSelfSigned(t *testing.T) {
+	op := ChainsToTrustAnchor{}
+	root := &x509.Certificate{
+		Subject: pkix.Name{CommonName: "root"},
+		Issuer:  pkix.Name{CommonName: "root"},
+	}
+	ctx := &EvaluationContext{
+		Cert:  &cert.Info{Cert: root},
+		Chain: []*cert.Info{{Cert: root}},
+	}
+
+	got, err := op.Evaluate(nil, ctx, nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got {
+		t.Error("self-signed root should not count without a trust store")
+	}
+
+	got, err = op.Evaluate(nil, ctx, []any{trust.SourceSelfSigned})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !got {
+		t.Error("self-signed root should count when self-signed is accepted")
+	}
+}
+
+func TestChainsToTrustAnchorStore(t *testing.T) {
+	op := ChainsToTrustAnchor{}
+	root := &x509.Certificate{
+		Raw:     []byte("root"),
+		Subject: pkix.Name{CommonName: "root"},
+		Issuer:  pkix.Name{CommonName: "root"},
+	}
+	store := trust.NewStore()
+	store.Add(root, trust.SourceSystemRoots)
+	ctx := &EvaluationContext{
+		Cert:  &cert.Info{Cert: root},
+		Chain: []*cert.Info{{Cert: root}},
+		Trust: store,
+	}
+
+	got, err := op.Evaluate(nil, ctx, nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !got {
+		t.Error("certificate in the trust store should be anchored")
+	}
+
+	got, err = op.Evaluate(nil, ctx, []any{trust.SourceTrustAnchors})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got {
+		t.Error("system root should not satisfy trust-anchors")
+	}
+
+	e := op.Explain(nil, ctx, nil)
+	if e.Actual != trust.SourceSystemRoots {
+		t.Errorf("Actual = %v, want %s", e.Actual, trust.SourceSystemRoots)
+	}
+}
+
+func TestChainsToTrustAnchorUnknownSource(t *testing.T) {
+	op := ChainsToTrustAnchor{}
+	if _, err := op.Evaluate(nil, nil, []any{"somewhere"}); err == nil {
+		t.Error("expected error for unknown anchor source")
+	}
+}
@@ -19,6 +19,10 @@ func GetFormatter(format string, opts Options) Formatter {
 		return NewJSONFormatter(opts)
 	case "yaml":
 		return NewYAMLFormatter(opts)
+	case "sarif":
+		return NewSARIFFormatter(opts)
+	case "junit":
+		return NewJUnitFormatter(opts)
 	default:
 		return NewTextFormatter(opts)
 	}
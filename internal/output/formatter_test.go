@@ -34,6 +34,20 @@ func TestGetFormatter_YAML(t *testing.T) {
 	}
 }
 
+func TestGetFormatter_SARIF(t *testing.T) {
+	formatter := GetFormatter("sarif", Options{})
+	if _, ok := formatter.(*SARIFFormatter); !ok {
+		t.Errorf("expected SARIFFormatter, got %T", formatter)
+	}
+}
+
+func TestGetFormatter_JUnit(t *testing.T) {
+	formatter := GetFormatter("junit", Options{})
+	if _, ok := formatter.(*JUnitFormatter); !ok {
+		t.Errorf("expected JUnitFormatter, got %T", formatter)
+	}
+}
+
 func TestGetFormatter_Unknown(t *testing.T) {
 	formatter := GetFormatter("unknown", Options{})
 	if _, ok := formatter.(*TextFormatter); !ok {
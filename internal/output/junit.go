@@ -0,0 +1,135 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/cavoq/PCL/internal/rule"
+)
+
+// JUnitFormatter writes results as JUnit XML. Each policy result becomes a
+// testsuite and each displayed rule a testcase, so rule visibility follows
+// the same verbosity options as the other formatters.
+type JUnitFormatter struct{}
+
+func NewJUnitFormatter(_ Options) *JUnitFormatter {
+	return &JUnitFormatter{}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+func (f *JUnitFormatter) Format(w io.Writer, out LintOutput) error {
+	doc := junitTestSuites{Name: "pcl"}
+
+	for _, pr := range out.Results {
+		certPath := pr.CertPath
+		if certPath == "" {
+			certPath = "-"
+		}
+		suite := junitTestSuite{
+			Name: fmt.Sprintf("%s (%s)", pr.PolicyID, certPath),
+			Properties: []junitProperty{
+				{Name: "policy", Value: pr.PolicyID},
+				{Name: "certType", Value: pr.CertType},
+				{Name: "certPath", Value: certPath},
+				{Name: "verdict", Value: pr.Verdict},
+			},
+		}
+		if pr.Path > 0 {
+			suite.Name = fmt.Sprintf("%s (%s, path %d)", pr.PolicyID, certPath, pr.Path)
+			suite.Properties = append(suite.Properties, junitProperty{Name: "path", Value: fmt.Sprint(pr.Path)})
+		}
+		if !pr.CheckedAt.IsZero() {
+			suite.Timestamp = pr.CheckedAt.Format("2006-01-02T15:04:05")
+		}
+
+		for _, rr := range pr.Results {
+			tc := junitTestCase{
+				Name:      rr.RuleID,
+				ClassName: pr.PolicyID + "." + pr.CertType,
+			}
+			switch rr.Verdict {
+			case rule.VerdictFail:
+				tc.Failure = junitFailureFor(rr)
+				suite.Failures++
+			case rule.VerdictSkip:
+				tc.Skipped = &junitSkipped{Message: rr.Message}
+				suite.Skipped++
+			}
+			suite.Cases = append(suite.Cases, tc)
+			suite.Tests++
+		}
+
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Skipped += suite.Skipped
+		doc.Suites = append(doc.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func junitFailureFor(rr rule.Result) *junitFailure {
+	msg := rr.Message
+	if msg == "" {
+		msg = fmt.Sprintf("rule %s failed", rr.RuleID)
+	}
+	text := msg
+	if rr.Reference != "" {
+		text = fmt.Sprintf("%s\nReference: %s", msg, rr.Reference)
+	}
+	return &junitFailure{
+		Message: msg,
+		Type:    rr.Severity,
+		Text:    text,
+	}
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/cavoq/PCL/internal/policy"
+	"github.com/cavoq/PCL/internal/rule"
+)
+
+func TestJUnitFormatter(t *testing.T) {
+	out := LintOutput{
+		Results: []policy.Result{
+			{
+				PolicyID: "rfc5280",
+				CertType: "leaf",
+				CertPath: "certs/leaf.pem",
+				Verdict:  "fail",
+				Results: []rule.Result{
+					{RuleID: "version-v3", Reference: "RFC5280 4.1.2.1", Verdict: rule.VerdictFail, Severity: "error", Message: "expected 3"},
+					{RuleID: "serial-positive", Verdict: rule.VerdictPass, Severity: "error"},
+					{RuleID: "san-if-empty-subject", Verdict: rule.VerdictSkip, Severity: "error"},
+				},
+			},
+			{
+				PolicyID: "rfc5280",
+				CertType: "root",
+				Verdict:  "pass",
+				Results:  []rule.Result{},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewJUnitFormatter(Options{}).Format(&buf, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Errorf("output should start with XML header")
+	}
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to parse JUnit output: %v\n%s", err, buf.String())
+	}
+
+	if doc.Tests != 3 || doc.Failures != 1 || doc.Skipped != 1 {
+		t.Errorf("totals = %d/%d/%d, want 3/1/1", doc.Tests, doc.Failures, doc.Skipped)
+	}
+	if len(doc.Suites) != 2 {
+		t.Fatalf("expected 2 suites, got %d", len(doc.Suites))
+	}
+
+	suite := doc.Suites[0]
+	if suite.Name != "rfc5280 (certs/leaf.pem)" {
+		t.Errorf("suite name = %q", suite.Name)
+	}
+	failure := suite.Cases[0].Failure
+	if failure == nil {
+		t.Fatal("expected failure element on first testcase")
+	}
+	if failure.Message != "expected 3" || !strings.Contains(failure.Text, "RFC5280 4.1.2.1") {
+		t.Errorf("failure = %+v", failure)
+	}
+	if suite.Cases[1].Failure != nil || suite.Cases[1].Skipped != nil {
+		t.Errorf("passed testcase should have no failure or skipped element")
+	}
+	if suite.Cases[2].Skipped == nil {
+		t.Errorf("skipped rule should be marked skipped")
+	}
+	if doc.Suites[1].Name != "rfc5280 (-)" {
+		t.Errorf("suite without path = %q", doc.Suites[1].Name)
+	}
+}
@@ -75,6 +75,7 @@ func FilterRules(output LintOutput, opts Options) LintOutput {
 			CertType:  pr.CertType,
 			CertPath:  pr.CertPath,
 			Source:    pr.Source,
+			Path:      pr.Path,
 			Verdict:   pr.Verdict,
 			CheckedAt: pr.CheckedAt,
 			Counts:    pr.Counts,
@@ -0,0 +1,205 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/cavoq/PCL/internal/rule"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifToolURI = "https://github.com/cavoq/PCL"
+)
+
+// SARIFFormatter writes results as a SARIF 2.1.0 log for code-scanning tools.
+// Every rule result becomes a SARIF result whose rule ID is qualified by the
+// policy ID, and whose artifact location is the linted file.
+type SARIFFormatter struct{}
+
+func NewSARIFFormatter(_ Options) *SARIFFormatter {
+	return &SARIFFormatter{}
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           sarifRuleProps     `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProps struct {
+	Policy    string `json:"policy"`
+	Reference string `json:"reference,omitempty"`
+	Severity  string `json:"severity,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string           `json:"ruleId"`
+	RuleIndex  int              `json:"ruleIndex"`
+	Kind       string           `json:"kind"`
+	Level      string           `json:"level"`
+	Message    sarifMessage     `json:"message"`
+	Locations  []sarifLocation  `json:"locations,omitempty"`
+	Properties sarifResultProps `json:"properties"`
+}
+
+type sarifResultProps struct {
+	CertType string `json:"certType,omitempty"`
+	Source   string `json:"source,omitempty"`
+	Path     int    `json:"path,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+func (f *SARIFFormatter) Format(w io.Writer, out LintOutput) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "pcl",
+			InformationURI: sarifToolURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	ruleIndex := map[string]int{}
+
+	for _, pr := range out.Results {
+		for _, rr := range pr.Results {
+			id := pr.PolicyID + "/" + rr.RuleID
+			idx, ok := ruleIndex[id]
+			if !ok {
+				idx = len(run.Tool.Driver.Rules)
+				ruleIndex[id] = idx
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRuleFor(pr.PolicyID, rr))
+			}
+
+			res := sarifResult{
+				RuleID:    id,
+				RuleIndex: idx,
+				Kind:      sarifKind(rr.Verdict),
+				Level:     "none",
+				Message:   sarifMessage{Text: sarifResultMessage(rr)},
+				Properties: sarifResultProps{
+					CertType: pr.CertType,
+					Source:   pr.Source,
+					Path:     pr.Path,
+				},
+			}
+			if rr.Verdict == rule.VerdictFail {
+				res.Level = sarifLevel(rr.Severity)
+			}
+			if pr.CertPath != "" {
+				res.Locations = []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: pr.CertPath},
+					},
+				}}
+			}
+			run.Results = append(run.Results, res)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
+
+func sarifRuleFor(policyID string, rr rule.Result) sarifRule {
+	desc := rr.RuleID
+	if rr.Reference != "" {
+		desc = fmt.Sprintf("%s (%s)", rr.RuleID, rr.Reference)
+	}
+	return sarifRule{
+		ID:                   policyID + "/" + rr.RuleID,
+		Name:                 rr.RuleID,
+		ShortDescription:     sarifMessage{Text: desc},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(rr.Severity)},
+		Properties: sarifRuleProps{
+			Policy:    policyID,
+			Reference: rr.Reference,
+			Severity:  rr.Severity,
+		},
+	}
+}
+
+// sarifLevel maps PCL severities onto SARIF result levels.
+func sarifLevel(severity string) string {
+	switch severity {
+	case "error":
+		return "error"
+	case "warning":
+		return "warning"
+	case "info", "notice":
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+func sarifKind(verdict string) string {
+	switch verdict {
+	case rule.VerdictPass:
+		return "pass"
+	case rule.VerdictSkip:
+		return "notApplicable"
+	default:
+		return "fail"
+	}
+}
+
+func sarifResultMessage(rr rule.Result) string {
+	if rr.Message != "" {
+		return rr.Message
+	}
+	msg := fmt.Sprintf("rule %s: %s", rr.RuleID, rr.Verdict)
+	if rr.Reference != "" {
+		msg += " (" + rr.Reference + ")"
+	}
+	return msg
+}
@@ -0,0 +1,102 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/cavoq/PCL/internal/policy"
+	"github.com/cavoq/PCL/internal/rule"
+)
+
+func TestSARIFFormatter(t *testing.T) {
+	out := LintOutput{
+		Results: []policy.Result{
+			{
+				PolicyID: "rfc5280",
+				CertType: "leaf",
+				CertPath: "certs/leaf.pem",
+				Results: []rule.Result{
+					{RuleID: "version-v3", Reference: "RFC5280 4.1.2.1", Verdict: rule.VerdictFail, Severity: "error"},
+					{RuleID: "aia-present", Verdict: rule.VerdictFail, Severity: "info", Message: "target not found"},
+					{RuleID: "serial-positive", Verdict: rule.VerdictPass, Severity: "error"},
+				},
+			},
+			{
+				PolicyID: "rfc5280",
+				CertType: "intermediate",
+				CertPath: "certs/intermediate.pem",
+				Results: []rule.Result{
+					{RuleID: "version-v3", Reference: "RFC5280 4.1.2.1", Verdict: rule.VerdictSkip, Severity: "error"},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewSARIFFormatter(Options{}).Format(&buf, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("failed to parse SARIF output: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF envelope: version=%s runs=%d", log.Version, len(log.Runs))
+	}
+
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 3 {
+		t.Fatalf("expected 3 distinct rules, got %d", len(run.Tool.Driver.Rules))
+	}
+	if got := run.Tool.Driver.Rules[0].Properties.Reference; got != "RFC5280 4.1.2.1" {
+		t.Errorf("rule reference = %q", got)
+	}
+	if len(run.Results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(run.Results))
+	}
+
+	tests := []struct {
+		idx   int
+		id    string
+		kind  string
+		level string
+		uri   string
+	}{
+		{0, "rfc5280/version-v3", "fail", "error", "certs/leaf.pem"},
+		{1, "rfc5280/aia-present", "fail", "note", "certs/leaf.pem"},
+		{2, "rfc5280/serial-positive", "pass", "none", "certs/leaf.pem"},
+		{3, "rfc5280/version-v3", "notApplicable", "none", "certs/intermediate.pem"},
+	}
+	for _, tt := range tests {
+		res := run.Results[tt.idx]
+		if res.RuleID != tt.id || res.Kind != tt.kind || res.Level != tt.level {
+			t.Errorf("result %d = %s/%s/%s, want %s/%s/%s", tt.idx, res.RuleID, res.Kind, res.Level, tt.id, tt.kind, tt.level)
+		}
+		if len(res.Locations) != 1 || res.Locations[0].PhysicalLocation.ArtifactLocation.URI != tt.uri {
+			t.Errorf("result %d location = %+v, want %s", tt.idx, res.Locations, tt.uri)
+		}
+	}
+	if run.Results[3].RuleIndex != 0 {
+		t.Errorf("repeated rule should reuse index 0, got %d", run.Results[3].RuleIndex)
+	}
+	if run.Results[1].Message.Text != "target not found" {
+		t.Errorf("message = %q", run.Results[1].Message.Text)
+	}
+}
+
+func TestSARIFFormatterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewSARIFFormatter(Options{}).Format(&buf, LintOutput{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("failed to parse SARIF output: %v", err)
+	}
+	if log.Runs[0].Results == nil {
+		t.Error("results should be an empty array, not null")
+	}
+}
@@ -60,6 +60,9 @@ func (f *TextFormatter) Format(w io.Writer, out LintOutput) error {
 		if pr.Source != "" && pr.Source != "local" {
 			sourceInfo = fmt.Sprintf(" (%s)", pr.Source)
 		}
+		if pr.Path > 0 {
+			sourceInfo += fmt.Sprintf(" | Path: %d", pr.Path)
+		}
 		passCount, failCount, skipCount, warnCount := countsFromResult(pr)
 		if _, err := fmt.Fprintf(
 			w,
@@ -250,11 +253,37 @@ func writeRulesTable(w io.Writer, results []rule.Result, passCount, failCount, s
 				return err
 			}
 		}
+		if err := writeChecks(w, rr.Checks, 1); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// writeChecks prints the nested checks of a composite rule as an indented
+// tree below the rule row.
+func writeChecks(w io.Writer, checks []rule.Check, depth int) error {
+	indent := strings.Repeat("   ", depth)
+	for _, c := range checks {
+		label := c.Combinator
+		if label == "" {
+			label = c.Target + " " + c.Operator
+		}
+		line := fmt.Sprintf("          %s%s %s", indent, verdictLabelColored(c.Verdict), label)
+		if c.Message != "" {
+			line += " (" + c.Message + ")"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		if err := writeChecks(w, c.Checks, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func countResults(results []rule.Result) (int, int, int, int) {
 	passed, failed, skipped, warned := 0, 0, 0, 0
 	for _, rr := range results {
@@ -375,3 +375,47 @@ func TestTextFormatter_MultipleResults(t *testing.T) {
 		t.Error("should have separators between multiple results")
 	}
 }
+
+func TestTextFormatter_CompositeChecks(t *testing.T) {
+	formatter := NewTextFormatter(Options{})
+
+	output := LintOutput{
+		Results: []policy.Result{
+			{
+				PolicyID: "policy1",
+				Verdict:  rule.VerdictFail,
+				Results: []rule.Result{
+					{
+						RuleID:   "san-dns-or-ip",
+						Verdict:  rule.VerdictFail,
+						Severity: "error",
+						Message:  "anyOf: 0 of 2 checks passed",
+						Checks: []rule.Check{
+							{Target: "certificate.subjectAltName.dNSName", Operator: "present", Verdict: rule.VerdictFail},
+							{Combinator: "not", Verdict: rule.VerdictFail, Checks: []rule.Check{
+								{Target: "certificate.subjectAltName.iPAddress", Operator: "absent", Verdict: rule.VerdictPass},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := formatter.Format(&buf, output); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := buf.String()
+	for _, want := range []string{
+		"anyOf: 0 of 2 checks passed",
+		"certificate.subjectAltName.dNSName present",
+		"not",
+		"certificate.subjectAltName.iPAddress absent",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("output missing %q\n%s", want, result)
+		}
+	}
+}
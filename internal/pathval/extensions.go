@@ -0,0 +1,158 @@
+package pathval
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"slices"
+
+	zasn1 "github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
+)
+
+// AnyPolicy is the anyPolicy OID (RFC 5280 §4.2.1.4).
+const AnyPolicy = "2.5.29.32.0"
+
+var (
+	oidKeyUsage          = zasn1.ObjectIdentifier{2, 5, 29, 15}
+	oidPolicyMappings    = zasn1.ObjectIdentifier{2, 5, 29, 33}
+	oidPolicyConstraints = zasn1.ObjectIdentifier{2, 5, 29, 36}
+	oidInhibitAnyPolicy  = zasn1.ObjectIdentifier{2, 5, 29, 54}
+)
+
+// processedExtensions are the extensions the algorithm, or certificate
+// parsing, understands. Other critical extensions fail validation.
+var processedExtensions = []string{
+	"2.5.29.14", // subjectKeyIdentifier
+	"2.5.29.15", // keyUsage
+	"2.5.29.17", // subjectAltName
+	"2.5.29.19", // basicConstraints
+	"2.5.29.30", // nameConstraints
+	"2.5.29.32", // certificatePolicies
+	"2.5.29.33", // policyMappings
+	"2.5.29.35", // authorityKeyIdentifier
+	"2.5.29.36", // policyConstraints
+	"2.5.29.37", // extKeyUsage
+	"2.5.29.54", // inhibitAnyPolicy
+}
+
+// PolicyMapping is one issuerDomainPolicy to subjectDomainPolicy pair of
+// the policyMappings extension.
+type PolicyMapping struct {
+	IssuerPolicy  string
+	SubjectPolicy string
+}
+
+// PolicyMappings returns the policyMappings of cert, or nil if it has none.
+func PolicyMappings(cert *x509.Certificate) []PolicyMapping {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidPolicyMappings) {
+			return decodePolicyMappings(ext.Value)
+		}
+	}
+	return nil
+}
+
+func decodePolicyMappings(data []byte) []PolicyMapping {
+	var seq []struct {
+		IssuerDomain  asn1.ObjectIdentifier
+		SubjectDomain asn1.ObjectIdentifier
+	}
+	if _, err := asn1.Unmarshal(data, &seq); err != nil {
+		return nil
+	}
+	mappings := make([]PolicyMapping, 0, len(seq))
+	for _, m := range seq {
+		mappings = append(mappings, PolicyMapping{
+			IssuerPolicy:  m.IssuerDomain.String(),
+			SubjectPolicy: m.SubjectDomain.String(),
+		})
+	}
+	return mappings
+}
+
+// PolicyConstraints holds the optional skip counts of the
+// policyConstraints extension.
+type PolicyConstraints struct {
+	RequireExplicitPolicy *int
+	InhibitPolicyMapping  *int
+}
+
+// ParsePolicyConstraints returns the policyConstraints of cert. Both fields
+// are nil if the extension is absent.
+func ParsePolicyConstraints(cert *x509.Certificate) PolicyConstraints {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidPolicyConstraints) {
+			return decodePolicyConstraints(ext.Value)
+		}
+	}
+	return PolicyConstraints{}
+}
+
+func decodePolicyConstraints(data []byte) PolicyConstraints {
+	var result PolicyConstraints
+	var seq asn1.RawValue
+	rest, err := asn1.Unmarshal(data, &seq)
+	if err != nil || len(rest) != 0 || seq.Tag != asn1.TagSequence {
+		return result
+	}
+
+	rest = seq.Bytes
+	for len(rest) > 0 {
+		var val asn1.RawValue
+		rest, err = asn1.Unmarshal(rest, &val)
+		if err != nil {
+			break
+		}
+		if val.Class == asn1.ClassContextSpecific {
+			// The skip counts are IMPLICIT tagged INTEGERs.
+			var n int
+			if _, err := asn1.UnmarshalWithParams(val.FullBytes, &n, fmt.Sprintf("tag:%d", val.Tag)); err == nil {
+				switch val.Tag {
+				case 0:
+					result.RequireExplicitPolicy = &n
+				case 1:
+					result.InhibitPolicyMapping = &n
+				}
+			}
+		}
+	}
+	return result
+}
+
+// InhibitAnyPolicy returns the skip count of the inhibitAnyPolicy
+// extension, or nil if cert has none.
+func InhibitAnyPolicy(cert *x509.Certificate) *int {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidInhibitAnyPolicy) {
+			var skipCerts int
+			if _, err := asn1.Unmarshal(ext.Value, &skipCerts); err == nil {
+				return &skipCerts
+			}
+		}
+	}
+	return nil
+}
+
+func hasExtension(cert *x509.Certificate, oid zasn1.ObjectIdentifier) bool {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oid) {
+			return true
+		}
+	}
+	return false
+}
+
+// unprocessedCritical returns the OIDs of critical extensions of cert that
+// the algorithm does not process.
+func unprocessedCritical(cert *x509.Certificate) []string {
+	var oids []string
+	for _, ext := range cert.Extensions {
+		if !ext.Critical {
+			continue
+		}
+		if oid := ext.Id.String(); !slices.Contains(processedExtensions, oid) {
+			oids = append(oids, oid)
+		}
+	}
+	return oids
+}
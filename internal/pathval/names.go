@@ -0,0 +1,242 @@
+package pathval
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
+	"github.com/zmap/zcrypto/x509/pkix"
+)
+
+var oidEmailAddress = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}
+
+// subtrees is one set of GeneralSubtrees, as taken from a nameConstraints
+// extension.
+type subtrees struct {
+	dns    []string
+	emails []string
+	uris   []string
+	ips    []net.IPNet
+	dirs   []pkix.Name
+}
+
+func permittedSubtrees(c *x509.Certificate) subtrees {
+	var s subtrees
+	for _, v := range c.PermittedDNSNames {
+		s.dns = append(s.dns, v.Data)
+	}
+	for _, v := range c.PermittedEmailAddresses {
+		s.emails = append(s.emails, v.Data)
+	}
+	for _, v := range c.PermittedURIs {
+		s.uris = append(s.uris, v.Data)
+	}
+	for _, v := range c.PermittedIPAddresses {
+		s.ips = append(s.ips, v.Data)
+	}
+	for _, v := range c.PermittedDirectoryNames {
+		s.dirs = append(s.dirs, v.Data)
+	}
+	return s
+}
+
+func excludedSubtrees(c *x509.Certificate) subtrees {
+	var s subtrees
+	for _, v := range c.ExcludedDNSNames {
+		s.dns = append(s.dns, v.Data)
+	}
+	for _, v := range c.ExcludedEmailAddresses {
+		s.emails = append(s.emails, v.Data)
+	}
+	for _, v := range c.ExcludedURIs {
+		s.uris = append(s.uris, v.Data)
+	}
+	for _, v := range c.ExcludedIPAddresses {
+		s.ips = append(s.ips, v.Data)
+	}
+	for _, v := range c.ExcludedDirectoryNames {
+		s.dirs = append(s.dirs, v.Data)
+	}
+	return s
+}
+
+// nameState holds permitted_subtrees and excluded_subtrees. The
+// intersection of permitted subtrees is kept as the list of sets it is
+// built from: a name must lie within every set that constrains its type.
+type nameState struct {
+	permitted []subtrees
+	excluded  subtrees
+}
+
+// add processes the nameConstraints of a CA certificate (§6.1.4(g)).
+func (s *nameState) add(c *x509.Certificate) {
+	s.permitted = append(s.permitted, permittedSubtrees(c))
+	ex := excludedSubtrees(c)
+	s.excluded.dns = append(s.excluded.dns, ex.dns...)
+	s.excluded.emails = append(s.excluded.emails, ex.emails...)
+	s.excluded.uris = append(s.excluded.uris, ex.uris...)
+	s.excluded.ips = append(s.excluded.ips, ex.ips...)
+	s.excluded.dirs = append(s.excluded.dirs, ex.dirs...)
+}
+
+// check verifies the subject name and subjectAltNames of c against the
+// constraints (§6.1.3(b) and (c)) and describes each violation.
+func (s *nameState) check(c *x509.Certificate) []string {
+	var problems []string
+	report := func(kind, name, why string) {
+		problems = append(problems, fmt.Sprintf("%s %q %s", kind, name, why))
+	}
+
+	if len(c.RawSubject) > 2 {
+		subject := c.Subject.String()
+		if anyMatch(s.excluded.dirs, c.Subject, matchDirectoryName) {
+			report("directoryName", subject, "is excluded")
+		}
+		for _, p := range s.permitted {
+			if len(p.dirs) > 0 && !anyMatch(p.dirs, c.Subject, matchDirectoryName) {
+				report("directoryName", subject, "is not permitted")
+				break
+			}
+		}
+	}
+
+	emails := append(subjectEmails(c.Subject), c.EmailAddresses...)
+	for _, email := range emails {
+		checkName(s, "rfc822Name", email, func(t subtrees) []string { return t.emails }, matchEmail, report)
+	}
+	for _, name := range c.DNSNames {
+		checkName(s, "dNSName", name, func(t subtrees) []string { return t.dns }, matchDNS, report)
+	}
+	for _, uri := range c.URIs {
+		checkName(s, "uniformResourceIdentifier", uri, func(t subtrees) []string { return t.uris }, matchURI, report)
+	}
+	for _, ip := range c.IPAddresses {
+		contains := func(n net.IPNet, ip net.IP) bool { return n.Contains(ip) }
+		if anyMatch(s.excluded.ips, ip, contains) {
+			report("iPAddress", ip.String(), "is excluded")
+		}
+		for _, p := range s.permitted {
+			if len(p.ips) > 0 && !anyMatch(p.ips, ip, contains) {
+				report("iPAddress", ip.String(), "is not permitted")
+				break
+			}
+		}
+	}
+
+	return problems
+}
+
+func checkName(s *nameState, kind, name string, of func(subtrees) []string, match func(constraint, name string) bool, report func(kind, name, why string)) {
+	if anyMatch(of(s.excluded), name, match) {
+		report(kind, name, "is excluded")
+	}
+	for _, p := range s.permitted {
+		if constraints := of(p); len(constraints) > 0 && !anyMatch(constraints, name, match) {
+			report(kind, name, "is not permitted")
+			return
+		}
+	}
+}
+
+func anyMatch[C, N any](constraints []C, name N, match func(C, N) bool) bool {
+	for _, c := range constraints {
+		if match(c, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchDNS implements dNSName subtree matching: the constraint matches the
+// name itself and any name formed by adding labels to its left. A leading
+// period restricts the match to names below the domain.
+func matchDNS(constraint, name string) bool {
+	constraint = strings.ToLower(constraint)
+	name = strings.ToLower(name)
+	if constraint == "" {
+		return true
+	}
+	if strings.HasPrefix(constraint, ".") {
+		return strings.HasSuffix(name, constraint)
+	}
+	return name == constraint || strings.HasSuffix(name, "."+constraint)
+}
+
+// matchEmail implements rfc822Name matching: a full mailbox, every mailbox
+// on a host, or (with a leading period) every mailbox below a domain.
+func matchEmail(constraint, email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	host := strings.ToLower(email[at+1:])
+	switch {
+	case strings.Contains(constraint, "@"):
+		c := strings.LastIndex(constraint, "@")
+		return email[:at] == constraint[:c] && host == strings.ToLower(constraint[c+1:])
+	case strings.HasPrefix(constraint, "."):
+		return strings.HasSuffix(host, strings.ToLower(constraint))
+	default:
+		return host == strings.ToLower(constraint)
+	}
+}
+
+// matchURI implements uniformResourceIdentifier matching on the host part:
+// an exact host, or (with a leading period) any host below a domain.
+func matchURI(constraint, uri string) bool {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if host == "" {
+		return false
+	}
+	if strings.HasPrefix(constraint, ".") {
+		return strings.HasSuffix(host, strings.ToLower(constraint))
+	}
+	return host == strings.ToLower(constraint)
+}
+
+// matchDirectoryName reports whether name lies within the subtree rooted at
+// constraint, i.e. whether the RDNs of constraint are a prefix of those of
+// name.
+func matchDirectoryName(constraint, name pkix.Name) bool {
+	base := constraint.ToRDNSequence()
+	full := name.ToRDNSequence()
+	if len(base) > len(full) {
+		return false
+	}
+	for i, rdn := range base {
+		if len(rdn) != len(full[i]) {
+			return false
+		}
+		for j, atv := range rdn {
+			other := full[i][j]
+			if !atv.Type.Equal(other.Type) {
+				return false
+			}
+			if !strings.EqualFold(strings.TrimSpace(fmt.Sprint(atv.Value)), strings.TrimSpace(fmt.Sprint(other.Value))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// subjectEmails returns emailAddress attributes of the subject, which are
+// subject to rfc822Name constraints (§4.2.1.10).
+func subjectEmails(name pkix.Name) []string {
+	var emails []string
+	for _, atv := range name.Names {
+		if atv.Type.Equal(oidEmailAddress) {
+			if s, ok := atv.Value.(string); ok {
+				emails = append(emails, s)
+			}
+		}
+	}
+	return emails
+}
@@ -0,0 +1,297 @@
+// Package pathval implements the RFC 5280 §6.1 certification path
+// validation algorithm.
+//
+// Validate runs the state machine over a path ending at a trust anchor and
+// records the outcome of every check for every certificate, so that
+// policies can assert on single steps as well as on the overall result.
+// Revocation (§6.1.3(a)(3)) is not part of this package; it is covered by
+// the CRL and OCSP operators.
+package pathval
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zmap/zcrypto/x509"
+)
+
+// Names of the checks recorded per step.
+const (
+	CheckSignature          = "signature"
+	CheckValidity           = "validity"
+	CheckIssuerName         = "issuerName"
+	CheckNameConstraints    = "nameConstraints"
+	CheckPolicy             = "policy"
+	CheckPolicyMappings     = "policyMappings"
+	CheckBasicConstraints   = "basicConstraints"
+	CheckPathLength         = "pathLength"
+	CheckKeyUsage           = "keyUsage"
+	CheckCriticalExtensions = "criticalExtensions"
+	CheckExplicitPolicy     = "explicitPolicy"
+)
+
+// Options are the algorithm inputs of §6.1.1 besides the path and the
+// trust anchor.
+type Options struct {
+	// Now is the time validity is checked at; zero means the current time.
+	Now time.Time
+
+	// InitialPolicies is the user-initial-policy-set. Empty means anyPolicy.
+	InitialPolicies []string
+
+	InitialExplicitPolicy       bool
+	InitialPolicyMappingInhibit bool
+	InitialAnyPolicyInhibit     bool
+}
+
+// Check is the outcome of one check on one certificate.
+type Check struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+// Step holds the checks run on one certificate of the path. Steps are
+// numbered as in the RFC: step 1 is the certificate issued by the trust
+// anchor, step n the target certificate.
+type Step struct {
+	Index   int
+	Subject string
+	Checks  []Check
+}
+
+// OK reports whether every check of the step passed.
+func (s Step) OK() bool {
+	for _, c := range s.Checks {
+		if !c.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// Result is the outcome of Validate.
+type Result struct {
+	Valid  bool
+	Anchor string
+	Steps  []Step
+	// Policies is the user-constrained policy set: the valid policies
+	// left after intersecting the policy tree with the initial policy set.
+	// It is nil when the policy tree is NULL.
+	Policies []string
+	// Failures describes every failed check, in path order.
+	Failures []string
+}
+
+type state struct {
+	opts          Options
+	n             int
+	tree          *policyTree
+	names         nameState
+	explicit      int
+	anyInhibit    int
+	mapping       int
+	maxPathLength int
+	workingKey    *x509.Certificate
+	issuerName    []byte
+	issuerString  string
+}
+
+// Validate validates path against anchor. The path is ordered as chains are
+// elsewhere in PCL, from the target certificate up to the certificate the
+// anchor issued; the anchor itself is not part of it. A nil anchor fails
+// validation. Name constraints of the anchor certificate seed the initial
+// permitted and excluded subtrees, as RFC 5937 allows.
+func Validate(path []*x509.Certificate, anchor *x509.Certificate, opts Options) Result {
+	if anchor == nil {
+		return Result{Failures: []string{"no trust anchor"}}
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	n := len(path)
+	s := &state{
+		opts:          opts,
+		n:             n,
+		tree:          newPolicyTree(),
+		explicit:      n + 1,
+		anyInhibit:    n + 1,
+		mapping:       n + 1,
+		maxPathLength: n,
+		workingKey:    anchor,
+		issuerName:    anchor.RawSubject,
+		issuerString:  anchor.Subject.String(),
+	}
+	s.names.add(anchor)
+	if opts.InitialExplicitPolicy {
+		s.explicit = 0
+	}
+	if opts.InitialAnyPolicyInhibit {
+		s.anyInhibit = 0
+	}
+	if opts.InitialPolicyMappingInhibit {
+		s.mapping = 0
+	}
+
+	res := Result{Anchor: anchor.Subject.String()}
+	for i := 1; i <= n; i++ {
+		c := path[n-i]
+		step := Step{Index: i, Subject: c.Subject.String()}
+		s.processCertificate(c, i, &step)
+		if i < n {
+			s.prepareNext(c, &step)
+		} else {
+			s.wrapUp(c, &step)
+		}
+		res.Steps = append(res.Steps, step)
+		for _, check := range step.Checks {
+			if !check.OK {
+				res.Failures = append(res.Failures, fmt.Sprintf("step %d (%s): %s: %s", i, step.Subject, check.Name, check.Detail))
+			}
+		}
+	}
+
+	if s.tree != nil {
+		res.Policies = s.tree.policies()
+	}
+	res.Valid = len(res.Failures) == 0
+	return res
+}
+
+func (st *Step) record(name string, ok bool, format string, args ...any) {
+	c := Check{Name: name, OK: ok}
+	if !ok {
+		c.Detail = fmt.Sprintf(format, args...)
+	}
+	st.Checks = append(st.Checks, c)
+}
+
+func selfIssued(c *x509.Certificate) bool {
+	return bytes.Equal(c.RawSubject, c.RawIssuer)
+}
+
+// processCertificate performs the basic certificate processing of §6.1.3.
+func (s *state) processCertificate(c *x509.Certificate, i int, step *Step) {
+	final := i == s.n
+
+	err := s.workingKey.CheckSignature(c.SignatureAlgorithm, c.RawTBSCertificate, c.Signature)
+	step.record(CheckSignature, err == nil, "not signed by the working public key of %s: %v", s.workingKey.Subject.String(), err)
+
+	now := s.opts.Now
+	valid := !now.Before(c.NotBefore) && !now.After(c.NotAfter)
+	step.record(CheckValidity, valid, "%s is outside %s to %s",
+		now.UTC().Format(time.RFC3339), c.NotBefore.UTC().Format(time.RFC3339), c.NotAfter.UTC().Format(time.RFC3339))
+
+	issuerOK := bytes.Equal(c.RawIssuer, s.issuerName) || c.Issuer.String() == s.issuerString
+	step.record(CheckIssuerName, issuerOK, "issuer %q does not match working issuer name %q", c.Issuer.String(), s.issuerString)
+
+	if !selfIssued(c) || final {
+		problems := s.names.check(c)
+		step.record(CheckNameConstraints, len(problems) == 0, "%s", strings.Join(problems, "; "))
+	}
+
+	// §6.1.3(d) and (e).
+	if s.tree != nil {
+		policies := make([]string, 0, len(c.PolicyIdentifiers))
+		for _, oid := range c.PolicyIdentifiers {
+			policies = append(policies, oid.String())
+		}
+		if len(policies) == 0 {
+			s.tree = nil
+		} else {
+			anyAllowed := s.anyInhibit > 0 || (!final && selfIssued(c))
+			s.tree.addLevel(policies, anyAllowed)
+			if !s.tree.prune() {
+				s.tree = nil
+			}
+		}
+	}
+
+	// §6.1.3(f).
+	step.record(CheckPolicy, s.explicit > 0 || s.tree != nil, "no valid policy remains and an explicit policy is required")
+}
+
+// prepareNext prepares the state for certificate i+1 (§6.1.4).
+func (s *state) prepareNext(c *x509.Certificate, step *Step) {
+	mappings := PolicyMappings(c)
+	anyMapped := false
+	for _, m := range mappings {
+		if m.IssuerPolicy == AnyPolicy || m.SubjectPolicy == AnyPolicy {
+			anyMapped = true
+		}
+	}
+	step.record(CheckPolicyMappings, !anyMapped, "anyPolicy must not be mapped")
+	if s.tree != nil && len(mappings) > 0 && !anyMapped {
+		if !s.tree.applyMappings(mappings, s.mapping == 0) {
+			s.tree = nil
+		}
+	}
+
+	s.issuerName = c.RawSubject
+	s.issuerString = c.Subject.String()
+	s.workingKey = c
+	s.names.add(c)
+
+	if !selfIssued(c) {
+		s.explicit = decrement(s.explicit)
+		s.mapping = decrement(s.mapping)
+		s.anyInhibit = decrement(s.anyInhibit)
+	}
+
+	pc := ParsePolicyConstraints(c)
+	if pc.RequireExplicitPolicy != nil && *pc.RequireExplicitPolicy < s.explicit {
+		s.explicit = *pc.RequireExplicitPolicy
+	}
+	if pc.InhibitPolicyMapping != nil && *pc.InhibitPolicyMapping < s.mapping {
+		s.mapping = *pc.InhibitPolicyMapping
+	}
+	if iap := InhibitAnyPolicy(c); iap != nil && *iap < s.anyInhibit {
+		s.anyInhibit = *iap
+	}
+
+	step.record(CheckBasicConstraints, c.BasicConstraintsValid && c.IsCA, "not a CA certificate")
+
+	if !selfIssued(c) {
+		step.record(CheckPathLength, s.maxPathLength > 0, "path length constraint exceeded")
+		s.maxPathLength = decrement(s.maxPathLength)
+	}
+	if c.BasicConstraintsValid && (c.MaxPathLen > 0 || c.MaxPathLenZero) && c.MaxPathLen < s.maxPathLength {
+		s.maxPathLength = c.MaxPathLen
+	}
+
+	if hasExtension(c, oidKeyUsage) {
+		step.record(CheckKeyUsage, c.KeyUsage&x509.KeyUsageCertSign != 0, "keyCertSign is not set")
+	}
+
+	s.checkCriticalExtensions(c, step)
+}
+
+// wrapUp performs the wrap-up procedure of §6.1.5 on the target.
+func (s *state) wrapUp(c *x509.Certificate, step *Step) {
+	s.explicit = decrement(s.explicit)
+	if pc := ParsePolicyConstraints(c); pc.RequireExplicitPolicy != nil && *pc.RequireExplicitPolicy == 0 {
+		s.explicit = 0
+	}
+
+	s.checkCriticalExtensions(c, step)
+
+	if s.tree != nil && !s.tree.intersect(s.opts.InitialPolicies) {
+		s.tree = nil
+	}
+	step.record(CheckExplicitPolicy, s.explicit > 0 || s.tree != nil, "no acceptable policy remains and an explicit policy is required")
+}
+
+func (s *state) checkCriticalExtensions(c *x509.Certificate, step *Step) {
+	unknown := unprocessedCritical(c)
+	step.record(CheckCriticalExtensions, len(unknown) == 0, "unrecognized critical extensions: %s", strings.Join(unknown, ", "))
+}
+
+func decrement(v int) int {
+	if v > 0 {
+		return v - 1
+	}
+	return 0
+}
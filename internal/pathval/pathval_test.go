@@ -0,0 +1,464 @@
+package pathval
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	stdx509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zmap/zcrypto/x509"
+)
+
+var (
+	testNow   = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	policyA   = asn1.ObjectIdentifier{1, 2, 3, 1}
+	policyB   = asn1.ObjectIdentifier{1, 2, 3, 2}
+	anyPolicy = asn1.ObjectIdentifier{2, 5, 29, 32, 0}
+)
+
+type testCert struct {
+	std  *stdx509.Certificate
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+var serial int64
+
+// issue creates a certificate from tmpl, signed by parent or self-signed if
+// parent is nil. Unset template fields get usable defaults.
+func issue(t *testing.T, tmpl *stdx509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	serial++
+	tmpl.SerialNumber = big.NewInt(serial)
+	if tmpl.NotBefore.IsZero() {
+		tmpl.NotBefore = testNow.AddDate(-1, 0, 0)
+	}
+	if tmpl.NotAfter.IsZero() {
+		tmpl.NotAfter = testNow.AddDate(1, 0, 0)
+	}
+
+	// CreateCertificate encodes Policies rather than PolicyIdentifiers.
+	tmpl.Policies = nil
+	for _, id := range tmpl.PolicyIdentifiers {
+		oid, err := stdx509.OIDFromInts(toUint64s(id))
+		if err != nil {
+			t.Fatalf("converting policy %s: %v", id, err)
+		}
+		tmpl.Policies = append(tmpl.Policies, oid)
+	}
+
+	issuerTmpl, signer := tmpl, key
+	if parent != nil {
+		issuerTmpl, signer = parent.std, parent.key
+	}
+	der, err := stdx509.CreateCertificate(rand.Reader, tmpl, issuerTmpl, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	std, err := stdx509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate with zcrypto: %v", err)
+	}
+	return &testCert{std: std, cert: cert, key: key}
+}
+
+func toUint64s(oid asn1.ObjectIdentifier) []uint64 {
+	out := make([]uint64, len(oid))
+	for i, v := range oid {
+		out[i] = uint64(v)
+	}
+	return out
+}
+
+func caTemplate(cn string) *stdx509.Certificate {
+	return &stdx509.Certificate{
+		Subject:               pkix.Name{CommonName: cn},
+		KeyUsage:              stdx509.KeyUsageCertSign | stdx509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		PolicyIdentifiers:     []asn1.ObjectIdentifier{anyPolicy},
+	}
+}
+
+func leafTemplate(dns ...string) *stdx509.Certificate {
+	return &stdx509.Certificate{
+		Subject:           pkix.Name{CommonName: "leaf"},
+		DNSNames:          dns,
+		KeyUsage:          stdx509.KeyUsageDigitalSignature,
+		PolicyIdentifiers: []asn1.ObjectIdentifier{policyA},
+	}
+}
+
+// chain returns root, intermediate and leaf issued from the templates.
+func chain(t *testing.T, rootTmpl, interTmpl, leafTmpl *stdx509.Certificate) (root, inter, leaf *testCert) {
+	t.Helper()
+	root = issue(t, rootTmpl, nil)
+	inter = issue(t, interTmpl, root)
+	leaf = issue(t, leafTmpl, inter)
+	return root, inter, leaf
+}
+
+func validate(root, inter, leaf *testCert, opts Options) Result {
+	if opts.Now.IsZero() {
+		opts.Now = testNow
+	}
+	return Validate([]*x509.Certificate{leaf.cert, inter.cert}, root.cert, opts)
+}
+
+func failedChecks(res Result) []string {
+	var failed []string
+	for _, s := range res.Steps {
+		for _, c := range s.Checks {
+			if !c.OK {
+				failed = append(failed, c.Name)
+			}
+		}
+	}
+	return failed
+}
+
+func assertFailed(t *testing.T, res Result, want ...string) {
+	t.Helper()
+	got := failedChecks(res)
+	if res.Valid != (len(want) == 0) {
+		t.Errorf("Valid = %v, failures %v", res.Valid, res.Failures)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("failed checks = %v, want %v (failures: %v)", got, want, res.Failures)
+	}
+}
+
+func TestValidateValidPath(t *testing.T) {
+	root, inter, leaf := chain(t, caTemplate("root"), caTemplate("inter"), leafTemplate("a.example.test"))
+
+	res := validate(root, inter, leaf, Options{})
+	assertFailed(t, res)
+
+	if res.Anchor != "CN=root" {
+		t.Errorf("Anchor = %q, want CN=root", res.Anchor)
+	}
+	if len(res.Steps) != 2 || res.Steps[0].Subject != "CN=inter" || res.Steps[1].Index != 2 {
+		t.Errorf("unexpected steps: %+v", res.Steps)
+	}
+	if !slices.Equal(res.Policies, []string{policyA.String()}) {
+		t.Errorf("Policies = %v, want [%s]", res.Policies, policyA)
+	}
+}
+
+func TestValidateNoAnchor(t *testing.T) {
+	_, inter, leaf := chain(t, caTemplate("root"), caTemplate("inter"), leafTemplate())
+
+	res := Validate([]*x509.Certificate{leaf.cert, inter.cert}, nil, Options{Now: testNow})
+	if res.Valid || len(res.Failures) != 1 || res.Failures[0] != "no trust anchor" {
+		t.Errorf("got %+v, want failure without trust anchor", res)
+	}
+}
+
+func TestValidateValidity(t *testing.T) {
+	root, inter, leaf := chain(t, caTemplate("root"), caTemplate("inter"), leafTemplate())
+
+	assertFailed(t, validate(root, inter, leaf, Options{Now: testNow.AddDate(2, 0, 0)}), CheckValidity, CheckValidity)
+}
+
+func TestValidateSignature(t *testing.T) {
+	root, inter, _ := chain(t, caTemplate("root"), caTemplate("inter"), leafTemplate())
+	impostor := issue(t, caTemplate("inter"), root)
+	leaf := issue(t, leafTemplate(), impostor)
+
+	assertFailed(t, validate(root, inter, leaf, Options{}), CheckSignature)
+}
+
+func TestValidateIssuerName(t *testing.T) {
+	root, inter, _ := chain(t, caTemplate("root"), caTemplate("inter"), leafTemplate())
+	other := issue(t, caTemplate("other"), root)
+	leaf := issue(t, leafTemplate(), other)
+
+	assertFailed(t, validate(root, inter, leaf, Options{}), CheckSignature, CheckIssuerName)
+}
+
+func TestValidateBasicConstraints(t *testing.T) {
+	inter := caTemplate("inter")
+	inter.IsCA = false
+	root, in, leaf := chain(t, caTemplate("root"), inter, leafTemplate())
+
+	assertFailed(t, validate(root, in, leaf, Options{}), CheckBasicConstraints)
+}
+
+func TestValidateKeyUsage(t *testing.T) {
+	inter := caTemplate("inter")
+	inter.KeyUsage = stdx509.KeyUsageDigitalSignature
+	root, in, leaf := chain(t, caTemplate("root"), inter, leafTemplate())
+
+	assertFailed(t, validate(root, in, leaf, Options{}), CheckKeyUsage)
+}
+
+func TestValidatePathLength(t *testing.T) {
+	rootTmpl := caTemplate("root")
+	root := issue(t, rootTmpl, nil)
+	inter1Tmpl := caTemplate("inter1")
+	inter1Tmpl.MaxPathLenZero = true
+	inter1 := issue(t, inter1Tmpl, root)
+	inter2 := issue(t, caTemplate("inter2"), inter1)
+	leaf := issue(t, leafTemplate(), inter2)
+
+	res := Validate([]*x509.Certificate{leaf.cert, inter2.cert, inter1.cert}, root.cert, Options{Now: testNow})
+	assertFailed(t, res, CheckPathLength)
+	if res.Steps[1].OK() {
+		t.Error("step 2 should carry the path length failure")
+	}
+}
+
+func TestValidateNameConstraints(t *testing.T) {
+	inter := caTemplate("inter")
+	inter.PermittedDNSDomains = []string{"example.test"}
+	inter.ExcludedDNSDomains = []string{"bad.example.test"}
+	inter.PermittedIPRanges = []*net.IPNet{{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)}}
+
+	tests := []struct {
+		name string
+		leaf *stdx509.Certificate
+		fail bool
+	}{
+		{"permitted", leafTemplate("www.example.test"), false},
+		{"outside permitted", leafTemplate("www.example.com"), true},
+		{"excluded", leafTemplate("x.bad.example.test"), true},
+		{"ip permitted", &stdx509.Certificate{Subject: pkix.Name{CommonName: "ip"}, IPAddresses: []net.IP{{10, 1, 2, 3}}, PolicyIdentifiers: []asn1.ObjectIdentifier{policyA}}, false},
+		{"ip outside permitted", &stdx509.Certificate{Subject: pkix.Name{CommonName: "ip"}, IPAddresses: []net.IP{{192, 168, 0, 1}}, PolicyIdentifiers: []asn1.ObjectIdentifier{policyA}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, in, leaf := chain(t, caTemplate("root"), inter, tt.leaf)
+			res := validate(root, in, leaf, Options{})
+			if tt.fail {
+				assertFailed(t, res, CheckNameConstraints)
+			} else {
+				assertFailed(t, res)
+			}
+		})
+	}
+}
+
+func TestValidateAnchorNameConstraints(t *testing.T) {
+	rootTmpl := caTemplate("root")
+	rootTmpl.PermittedDNSDomains = []string{"example.test"}
+	root, inter, leaf := chain(t, rootTmpl, caTemplate("inter"), leafTemplate("www.example.com"))
+
+	assertFailed(t, validate(root, inter, leaf, Options{}), CheckNameConstraints)
+}
+
+func TestValidateDirectoryNameConstraints(t *testing.T) {
+	inter := caTemplate("inter")
+	inter.ExtraExtensions = []pkix.Extension{directoryNameConstraint(t, pkix.Name{Organization: []string{"Good Org"}})}
+
+	good := leafTemplate()
+	good.Subject = pkix.Name{Organization: []string{"Good Org"}, CommonName: "leaf"}
+	root, in, leaf := chain(t, caTemplate("root"), inter, good)
+	assertFailed(t, validate(root, in, leaf, Options{}))
+
+	bad := leafTemplate()
+	bad.Subject = pkix.Name{Organization: []string{"Other Org"}, CommonName: "leaf"}
+	root, in, leaf = chain(t, caTemplate("root"), inter, bad)
+	assertFailed(t, validate(root, in, leaf, Options{}), CheckNameConstraints)
+}
+
+// directoryNameConstraint encodes a nameConstraints extension permitting the
+// subtree rooted at name.
+func directoryNameConstraint(t *testing.T, name pkix.Name) pkix.Extension {
+	t.Helper()
+	rdn, err := asn1.Marshal(name.ToRDNSequence())
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 4, IsCompound: true, Bytes: rdn}
+	subtree, err := asn1.Marshal(struct{ Base asn1.RawValue }{base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	permitted := asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: subtree}
+	value, err := asn1.Marshal(struct{ Permitted asn1.RawValue }{permitted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkix.Extension{Id: asn1.ObjectIdentifier{2, 5, 29, 30}, Critical: true, Value: value}
+}
+
+func TestValidatePolicies(t *testing.T) {
+	interA := caTemplate("inter")
+	interA.PolicyIdentifiers = []asn1.ObjectIdentifier{policyA}
+
+	t.Run("acceptable policy", func(t *testing.T) {
+		root, in, leaf := chain(t, caTemplate("root"), interA, leafTemplate())
+		res := validate(root, in, leaf, Options{InitialPolicies: []string{policyA.String()}, InitialExplicitPolicy: true})
+		assertFailed(t, res)
+	})
+
+	t.Run("no acceptable policy", func(t *testing.T) {
+		root, in, leaf := chain(t, caTemplate("root"), interA, leafTemplate())
+		res := validate(root, in, leaf, Options{InitialPolicies: []string{policyB.String()}, InitialExplicitPolicy: true})
+		assertFailed(t, res, CheckExplicitPolicy)
+		if res.Policies != nil {
+			t.Errorf("Policies = %v, want NULL tree", res.Policies)
+		}
+	})
+
+	t.Run("leaf policy not in path", func(t *testing.T) {
+		leafB := leafTemplate()
+		leafB.PolicyIdentifiers = []asn1.ObjectIdentifier{policyB}
+		root, in, leaf := chain(t, caTemplate("root"), interA, leafB)
+		assertFailed(t, validate(root, in, leaf, Options{InitialExplicitPolicy: true}), CheckPolicy, CheckExplicitPolicy)
+
+		// Without an explicit policy requirement the path stays valid but
+		// carries no policy.
+		res := validate(root, in, leaf, Options{})
+		assertFailed(t, res)
+		if res.Policies != nil {
+			t.Errorf("Policies = %v, want NULL tree", res.Policies)
+		}
+	})
+
+	t.Run("require explicit policy", func(t *testing.T) {
+		rootTmpl := caTemplate("root")
+		rootTmpl.ExtraExtensions = []pkix.Extension{requireExplicitPolicyZero(t)}
+		leafNone := leafTemplate()
+		leafNone.PolicyIdentifiers = nil
+		root, in, leaf := chain(t, rootTmpl, caTemplate("inter"), leafNone)
+		// The anchor's own constraints are not processed, so this path
+		// only fails once an intermediate requires explicit policy.
+		assertFailed(t, validate(root, in, leaf, Options{}))
+
+		interTmpl := caTemplate("inter")
+		interTmpl.ExtraExtensions = []pkix.Extension{requireExplicitPolicyZero(t)}
+		root, in, leaf = chain(t, caTemplate("root"), interTmpl, leafNone)
+		assertFailed(t, validate(root, in, leaf, Options{}), CheckPolicy, CheckExplicitPolicy)
+	})
+
+	t.Run("inhibit any policy", func(t *testing.T) {
+		interTmpl := caTemplate("inter")
+		interTmpl.ExtraExtensions = []pkix.Extension{inhibitAnyPolicyZero(t)}
+		leafAny := leafTemplate()
+		leafAny.PolicyIdentifiers = []asn1.ObjectIdentifier{anyPolicy}
+		root, in, leaf := chain(t, caTemplate("root"), interTmpl, leafAny)
+		assertFailed(t, validate(root, in, leaf, Options{InitialExplicitPolicy: true}), CheckPolicy, CheckExplicitPolicy)
+	})
+}
+
+// The standard library parses but does not encode policyConstraints and
+// inhibitAnyPolicy, so the tests build both extensions by hand.
+func requireExplicitPolicyZero(t *testing.T) pkix.Extension {
+	t.Helper()
+	value, err := asn1.Marshal(struct {
+		RequireExplicitPolicy int `asn1:"tag:0"`
+	}{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkix.Extension{Id: asn1.ObjectIdentifier{2, 5, 29, 36}, Critical: true, Value: value}
+}
+
+func inhibitAnyPolicyZero(t *testing.T) pkix.Extension {
+	t.Helper()
+	value, err := asn1.Marshal(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkix.Extension{Id: asn1.ObjectIdentifier{2, 5, 29, 54}, Critical: true, Value: value}
+}
+
+func TestValidatePolicyMappings(t *testing.T) {
+	root := issue(t, caTemplate("root"), nil)
+	inter1Tmpl := caTemplate("inter1")
+	inter1Tmpl.PolicyIdentifiers = []asn1.ObjectIdentifier{policyA}
+	inter1Tmpl.ExtraExtensions = []pkix.Extension{policyMappingsExtension(t, policyA, policyB)}
+	inter1 := issue(t, inter1Tmpl, root)
+	leafTmpl := leafTemplate()
+	leafTmpl.PolicyIdentifiers = []asn1.ObjectIdentifier{policyB}
+	leaf := issue(t, leafTmpl, inter1)
+
+	res := Validate([]*x509.Certificate{leaf.cert, inter1.cert}, root.cert, Options{
+		Now:                   testNow,
+		InitialPolicies:       []string{policyA.String()},
+		InitialExplicitPolicy: true,
+	})
+	assertFailed(t, res)
+
+	res = Validate([]*x509.Certificate{leaf.cert, inter1.cert}, root.cert, Options{
+		Now:                         testNow,
+		InitialPolicies:             []string{policyA.String()},
+		InitialExplicitPolicy:       true,
+		InitialPolicyMappingInhibit: true,
+	})
+	assertFailed(t, res, CheckPolicy, CheckExplicitPolicy)
+}
+
+func policyMappingsExtension(t *testing.T, issuer, subject asn1.ObjectIdentifier) pkix.Extension {
+	t.Helper()
+	value, err := asn1.Marshal([]struct {
+		IssuerDomain  asn1.ObjectIdentifier
+		SubjectDomain asn1.ObjectIdentifier
+	}{{issuer, subject}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkix.Extension{Id: asn1.ObjectIdentifier{2, 5, 29, 33}, Critical: true, Value: value}
+}
+
+func TestValidateUnknownCriticalExtension(t *testing.T) {
+	leafTmpl := leafTemplate()
+	leafTmpl.ExtraExtensions = []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 2, 3, 4, 5}, Critical: true, Value: []byte{0x05, 0x00}}}
+	root, inter, leaf := chain(t, caTemplate("root"), caTemplate("inter"), leafTmpl)
+
+	res := validate(root, inter, leaf, Options{})
+	assertFailed(t, res, CheckCriticalExtensions)
+	if !strings.Contains(res.Failures[0], "1.2.3.4.5") {
+		t.Errorf("failure %q does not name the extension", res.Failures[0])
+	}
+}
+
+func TestBuildTree(t *testing.T) {
+	root, inter, leaf := chain(t, caTemplate("root"), caTemplate("inter"), leafTemplate())
+	n := BuildTree(validate(root, inter, leaf, Options{Now: testNow.AddDate(2, 0, 0)}))
+
+	for path, want := range map[string]any{
+		"valid":                  false,
+		"anchor":                 "CN=root",
+		"length":                 2,
+		"steps.0.index":          1,
+		"steps.1.subject":        "CN=leaf",
+		"steps.1.valid":          false,
+		"steps.1.validity":       false,
+		"steps.1.signature":      true,
+		"steps.0.pathLength":     true,
+		"policies.0":             policyA.String(),
+		"failures.0":             nil,
+		"steps.1.explicitPolicy": true,
+	} {
+		got, ok := n.Resolve(path)
+		if !ok {
+			t.Errorf("%s: not found", path)
+			continue
+		}
+		if want != nil && got.Value != want {
+			t.Errorf("%s = %v, want %v", path, got.Value, want)
+		}
+	}
+	if _, ok := n.Resolve("steps.1.pathLength"); ok {
+		t.Error("the target should have no pathLength check")
+	}
+}
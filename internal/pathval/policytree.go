@@ -0,0 +1,228 @@
+package pathval
+
+import (
+	"slices"
+	"sort"
+)
+
+// policyNode is a node of the valid_policy_tree (RFC 5280 §6.1.2(a)).
+// Qualifiers are not tracked; PCL evaluates them per certificate.
+type policyNode struct {
+	policy   string
+	expected []string
+	parent   *policyNode
+	children []*policyNode
+}
+
+func (n *policyNode) addChild(policy string, expected []string) *policyNode {
+	child := &policyNode{policy: policy, expected: expected, parent: n}
+	n.children = append(n.children, child)
+	return child
+}
+
+func (n *policyNode) hasChild(policy string) bool {
+	return slices.ContainsFunc(n.children, func(c *policyNode) bool { return c.policy == policy })
+}
+
+// policyTree is the valid_policy_tree. levels[d] holds the nodes at depth
+// d; a nil tree is the NULL tree of the RFC.
+type policyTree struct {
+	levels [][]*policyNode
+}
+
+func newPolicyTree() *policyTree {
+	root := &policyNode{policy: AnyPolicy, expected: []string{AnyPolicy}}
+	return &policyTree{levels: [][]*policyNode{{root}}}
+}
+
+func (t *policyTree) depth() int {
+	return len(t.levels) - 1
+}
+
+// addLevel processes the certificatePolicies of the certificate at depth
+// i = t.depth()+1 (§6.1.3(d)(1) and (2)). anyAllowed reports whether an
+// anyPolicy in the certificate is honoured.
+func (t *policyTree) addLevel(policies []string, anyAllowed bool) {
+	parents := t.levels[len(t.levels)-1]
+	var level []*policyNode
+
+	for _, p := range policies {
+		if p == AnyPolicy {
+			continue
+		}
+		matched := false
+		for _, parent := range parents {
+			if slices.Contains(parent.expected, p) {
+				level = append(level, parent.addChild(p, []string{p}))
+				matched = true
+			}
+		}
+		if matched {
+			continue
+		}
+		for _, parent := range parents {
+			if parent.policy == AnyPolicy {
+				level = append(level, parent.addChild(p, []string{p}))
+			}
+		}
+	}
+
+	if anyAllowed && slices.Contains(policies, AnyPolicy) {
+		for _, parent := range parents {
+			for _, e := range parent.expected {
+				if !parent.hasChild(e) {
+					level = append(level, parent.addChild(e, []string{e}))
+				}
+			}
+		}
+	}
+
+	t.levels = append(t.levels, level)
+}
+
+// prune deletes nodes without children above the deepest level
+// (§6.1.3(d)(3)). It reports false once the root is deleted, i.e. the tree
+// has become NULL.
+func (t *policyTree) prune() bool {
+	for d := t.depth() - 1; d >= 0; d-- {
+		kept := t.levels[d][:0]
+		for _, n := range t.levels[d] {
+			if len(n.children) > 0 {
+				kept = append(kept, n)
+			} else if n.parent != nil {
+				n.parent.removeChild(n)
+			}
+		}
+		t.levels[d] = kept
+	}
+	return len(t.levels[0]) > 0
+}
+
+func (n *policyNode) removeChild(child *policyNode) {
+	n.children = slices.DeleteFunc(n.children, func(c *policyNode) bool { return c == child })
+}
+
+// removeLeaf deletes nodes at the deepest level for which drop is true.
+func (t *policyTree) removeLeaf(drop func(*policyNode) bool) {
+	d := t.depth()
+	kept := t.levels[d][:0]
+	for _, n := range t.levels[d] {
+		if drop(n) {
+			if n.parent != nil {
+				n.parent.removeChild(n)
+			}
+			continue
+		}
+		kept = append(kept, n)
+	}
+	t.levels[d] = kept
+}
+
+// applyMappings processes the policyMappings of the certificate at the
+// deepest level (§6.1.4(b)). With mapping inhibited, mapped policies are
+// deleted instead. It reports false if the tree becomes NULL.
+func (t *policyTree) applyMappings(mappings []PolicyMapping, inhibited bool) bool {
+	mapped := map[string][]string{}
+	var issuerPolicies []string
+	for _, m := range mappings {
+		if _, ok := mapped[m.IssuerPolicy]; !ok {
+			issuerPolicies = append(issuerPolicies, m.IssuerPolicy)
+		}
+		mapped[m.IssuerPolicy] = append(mapped[m.IssuerPolicy], m.SubjectPolicy)
+	}
+
+	d := t.depth()
+	if inhibited {
+		t.removeLeaf(func(n *policyNode) bool { _, ok := mapped[n.policy]; return ok })
+		return t.prune()
+	}
+
+	for _, p := range issuerPolicies {
+		found := false
+		for _, n := range t.levels[d] {
+			if n.policy == p {
+				n.expected = mapped[p]
+				found = true
+			}
+		}
+		if found {
+			continue
+		}
+		for _, n := range t.levels[d] {
+			if n.policy == AnyPolicy && n.parent != nil {
+				t.levels[d] = append(t.levels[d], n.parent.addChild(p, mapped[p]))
+				break
+			}
+		}
+	}
+	return true
+}
+
+// intersect computes the intersection of the tree with the
+// user-initial-policy-set (§6.1.5(g)(iii)). It reports false if the tree
+// becomes NULL.
+func (t *policyTree) intersect(initial []string) bool {
+	if len(initial) == 0 || slices.Contains(initial, AnyPolicy) {
+		return true
+	}
+
+	// valid_policy_node_set: nodes whose parent is an anyPolicy node.
+	var nodeSet []*policyNode
+	for _, level := range t.levels[1:] {
+		for _, n := range level {
+			if n.parent.policy == AnyPolicy && n.policy != AnyPolicy {
+				nodeSet = append(nodeSet, n)
+			}
+		}
+	}
+	var remove []*policyNode
+	for _, n := range nodeSet {
+		if !slices.Contains(initial, n.policy) {
+			remove = append(remove, n)
+		}
+	}
+	for _, n := range remove {
+		t.removeSubtree(n)
+	}
+
+	d := t.depth()
+	for _, n := range t.levels[d] {
+		if n.policy != AnyPolicy || n.parent == nil {
+			continue
+		}
+		for _, p := range initial {
+			if !slices.ContainsFunc(nodeSet, func(v *policyNode) bool { return v.policy == p }) {
+				t.levels[d] = append(t.levels[d], n.parent.addChild(p, []string{p}))
+			}
+		}
+	}
+	t.removeLeaf(func(n *policyNode) bool { return n.policy == AnyPolicy && n.parent != nil })
+
+	return t.prune()
+}
+
+func (t *policyTree) removeSubtree(n *policyNode) {
+	for _, c := range slices.Clone(n.children) {
+		t.removeSubtree(c)
+	}
+	if n.parent != nil {
+		n.parent.removeChild(n)
+	}
+	for d, level := range t.levels {
+		t.levels[d] = slices.DeleteFunc(level, func(v *policyNode) bool { return v == n })
+	}
+}
+
+// policies returns the valid policies of the deepest level, sorted.
+func (t *policyTree) policies() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, n := range t.levels[t.depth()] {
+		if !seen[n.policy] {
+			seen[n.policy] = true
+			out = append(out, n.policy)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
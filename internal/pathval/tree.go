@@ -0,0 +1,77 @@
+package pathval
+
+import (
+	"fmt"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/cert"
+	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/trust"
+)
+
+// FromChain splits chain, starting at position pos, into the path to
+// validate and its trust anchor, as resolved by store. With a nil store
+// the last certificate is the anchor if it is self-signed. The anchor's
+// Cert is nil if none was found.
+func FromChain(chain []*cert.Info, pos int, store *trust.Store) ([]*x509.Certificate, trust.Anchor) {
+	var path []*x509.Certificate
+	for i := pos; i < len(chain); i++ {
+		if chain[i] != nil && chain[i].Cert != nil {
+			path = append(path, chain[i].Cert)
+		}
+	}
+	return store.Resolve(path)
+}
+
+// BuildTree renders a validation result as the pathValidation node:
+//
+//	pathValidation.valid              overall result
+//	pathValidation.anchor             trust anchor subject
+//	pathValidation.length             number of certificates below the anchor
+//	pathValidation.policies.N         user-constrained policy set
+//	pathValidation.failures.N         failed checks, in path order
+//	pathValidation.steps.N.index      RFC step number (1 = issued by the anchor)
+//	pathValidation.steps.N.subject    certificate subject
+//	pathValidation.steps.N.valid      all checks of the step passed
+//	pathValidation.steps.N.<check>    outcome of each check that applied
+func BuildTree(res Result) *node.Node {
+	n := node.New("pathValidation", nil)
+	n.Children["valid"] = node.New("valid", res.Valid)
+	if res.Anchor != "" {
+		n.Children["anchor"] = node.New("anchor", res.Anchor)
+	}
+	n.Children["length"] = node.New("length", len(res.Steps))
+
+	if res.Policies != nil {
+		n.Children["policies"] = listNode("policies", res.Policies)
+	}
+	if len(res.Failures) > 0 {
+		n.Children["failures"] = listNode("failures", res.Failures)
+	}
+
+	steps := node.New("steps", nil)
+	for i, step := range res.Steps {
+		key := fmt.Sprintf("%d", i)
+		sn := node.New(key, nil)
+		sn.Children["index"] = node.New("index", step.Index)
+		sn.Children["subject"] = node.New("subject", step.Subject)
+		sn.Children["valid"] = node.New("valid", step.OK())
+		for _, c := range step.Checks {
+			sn.Children[c.Name] = node.New(c.Name, c.OK)
+		}
+		steps.Children[key] = sn
+	}
+	n.Children["steps"] = steps
+
+	return n
+}
+
+func listNode(name string, values []string) *node.Node {
+	n := node.New(name, nil)
+	for i, v := range values {
+		key := fmt.Sprintf("%d", i)
+		n.Children[key] = node.New(key, v)
+	}
+	return n
+}
@@ -18,6 +18,23 @@ type Policy struct {
 	TSTType   []string    `yaml:"tstType,omitempty"`
 	SCTType   []string    `yaml:"sctType,omitempty"`
 	Rules     []rule.Rule `yaml:"rules"`
+
+	// EffectiveFrom, EffectiveUntil and EffectiveField set the effective
+	// window of every rule that does not set its own.
+	EffectiveFrom  string `yaml:"effectiveFrom,omitempty"`
+	EffectiveUntil string `yaml:"effectiveUntil,omitempty"`
+	EffectiveField string `yaml:"effectiveField,omitempty"`
+}
+
+// scoped returns r with its effective window narrowed to the policy's, so
+// that a rule never applies outside the policy that holds it.
+func (p Policy) scoped(r rule.Rule) rule.Rule {
+	r.EffectiveFrom, r.EffectiveUntil = rule.IntersectEffectiveWindows(
+		r.EffectiveFrom, r.EffectiveUntil, p.EffectiveFrom, p.EffectiveUntil)
+	if r.EffectiveField == "" {
+		r.EffectiveField = p.EffectiveField
+	}
+	return r
 }
 
 type Result struct {
@@ -25,6 +42,7 @@ type Result struct {
 	CertType  string        `json:"cert_type" yaml:"cert_type"`
 	CertPath  string        `json:"cert_path" yaml:"cert_path"`
 	Source    string        `json:"source" yaml:"source"`
+	Path      int           `json:"path,omitempty" yaml:"path,omitempty"` // 1-based certification path, set when every valid path is linted
 	Results   []rule.Result `json:"rules" yaml:"rules"`
 	Verdict   string        `json:"verdict" yaml:"verdict"`
 	CheckedAt time.Time     `json:"checked_at" yaml:"checked_at"`
@@ -48,7 +66,7 @@ func Evaluate(
 	verdict := "pass"
 
 	for _, r := range p.Rules {
-		res := rule.Evaluate(root, r, reg, ctx)
+		res := rule.Evaluate(root, p.scoped(r), reg, ctx)
 		results = append(results, res)
 
 		if res.Verdict == rule.VerdictFail && r.Severity == "error" {
@@ -56,6 +74,11 @@ func Evaluate(
 		}
 	}
 
+	checkedAt := time.Now()
+	if ctx != nil && !ctx.Now.IsZero() {
+		checkedAt = ctx.Now
+	}
+
 	certType := ""
 	certPath := ""
 	source := ""
@@ -72,6 +95,6 @@ func Evaluate(
 		Source:    source,
 		Results:   results,
 		Verdict:   verdict,
-		CheckedAt: time.Now(),
+		CheckedAt: checkedAt,
 	}
 }
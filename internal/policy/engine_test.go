@@ -2,6 +2,7 @@ package policy
 
 import (
 	"testing"
+	"time"
 
 	"github.com/cavoq/PCL/internal/node"
 	"github.com/cavoq/PCL/internal/operator"
@@ -112,3 +113,59 @@ func TestPolicyMultipleRules(t *testing.T) {
 		t.Fatalf("expected 2 rule results, got %d", len(res.Results))
 	}
 }
+
+func TestPolicyCheckedAtUsesEvaluationTime(t *testing.T) {
+	at := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	ctx := operator.NewEvaluationContext(node.New("root", nil), nil, nil, operator.WithNow(at))
+
+	res := Evaluate(Policy{ID: "test-policy"}, ctx.Root, operator.NewRegistry(), ctx)
+
+	if !res.CheckedAt.Equal(at) {
+		t.Fatalf("CheckedAt = %v, want %v", res.CheckedAt, at)
+	}
+}
+
+func TestPolicyEffectiveWindow(t *testing.T) {
+	root := node.New("root", nil)
+	cert := node.New("certificate", nil)
+	validity := node.New("validity", nil)
+	validity.Children["notBefore"] = node.New("notBefore", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+	cert.Children["validity"] = validity
+	cert.Children["version"] = node.New("version", 1)
+	root.Children["certificate"] = cert
+
+	reg := operator.NewRegistry()
+	reg.Register(operator.Eq{})
+
+	p := Policy{
+		ID:            "test-policy",
+		EffectiveFrom: "2020-09-01",
+		Rules: []rule.Rule{
+			{ID: "current", Target: "certificate.version", Operator: "eq", Operands: []any{3}, Severity: "error"},
+			{ID: "legacy", Target: "certificate.version", Operator: "eq", Operands: []any{3}, Severity: "error", EffectiveFrom: "2010-01-01"},
+		},
+	}
+
+	res := Evaluate(p, root, reg, nil)
+
+	if res.Results[0].Verdict != rule.VerdictSkip {
+		t.Errorf("current rule verdict = %s, want skip outside the policy window", res.Results[0].Verdict)
+	}
+	if res.Results[1].Verdict != rule.VerdictSkip {
+		t.Errorf("legacy rule verdict = %s, want skip outside the policy window despite its own", res.Results[1].Verdict)
+	}
+	if res.Verdict != "pass" {
+		t.Errorf("verdict = %q, want pass", res.Verdict)
+	}
+
+	p.EffectiveFrom, p.EffectiveUntil = "2018-01-01", "2020-01-01"
+	p.Rules[1].EffectiveUntil = "2018-06-01"
+	res = Evaluate(p, root, reg, nil)
+
+	if res.Results[0].Verdict != rule.VerdictFail {
+		t.Errorf("current rule verdict = %s, want fail inside the policy window", res.Results[0].Verdict)
+	}
+	if res.Results[1].Verdict != rule.VerdictSkip {
+		t.Errorf("legacy rule verdict = %s, want skip after its own window", res.Results[1].Verdict)
+	}
+}
@@ -9,12 +9,15 @@ import (
 	"github.com/cavoq/PCL/internal/crl"
 	"github.com/cavoq/PCL/internal/oid"
 	"github.com/cavoq/PCL/internal/rule"
+	"github.com/cavoq/PCL/internal/sct"
+	"github.com/cavoq/PCL/internal/tst"
 )
 
 const (
 	InputCert     = "cert"
 	InputCRL      = "crl"
 	InputOCSP     = "ocsp"
+	InputCSR      = "csr"
 	InputTST      = "tst"
 	InputSCT      = "sct"
 	InputAttrCert = "attrCert"
@@ -52,6 +55,26 @@ func ByCRL(policies []Policy, revocationList *x509.RevocationList) []Policy {
 	return filtered
 }
 
+func ByTST(policies []Policy, token *tst.Token) []Policy {
+	var filtered []Policy
+	for _, p := range policies {
+		if AppliesToTST(p, token) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+func BySCT(policies []Policy, list *sct.List) []Policy {
+	var filtered []Policy
+	for _, p := range policies {
+		if AppliesToSCT(p, list) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
 func AppliesToInput(p Policy, inputType string) bool {
 	if len(p.AppliesTo) > 0 {
 		return slices.Contains(p.AppliesTo, inputType)
@@ -94,12 +117,21 @@ func AppliesToCertificate(p Policy, cert *x509.Certificate) bool {
 			if !cert.BasicConstraintsValid || !cert.IsCA {
 				return true
 			}
+		case "precertificate":
+			if cert.IsPrecert {
+				return true
+			}
 		default:
 			for _, eku := range cert.ExtKeyUsage {
 				if oid.ExtKeyUsageToOID(eku) == ct {
 					return true
 				}
 			}
+			for _, eku := range cert.UnknownExtKeyUsage {
+				if eku.String() == ct {
+					return true
+				}
+			}
 		}
 	}
 
@@ -119,7 +151,7 @@ func AppliesToCRL(p Policy, hasDeltaIndicator bool, isIndirectCRL bool) bool {
 		ct = oid.NormalizeOID(ct)
 
 		switch ct {
-		case oid.DeltaCRLIndicator:
+		case oid.DeltaCRLIndicator, "deltaCRL":
 			if hasDeltaIndicator {
 				return true
 			}
@@ -137,49 +169,96 @@ func AppliesToCRL(p Policy, hasDeltaIndicator bool, isIndirectCRL bool) bool {
 	return false
 }
 
-func inferInputTypeFromRules(rules []rule.Rule) string {
-	if len(rules) == 0 {
-		return ""
+// AppliesToTST matches tstType entries against a time-stamp: "response"
+// and "token" select by how it was delivered, any other entry is compared
+// with the TSA policy OID of the TSTInfo.
+func AppliesToTST(p Policy, token *tst.Token) bool {
+	if token == nil || !AppliesToInput(p, InputTST) {
+		return false
 	}
 
-	target := rules[0].Target
-	if strings.HasPrefix(target, "certificate.") || target == "certificate" {
-		return InputCert
+	if len(p.TSTType) == 0 {
+		return true
 	}
-	if strings.HasPrefix(target, "crl.") || target == "crl" {
-		return InputCRL
+
+	for _, tt := range p.TSTType {
+		switch tt {
+		case "response":
+			if token.Status != nil {
+				return true
+			}
+		case "token":
+			if token.Status == nil {
+				return true
+			}
+		default:
+			if token.Info != nil && token.Info.Policy == tt {
+				return true
+			}
+		}
 	}
-	if strings.HasPrefix(target, "ocsp.") || target == "ocsp" {
-		return InputOCSP
+
+	return false
+}
+
+// AppliesToSCT matches sctType entries against how an SCT list was
+// delivered: "tls" or "ocsp".
+func AppliesToSCT(p Policy, list *sct.List) bool {
+	if list == nil || !AppliesToInput(p, InputSCT) {
+		return false
 	}
 
-	if rules[0].When != nil && rules[0].When.Target != "" {
-		whenTarget := rules[0].When.Target
-		if strings.HasPrefix(whenTarget, "certificate.") || whenTarget == "certificate" {
-			return InputCert
-		}
-		if strings.HasPrefix(whenTarget, "crl.") || whenTarget == "crl" {
-			return InputCRL
-		}
-		if strings.HasPrefix(whenTarget, "ocsp.") || whenTarget == "ocsp" {
-			return InputOCSP
+	if len(p.SCTType) == 0 {
+		return true
+	}
+
+	return slices.Contains(p.SCTType, list.Delivery)
+}
+
+func inferInputTypeFromRules(rules []rule.Rule) string {
+	if len(rules) == 0 {
+		return ""
+	}
+
+	for _, target := range rules[0].Targets() {
+		if inputType := inputTypeForTarget(target); inputType != "" {
+			return inputType
 		}
 	}
 
 	return ""
 }
 
+func inputTypeForTarget(target string) string {
+	switch {
+	case strings.HasPrefix(target, "certificate.") || target == "certificate":
+		return InputCert
+	case strings.HasPrefix(target, "crl.") || target == "crl":
+		return InputCRL
+	case strings.HasPrefix(target, "ocsp.") || target == "ocsp":
+		return InputOCSP
+	case strings.HasPrefix(target, "csr.") || target == "csr":
+		return InputCSR
+	case strings.HasPrefix(target, "tst.") || target == "tst":
+		return InputTST
+	case strings.HasPrefix(target, "sct.") || target == "sct":
+		return InputSCT
+	case strings.HasPrefix(target, "attrCert.") || target == "attrCert":
+		return InputAttrCert
+	}
+	return ""
+}
+
 func appliesToCRLInput(p Policy) bool {
 	if len(p.AppliesTo) > 0 {
 		return slices.Contains(p.AppliesTo, InputCRL)
 	}
 
 	for _, r := range p.Rules {
-		if strings.HasPrefix(r.Target, "crl.") || r.Target == "crl" {
-			return true
-		}
-		if r.When != nil && (strings.HasPrefix(r.When.Target, "crl.") || r.When.Target == "crl") {
-			return true
+		for _, target := range r.Targets() {
+			if inputTypeForTarget(target) == InputCRL {
+				return true
+			}
 		}
 	}
 
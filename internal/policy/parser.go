@@ -14,7 +14,7 @@ import (
 )
 
 func ParseFile(path string) (Policy, error) {
-	return parseFileWithIncludes(path, map[string]bool{})
+	return parseFileWithIncludes(path, map[string]bool{}, nil)
 }
 
 func Parse(data []byte) (Policy, error) {
@@ -29,6 +29,25 @@ func Parse(data []byte) (Policy, error) {
 }
 
 func ParseDir(dir string) ([]Policy, error) {
+	files, err := policyFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]Policy, 0, len(files))
+	for _, path := range files {
+		p, err := ParseFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", filepath.Base(path), err)
+		}
+		policies = append(policies, p)
+	}
+
+	return policies, nil
+}
+
+// policyFiles returns the YAML files directly inside dir, sorted by name.
+func policyFiles(dir string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("reading directory: %w", err)
@@ -38,7 +57,7 @@ func ParseDir(dir string) ([]Policy, error) {
 		return entries[i].Name() < entries[j].Name()
 	})
 
-	policies := make([]Policy, 0, len(entries))
+	files := make([]string, 0, len(entries))
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -48,15 +67,10 @@ func ParseDir(dir string) ([]Policy, error) {
 		if !strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml") {
 			continue
 		}
-
-		p, err := ParseFile(filepath.Join(dir, name))
-		if err != nil {
-			return nil, fmt.Errorf("parsing %s: %w", name, err)
-		}
-		policies = append(policies, p)
+		files = append(files, filepath.Join(dir, name))
 	}
 
-	return policies, nil
+	return files, nil
 }
 
 func validatePolicy(p Policy) error {
@@ -68,29 +82,95 @@ func validatePolicy(p Policy) error {
 			return fmt.Errorf("include %d: path is required", i)
 		}
 	}
+	if err := rule.ValidateEffectiveWindow(p.EffectiveFrom, p.EffectiveUntil); err != nil {
+		return err
+	}
 	for i, r := range p.Rules {
 		if strings.TrimSpace(r.ID) == "" {
 			return fmt.Errorf("rule %d: id is required", i)
 		}
-		if strings.TrimSpace(r.Target) == "" {
-			return fmt.Errorf("rule %s: target is required", r.ID)
+		if err := rule.ValidateEffectiveWindow(r.EffectiveFrom, r.EffectiveUntil); err != nil {
+			return fmt.Errorf("rule %s: %w", r.ID, err)
+		}
+		if err := validateScopedWindow(p, r); err != nil {
+			return err
 		}
-		if strings.TrimSpace(r.Operator) == "" {
-			return fmt.Errorf("rule %s: operator is required", r.ID)
+		if err := validateCondition(r.Body(), ""); err != nil {
+			return fmt.Errorf("rule %s: %w", r.ID, err)
 		}
 		if r.When != nil {
-			if strings.TrimSpace(r.When.Target) == "" {
-				return fmt.Errorf("rule %s: when.target is required", r.ID)
-			}
-			if strings.TrimSpace(r.When.Operator) == "" {
-				return fmt.Errorf("rule %s: when.operator is required", r.ID)
+			if err := validateCondition(*r.When, "when."); err != nil {
+				return fmt.Errorf("rule %s: %w", r.ID, err)
 			}
 		}
 	}
 	return nil
 }
 
-func parseFileWithIncludes(path string, seen map[string]bool) (Policy, error) {
+// validateScopedWindow checks that r's effective window overlaps the
+// window of the policy p that holds it.
+func validateScopedWindow(p Policy, r rule.Rule) error {
+	s := p.scoped(r)
+	if err := rule.ValidateEffectiveWindow(s.EffectiveFrom, s.EffectiveUntil); err != nil {
+		return fmt.Errorf("rule %s: effective window is outside policy %s: %w", r.ID, p.ID, err)
+	}
+	return nil
+}
+
+// validateCondition checks that a condition is either a target/operator
+// pair or exactly one of allOf, anyOf and not, recursively.
+func validateCondition(c rule.Condition, path string) error {
+	combinators := 0
+	if len(c.AllOf) > 0 {
+		combinators++
+	}
+	if len(c.AnyOf) > 0 {
+		combinators++
+	}
+	if c.Not != nil {
+		combinators++
+	}
+
+	if combinators == 0 {
+		if strings.TrimSpace(c.Target) == "" {
+			return fmt.Errorf("%starget is required", path)
+		}
+		if strings.TrimSpace(c.Operator) == "" {
+			return fmt.Errorf("%soperator is required", path)
+		}
+		return nil
+	}
+	where := strings.TrimSuffix(path, ".")
+	if where == "" {
+		where = "rule"
+	}
+	if combinators > 1 {
+		return fmt.Errorf("%s: only one of allOf, anyOf or not may be set", where)
+	}
+	if c.Target != "" || c.Operator != "" {
+		return fmt.Errorf("%s: target/operator cannot be combined with allOf, anyOf or not", where)
+	}
+
+	for i, sub := range c.AllOf {
+		if err := validateCondition(sub, fmt.Sprintf("%sallOf[%d].", path, i)); err != nil {
+			return err
+		}
+	}
+	for i, sub := range c.AnyOf {
+		if err := validateCondition(sub, fmt.Sprintf("%sanyOf[%d].", path, i)); err != nil {
+			return err
+		}
+	}
+	if c.Not != nil {
+		return validateCondition(*c.Not, path+"not.")
+	}
+	return nil
+}
+
+// parseFileWithIncludes parses path and merges the rules of its includes.
+// onParsed, if set, is called with each file and its own rules before they
+// are merged.
+func parseFileWithIncludes(path string, seen map[string]bool, onParsed func(path string, p Policy)) (Policy, error) {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		return Policy{}, fmt.Errorf("resolving path: %w", err)
@@ -110,6 +190,9 @@ func parseFileWithIncludes(path string, seen map[string]bool) (Policy, error) {
 	if err != nil {
 		return Policy{}, err
 	}
+	if onParsed != nil {
+		onParsed(absPath, p)
+	}
 
 	if len(p.Includes) == 0 {
 		return p, nil
@@ -124,11 +207,19 @@ func parseFileWithIncludes(path string, seen map[string]bool) (Policy, error) {
 		if !filepath.IsAbs(incPath) {
 			incPath = filepath.Join(baseDir, incPath)
 		}
-		incPolicy, err := parseFileWithIncludes(incPath, seen)
+		incPolicy, err := parseFileWithIncludes(incPath, seen, onParsed)
 		if err != nil {
 			return Policy{}, fmt.Errorf("including %s: %w", inc, err)
 		}
-		merged.Rules = append(merged.Rules, incPolicy.Rules...)
+		// Included rules keep the effective window of the file that
+		// defines them.
+		for _, r := range incPolicy.Rules {
+			r = incPolicy.scoped(r)
+			if err := validateScopedWindow(p, r); err != nil {
+				return Policy{}, fmt.Errorf("including %s: %w", inc, err)
+			}
+			merged.Rules = append(merged.Rules, r)
+		}
 	}
 
 	merged.Rules = append(merged.Rules, p.Rules...)
@@ -4,6 +4,12 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/sct"
+	"github.com/cavoq/PCL/internal/tst"
 )
 
 func TestParse_Valid(t *testing.T) {
@@ -137,6 +143,97 @@ rules:
     target: certificate.version
     operator: eq
     operands: [3]
+`),
+		},
+		{
+			name: "composite with target",
+			data: []byte(`
+id: test-policy
+rules:
+  - id: r1
+    target: certificate.version
+    operator: eq
+    anyOf:
+      - target: certificate.subjectAltName
+        operator: present
+`),
+		},
+		{
+			name: "multiple combinators",
+			data: []byte(`
+id: test-policy
+rules:
+  - id: r1
+    allOf:
+      - target: certificate.version
+        operator: present
+    anyOf:
+      - target: certificate.subjectAltName
+        operator: present
+`),
+		},
+		{
+			name: "nested check missing operator",
+			data: []byte(`
+id: test-policy
+rules:
+  - id: r1
+    anyOf:
+      - target: certificate.subjectAltName.dNSName
+      - target: certificate.subjectAltName.iPAddress
+        operator: present
+`),
+		},
+		{
+			name: "when not missing target",
+			data: []byte(`
+id: test-policy
+rules:
+  - id: r1
+    when:
+      not:
+        operator: present
+    target: certificate.version
+    operator: eq
+    operands: [3]
+`),
+		},
+		{
+			name: "invalid effectiveFrom",
+			data: []byte(`
+id: test-policy
+effectiveFrom: last year
+rules:
+  - id: r1
+    target: certificate.version
+    operator: eq
+    operands: [3]
+`),
+		},
+		{
+			name: "effective window ends before it starts",
+			data: []byte(`
+id: test-policy
+rules:
+  - id: r1
+    effectiveFrom: 2026-03-15
+    effectiveUntil: 2020-09-01
+    target: certificate.version
+    operator: eq
+    operands: [3]
+`),
+		},
+		{
+			name: "rule window outside policy window",
+			data: []byte(`
+id: test-policy
+effectiveFrom: 2020-09-01
+rules:
+  - id: r1
+    effectiveUntil: 2020-01-01
+    target: certificate.version
+    operator: eq
+    operands: [3]
 `),
 		},
 	}
@@ -151,6 +248,57 @@ rules:
 	}
 }
 
+func TestParse_CompositeRule(t *testing.T) {
+	data := []byte(`
+id: test-policy
+rules:
+  - id: san-dns-or-ip
+    anyOf:
+      - target: certificate.subjectAltName.dNSName
+        operator: present
+      - not:
+          target: certificate.subjectAltName.iPAddress
+          operator: absent
+    when:
+      allOf:
+        - target: certificate.basicConstraints.cA
+          operator: eq
+          operands: [false]
+    severity: error
+`)
+
+	p, err := Parse(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := p.Rules[0]
+	if len(r.AnyOf) != 2 || r.AnyOf[1].Not == nil {
+		t.Fatalf("anyOf not parsed: %+v", r.AnyOf)
+	}
+	if r.When == nil || len(r.When.AllOf) != 1 {
+		t.Fatalf("when.allOf not parsed: %+v", r.When)
+	}
+	if !AppliesToInput(p, InputCert) || AppliesToInput(p, InputCRL) {
+		t.Error("composite rule targets should infer the certificate input type")
+	}
+}
+
+func TestParse_InvalidCompositeMessage(t *testing.T) {
+	_, err := Parse([]byte(`
+id: test-policy
+rules:
+  - id: r1
+    anyOf:
+      - target: certificate.subjectAltName.dNSName
+`))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if err.Error() != "rule r1: anyOf[0].operator is required" {
+		t.Errorf("error = %q", err.Error())
+	}
+}
+
 func TestParseFile(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "policy.yaml")
@@ -224,6 +372,61 @@ rules:
 	}
 }
 
+func TestParseFile_IncludesKeepEffectiveWindow(t *testing.T) {
+	dir := t.TempDir()
+
+	base := []byte(`
+id: base
+effectiveUntil: 2020-09-01
+rules:
+  - id: legacy-rule
+    target: certificate.version
+    operator: eq
+    operands: [3]
+  - id: own-window
+    effectiveFrom: 2015-01-01
+    effectiveUntil: 2016-01-01
+    target: certificate.version
+    operator: eq
+    operands: [3]
+`)
+	child := []byte(`
+id: child
+effectiveFrom: 2012-01-01
+includes:
+  - base.yaml
+rules:
+  - id: current-rule
+    target: certificate.version
+    operator: eq
+    operands: [3]
+`)
+
+	if err := os.WriteFile(filepath.Join(dir, "base.yaml"), base, 0644); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "child.yaml"), child, 0644); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	p, err := ParseFile(filepath.Join(dir, "child.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(p.Rules) != 3 {
+		t.Fatalf("expected 3 rules, got %d", len(p.Rules))
+	}
+	if r := p.Rules[0]; r.EffectiveFrom != "" || r.EffectiveUntil != "2020-09-01" {
+		t.Errorf("legacy-rule window = [%q, %q), want the window of base.yaml", r.EffectiveFrom, r.EffectiveUntil)
+	}
+	if r := p.Rules[1]; r.EffectiveFrom != "2015-01-01" || r.EffectiveUntil != "2016-01-01" {
+		t.Errorf("own-window window = [%q, %q), want its own window", r.EffectiveFrom, r.EffectiveUntil)
+	}
+	if r := p.Rules[2]; r.EffectiveFrom != "" {
+		t.Errorf("current-rule effectiveFrom = %q, want it applied at evaluation", r.EffectiveFrom)
+	}
+}
+
 func TestParseFile_IncludesCycle(t *testing.T) {
 	dir := t.TempDir()
 
@@ -438,3 +641,115 @@ rules:
 		t.Errorf("expected operand[0] to be 'SHA256-RSA', got %v (type %T)", operandsSlice[0], operandsSlice[0])
 	}
 }
+
+func TestParse_CSRInputType(t *testing.T) {
+	p, err := Parse([]byte(`
+id: test-policy
+rules:
+  - id: r1
+    target: csr.subject.commonName
+    operator: present
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !AppliesToInput(p, InputCSR) || AppliesToInput(p, InputCert) {
+		t.Error("csr targets should infer the CSR input type")
+	}
+	if AppliesToCertificate(p, &x509.Certificate{}) {
+		t.Error("a CSR policy should not apply to certificates")
+	}
+}
+
+func TestParse_AttrCertInputType(t *testing.T) {
+	p, err := Parse([]byte(`
+id: test-policy
+rules:
+  - id: r1
+    target: attrCert.attributes.role
+    operator: present
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !AppliesToInput(p, InputAttrCert) || AppliesToInput(p, InputCert) {
+		t.Error("attrCert targets should infer the attribute certificate input type")
+	}
+}
+
+func TestParse_TSTType(t *testing.T) {
+	p, err := Parse([]byte(`
+id: test-policy
+tstType: [response, 1.2.3.4.1]
+rules:
+  - id: r1
+    target: tst.policy
+    operator: present
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !AppliesToInput(p, InputTST) || AppliesToInput(p, InputCert) {
+		t.Error("tst targets should infer the TST input type")
+	}
+
+	response := &tst.Token{Status: &tst.Status{}, Info: &tst.TSTInfo{Policy: "9.9.9"}}
+	token := &tst.Token{Info: &tst.TSTInfo{Policy: "1.2.3.4.1"}}
+	other := &tst.Token{Info: &tst.TSTInfo{Policy: "9.9.9"}}
+	if !AppliesToTST(p, response) || !AppliesToTST(p, token) || AppliesToTST(p, other) {
+		t.Error("tstType should select responses and tokens under TSA policy 1.2.3.4.1")
+	}
+	if got := ByTST([]Policy{p}, other); len(got) != 0 {
+		t.Errorf("ByTST returned %d policies, want 0", len(got))
+	}
+}
+
+func TestParse_SCTType(t *testing.T) {
+	p, err := Parse([]byte(`
+id: test-policy
+sctType: [ocsp]
+rules:
+  - id: r1
+    target: sct.count
+    operator: gte
+    operands: [2]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !AppliesToInput(p, InputSCT) || AppliesToInput(p, InputCert) {
+		t.Error("sct targets should infer the SCT input type")
+	}
+
+	stapled := &sct.List{Delivery: sct.DeliveryOCSP}
+	extension := &sct.List{Delivery: sct.DeliveryTLS}
+	if !AppliesToSCT(p, stapled) || AppliesToSCT(p, extension) {
+		t.Error("sctType should select SCT lists stapled to OCSP responses")
+	}
+	if got := BySCT([]Policy{p}, extension); len(got) != 0 {
+		t.Errorf("BySCT returned %d policies, want 0", len(got))
+	}
+}
+
+func TestAppliesToCertificate_Precertificate(t *testing.T) {
+	p, err := Parse([]byte(`
+id: test-policy
+certType: [precertificate, precertSigning]
+rules:
+  - id: r1
+    target: certificate.version
+    operator: eq
+    operands: [3]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	signing := &x509.Certificate{UnknownExtKeyUsage: []asn1.ObjectIdentifier{{1, 3, 6, 1, 4, 1, 11129, 2, 4, 4}}}
+	if !AppliesToCertificate(p, &x509.Certificate{IsPrecert: true}) || !AppliesToCertificate(p, signing) {
+		t.Error("certType should select precertificates and precertificate signing certificates")
+	}
+	if AppliesToCertificate(p, &x509.Certificate{}) {
+		t.Error("certType should not select a final certificate")
+	}
+}
@@ -0,0 +1,215 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/cavoq/PCL/internal/operator"
+	"github.com/cavoq/PCL/internal/rule"
+)
+
+const (
+	IssueError   = "error"
+	IssueWarning = "warning"
+)
+
+// Issue is a problem found by Validator in a policy file.
+type Issue struct {
+	Severity string `json:"severity" yaml:"severity"`
+	File     string `json:"file" yaml:"file"`
+	Rule     string `json:"rule,omitempty" yaml:"rule,omitempty"`
+	Message  string `json:"message" yaml:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Rule == "" {
+		return fmt.Sprintf("%s: %s: %s", i.File, i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: rule %s: %s", i.File, i.Severity, i.Rule, i.Message)
+}
+
+// Validator checks policy files for mistakes that parsing accepts but that
+// would only surface during evaluation: unknown operators, malformed
+// operands, targets no tree contains and rule IDs repeated across includes.
+type Validator struct {
+	Registry *operator.Registry
+	// Fields maps each tree root to the top-level fields it may contain.
+	// Targets are not checked when Fields is nil.
+	Fields map[string][]string
+}
+
+// ValidatePath validates a policy file, or every policy file in a directory.
+func (v Validator) ValidatePath(path string) ([]Issue, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return v.ValidateFile(path), nil
+	}
+
+	files, err := policyFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	var issues []Issue
+	for _, f := range files {
+		issues = append(issues, v.ValidateFile(f)...)
+	}
+	return issues, nil
+}
+
+// ValidateFile validates a policy file together with its includes. Parse
+// errors are reported as a single error issue.
+func (v Validator) ValidateFile(path string) []Issue {
+	type parsedFile struct {
+		path  string
+		rules []rule.Rule
+	}
+	var files []parsedFile
+	_, err := parseFileWithIncludes(path, map[string]bool{}, func(p string, pol Policy) {
+		files = append(files, parsedFile{path: displayPath(p), rules: pol.Rules})
+	})
+	if err != nil {
+		return []Issue{{Severity: IssueError, File: path, Message: err.Error()}}
+	}
+
+	var issues []Issue
+	origins := map[string][]string{}
+	var ids []string
+	for _, f := range files {
+		for _, r := range f.rules {
+			if _, ok := origins[r.ID]; !ok {
+				ids = append(ids, r.ID)
+			}
+			origins[r.ID] = append(origins[r.ID], f.path)
+			issues = append(issues, v.checkRule(f.path, r)...)
+		}
+	}
+
+	for _, id := range ids {
+		if len(origins[id]) > 1 {
+			issues = append(issues, Issue{
+				Severity: IssueError,
+				File:     files[0].path,
+				Rule:     id,
+				Message:  "duplicate rule id, defined in " + strings.Join(origins[id], ", "),
+			})
+		}
+	}
+	return issues
+}
+
+func (v Validator) checkRule(file string, r rule.Rule) []Issue {
+	var issues []Issue
+	report := func(severity, msg string) {
+		issues = append(issues, Issue{Severity: severity, File: file, Rule: r.ID, Message: msg})
+	}
+
+	v.checkCondition(r.Body(), "", report)
+	if r.When != nil {
+		v.checkCondition(*r.When, "when.", report)
+	}
+	if r.EffectiveField != "" {
+		if msg := v.checkTarget(r.EffectiveField); msg != "" {
+			report(IssueWarning, "effectiveField: "+msg)
+		}
+	}
+	return issues
+}
+
+func (v Validator) checkCondition(c rule.Condition, path string, report func(severity, msg string)) {
+	for i, sub := range c.AllOf {
+		v.checkCondition(sub, fmt.Sprintf("%sallOf[%d].", path, i), report)
+	}
+	for i, sub := range c.AnyOf {
+		v.checkCondition(sub, fmt.Sprintf("%sanyOf[%d].", path, i), report)
+	}
+	if c.Not != nil {
+		v.checkCondition(*c.Not, path+"not.", report)
+	}
+	if c.IsComposite() {
+		return
+	}
+
+	if v.Registry != nil {
+		if _, err := v.Registry.Get(c.Operator); err != nil {
+			msg := fmt.Sprintf("%soperator: unknown operator %q", path, c.Operator)
+			if s := suggestOperator(v.Registry, c.Operator); s != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", s)
+			}
+			report(IssueError, msg)
+		} else if err := operator.ValidateOperands(v.Registry, c.Operator, rule.NormalizeOperands(c.Operands)); err != nil {
+			report(IssueError, fmt.Sprintf("%soperands: %s: %v", path, c.Operator, err))
+		}
+	}
+
+	if msg := v.checkTarget(c.Target); msg != "" {
+		report(IssueWarning, fmt.Sprintf("%starget: %s", path, msg))
+	}
+}
+
+// checkTarget reports targets whose root or first field no tree builder
+// produces. Deeper segments depend on the input and are not checked.
+func (v Validator) checkTarget(target string) string {
+	if v.Fields == nil {
+		return ""
+	}
+	parts := strings.SplitN(target, ".", 3)
+	fields, ok := v.Fields[parts[0]]
+	if !ok {
+		return fmt.Sprintf("unknown root %q in %s", parts[0], target)
+	}
+	if len(parts) > 1 && parts[1] != "*" && !slices.Contains(fields, parts[1]) {
+		return fmt.Sprintf("%s has no field %q (in %s)", parts[0], parts[1], target)
+	}
+	return ""
+}
+
+// suggestOperator returns the registered operator closest to name, if it is
+// within a small edit distance.
+func suggestOperator(reg *operator.Registry, name string) string {
+	best, bestDist := "", 3
+	for _, candidate := range reg.Names() {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(candidate)); d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// displayPath shortens absolute paths below the working directory.
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,144 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cavoq/PCL/internal/operator"
+)
+
+func writePolicyFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("writing %s: %v", name, err)
+	}
+	return path
+}
+
+func testValidator() Validator {
+	return Validator{
+		Registry: operator.DefaultRegistry(),
+		Fields:   map[string][]string{"certificate": {"version", "extensions", "subjectAltName"}},
+	}
+}
+
+func findIssue(issues []Issue, ruleID, fragment string) *Issue {
+	for i := range issues {
+		if issues[i].Rule == ruleID && strings.Contains(issues[i].Message, fragment) {
+			return &issues[i]
+		}
+	}
+	return nil
+}
+
+func TestValidateFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writePolicyFile(t, dir, "p.yaml", `
+id: p
+rules:
+  - id: ok
+    target: certificate.version
+    operator: eq
+    operands: [3]
+  - id: typo-operator
+    target: certificate.version
+    operator: eqq
+    operands: [3]
+  - id: bad-operand
+    target: certificate.version
+    operator: gte
+    operands: ["three"]
+  - id: typo-target
+    target: certificate.extenions.basicConstraints
+    operator: present
+  - id: nested
+    anyOf:
+      - target: certificate.subjectAltName.dNSName
+        operator: regex
+        operands: ["[a-"]
+      - target: certificate.version
+        operator: present
+        operands: [1]
+`)
+
+	issues := testValidator().ValidateFile(path)
+
+	if findIssue(issues, "ok", "") != nil {
+		t.Errorf("valid rule reported: %v", issues)
+	}
+	if is := findIssue(issues, "typo-operator", `unknown operator "eqq" (did you mean "eq"?)`); is == nil || is.Severity != IssueError {
+		t.Errorf("expected unknown operator error, got %v", issues)
+	}
+	if is := findIssue(issues, "bad-operand", "expected a number"); is == nil || is.Severity != IssueError {
+		t.Errorf("expected operand error, got %v", issues)
+	}
+	if is := findIssue(issues, "typo-target", `no field "extenions"`); is == nil || is.Severity != IssueWarning {
+		t.Errorf("expected unknown target warning, got %v", issues)
+	}
+	if findIssue(issues, "nested", "anyOf[0].operands: regex") == nil {
+		t.Errorf("expected invalid regex in anyOf[0], got %v", issues)
+	}
+	if findIssue(issues, "nested", "anyOf[1].operands: present: takes no operands") == nil {
+		t.Errorf("expected unexpected operands in anyOf[1], got %v", issues)
+	}
+}
+
+func TestValidateFileDuplicateIDsAcrossIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writePolicyFile(t, dir, "base.yaml", `
+id: base
+rules:
+  - id: version
+    target: certificate.version
+    operator: eq
+    operands: [3]
+`)
+	path := writePolicyFile(t, dir, "child.yaml", `
+id: child
+includes: [base.yaml]
+rules:
+  - id: version
+    target: certificate.version
+    operator: present
+`)
+
+	issues := testValidator().ValidateFile(path)
+	is := findIssue(issues, "version", "duplicate rule id")
+	if is == nil {
+		t.Fatalf("expected duplicate rule id, got %v", issues)
+	}
+	if !strings.Contains(is.Message, "base.yaml") || !strings.Contains(is.Message, "child.yaml") {
+		t.Errorf("duplicate should name both files, got %q", is.Message)
+	}
+}
+
+func TestValidateFileParseError(t *testing.T) {
+	dir := t.TempDir()
+	path := writePolicyFile(t, dir, "bad.yaml", "rules: []\n")
+
+	issues := testValidator().ValidateFile(path)
+	if len(issues) != 1 || issues[0].Severity != IssueError || !strings.Contains(issues[0].Message, "policy id is required") {
+		t.Errorf("expected single parse error, got %v", issues)
+	}
+}
+
+func TestValidatePathDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writePolicyFile(t, dir, "a.yaml", "id: a\nrules:\n  - id: r\n    target: certificate.version\n    operator: eqq\n")
+	writePolicyFile(t, dir, "b.yaml", "id: b\nrules:\n  - id: r\n    target: crl.thisUpdate\n    operator: present\n")
+	writePolicyFile(t, dir, "notes.txt", "not a policy")
+
+	issues, err := testValidator().ValidatePath(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %v", issues)
+	}
+	if !strings.Contains(issues[1].Message, `unknown root "crl"`) {
+		t.Errorf("expected unknown root warning, got %q", issues[1].Message)
+	}
+}
@@ -0,0 +1,179 @@
+package rule
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/operator"
+)
+
+const (
+	CombinatorAllOf = "allOf"
+	CombinatorAnyOf = "anyOf"
+	CombinatorNot   = "not"
+)
+
+// Check is the outcome of one condition within a composite rule. Leaf checks
+// carry the target and operator, combinators carry their nested checks.
+type Check struct {
+	Combinator string  `json:"combinator,omitempty" yaml:"combinator,omitempty"`
+	Target     string  `json:"target,omitempty" yaml:"target,omitempty"`
+	Operator   string  `json:"operator,omitempty" yaml:"operator,omitempty"`
+	Verdict    string  `json:"verdict" yaml:"verdict"`
+	Message    string  `json:"message,omitempty" yaml:"message,omitempty"`
+	Checks     []Check `json:"checks,omitempty" yaml:"checks,omitempty"`
+
+	Explanation *operator.Explanation `json:"explanation,omitempty" yaml:"explanation,omitempty"`
+}
+
+// evaluateCheck evaluates a condition tree. Every nested condition is
+// evaluated so that each one can be reported, even when the outcome is
+// already decided. The returned error is the first operator error found.
+func evaluateCheck(
+	root *node.Node,
+	c *Condition,
+	reg *operator.Registry,
+	ctx *operator.EvaluationContext,
+) (Check, error) {
+	switch {
+	case len(c.AllOf) > 0:
+		return evaluateGroup(root, CombinatorAllOf, c.AllOf, reg, ctx)
+	case len(c.AnyOf) > 0:
+		return evaluateGroup(root, CombinatorAnyOf, c.AnyOf, reg, ctx)
+	case c.Not != nil:
+		inner, err := evaluateCheck(root, c.Not, reg, ctx)
+		check := Check{Combinator: CombinatorNot, Checks: []Check{inner}, Verdict: VerdictPass}
+		if err != nil {
+			check.Verdict = VerdictFail
+			check.Message = err.Error()
+			return check, err
+		}
+		switch inner.Verdict {
+		case VerdictPass:
+			check.Verdict = VerdictFail
+			check.Message = "not: nested check passed"
+		case VerdictSkip:
+			check.Verdict = VerdictSkip
+			check.Message = "not: nested check skipped"
+		}
+		return check, nil
+	default:
+		return evaluateLeaf(root, c, reg, ctx)
+	}
+}
+
+// evaluateGroup combines the checks of allOf or anyOf. Skipped checks,
+// whose target is missing or whose input the operator does not support,
+// count for neither outcome, and a group of only skipped checks is skipped
+// itself. Any other operator error fails allOf, but only fails anyOf when
+// no other check passes.
+func evaluateGroup(
+	root *node.Node,
+	combinator string,
+	conds []Condition,
+	reg *operator.Registry,
+	ctx *operator.EvaluationContext,
+) (Check, error) {
+	check := Check{Combinator: combinator, Checks: make([]Check, 0, len(conds))}
+	var firstErr error
+	passed, skipped := 0, 0
+	for i := range conds {
+		sub, err := evaluateCheck(root, &conds[i], reg, ctx)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		switch sub.Verdict {
+		case VerdictPass:
+			passed++
+		case VerdictSkip:
+			skipped++
+		}
+		check.Checks = append(check.Checks, sub)
+	}
+
+	if combinator == CombinatorAnyOf && passed > 0 {
+		check.Verdict = VerdictPass
+		return check, nil
+	}
+	if firstErr == nil && skipped == len(conds) {
+		check.Verdict = VerdictSkip
+		check.Message = fmt.Sprintf("%s: all %d checks skipped", combinator, len(conds))
+		return check, nil
+	}
+
+	check.Verdict = VerdictPass
+	if combinator == CombinatorAnyOf || passed+skipped < len(conds) || firstErr != nil {
+		check.Verdict = VerdictFail
+		check.Message = fmt.Sprintf("%s: %d of %d checks passed", combinator, passed, len(conds))
+	}
+	return check, firstErr
+}
+
+func evaluateLeaf(
+	root *node.Node,
+	c *Condition,
+	reg *operator.Registry,
+	ctx *operator.EvaluationContext,
+) (Check, error) {
+	check := Check{Target: c.Target, Operator: c.Operator, Verdict: VerdictFail}
+
+	op, err := reg.Get(c.Operator)
+	if err != nil {
+		err = fmt.Errorf("operator not found: %s", c.Operator)
+		check.Message = err.Error()
+		return check, err
+	}
+
+	n, found := root.Resolve(c.Target)
+	if !found {
+		if !evaluatesMissingTarget(c.Target, c.Operator) {
+			check.Verdict = VerdictSkip
+			check.Message = "target not found: " + c.Target
+			return check, nil
+		}
+		n = nil
+	}
+
+	ok, err := op.Evaluate(n, ctx, NormalizeOperands(c.Operands))
+	if err != nil {
+		err = fmt.Errorf("operator %s on %s: %w", c.Operator, c.Target, err)
+		check.Message = err.Error()
+		// Like a simple rule, an unsupported input is skipped, not failed
+		if errors.Is(err, operator.ErrUnsupported) {
+			check.Verdict = VerdictSkip
+			return check, nil
+		}
+		return check, err
+	}
+	if ok {
+		check.Verdict = VerdictPass
+		return check, nil
+	}
+	explanation := operator.Explain(op, c.Target, n, ctx, NormalizeOperands(c.Operands))
+	check.Explanation = &explanation
+	if found {
+		check.Message = explanation.String()
+	} else {
+		check.Message = "target not found: " + c.Target
+	}
+	return check, nil
+}
+
+func evaluateComposite(
+	root *node.Node,
+	r Rule,
+	reg *operator.Registry,
+	ctx *operator.EvaluationContext,
+) Result {
+	body := r.Body()
+	check, _ := evaluateCheck(root, &body, reg, ctx)
+	return Result{
+		RuleID:    r.ID,
+		Reference: r.Reference,
+		Verdict:   check.Verdict,
+		Severity:  r.Severity,
+		Message:   check.Message,
+		Checks:    check.Checks,
+	}
+}
@@ -0,0 +1,234 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/operator"
+)
+
+func composeFixture() (*node.Node, *operator.Registry) {
+	root := node.New("certificate", nil)
+	san := node.New("subjectAltName", nil)
+	san.Children["dNSName"] = node.New("dNSName", "example.com")
+	root.Children["subjectAltName"] = san
+	root.Children["version"] = node.New("version", 3)
+	return root, operator.DefaultRegistry()
+}
+
+func TestCompositeAnyOfPasses(t *testing.T) {
+	root, reg := composeFixture()
+	r := Rule{
+		ID: "san-dns-or-ip",
+		AnyOf: []Condition{
+			{Target: "certificate.subjectAltName.dNSName", Operator: "present"},
+			{Target: "certificate.subjectAltName.iPAddress", Operator: "present"},
+		},
+		Severity: "error",
+	}
+
+	res := Evaluate(root, r, reg, nil)
+	if res.Verdict != VerdictPass {
+		t.Fatalf("expected pass, got %s (%s)", res.Verdict, res.Message)
+	}
+	if len(res.Checks) != 2 {
+		t.Fatalf("expected 2 checks, got %d", len(res.Checks))
+	}
+	if res.Checks[0].Verdict != VerdictPass || res.Checks[1].Verdict != VerdictFail {
+		t.Errorf("check verdicts = %s/%s, want pass/fail", res.Checks[0].Verdict, res.Checks[1].Verdict)
+	}
+}
+
+func TestCompositeAllOfReportsFailingCheck(t *testing.T) {
+	root, reg := composeFixture()
+	r := Rule{
+		ID: "all",
+		AllOf: []Condition{
+			{Target: "certificate.version", Operator: "eq", Operands: []any{3}},
+			{Target: "certificate.subjectAltName.dNSName", Operator: "regex", Operands: []any{"^https://"}},
+		},
+	}
+
+	res := Evaluate(root, r, reg, nil)
+	if res.Verdict != VerdictFail {
+		t.Fatalf("expected fail, got %s", res.Verdict)
+	}
+	if res.Message != "allOf: 1 of 2 checks passed" {
+		t.Errorf("message = %q", res.Message)
+	}
+	if res.Checks[1].Verdict != VerdictFail || res.Checks[1].Message == "" {
+		t.Errorf("failing check should be explained, got %s %q", res.Checks[1].Verdict, res.Checks[1].Message)
+	}
+}
+
+func TestCompositeMissingTargetSkips(t *testing.T) {
+	root, reg := composeFixture()
+	uri := Condition{Target: "certificate.subjectAltName.uniformResourceIdentifier", Operator: "regex", Operands: []any{"^https://"}}
+
+	res := Evaluate(root, Rule{ID: "all", AllOf: []Condition{
+		{Target: "certificate.version", Operator: "eq", Operands: []any{3}},
+		uri,
+	}}, reg, nil)
+	if res.Verdict != VerdictPass {
+		t.Fatalf("allOf with a missing target: expected pass, got %s (%s)", res.Verdict, res.Message)
+	}
+	if res.Checks[1].Verdict != VerdictSkip || !strings.HasPrefix(res.Checks[1].Message, "target not found") {
+		t.Errorf("missing target should be skipped and explained, got %s %q", res.Checks[1].Verdict, res.Checks[1].Message)
+	}
+
+	res = Evaluate(root, Rule{ID: "any", AnyOf: []Condition{uri}}, reg, nil)
+	if res.Verdict != VerdictSkip {
+		t.Errorf("anyOf of only missing targets: expected skip, got %s", res.Verdict)
+	}
+
+	res = Evaluate(root, Rule{ID: "not", Not: &uri}, reg, nil)
+	if res.Verdict != VerdictSkip {
+		t.Errorf("not of a missing target: expected skip, got %s", res.Verdict)
+	}
+}
+
+func TestCompositeAnyOfPassesDespiteError(t *testing.T) {
+	root, reg := composeFixture()
+	r := Rule{
+		ID: "any",
+		AnyOf: []Condition{
+			{Target: "certificate.subjectAltName.dNSName", Operator: "gte", Operands: []any{1}},
+			{Target: "certificate.version", Operator: "eq", Operands: []any{3}},
+		},
+	}
+
+	res := Evaluate(root, r, reg, nil)
+	if res.Verdict != VerdictPass {
+		t.Fatalf("expected pass, got %s (%s)", res.Verdict, res.Message)
+	}
+	if res.Checks[0].Verdict != VerdictFail || res.Checks[0].Message == "" {
+		t.Errorf("erroring check should still be reported, got %s %q", res.Checks[0].Verdict, res.Checks[0].Message)
+	}
+
+	r.AnyOf = r.AnyOf[:1]
+	if res := Evaluate(root, r, reg, nil); res.Verdict != VerdictFail {
+		t.Errorf("anyOf with only an erroring check: expected fail, got %s", res.Verdict)
+	}
+}
+
+func TestCompositeUnsupportedSkips(t *testing.T) {
+	root, reg := composeFixture()
+	reg.Register(unsupportedOp{})
+	unsupported := Condition{Target: "certificate.version", Operator: "unsupported"}
+
+	res := Evaluate(root, Rule{ID: "all", AllOf: []Condition{
+		{Target: "certificate.version", Operator: "eq", Operands: []any{3}},
+		unsupported,
+	}}, reg, nil)
+	if res.Verdict != VerdictPass {
+		t.Fatalf("allOf with an unsupported check: expected pass, got %s (%s)", res.Verdict, res.Message)
+	}
+	if res.Checks[1].Verdict != VerdictSkip || !strings.Contains(res.Checks[1].Message, "unsupported") {
+		t.Errorf("unsupported check should be skipped and explained, got %s %q", res.Checks[1].Verdict, res.Checks[1].Message)
+	}
+
+	if res := Evaluate(root, Rule{ID: "any", AnyOf: []Condition{unsupported}}, reg, nil); res.Verdict != VerdictSkip {
+		t.Errorf("anyOf of only unsupported checks: expected skip, got %s", res.Verdict)
+	}
+	if res := Evaluate(root, Rule{ID: "not", Not: &unsupported}, reg, nil); res.Verdict != VerdictSkip {
+		t.Errorf("not of an unsupported check: expected skip, got %s", res.Verdict)
+	}
+}
+
+func TestCompositeNot(t *testing.T) {
+	root, reg := composeFixture()
+	r := Rule{
+		ID:  "no-ip",
+		Not: &Condition{Target: "certificate.subjectAltName.iPAddress", Operator: "present"},
+	}
+	if res := Evaluate(root, r, reg, nil); res.Verdict != VerdictPass {
+		t.Errorf("expected pass, got %s", res.Verdict)
+	}
+
+	r.Not = &Condition{Target: "certificate.subjectAltName.dNSName", Operator: "present"}
+	res := Evaluate(root, r, reg, nil)
+	if res.Verdict != VerdictFail {
+		t.Errorf("expected fail, got %s", res.Verdict)
+	}
+	if res.Checks[0].Combinator != "" || res.Checks[0].Verdict != VerdictPass {
+		t.Errorf("nested check = %+v", res.Checks[0])
+	}
+}
+
+func TestCompositeNested(t *testing.T) {
+	root, reg := composeFixture()
+	r := Rule{
+		ID: "nested",
+		AllOf: []Condition{
+			{Target: "certificate.version", Operator: "eq", Operands: []any{3}},
+			{AnyOf: []Condition{
+				{Target: "certificate.subjectAltName.iPAddress", Operator: "present"},
+				{Not: &Condition{Target: "certificate.subjectAltName.dNSName", Operator: "absent"}},
+			}},
+		},
+	}
+
+	res := Evaluate(root, r, reg, nil)
+	if res.Verdict != VerdictPass {
+		t.Fatalf("expected pass, got %s (%s)", res.Verdict, res.Message)
+	}
+	if res.Checks[1].Combinator != CombinatorAnyOf || len(res.Checks[1].Checks) != 2 {
+		t.Errorf("nested anyOf not reported: %+v", res.Checks[1])
+	}
+}
+
+func TestCompositeOperatorNotFound(t *testing.T) {
+	root, reg := composeFixture()
+	r := Rule{
+		ID:    "bad-op",
+		AnyOf: []Condition{{Target: "certificate.version", Operator: "eqq", Operands: []any{3}}},
+	}
+
+	res := Evaluate(root, r, reg, nil)
+	if res.Verdict != VerdictFail {
+		t.Fatalf("expected fail, got %s", res.Verdict)
+	}
+	if res.Checks[0].Message != "operator not found: eqq" {
+		t.Errorf("check message = %q", res.Checks[0].Message)
+	}
+}
+
+func TestCompositeWhen(t *testing.T) {
+	root, reg := composeFixture()
+	r := Rule{
+		ID:       "when-anyof",
+		Target:   "certificate.version",
+		Operator: "eq",
+		Operands: []any{3},
+		When: &Condition{AnyOf: []Condition{
+			{Target: "certificate.subjectAltName.iPAddress", Operator: "present"},
+			{Target: "certificate.subjectAltName.rfc822Name", Operator: "present"},
+		}},
+	}
+	if res := Evaluate(root, r, reg, nil); res.Verdict != VerdictSkip {
+		t.Errorf("expected skip when no anyOf branch matches, got %s", res.Verdict)
+	}
+
+	r.When = &Condition{AllOf: []Condition{
+		{Target: "certificate.subjectAltName.dNSName", Operator: "present"},
+		{Not: &Condition{Target: "certificate.subjectAltName.iPAddress", Operator: "present"}},
+	}}
+	if res := Evaluate(root, r, reg, nil); res.Verdict != VerdictPass {
+		t.Errorf("expected pass when allOf condition holds, got %s", res.Verdict)
+	}
+}
+
+func TestRuleTargets(t *testing.T) {
+	r := Rule{
+		AnyOf: []Condition{
+			{Target: "crl.thisUpdate", Operator: "present"},
+			{Not: &Condition{Target: "crl.nextUpdate", Operator: "absent"}},
+		},
+		When: &Condition{Target: "crl.version", Operator: "present"},
+	}
+	got := strings.Join(r.Targets(), ",")
+	if got != "crl.thisUpdate,crl.nextUpdate,crl.version" {
+		t.Errorf("Targets() = %s", got)
+	}
+}
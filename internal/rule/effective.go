@@ -0,0 +1,130 @@
+package rule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cavoq/PCL/internal/node"
+)
+
+// defaultEffectiveFields are the dates compared against a rule's effective
+// window when it names no EffectiveField; the first present in the tree is
+// used.
+var defaultEffectiveFields = []string{
+	"certificate.validity.notBefore",
+	"crl.thisUpdate",
+	"ocsp.producedAt",
+	"tst.genTime",
+	"attrCert.validity.notBefore",
+}
+
+// HasEffectiveWindow reports whether the rule sets effectiveFrom or
+// effectiveUntil.
+func (r Rule) HasEffectiveWindow() bool {
+	return r.EffectiveFrom != "" || r.EffectiveUntil != ""
+}
+
+// ParseEffectiveDate parses an effectiveFrom or effectiveUntil value.
+func ParseEffectiveDate(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, want RFC 3339 or YYYY-MM-DD", s)
+}
+
+// ValidateEffectiveWindow checks that from and until parse and that from
+// precedes until.
+func ValidateEffectiveWindow(from, until string) error {
+	var start, end time.Time
+	var err error
+	if from != "" {
+		if start, err = ParseEffectiveDate(from); err != nil {
+			return fmt.Errorf("effectiveFrom: %w", err)
+		}
+	}
+	if until != "" {
+		if end, err = ParseEffectiveDate(until); err != nil {
+			return fmt.Errorf("effectiveUntil: %w", err)
+		}
+	}
+	if from != "" && until != "" && !start.Before(end) {
+		return fmt.Errorf("effectiveFrom %s must be before effectiveUntil %s", from, until)
+	}
+	return nil
+}
+
+// IntersectEffectiveWindows returns the window shared by [from1, until1)
+// and [from2, until2), taking the later start and the earlier end. An empty
+// bound is open. A bound that does not parse is kept from the first window
+// so that validation and evaluation report it.
+func IntersectEffectiveWindows(from1, until1, from2, until2 string) (from, until string) {
+	from = pickDate(from1, from2, func(a, b time.Time) bool { return b.After(a) })
+	until = pickDate(until1, until2, func(a, b time.Time) bool { return b.Before(a) })
+	return from, until
+}
+
+// pickDate returns b if it is set and preferred over a, otherwise a.
+func pickDate(a, b string, prefer func(a, b time.Time) bool) string {
+	if b == "" {
+		return a
+	}
+	if a == "" {
+		return b
+	}
+	ta, errA := ParseEffectiveDate(a)
+	tb, errB := ParseEffectiveDate(b)
+	if errA == nil && errB == nil && prefer(ta, tb) {
+		return b
+	}
+	return a
+}
+
+// outsideEffectiveWindow returns why the input falls outside the rule's
+// effective window, or "" when the rule applies.
+func outsideEffectiveWindow(root *node.Node, r Rule) (string, error) {
+	fields := defaultEffectiveFields
+	if r.EffectiveField != "" {
+		fields = []string{r.EffectiveField}
+	}
+
+	var field string
+	var n *node.Node
+	for _, f := range fields {
+		if found, ok := root.Resolve(f); ok {
+			field, n = f, found
+			break
+		}
+	}
+	if n == nil {
+		return "effective date not found: " + strings.Join(fields, ", "), nil
+	}
+	date, ok := n.Value.(time.Time)
+	if !ok {
+		return "", fmt.Errorf("%s is %T, not a date", field, n.Value)
+	}
+
+	if r.EffectiveFrom != "" {
+		from, err := ParseEffectiveDate(r.EffectiveFrom)
+		if err != nil {
+			return "", err
+		}
+		if date.Before(from) {
+			return fmt.Sprintf("not yet effective: %s %s is before effectiveFrom %s",
+				field, date.UTC().Format(time.RFC3339), r.EffectiveFrom), nil
+		}
+	}
+	if r.EffectiveUntil != "" {
+		until, err := ParseEffectiveDate(r.EffectiveUntil)
+		if err != nil {
+			return "", err
+		}
+		if !date.Before(until) {
+			return fmt.Sprintf("no longer effective: %s %s is not before effectiveUntil %s",
+				field, date.UTC().Format(time.RFC3339), r.EffectiveUntil), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,154 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/operator"
+)
+
+func effectiveTree(notBefore time.Time) *node.Node {
+	root := node.New("root", nil)
+	cert := node.New("certificate", nil)
+	validity := node.New("validity", nil)
+	validity.Children["notBefore"] = node.New("notBefore", notBefore)
+	cert.Children["validity"] = validity
+	cert.Children["version"] = node.New("version", 3)
+	root.Children["certificate"] = cert
+	return root
+}
+
+func TestEffectiveWindow(t *testing.T) {
+	reg := operator.NewRegistry()
+	reg.Register(operator.Eq{})
+
+	notBefore := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		from, until string
+		want        string
+		message     string
+	}{
+		{name: "no window", want: VerdictPass},
+		{name: "from is inclusive", from: "2020-09-01", want: VerdictPass},
+		{name: "before from", from: "2020-09-02", want: VerdictSkip, message: "not yet effective"},
+		{name: "until is exclusive", until: "2020-09-01T00:00:00Z", want: VerdictSkip, message: "no longer effective"},
+		{name: "inside window", from: "2019-01-01", until: "2021-01-01", want: VerdictPass},
+		{name: "invalid date", from: "September 2020", want: VerdictFail, message: "effective date error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Rule{
+				ID:             "test",
+				Target:         "certificate.version",
+				Operator:       "eq",
+				Operands:       []any{3},
+				EffectiveFrom:  tt.from,
+				EffectiveUntil: tt.until,
+			}
+			res := Evaluate(effectiveTree(notBefore), r, reg, nil)
+			if res.Verdict != tt.want {
+				t.Fatalf("verdict = %s, want %s (%s)", res.Verdict, tt.want, res.Message)
+			}
+			if !strings.Contains(res.Message, tt.message) {
+				t.Errorf("message = %q, want it to contain %q", res.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestEffectiveWindowField(t *testing.T) {
+	reg := operator.NewRegistry()
+	reg.Register(operator.Eq{})
+
+	root := effectiveTree(time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC))
+	root.Children["certificate"].Children["issued"] = node.New("issued", time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	r := Rule{
+		ID:             "test",
+		Target:         "certificate.version",
+		Operator:       "eq",
+		Operands:       []any{3},
+		EffectiveFrom:  "2019-01-01",
+		EffectiveField: "certificate.issued",
+	}
+	if res := Evaluate(root, r, reg, nil); res.Verdict != VerdictSkip {
+		t.Errorf("verdict = %s, want skip on the configured field", res.Verdict)
+	}
+
+	r.EffectiveField = "certificate.version"
+	if res := Evaluate(root, r, reg, nil); res.Verdict != VerdictFail {
+		t.Errorf("verdict = %s, want fail when the field is not a date", res.Verdict)
+	}
+
+	r.EffectiveField = "certificate.missing"
+	res := Evaluate(root, r, reg, nil)
+	if res.Verdict != VerdictSkip || !strings.Contains(res.Message, "not found") {
+		t.Errorf("got %s %q, want skip when the field is missing", res.Verdict, res.Message)
+	}
+}
+
+func TestEffectiveWindowDefaultFields(t *testing.T) {
+	reg := operator.NewRegistry()
+	reg.Register(operator.Present{})
+
+	genTime := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+	tst := node.New("tst", nil)
+	tst.Children["genTime"] = node.New("genTime", genTime)
+	tst.Children["nonce"] = node.New("nonce", "1")
+
+	attrCert := node.New("attrCert", nil)
+	validity := node.New("validity", nil)
+	validity.Children["notBefore"] = node.New("notBefore", genTime)
+	attrCert.Children["validity"] = validity
+	attrCert.Children["holder"] = node.New("holder", nil)
+
+	tests := []struct {
+		root   *node.Node
+		target string
+	}{
+		{tst, "tst.nonce"},
+		{attrCert, "attrCert.holder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.root.Name, func(t *testing.T) {
+			r := Rule{ID: "test", Target: tt.target, Operator: "present", EffectiveFrom: "2020-01-01"}
+			if res := Evaluate(tt.root, r, reg, nil); res.Verdict != VerdictPass {
+				t.Errorf("verdict = %s, want pass inside the window (%s)", res.Verdict, res.Message)
+			}
+
+			r.EffectiveFrom = "2021-01-01"
+			if res := Evaluate(tt.root, r, reg, nil); res.Verdict != VerdictSkip || !strings.Contains(res.Message, "not yet effective") {
+				t.Errorf("got %s %q, want skip before the window", res.Verdict, res.Message)
+			}
+		})
+	}
+
+	res := Evaluate(node.New("csr", nil), Rule{ID: "test", Target: "csr", Operator: "present", EffectiveFrom: "2020-01-01"}, reg, nil)
+	if res.Verdict != VerdictSkip || !strings.Contains(res.Message, strings.Join(defaultEffectiveFields, ", ")) {
+		t.Errorf("got %s %q, want skip listing every default field", res.Verdict, res.Message)
+	}
+}
+
+func TestValidateEffectiveWindow(t *testing.T) {
+	tests := []struct {
+		from, until string
+		wantErr     bool
+	}{
+		{"", "", false},
+		{"2020-09-01", "", false},
+		{"", "2026-03-15T00:00:00Z", false},
+		{"2020-09-01", "2026-03-15", false},
+		{"2026-03-15", "2020-09-01", true},
+		{"2020-09-01", "2020-09-01", true},
+		{"01/09/2020", "", true},
+	}
+	for _, tt := range tests {
+		err := ValidateEffectiveWindow(tt.from, tt.until)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateEffectiveWindow(%q, %q) error = %v, wantErr %v", tt.from, tt.until, err, tt.wantErr)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package rule
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 
@@ -15,16 +16,19 @@ const (
 )
 
 type Result struct {
-	RuleID    string `json:"rule_id" yaml:"rule_id"`
-	Reference string `json:"reference,omitempty" yaml:"reference,omitempty"`
-	Verdict   string `json:"verdict" yaml:"verdict"`
-	Severity  string `json:"severity" yaml:"severity"`
-	Message   string `json:"message,omitempty" yaml:"message,omitempty"`
+	RuleID    string  `json:"rule_id" yaml:"rule_id"`
+	Reference string  `json:"reference,omitempty" yaml:"reference,omitempty"`
+	Verdict   string  `json:"verdict" yaml:"verdict"`
+	Severity  string  `json:"severity" yaml:"severity"`
+	Message   string  `json:"message,omitempty" yaml:"message,omitempty"`
+	Checks    []Check `json:"checks,omitempty" yaml:"checks,omitempty"`
+
+	Explanation *operator.Explanation `json:"explanation,omitempty" yaml:"explanation,omitempty"`
 }
 
-// normalizeOperands converts Operands (any type) to []any for operator evaluation.
+// NormalizeOperands converts Operands (any type) to []any for operator evaluation.
 // Handles: []any (direct use), map[string]any (wrap as single element), nil (empty).
-func normalizeOperands(operands any) []any {
+func NormalizeOperands(operands any) []any {
 	if operands == nil {
 		return nil
 	}
@@ -46,72 +50,62 @@ func Evaluate(
 ) Result {
 	if !certTypeMatches(r, ctx) {
 		return Result{
-			RuleID:   r.ID,
+			RuleID:    r.ID,
 			Reference: r.Reference,
-			Verdict:  VerdictSkip,
-			Severity: r.Severity,
+			Verdict:   VerdictSkip,
+			Severity:  r.Severity,
 		}
 	}
 
-	if r.When != nil {
-		conditionMet, err := evaluateCondition(root, r.When, reg, ctx)
+	if r.HasEffectiveWindow() {
+		reason, err := outsideEffectiveWindow(root, r)
 		if err != nil {
 			return Result{
 				RuleID:    r.ID,
 				Reference: r.Reference,
 				Verdict:   VerdictFail,
-				Message:   "when condition error: " + err.Error(),
+				Message:   "effective date error: " + err.Error(),
 				Severity:  r.Severity,
 			}
 		}
-		if !conditionMet {
+		if reason != "" {
 			return Result{
 				RuleID:    r.ID,
 				Reference: r.Reference,
 				Verdict:   VerdictSkip,
 				Severity:  r.Severity,
+				Message:   reason,
 			}
 		}
 	}
 
-	n, found := root.Resolve(r.Target)
-
-	// For presence/absence/null operators, continue evaluation even if target not found
-	if !found && r.Operator != "present" && r.Operator != "absent" && r.Operator != "isNull" {
-		// Special handling for eq/neq on keyUsage boolean fields
-		if (r.Operator == "eq" || r.Operator == "neq") && isKeyUsageBooleanField(r.Target) {
-			var targetNode *node.Node
-			op, err := reg.Get(r.Operator)
-			if err != nil {
-				return Result{
-					RuleID:    r.ID,
-					Reference: r.Reference,
-					Verdict:   VerdictFail,
-					Message:   fmt.Sprintf("operator not found: %s", r.Operator),
-					Severity:  r.Severity,
-				}
-			}
-			ok, err := op.Evaluate(targetNode, ctx, normalizeOperands(r.Operands))
-			if err != nil {
-				return Result{
-					RuleID:    r.ID,
-					Reference: r.Reference,
-					Verdict:   VerdictFail,
-					Message:   fmt.Sprintf("operator %s on %s: %v", r.Operator, r.Target, err),
-					Severity:  r.Severity,
-				}
-			}
-			verdict := VerdictPass
-			if !ok {
-				verdict = VerdictFail
+	if r.When != nil {
+		conditionMet, err := evaluateCondition(root, r.When, reg, ctx)
+		if err != nil {
+			return Result{
+				RuleID:    r.ID,
+				Reference: r.Reference,
+				Verdict:   VerdictFail,
+				Message:   "when condition error: " + err.Error(),
+				Severity:  r.Severity,
 			}
+		}
+		if !conditionMet {
 			return Result{
 				RuleID:    r.ID,
 				Reference: r.Reference,
-				Verdict:   verdict,
+				Verdict:   VerdictSkip,
 				Severity:  r.Severity,
 			}
 		}
+	}
+
+	if r.IsComposite() {
+		return evaluateComposite(root, r, reg, ctx)
+	}
+
+	n, found := root.Resolve(r.Target)
+	if !found && !evaluatesMissingTarget(r.Target, r.Operator) {
 		return Result{
 			RuleID:    r.ID,
 			Reference: r.Reference,
@@ -138,28 +132,46 @@ func Evaluate(
 		}
 	}
 
-	ok, err := op.Evaluate(targetNode, ctx, normalizeOperands(r.Operands))
+	ok, err := op.Evaluate(targetNode, ctx, NormalizeOperands(r.Operands))
 	if err != nil {
-		return Result{
-			RuleID:    r.ID,
-			Reference: r.Reference,
-			Verdict:   VerdictFail,
-			Message:   fmt.Sprintf("operator %s on %s: %v", r.Operator, r.Target, err),
-			Severity:  r.Severity,
-		}
+		return operatorErrorResult(r, err)
 	}
 
-	verdict := VerdictPass
-	if !ok {
-		verdict = VerdictFail
+	return verdictResult(r, ok, op, targetNode, ctx)
+}
+
+// operatorErrorResult fails the rule with the operator error, or skips it
+// when the operator could not check its input.
+func operatorErrorResult(r Rule, err error) Result {
+	res := Result{
+		RuleID:    r.ID,
+		Reference: r.Reference,
+		Verdict:   VerdictFail,
+		Message:   fmt.Sprintf("operator %s on %s: %v", r.Operator, r.Target, err),
+		Severity:  r.Severity,
+	}
+	if errors.Is(err, operator.ErrUnsupported) {
+		res.Verdict = VerdictSkip
 	}
+	return res
+}
 
-	return Result{
+// verdictResult builds the result of a completed operator check. Failed
+// checks carry an explanation of the observed and expected values.
+func verdictResult(r Rule, ok bool, op operator.Operator, n *node.Node, ctx *operator.EvaluationContext) Result {
+	res := Result{
 		RuleID:    r.ID,
 		Reference: r.Reference,
-		Verdict:   verdict,
+		Verdict:   VerdictPass,
 		Severity:  r.Severity,
 	}
+	if !ok {
+		explanation := operator.Explain(op, r.Target, n, ctx, NormalizeOperands(r.Operands))
+		res.Verdict = VerdictFail
+		res.Message = explanation.String()
+		res.Explanation = &explanation
+	}
+	return res
 }
 
 func evaluateCondition(
@@ -168,14 +180,11 @@ func evaluateCondition(
 	reg *operator.Registry,
 	ctx *operator.EvaluationContext,
 ) (bool, error) {
-	n, _ := root.Resolve(cond.Target)
-
-	op, err := reg.Get(cond.Operator)
+	check, err := evaluateCheck(root, cond, reg, ctx)
 	if err != nil {
-		return false, fmt.Errorf("operator not found: %s", cond.Operator)
+		return false, err
 	}
-
-	return op.Evaluate(n, ctx, normalizeOperands(cond.Operands))
+	return check.Verdict == VerdictPass, nil
 }
 
 func certTypeMatches(r Rule, ctx *operator.EvaluationContext) bool {
@@ -185,7 +194,25 @@ func certTypeMatches(r Rule, ctx *operator.EvaluationContext) bool {
 	if ctx == nil || ctx.Cert == nil {
 		return true
 	}
-	return slices.Contains(r.CertType, ctx.Cert.Type)
+	if slices.Contains(r.CertType, ctx.Cert.Type) {
+		return true
+	}
+	// Precertificates are leaves that rules may also single out
+	return ctx.Cert.Cert != nil && ctx.Cert.Cert.IsPrecert && slices.Contains(r.CertType, "precertificate")
+}
+
+// evaluatesMissingTarget reports whether a check still runs, with a nil
+// node, when its target is not found: presence and null checks, and eq/neq
+// on keyUsage bits, which are implicitly false when not present. Any other
+// check of a missing target is skipped.
+func evaluatesMissingTarget(target, operator string) bool {
+	switch operator {
+	case "present", "absent", "isNull":
+		return true
+	case "eq", "neq":
+		return isKeyUsageBooleanField(target)
+	}
+	return false
 }
 
 // isKeyUsageBooleanField checks if the target is a keyUsage boolean field.
@@ -205,5 +232,3 @@ func isKeyUsageBooleanField(target string) bool {
 	}
 	return slices.Contains(keyUsageFields, target)
 }
-
-
@@ -104,6 +104,31 @@ func TestRuleEvaluationOperatorError(t *testing.T) {
 	}
 }
 
+type unsupportedOp struct{}
+
+func (unsupportedOp) Name() string { return "unsupported" }
+
+func (unsupportedOp) Evaluate(_ *node.Node, _ *operator.EvaluationContext, _ []any) (bool, error) {
+	return false, fmt.Errorf("%w: no verifier", operator.ErrUnsupported)
+}
+
+func TestRuleEvaluationOperatorUnsupported(t *testing.T) {
+	root := node.New("root", nil)
+	root.Children["a"] = node.New("a", 42)
+
+	reg := operator.NewRegistry()
+	reg.Register(unsupportedOp{})
+
+	res := Evaluate(root, Rule{ID: "test", Target: "a", Operator: "unsupported"}, reg, nil)
+
+	if res.Verdict != VerdictSkip {
+		t.Fatalf("expected skip for an unsupported input, got %s", res.Verdict)
+	}
+	if res.Message != "operator unsupported on a: unsupported: no verifier" {
+		t.Fatalf("unexpected message: %q", res.Message)
+	}
+}
+
 func TestRuleEvaluationWithReference(t *testing.T) {
 	root := node.New("root", nil)
 	root.Children["a"] = node.New("a", 42)
@@ -261,3 +286,29 @@ func TestRuleEvaluationMissingTarget(t *testing.T) {
 		t.Errorf("expected fail for missing target with 'present' operator, got %s", res.Verdict)
 	}
 }
+
+func TestRuleEvaluationFailExplanation(t *testing.T) {
+	root := node.New("root", nil)
+	root.Children["a"] = node.New("a", 42)
+
+	reg := operator.NewRegistry()
+	reg.Register(operator.Gte{})
+	r := Rule{
+		ID:       "test",
+		Target:   "a",
+		Operator: "gte",
+		Operands: []any{100},
+	}
+
+	res := Evaluate(root, r, reg, nil)
+
+	if res.Verdict != VerdictFail {
+		t.Fatalf("expected rule to fail")
+	}
+	if res.Explanation == nil {
+		t.Fatal("expected failed rule to carry an explanation")
+	}
+	if res.Message != "a: got 42, expected >= 100" {
+		t.Errorf("message = %q", res.Message)
+	}
+}
@@ -1,19 +1,85 @@
 // Package rule provides rule types and verdict constants.
 package rule
 
+// Condition is a single target/operator check, or a boolean combination of
+// nested conditions via allOf, anyOf or not.
 type Condition struct {
-	Target   string `yaml:"target"`
-	Operator string `yaml:"operator"`
-	Operands any    `yaml:"operands"` // Can be []any or map[string]any
+	Target   string      `yaml:"target,omitempty"`
+	Operator string      `yaml:"operator,omitempty"`
+	Operands any         `yaml:"operands"` // Can be []any or map[string]any
+	AllOf    []Condition `yaml:"allOf,omitempty"`
+	AnyOf    []Condition `yaml:"anyOf,omitempty"`
+	Not      *Condition  `yaml:"not,omitempty"`
 }
 
 type Rule struct {
-	ID        string     `yaml:"id"`
-	Reference string     `yaml:"reference,omitempty"`
-	Target    string     `yaml:"target"`
-	Operator  string     `yaml:"operator"`
-	Operands  any        `yaml:"operands"` // Can be []any or map[string]any
-	Severity  string     `yaml:"severity"`
-	CertType  []string   `yaml:"certType,omitempty"`
-	When      *Condition `yaml:"when,omitempty"`
+	ID        string      `yaml:"id"`
+	Reference string      `yaml:"reference,omitempty"`
+	Target    string      `yaml:"target"`
+	Operator  string      `yaml:"operator"`
+	Operands  any         `yaml:"operands"` // Can be []any or map[string]any
+	AllOf     []Condition `yaml:"allOf,omitempty"`
+	AnyOf     []Condition `yaml:"anyOf,omitempty"`
+	Not       *Condition  `yaml:"not,omitempty"`
+	Severity  string      `yaml:"severity"`
+	CertType  []string    `yaml:"certType,omitempty"`
+	When      *Condition  `yaml:"when,omitempty"`
+
+	// EffectiveFrom and EffectiveUntil bound the dates, by default the
+	// certificate's notBefore, a rule applies to. From is inclusive, until
+	// exclusive; both accept RFC 3339 times or YYYY-MM-DD dates.
+	EffectiveFrom  string `yaml:"effectiveFrom,omitempty"`
+	EffectiveUntil string `yaml:"effectiveUntil,omitempty"`
+	// EffectiveField is the date target compared against the window.
+	EffectiveField string `yaml:"effectiveField,omitempty"`
+}
+
+// IsComposite reports whether the condition combines nested conditions.
+func (c Condition) IsComposite() bool {
+	return len(c.AllOf) > 0 || len(c.AnyOf) > 0 || c.Not != nil
+}
+
+// Targets returns every target referenced by the condition, depth first.
+func (c Condition) Targets() []string {
+	var targets []string
+	if c.Target != "" {
+		targets = append(targets, c.Target)
+	}
+	for _, sub := range c.AllOf {
+		targets = append(targets, sub.Targets()...)
+	}
+	for _, sub := range c.AnyOf {
+		targets = append(targets, sub.Targets()...)
+	}
+	if c.Not != nil {
+		targets = append(targets, c.Not.Targets()...)
+	}
+	return targets
+}
+
+// Body returns the rule's check as a condition.
+func (r Rule) Body() Condition {
+	return Condition{
+		Target:   r.Target,
+		Operator: r.Operator,
+		Operands: r.Operands,
+		AllOf:    r.AllOf,
+		AnyOf:    r.AnyOf,
+		Not:      r.Not,
+	}
+}
+
+// IsComposite reports whether the rule body uses allOf, anyOf or not.
+func (r Rule) IsComposite() bool {
+	return r.Body().IsComposite()
+}
+
+// Targets returns the targets of the rule body followed by those of its
+// when condition.
+func (r Rule) Targets() []string {
+	targets := r.Body().Targets()
+	if r.When != nil {
+		targets = append(targets, r.When.Targets()...)
+	}
+	return targets
 }
@@ -0,0 +1,117 @@
+// Package sct provides standalone Certificate Transparency SCT lists.
+package sct
+
+import (
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	zct "github.com/zmap/zcrypto/x509/ct"
+
+	"github.com/cavoq/PCL/internal/ct"
+	fileio "github.com/cavoq/PCL/internal/io"
+	"github.com/cavoq/PCL/internal/source"
+)
+
+var extensions = []string{".sct", ".bin", ".der"}
+
+// Delivery methods of an SCT list outside the certificate (RFC 6962 3.3).
+const (
+	// DeliveryTLS is the TLS-encoded SignedCertificateTimestampList of the
+	// signed_certificate_timestamp TLS extension.
+	DeliveryTLS = "tls"
+	// DeliveryOCSP is the DER OCTET STRING wrapping the list in the
+	// 1.3.6.1.4.1.11129.2.4.5 singleExtension of a stapled OCSP response.
+	DeliveryOCSP = "ocsp"
+)
+
+// List is an SCT list with the way it was delivered.
+type List struct {
+	Raw      []byte // TLS-encoded SignedCertificateTimestampList
+	SCTs     []*zct.SignedCertificateTimestamp
+	Delivery string
+}
+
+type Info struct {
+	List     *List
+	FilePath string
+	Hash     string
+	Source   source.Info
+	Format   source.Format
+}
+
+func ParseSCTList(data []byte) (*List, error) {
+	list, _, err := parseSCTList(data)
+	return list, err
+}
+
+func parseSCTList(data []byte) (*List, source.Format, error) {
+	var wrapped []byte
+	if rest, err := asn1.Unmarshal(data, &wrapped); err == nil && len(rest) == 0 {
+		scts, err := ct.ParseSCTList(wrapped)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse DER SCT list: %w", err)
+		}
+		return &List{Raw: wrapped, SCTs: scts, Delivery: DeliveryOCSP}, source.FormatDER, nil
+	}
+
+	scts, err := ct.ParseSCTList(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse TLS or DER SCT list: %w", err)
+	}
+	return &List{Raw: data, SCTs: scts, Delivery: DeliveryTLS}, source.FormatTLS, nil
+}
+
+func GetSCTFiles(path string) ([]string, error) {
+	return fileio.GetFilesWithExtensions(path, extensions...)
+}
+
+func GetSCTs(path string) ([]*Info, error) {
+	files, err := GetSCTFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*Info, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		info, err := NewInfo(data, file, source.Info{Type: source.Local})
+		if err != nil {
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	if len(infos) == 0 && len(files) > 0 {
+		return nil, fmt.Errorf("no valid items found in %s", path)
+	}
+
+	return infos, nil
+}
+
+// NewInfo parses an SCT list held in memory. The encoding tells how the
+// list was delivered: TLS-encoded from the TLS extension, or wrapped in a
+// DER OCTET STRING from the OCSP extension. name identifies the list in
+// lint results.
+func NewInfo(data []byte, name string, sourceInfo source.Info) (*Info, error) {
+	list, format, err := parseSCTList(data)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(list.Raw)
+	sourceInfo.Format = format
+	return &Info{
+		List:     list,
+		FilePath: name,
+		Hash:     hex.EncodeToString(hash[:]),
+		Source:   sourceInfo,
+		Format:   format,
+	}, nil
+}
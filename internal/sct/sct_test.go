@@ -0,0 +1,81 @@
+package sct
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cavoq/PCL/internal/loader"
+	"github.com/cavoq/PCL/internal/source"
+)
+
+const testdata = "../../tests/scts"
+
+func TestParseSCTList_TLS(t *testing.T) {
+	list, err := loader.Load(filepath.Join(testdata, "ct-tls-leaf.sct"), ParseSCTList)
+	if err != nil {
+		t.Fatalf("failed to load SCT list: %v", err)
+	}
+	if list.Delivery != DeliveryTLS {
+		t.Errorf("delivery = %q, want %q", list.Delivery, DeliveryTLS)
+	}
+	if len(list.SCTs) != 2 {
+		t.Errorf("expected 2 SCTs, got %d", len(list.SCTs))
+	}
+}
+
+func TestParseSCTList_OCSP(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(testdata, "ct-tls-leaf-ocsp.der"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := NewInfo(data, "ct-tls-leaf-ocsp.der", source.Info{Type: source.Local})
+	if err != nil {
+		t.Fatalf("NewInfo: %v", err)
+	}
+	if info.List.Delivery != DeliveryOCSP || len(info.List.SCTs) != 2 {
+		t.Errorf("delivery = %q with %d SCTs, want ocsp with 2", info.List.Delivery, len(info.List.SCTs))
+	}
+	if info.Format != source.FormatDER || info.Source.Format != source.FormatDER {
+		t.Errorf("format = %q, source format = %q, want DER", info.Format, info.Source.Format)
+	}
+	// Raw is the TLS-encoded list, whatever its wrapping
+	if unwrapped, err := ParseSCTList(info.List.Raw); err != nil || unwrapped.Delivery != DeliveryTLS {
+		t.Errorf("raw list does not parse as TLS-encoded: %v", err)
+	}
+}
+
+func TestParseSCTList_Invalid(t *testing.T) {
+	if _, err := ParseSCTList([]byte("not an SCT list")); err == nil {
+		t.Fatal("expected error for invalid SCT list data")
+	}
+	if _, err := ParseSCTList([]byte{0x04, 0x02, 0x00, 0x00}); err == nil {
+		t.Fatal("expected error for an empty wrapped SCT list")
+	}
+}
+
+func TestGetSCTs(t *testing.T) {
+	infos, err := GetSCTs(testdata)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 SCT lists, got %d", len(infos))
+	}
+	for _, info := range infos {
+		if info.Hash == "" || info.Source.Type != source.Local {
+			t.Errorf("%s: hash %q, source %q", info.FilePath, info.Hash, info.Source.Type)
+		}
+	}
+}
+
+func TestGetSCTs_NoValidItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.sct")
+	if err := os.WriteFile(path, []byte("not an SCT list"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetSCTs(path); err == nil {
+		t.Fatal("expected error when no file parses")
+	}
+}
@@ -0,0 +1,108 @@
+// Package zcrypto provides zcrypto-based SCT list tree building.
+package zcrypto
+
+import (
+	"github.com/zmap/zcrypto/x509"
+
+	certzcrypto "github.com/cavoq/PCL/internal/cert/zcrypto"
+	"github.com/cavoq/PCL/internal/ct"
+	"github.com/cavoq/PCL/internal/data"
+	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/sct"
+)
+
+type SCTBuilder struct{}
+
+func NewSCTBuilder() *SCTBuilder {
+	return &SCTBuilder{}
+}
+
+func (b *SCTBuilder) Build(list *sct.List) *node.Node {
+	return buildSCTList(list, nil, nil)
+}
+
+func BuildTree(list *sct.List) *node.Node {
+	return NewSCTBuilder().Build(list)
+}
+
+// BuildTreeWithCert builds the tree like BuildTree, looking the logs up in
+// logs and verifying every SCT over cert, the certificate the list was
+// delivered with. Either may be nil.
+func BuildTreeWithCert(list *sct.List, cert *x509.Certificate, logs *data.Loader) *node.Node {
+	return buildSCTList(list, cert, logs)
+}
+
+// Fields lists the top-level children an SCT list tree may contain.
+var Fields = []string{
+	"delivery",
+	"count",
+	"timestamps",
+	"logsKnown",
+	"validCount",
+	"operatorCount",
+}
+
+func buildSCTList(list *sct.List, cert *x509.Certificate, logs *data.Loader) *node.Node {
+	root := node.New("sct", nil)
+	root.Children["delivery"] = node.New("delivery", list.Delivery)
+	root.Children["count"] = node.New("count", len(list.SCTs))
+
+	if logs != nil && !logs.CTLogsLoaded() {
+		logs = nil
+	}
+
+	timestamps := node.New("timestamps", nil)
+	known := true
+	validLogs := make(map[[32]byte]bool)
+	operators := make(map[string]bool)
+	for i, s := range list.SCTs {
+		n := certzcrypto.BuildSCT(s, i)
+		timestamps.Children[n.Name] = n
+
+		if logs == nil {
+			continue
+		}
+		log := logs.CTLog(s.LogID)
+		n.Children["log"] = buildLog(log)
+		if log == nil {
+			known = false
+			continue
+		}
+
+		// SCTs delivered outside the certificate are over the final
+		// certificate itself (x509_entry)
+		if cert != nil {
+			ok := ct.Verify(s, log.Key, ct.X509SignedData(s, cert.Raw)) == nil
+			n.Children["signatureValid"] = node.New("signatureValid", ok)
+			if ok {
+				validLogs[s.LogID] = true
+				operators[log.Operator] = true
+			}
+		}
+	}
+	root.Children["timestamps"] = timestamps
+
+	if logs != nil {
+		root.Children["logsKnown"] = node.New("logsKnown", known)
+		if cert != nil {
+			root.Children["validCount"] = node.New("validCount", len(validLogs))
+			root.Children["operatorCount"] = node.New("operatorCount", len(operators))
+		}
+	}
+
+	return root
+}
+
+func buildLog(log *data.CTLog) *node.Node {
+	n := node.New("log", nil)
+	n.Children["known"] = node.New("known", log != nil)
+	if log == nil {
+		return n
+	}
+	n.Children["description"] = node.New("description", log.Description)
+	n.Children["operator"] = node.New("operator", log.Operator)
+	if log.State != "" {
+		n.Children["state"] = node.New("state", log.State)
+	}
+	return n
+}
@@ -0,0 +1,111 @@
+package zcrypto
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/data"
+	"github.com/cavoq/PCL/internal/node/nodetest"
+	"github.com/cavoq/PCL/internal/sct"
+)
+
+const (
+	testdata = "../../../tests/scts"
+	certs    = "../../../tests/certs"
+	logs     = "../../../tests/data"
+)
+
+func loadList(t *testing.T, name string) *sct.List {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join(testdata, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := sct.ParseSCTList(raw)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return list
+}
+
+func loadLeaf(t *testing.T) *x509.Certificate {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join(certs, "ct-tls-leaf.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(raw)
+	c, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func loadLogs(t *testing.T) *data.Loader {
+	t.Helper()
+	l := &data.Loader{}
+	if err := l.LoadCTLogs(filepath.Join(logs, "ct_log_list.json")); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestBuildTree(t *testing.T) {
+	root := BuildTree(loadList(t, "ct-tls-leaf.sct"))
+
+	nodetest.AssertPathValue(t, root, "sct.delivery", "tls")
+	nodetest.AssertPathValue(t, root, "sct.count", 2)
+	nodetest.AssertPathValue(t, root, "sct.timestamps.0.version", 0)
+	nodetest.AssertPathValue(t, root, "sct.timestamps.1.signatureAlgorithmString", "SHA256-ECDSA")
+	nodetest.AssertPathNotExists(t, root, "sct.timestamps.0.log")
+	nodetest.AssertPathNotExists(t, root, "sct.logsKnown")
+	nodetest.AssertPathNotExists(t, root, "sct.validCount")
+}
+
+func TestBuildTreeWithCert(t *testing.T) {
+	root := BuildTreeWithCert(loadList(t, "ct-tls-leaf.sct"), loadLeaf(t), loadLogs(t))
+
+	nodetest.AssertPathValue(t, root, "sct.logsKnown", true)
+	nodetest.AssertPathValue(t, root, "sct.validCount", 2)
+	nodetest.AssertPathValue(t, root, "sct.operatorCount", 2)
+	nodetest.AssertPathValue(t, root, "sct.timestamps.0.log.known", true)
+	nodetest.AssertPathValue(t, root, "sct.timestamps.0.log.description", "Test Log D")
+	nodetest.AssertPathValue(t, root, "sct.timestamps.0.log.state", "usable")
+	nodetest.AssertPathValue(t, root, "sct.timestamps.1.log.operator", "Test Operator Two")
+	nodetest.AssertPathValue(t, root, "sct.timestamps.1.signatureValid", true)
+}
+
+func TestBuildTreeWithCert_DuplicateLog(t *testing.T) {
+	list := loadList(t, "ct-tls-leaf.sct")
+	list.SCTs = append(list.SCTs, list.SCTs[0])
+	root := BuildTreeWithCert(list, loadLeaf(t), loadLogs(t))
+
+	nodetest.AssertPathValue(t, root, "sct.count", 3)
+	nodetest.AssertPathValue(t, root, "sct.validCount", 2)
+	nodetest.AssertPathValue(t, root, "sct.timestamps.2.signatureValid", true)
+}
+
+func TestBuildTreeWithCert_Stapled(t *testing.T) {
+	root := BuildTreeWithCert(loadList(t, "ct-tls-leaf-ocsp.der"), loadLeaf(t), loadLogs(t))
+
+	nodetest.AssertPathValue(t, root, "sct.delivery", "ocsp")
+	nodetest.AssertPathValue(t, root, "sct.logsKnown", false)
+	nodetest.AssertPathValue(t, root, "sct.validCount", 0)
+	nodetest.AssertPathValue(t, root, "sct.operatorCount", 0)
+	nodetest.AssertPathValue(t, root, "sct.timestamps.0.signatureValid", false)
+	nodetest.AssertPathValue(t, root, "sct.timestamps.1.log.known", false)
+	nodetest.AssertPathNotExists(t, root, "sct.timestamps.1.signatureValid")
+}
+
+func TestBuildTreeWithCert_NoCert(t *testing.T) {
+	root := BuildTreeWithCert(loadList(t, "ct-tls-leaf.sct"), nil, loadLogs(t))
+
+	nodetest.AssertPathValue(t, root, "sct.logsKnown", true)
+	nodetest.AssertPathNotExists(t, root, "sct.timestamps.0.signatureValid")
+	nodetest.AssertPathNotExists(t, root, "sct.validCount")
+}
@@ -7,6 +7,7 @@ const (
 	Local      Type = "local"
 	Downloaded Type = "downloaded"
 	Extracted  Type = "extracted"
+	Memory     Type = "memory"
 )
 
 type Format string
@@ -15,6 +16,7 @@ const (
 	FormatDER   Format = "DER"
 	FormatPEM   Format = "PEM"
 	FormatPKCS7 Format = "PKCS7"
+	FormatTLS   Format = "TLS"
 )
 
 type Info struct {
@@ -0,0 +1,40 @@
+package trust
+
+import (
+	"fmt"
+	"os"
+)
+
+// systemBundles are the locations of the CA bundle on common Linux
+// distributions, in the order they are tried.
+var systemBundles = []string{
+	"/etc/ssl/certs/ca-certificates.crt",                // Debian, Ubuntu, Gentoo, Arch
+	"/etc/pki/tls/certs/ca-bundle.crt",                  // Fedora, RHEL 6
+	"/etc/ssl/ca-bundle.pem",                            // openSUSE
+	"/etc/pki/tls/cacert.pem",                           // OpenELEC
+	"/etc/pki/ca-trust/extracted/pem/tls-ca-bundle.pem", // CentOS, RHEL 7
+	"/etc/ssl/cert.pem",                                 // Alpine
+}
+
+// AddSystemRoots loads the first system CA bundle found. SSL_CERT_FILE, if
+// set, takes precedence over the built-in locations.
+func (s *Store) AddSystemRoots() error {
+	bundles := systemBundles
+	if file := os.Getenv("SSL_CERT_FILE"); file != "" {
+		bundles = []string{file}
+	}
+
+	for _, file := range bundles {
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+		n, err := s.addFile(file, SourceSystemRoots)
+		if err != nil {
+			return fmt.Errorf("loading system roots from %s: %w", file, err)
+		}
+		if n > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("no system CA bundle found (tried %v)", bundles)
+}
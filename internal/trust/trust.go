@@ -0,0 +1,173 @@
+// Package trust holds the trust anchors certificate chains are validated
+// against.
+//
+// Without a store PCL treats the self-signed certificate at the top of a
+// chain as its anchor. With a store only certificates loaded into it anchor
+// a chain, so policies can tell a chain to a known root from a chain to an
+// arbitrary self-signed certificate.
+package trust
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/cert"
+	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/source"
+)
+
+// Anchor sources, reported as certificate.chain.anchoredIn.
+const (
+	SourceTrustAnchors = "trust-anchors"
+	SourceSystemRoots  = "system-roots"
+	SourceSelfSigned   = "self-signed"
+)
+
+// Anchor is a trust anchor certificate and the source it was loaded from.
+type Anchor struct {
+	Cert   *x509.Certificate
+	Source string
+}
+
+// Store is a set of trust anchors. A nil *Store is valid and falls back to
+// self-signed anchors.
+type Store struct {
+	anchors   []Anchor
+	bySubject map[string][]int
+}
+
+// NewStore returns an empty store.
+func NewStore() *Store {
+	return &Store{bySubject: make(map[string][]int)}
+}
+
+// Add adds c as an anchor from source. A certificate already in the store
+// keeps its first source.
+func (s *Store) Add(c *x509.Certificate, source string) {
+	if c == nil || s.contains(c) {
+		return
+	}
+	s.anchors = append(s.anchors, Anchor{Cert: c, Source: source})
+	key := c.Subject.String()
+	s.bySubject[key] = append(s.bySubject[key], len(s.anchors)-1)
+}
+
+// AddPath loads every certificate of the PEM bundles or DER files under
+// path, a file or a directory.
+func (s *Store) AddPath(path, source string) error {
+	files, err := cert.GetCertFiles(path)
+	if err != nil {
+		return err
+	}
+	loaded := 0
+	for _, file := range files {
+		n, err := s.addFile(file, source)
+		if err != nil {
+			continue
+		}
+		loaded += n
+	}
+	if loaded == 0 {
+		return fmt.Errorf("no trust anchors found in %s", path)
+	}
+	return nil
+}
+
+func (s *Store) addFile(file, sourceName string) (int, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	infos, err := cert.NewInfos(data, file, source.Info{Type: source.Local})
+	if err != nil {
+		return 0, err
+	}
+	for _, info := range infos {
+		s.Add(info.Cert, sourceName)
+	}
+	return len(infos), nil
+}
+
+// Len returns the number of anchors in the store.
+func (s *Store) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.anchors)
+}
+
+func (s *Store) contains(c *x509.Certificate) bool {
+	_, ok := s.lookup(c)
+	return ok
+}
+
+// lookup returns the anchor that is c itself.
+func (s *Store) lookup(c *x509.Certificate) (Anchor, bool) {
+	for _, i := range s.bySubject[c.Subject.String()] {
+		if bytes.Equal(s.anchors[i].Cert.Raw, c.Raw) {
+			return s.anchors[i], true
+		}
+	}
+	return Anchor{}, false
+}
+
+// issuerOf returns an anchor whose subject matches the issuer of c and
+// whose key verifies the signature of c.
+func (s *Store) issuerOf(c *x509.Certificate) (Anchor, bool) {
+	for _, i := range s.bySubject[c.Issuer.String()] {
+		if c.CheckSignatureFrom(s.anchors[i].Cert) == nil {
+			return s.anchors[i], true
+		}
+	}
+	return Anchor{}, false
+}
+
+// Resolve finds the trust anchor of path, ordered from the target up. The
+// first certificate of the path that is itself an anchor ends the path;
+// otherwise an anchor that issued the top certificate is used. The
+// returned path excludes the anchor. Without a store the top certificate
+// is the anchor if it is self-signed. The anchor's Cert is nil if none was
+// found.
+func (s *Store) Resolve(path []*x509.Certificate) ([]*x509.Certificate, Anchor) {
+	if len(path) == 0 {
+		return nil, Anchor{}
+	}
+
+	if s == nil {
+		top := path[len(path)-1]
+		if !cert.IsSelfSigned(top) {
+			return path, Anchor{}
+		}
+		return path[:len(path)-1], Anchor{Cert: top, Source: SourceSelfSigned}
+	}
+
+	for i, c := range path {
+		if a, ok := s.lookup(c); ok {
+			return path[:i], a
+		}
+	}
+	if a, ok := s.issuerOf(path[len(path)-1]); ok {
+		return path, a
+	}
+	return path, Anchor{}
+}
+
+// BuildTree renders the anchor of a chain as the chain node:
+//
+//	chain.anchored     a trust anchor was found
+//	chain.anchoredIn   anchor source: trust-anchors, system-roots or self-signed
+//	chain.anchor       anchor subject
+//	chain.length       certificates from this one up to, excluding, the anchor
+func BuildTree(path []*x509.Certificate, anchor Anchor) *node.Node {
+	n := node.New("chain", nil)
+	n.Children["anchored"] = node.New("anchored", anchor.Cert != nil)
+	if anchor.Cert != nil {
+		n.Children["anchoredIn"] = node.New("anchoredIn", anchor.Source)
+		n.Children["anchor"] = node.New("anchor", anchor.Cert.Subject.String())
+	}
+	n.Children["length"] = node.New("length", len(path))
+	return n
+}
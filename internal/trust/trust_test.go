@@ -0,0 +1,171 @@
+package trust
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/cert"
+)
+
+const certs = "../../tests/certs"
+
+func load(t *testing.T, name string) *x509.Certificate {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(certs, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := cert.ParseCertificate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestResolveWithoutStore(t *testing.T) {
+	leaf, inter, root := load(t, "leaf.pem"), load(t, "intermediate.pem"), load(t, "root.pem")
+
+	var s *Store
+	path, anchor := s.Resolve([]*x509.Certificate{leaf, inter, root})
+	if anchor.Cert != root || anchor.Source != SourceSelfSigned {
+		t.Errorf("anchor = %+v, want self-signed root", anchor)
+	}
+	if len(path) != 2 {
+		t.Errorf("path length = %d, want 2", len(path))
+	}
+
+	path, anchor = s.Resolve([]*x509.Certificate{leaf, inter})
+	if anchor.Cert != nil {
+		t.Errorf("chain without root should have no anchor, got %+v", anchor)
+	}
+	if len(path) != 2 {
+		t.Errorf("path length = %d, want 2", len(path))
+	}
+}
+
+func TestResolveWithStore(t *testing.T) {
+	leaf, inter, root := load(t, "leaf.pem"), load(t, "intermediate.pem"), load(t, "root.pem")
+
+	s := NewStore()
+	s.Add(root, SourceTrustAnchors)
+
+	t.Run("anchor in chain", func(t *testing.T) {
+		path, anchor := s.Resolve([]*x509.Certificate{leaf, inter, root})
+		if anchor.Cert == nil || anchor.Source != SourceTrustAnchors {
+			t.Fatalf("anchor = %+v, want trust-anchors root", anchor)
+		}
+		if len(path) != 2 {
+			t.Errorf("path length = %d, want 2", len(path))
+		}
+	})
+
+	t.Run("anchor issued top of chain", func(t *testing.T) {
+		path, anchor := s.Resolve([]*x509.Certificate{leaf, inter})
+		if anchor.Cert == nil || anchor.Cert.Subject.String() != root.Subject.String() {
+			t.Fatalf("anchor = %+v, want root from store", anchor)
+		}
+		if len(path) != 2 {
+			t.Errorf("path length = %d, want 2", len(path))
+		}
+	})
+
+	t.Run("anchor is the certificate itself", func(t *testing.T) {
+		path, anchor := s.Resolve([]*x509.Certificate{root})
+		if anchor.Cert == nil || len(path) != 0 {
+			t.Errorf("got path %d, anchor %+v; want empty path anchored at root", len(path), anchor)
+		}
+	})
+
+	t.Run("untrusted self-signed root", func(t *testing.T) {
+		other := NewStore()
+		other.Add(load(t, "nc-root.pem"), SourceTrustAnchors)
+		if _, anchor := other.Resolve([]*x509.Certificate{leaf, inter, root}); anchor.Cert != nil {
+			t.Errorf("self-signed root outside the store must not anchor, got %+v", anchor)
+		}
+	})
+}
+
+func TestAddKeepsFirstSource(t *testing.T) {
+	root := load(t, "root.pem")
+
+	s := NewStore()
+	s.Add(root, SourceTrustAnchors)
+	s.Add(root, SourceSystemRoots)
+	if s.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", s.Len())
+	}
+	if _, anchor := s.Resolve([]*x509.Certificate{root}); anchor.Source != SourceTrustAnchors {
+		t.Errorf("source = %q, want %q", anchor.Source, SourceTrustAnchors)
+	}
+}
+
+func TestAddPathBundle(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "bundle.pem")
+	var data []byte
+	for _, name := range []string{"root.pem", "nc-root.pem"} {
+		b, err := os.ReadFile(filepath.Join(certs, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, b...)
+	}
+	if err := os.WriteFile(bundle, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewStore()
+	if err := s.AddPath(bundle, SourceTrustAnchors); err != nil {
+		t.Fatalf("AddPath: %v", err)
+	}
+	if s.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", s.Len())
+	}
+
+	if err := NewStore().AddPath(t.TempDir(), SourceTrustAnchors); err == nil {
+		t.Error("expected error for directory without certificates")
+	}
+}
+
+func TestAddSystemRoots(t *testing.T) {
+	t.Setenv("SSL_CERT_FILE", filepath.Join(certs, "root.pem"))
+	s := NewStore()
+	if err := s.AddSystemRoots(); err != nil {
+		t.Fatalf("AddSystemRoots: %v", err)
+	}
+	if _, anchor := s.Resolve([]*x509.Certificate{load(t, "root.pem")}); anchor.Source != SourceSystemRoots {
+		t.Errorf("source = %q, want %q", anchor.Source, SourceSystemRoots)
+	}
+
+	t.Setenv("SSL_CERT_FILE", filepath.Join(t.TempDir(), "missing.pem"))
+	if err := NewStore().AddSystemRoots(); err == nil {
+		t.Error("expected error for missing bundle")
+	}
+}
+
+func TestBuildTree(t *testing.T) {
+	root := load(t, "root.pem")
+	n := BuildTree([]*x509.Certificate{load(t, "leaf.pem")}, Anchor{Cert: root, Source: SourceSystemRoots})
+
+	for path, want := range map[string]any{
+		"anchored":   true,
+		"anchoredIn": SourceSystemRoots,
+		"anchor":     root.Subject.String(),
+		"length":     1,
+	} {
+		got, ok := n.Resolve(path)
+		if !ok || got.Value != want {
+			t.Errorf("%s = %v, want %v", path, got, want)
+		}
+	}
+
+	n = BuildTree(nil, Anchor{})
+	if got, _ := n.Resolve("anchored"); got.Value != false {
+		t.Error("anchored should be false without anchor")
+	}
+	if _, ok := n.Resolve("anchoredIn"); ok {
+		t.Error("anchoredIn should be absent without anchor")
+	}
+}
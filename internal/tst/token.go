@@ -0,0 +1,764 @@
+package tst
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	zasn1 "github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
+	"github.com/zmap/zcrypto/x509/pkix"
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+
+	pclasn1 "github.com/cavoq/PCL/internal/asn1"
+)
+
+// Content types (RFC 5652, RFC 3161) and CMS signed attribute OIDs
+// (RFC 5652, RFC 2634, RFC 5035)
+const (
+	OIDSignedData = "1.2.840.113549.1.7.2"
+	OIDTSTInfo    = "1.2.840.113549.1.9.16.1.4"
+
+	oidContentType          = "1.2.840.113549.1.9.3"
+	oidMessageDigest        = "1.2.840.113549.1.9.4"
+	oidSigningTime          = "1.2.840.113549.1.9.5"
+	oidSigningCertificate   = "1.2.840.113549.1.9.16.2.12"
+	oidSigningCertificateV2 = "1.2.840.113549.1.9.16.2.47"
+)
+
+// Hash algorithm OIDs used in message imprints and CMS digests
+const (
+	oidSHA1   = "1.3.14.3.2.26"
+	oidSHA224 = "2.16.840.1.101.3.4.2.4"
+	oidSHA256 = "2.16.840.1.101.3.4.2.1"
+	oidSHA384 = "2.16.840.1.101.3.4.2.2"
+	oidSHA512 = "2.16.840.1.101.3.4.2.3"
+)
+
+var hashes = map[string]crypto.Hash{
+	oidSHA1:   crypto.SHA1,
+	oidSHA224: crypto.SHA224,
+	oidSHA256: crypto.SHA256,
+	oidSHA384: crypto.SHA384,
+	oidSHA512: crypto.SHA512,
+}
+
+var hashNames = map[string]string{
+	oidSHA1:   "SHA1",
+	oidSHA224: "SHA224",
+	oidSHA256: "SHA256",
+	oidSHA384: "SHA384",
+	oidSHA512: "SHA512",
+}
+
+// HashName returns the name of a hash algorithm OID, or "" if unknown.
+func HashName(oid string) string {
+	return hashNames[oid]
+}
+
+// HashSize returns the digest length of a hash algorithm OID, or 0 if
+// unknown.
+func HashSize(oid string) int {
+	if h, ok := hashes[oid]; ok {
+		return h.Size()
+	}
+	return 0
+}
+
+// PKIStatus values (RFC 3161 2.4.2)
+var statusNames = []string{"granted", "grantedWithMods", "rejection", "waiting", "revocationWarning", "revocationNotification"}
+
+// PKIFailureInfo bits (RFC 3161 2.4.2)
+var failureNames = map[int]string{
+	0:  "badAlg",
+	2:  "badRequest",
+	5:  "badDataFormat",
+	14: "timeNotAvailable",
+	15: "unacceptedPolicy",
+	16: "unacceptedExtension",
+	17: "addInfoNotAvailable",
+	25: "systemFailure",
+}
+
+// Token is a parsed RFC 3161 time-stamp token. Status is set when the token
+// was read from a TimeStampResp; a response that was not granted carries no
+// token, so Info is nil.
+type Token struct {
+	Raw    []byte // DER TimeStampResp or ContentInfo as read
+	Status *Status
+
+	ContentType       string // ContentInfo contentType
+	SignedDataVersion int
+	DigestAlgorithms  []pclasn1.ParamsState
+	EContentType      string
+	EContent          []byte // DER TSTInfo as signed
+	Info              *TSTInfo
+	Certificates      []*x509.Certificate
+	Signers           []*SignerInfo
+}
+
+// Status is the PKIStatusInfo of a TimeStampResp.
+type Status struct {
+	Status       int
+	StatusString []string
+	FailInfo     asn1.BitString
+}
+
+// Name returns the PKIStatus name, e.g. "granted".
+func (s *Status) Name() string {
+	if s.Status >= 0 && s.Status < len(statusNames) {
+		return statusNames[s.Status]
+	}
+	return fmt.Sprintf("unknown(%d)", s.Status)
+}
+
+// Failures returns the names of the PKIFailureInfo bits set.
+func (s *Status) Failures() []string {
+	var out []string
+	for i := 0; i < s.FailInfo.BitLength; i++ {
+		if s.FailInfo.At(i) == 0 {
+			continue
+		}
+		if name, ok := failureNames[i]; ok {
+			out = append(out, name)
+		} else {
+			out = append(out, fmt.Sprintf("bit%d", i))
+		}
+	}
+	return out
+}
+
+// TSTInfo is the signed content of a time-stamp token.
+//
+// ASN.1 structure (RFC 3161 2.4.2):
+//
+//	TSTInfo ::= SEQUENCE {
+//	    version        INTEGER { v1(1) },
+//	    policy         TSAPolicyId,
+//	    messageImprint MessageImprint,
+//	    serialNumber   INTEGER,
+//	    genTime        GeneralizedTime,
+//	    accuracy       Accuracy OPTIONAL,
+//	    ordering       BOOLEAN DEFAULT FALSE,
+//	    nonce          INTEGER OPTIONAL,
+//	    tsa            [0] GeneralName OPTIONAL,
+//	    extensions     [1] IMPLICIT Extensions OPTIONAL }
+type TSTInfo struct {
+	Version       int
+	Policy        string
+	HashAlgorithm pclasn1.ParamsState
+	HashedMessage []byte
+	SerialNumber  *big.Int
+	GenTime       time.Time
+	Accuracy      *Accuracy
+	Ordering      bool
+	Nonce         *big.Int
+	TSA           []byte // DER GeneralName, nil when absent
+	Extensions    []pkix.Extension
+}
+
+// Accuracy is the deviation around genTime. Absent fields are zero.
+type Accuracy struct {
+	Seconds int
+	Millis  int
+	Micros  int
+}
+
+// SignerInfo is a CMS SignerInfo with the signed attributes a time-stamp
+// token uses decoded.
+type SignerInfo struct {
+	Version int
+
+	// The signer is identified by issuer and serial number or, for
+	// version 3, by subject key identifier.
+	Issuer       []byte // DER Name
+	SerialNumber *big.Int
+	SubjectKeyID []byte
+
+	DigestAlgorithm    pclasn1.ParamsState
+	SignatureAlgorithm pclasn1.ParamsState
+	Signature          []byte
+
+	// SignedAttrs is the DER SET OF signed attributes the signature covers.
+	SignedAttrs   []byte
+	ContentType   string
+	MessageDigest []byte
+	SigningTime   time.Time
+
+	// ESSVersion is 1 for signingCertificate, 2 for signingCertificateV2
+	// and 0 when neither is present.
+	ESSVersion int
+	ESSCertIDs []ESSCertID
+}
+
+// ESSCertID identifies a certificate by hash (RFC 2634, RFC 5035).
+type ESSCertID struct {
+	HashAlgorithm string // OID
+	CertHash      []byte
+	SerialNumber  *big.Int // from issuerSerial, nil when absent
+}
+
+// Matches reports whether c hashes to the certificate hash.
+func (e ESSCertID) Matches(c *x509.Certificate) bool {
+	h, ok := hashes[e.HashAlgorithm]
+	if !ok || c == nil || !h.Available() {
+		return false
+	}
+	d := h.New()
+	d.Write(c.Raw)
+	return bytes.Equal(d.Sum(nil), e.CertHash)
+}
+
+// Parse parses a DER TimeStampResp or a bare TimeStampToken.
+//
+// ASN.1 structure (RFC 3161 2.4.2):
+//
+//	TimeStampResp ::= SEQUENCE {
+//	    status         PKIStatusInfo,
+//	    timeStampToken TimeStampToken OPTIONAL }
+//	TimeStampToken ::= ContentInfo
+func Parse(der []byte) (*Token, error) {
+	input := cryptobyte.String(der)
+	var outer cryptobyte.String
+	if !input.ReadASN1(&outer, cryptobyte_asn1.SEQUENCE) || !input.Empty() {
+		return nil, errors.New("malformed time-stamp: not a DER SEQUENCE")
+	}
+
+	t := &Token{Raw: der}
+
+	// A ContentInfo starts with its contentType, a TimeStampResp with
+	// the PKIStatusInfo SEQUENCE
+	if outer.PeekASN1Tag(cryptobyte_asn1.SEQUENCE) {
+		status, err := parseStatus(&outer)
+		if err != nil {
+			return nil, err
+		}
+		t.Status = status
+		if outer.Empty() {
+			return t, nil
+		}
+		var contentInfo cryptobyte.String
+		if !outer.ReadASN1(&contentInfo, cryptobyte_asn1.SEQUENCE) {
+			return nil, errors.New("malformed time-stamp response: invalid timeStampToken")
+		}
+		outer = contentInfo
+	}
+
+	if err := t.parseContentInfo(outer); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func parseStatus(outer *cryptobyte.String) (*Status, error) {
+	var info cryptobyte.String
+	s := &Status{}
+	if !outer.ReadASN1(&info, cryptobyte_asn1.SEQUENCE) || !info.ReadASN1Integer(&s.Status) {
+		return nil, errors.New("malformed time-stamp response: invalid status")
+	}
+
+	if info.PeekASN1Tag(cryptobyte_asn1.SEQUENCE) {
+		var text cryptobyte.String
+		if !info.ReadASN1(&text, cryptobyte_asn1.SEQUENCE) {
+			return nil, errors.New("malformed time-stamp response: invalid statusString")
+		}
+		for !text.Empty() {
+			var str cryptobyte.String
+			if !text.ReadASN1(&str, cryptobyte_asn1.UTF8String) {
+				return nil, errors.New("malformed time-stamp response: invalid statusString")
+			}
+			s.StatusString = append(s.StatusString, string(str))
+		}
+	}
+
+	if info.PeekASN1Tag(cryptobyte_asn1.BIT_STRING) {
+		var element cryptobyte.String
+		if !info.ReadASN1Element(&element, cryptobyte_asn1.BIT_STRING) {
+			return nil, errors.New("malformed time-stamp response: invalid failInfo")
+		}
+		if _, err := asn1.Unmarshal(element, &s.FailInfo); err != nil {
+			return nil, fmt.Errorf("malformed time-stamp response: invalid failInfo: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// parseContentInfo parses the ContentInfo wrapping the SignedData.
+//
+// ASN.1 structure (RFC 5652 5.1):
+//
+//	SignedData ::= SEQUENCE {
+//	    version          CMSVersion,
+//	    digestAlgorithms SET OF DigestAlgorithmIdentifier,
+//	    encapContentInfo EncapsulatedContentInfo,
+//	    certificates     [0] IMPLICIT CertificateSet OPTIONAL,
+//	    crls             [1] IMPLICIT RevocationInfoChoices OPTIONAL,
+//	    signerInfos      SET OF SignerInfo }
+func (t *Token) parseContentInfo(contentInfo cryptobyte.String) error {
+	var contentType asn1.ObjectIdentifier
+	var content, signedData cryptobyte.String
+	if !contentInfo.ReadASN1ObjectIdentifier(&contentType) {
+		return errors.New("malformed time-stamp token: invalid contentType")
+	}
+	t.ContentType = contentType.String()
+	if t.ContentType != OIDSignedData {
+		return fmt.Errorf("time-stamp token content type %s is not signedData", t.ContentType)
+	}
+	if !contentInfo.ReadASN1(&content, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) ||
+		!content.ReadASN1(&signedData, cryptobyte_asn1.SEQUENCE) {
+		return errors.New("malformed time-stamp token: invalid SignedData")
+	}
+
+	var digestAlgorithms cryptobyte.String
+	if !signedData.ReadASN1Integer(&t.SignedDataVersion) ||
+		!signedData.ReadASN1(&digestAlgorithms, cryptobyte_asn1.SET) {
+		return errors.New("malformed time-stamp token: invalid SignedData")
+	}
+	for !digestAlgorithms.Empty() {
+		var algorithm cryptobyte.String
+		if !digestAlgorithms.ReadASN1Element(&algorithm, cryptobyte_asn1.SEQUENCE) {
+			return errors.New("malformed time-stamp token: invalid digestAlgorithms")
+		}
+		t.DigestAlgorithms = append(t.DigestAlgorithms, pclasn1.ParseAlgorithmIDParams(algorithm))
+	}
+
+	var encap, eContent, octets cryptobyte.String
+	var eContentType asn1.ObjectIdentifier
+	if !signedData.ReadASN1(&encap, cryptobyte_asn1.SEQUENCE) ||
+		!encap.ReadASN1ObjectIdentifier(&eContentType) ||
+		!encap.ReadASN1(&eContent, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) ||
+		!eContent.ReadASN1(&octets, cryptobyte_asn1.OCTET_STRING) {
+		return errors.New("malformed time-stamp token: invalid encapContentInfo")
+	}
+	t.EContentType = eContentType.String()
+	t.EContent = octets
+
+	var certificates cryptobyte.String
+	var hasCertificates bool
+	if !signedData.ReadOptionalASN1(&certificates, &hasCertificates, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) ||
+		!signedData.SkipOptionalASN1(cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) {
+		return errors.New("malformed time-stamp token: invalid certificates")
+	}
+	for !certificates.Empty() {
+		var element cryptobyte.String
+		var tag cryptobyte_asn1.Tag
+		if !certificates.ReadAnyASN1Element(&element, &tag) {
+			return errors.New("malformed time-stamp token: invalid certificates")
+		}
+		// Other certificate formats are tagged; only X.509 certificates
+		// are kept
+		if tag != cryptobyte_asn1.SEQUENCE {
+			continue
+		}
+		if c, err := x509.ParseCertificate(element); err == nil {
+			t.Certificates = append(t.Certificates, c)
+		}
+	}
+
+	var signerInfos cryptobyte.String
+	if !signedData.ReadASN1(&signerInfos, cryptobyte_asn1.SET) {
+		return errors.New("malformed time-stamp token: invalid signerInfos")
+	}
+	for !signerInfos.Empty() {
+		var signerInfo cryptobyte.String
+		if !signerInfos.ReadASN1(&signerInfo, cryptobyte_asn1.SEQUENCE) {
+			return errors.New("malformed time-stamp token: invalid SignerInfo")
+		}
+		s, err := parseSignerInfo(signerInfo)
+		if err != nil {
+			return err
+		}
+		t.Signers = append(t.Signers, s)
+	}
+
+	if t.EContentType == OIDTSTInfo {
+		info, err := parseTSTInfo(t.EContent)
+		if err != nil {
+			return err
+		}
+		t.Info = info
+	}
+	return nil
+}
+
+func parseTSTInfo(der []byte) (*TSTInfo, error) {
+	input := cryptobyte.String(der)
+	var seq, imprint, hashAlgorithm, hashedMessage cryptobyte.String
+	var policy asn1.ObjectIdentifier
+	info := &TSTInfo{SerialNumber: new(big.Int)}
+	if !input.ReadASN1(&seq, cryptobyte_asn1.SEQUENCE) ||
+		!seq.ReadASN1Integer(&info.Version) ||
+		!seq.ReadASN1ObjectIdentifier(&policy) ||
+		!seq.ReadASN1(&imprint, cryptobyte_asn1.SEQUENCE) ||
+		!imprint.ReadASN1Element(&hashAlgorithm, cryptobyte_asn1.SEQUENCE) ||
+		!imprint.ReadASN1(&hashedMessage, cryptobyte_asn1.OCTET_STRING) ||
+		!seq.ReadASN1Integer(info.SerialNumber) {
+		return nil, errors.New("malformed TSTInfo")
+	}
+	info.Policy = policy.String()
+	info.HashAlgorithm = pclasn1.ParseAlgorithmIDParams(hashAlgorithm)
+	info.HashedMessage = hashedMessage
+
+	var genTime cryptobyte.String
+	if !seq.ReadASN1(&genTime, cryptobyte_asn1.GeneralizedTime) {
+		return nil, errors.New("malformed TSTInfo: invalid genTime")
+	}
+	t, err := parseGeneralizedTime(string(genTime))
+	if err != nil {
+		return nil, fmt.Errorf("malformed TSTInfo: invalid genTime: %w", err)
+	}
+	info.GenTime = t
+
+	if seq.PeekASN1Tag(cryptobyte_asn1.SEQUENCE) {
+		var accuracy cryptobyte.String
+		info.Accuracy = &Accuracy{}
+		if !seq.ReadASN1(&accuracy, cryptobyte_asn1.SEQUENCE) ||
+			!readOptionalInteger(&accuracy, &info.Accuracy.Seconds, cryptobyte_asn1.INTEGER) ||
+			!readOptionalInteger(&accuracy, &info.Accuracy.Millis, cryptobyte_asn1.Tag(0).ContextSpecific()) ||
+			!readOptionalInteger(&accuracy, &info.Accuracy.Micros, cryptobyte_asn1.Tag(1).ContextSpecific()) {
+			return nil, errors.New("malformed TSTInfo: invalid accuracy")
+		}
+	}
+
+	if seq.PeekASN1Tag(cryptobyte_asn1.BOOLEAN) && !seq.ReadASN1Boolean(&info.Ordering) {
+		return nil, errors.New("malformed TSTInfo: invalid ordering")
+	}
+
+	if seq.PeekASN1Tag(cryptobyte_asn1.INTEGER) {
+		info.Nonce = new(big.Int)
+		if !seq.ReadASN1Integer(info.Nonce) {
+			return nil, errors.New("malformed TSTInfo: invalid nonce")
+		}
+	}
+
+	var tsa cryptobyte.String
+	var hasTSA bool
+	if !seq.ReadOptionalASN1(&tsa, &hasTSA, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) {
+		return nil, errors.New("malformed TSTInfo: invalid tsa")
+	}
+	if hasTSA {
+		info.TSA = tsa
+	}
+
+	var extensions cryptobyte.String
+	var hasExtensions bool
+	if !seq.ReadOptionalASN1(&extensions, &hasExtensions, cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) {
+		return nil, errors.New("malformed TSTInfo: invalid extensions")
+	}
+	for !extensions.Empty() {
+		var element cryptobyte.String
+		var ext pkix.Extension
+		if !extensions.ReadASN1Element(&element, cryptobyte_asn1.SEQUENCE) {
+			return nil, errors.New("malformed TSTInfo: invalid extensions")
+		}
+		if _, err := zasn1.Unmarshal(element, &ext); err != nil {
+			return nil, fmt.Errorf("malformed TSTInfo: invalid extension: %w", err)
+		}
+		info.Extensions = append(info.Extensions, ext)
+	}
+
+	return info, nil
+}
+
+// readOptionalInteger reads an INTEGER, or an INTEGER IMPLICIT-tagged with
+// tag, if one is next. cryptobyte's optional readers expect explicit tags.
+func readOptionalInteger(s *cryptobyte.String, out *int, tag cryptobyte_asn1.Tag) bool {
+	if !s.PeekASN1Tag(tag) {
+		return true
+	}
+	var element cryptobyte.String
+	if !s.ReadASN1Element(&element, tag) {
+		return false
+	}
+	integer := cryptobyte.String(append([]byte{byte(cryptobyte_asn1.INTEGER)}, element[1:]...))
+	return integer.ReadASN1Integer(out)
+}
+
+// parseGeneralizedTime parses a GeneralizedTime, which in a TSTInfo may
+// carry fractional seconds (RFC 3161 2.4.2).
+func parseGeneralizedTime(s string) (time.Time, error) {
+	return time.Parse("20060102150405.999999999Z0700", s)
+}
+
+// parseSignerInfo parses a SignerInfo.
+//
+// ASN.1 structure (RFC 5652 5.3):
+//
+//	SignerInfo ::= SEQUENCE {
+//	    version            CMSVersion,
+//	    sid                SignerIdentifier,
+//	    digestAlgorithm    DigestAlgorithmIdentifier,
+//	    signedAttrs        [0] IMPLICIT SignedAttributes OPTIONAL,
+//	    signatureAlgorithm SignatureAlgorithmIdentifier,
+//	    signature          SignatureValue,
+//	    unsignedAttrs      [1] IMPLICIT UnsignedAttributes OPTIONAL }
+//	SignerIdentifier ::= CHOICE {
+//	    issuerAndSerialNumber IssuerAndSerialNumber,
+//	    subjectKeyIdentifier  [0] SubjectKeyIdentifier }
+func parseSignerInfo(input cryptobyte.String) (*SignerInfo, error) {
+	s := &SignerInfo{}
+	if !input.ReadASN1Integer(&s.Version) {
+		return nil, errors.New("malformed SignerInfo: invalid version")
+	}
+
+	if input.PeekASN1Tag(cryptobyte_asn1.SEQUENCE) {
+		var sid, issuer cryptobyte.String
+		s.SerialNumber = new(big.Int)
+		if !input.ReadASN1(&sid, cryptobyte_asn1.SEQUENCE) ||
+			!sid.ReadASN1Element(&issuer, cryptobyte_asn1.SEQUENCE) ||
+			!sid.ReadASN1Integer(s.SerialNumber) {
+			return nil, errors.New("malformed SignerInfo: invalid issuerAndSerialNumber")
+		}
+		s.Issuer = issuer
+	} else {
+		var ski cryptobyte.String
+		if !input.ReadASN1(&ski, cryptobyte_asn1.Tag(0).ContextSpecific()) {
+			return nil, errors.New("malformed SignerInfo: invalid sid")
+		}
+		s.SubjectKeyID = ski
+	}
+
+	var digestAlgorithm cryptobyte.String
+	if !input.ReadASN1Element(&digestAlgorithm, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("malformed SignerInfo: invalid digestAlgorithm")
+	}
+	s.DigestAlgorithm = pclasn1.ParseAlgorithmIDParams(digestAlgorithm)
+
+	signedAttrsTag := cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()
+	if input.PeekASN1Tag(signedAttrsTag) {
+		var element cryptobyte.String
+		if !input.ReadASN1Element(&element, signedAttrsTag) {
+			return nil, errors.New("malformed SignerInfo: invalid signedAttrs")
+		}
+		// The signature covers the attributes with their universal SET OF
+		// tag, not the [0] IMPLICIT tag (RFC 5652 5.4)
+		s.SignedAttrs = append([]byte{0x31}, element[1:]...)
+		if err := s.parseSignedAttrs(element); err != nil {
+			return nil, err
+		}
+	}
+
+	var signatureAlgorithm, signature cryptobyte.String
+	if !input.ReadASN1Element(&signatureAlgorithm, cryptobyte_asn1.SEQUENCE) ||
+		!input.ReadASN1(&signature, cryptobyte_asn1.OCTET_STRING) {
+		return nil, errors.New("malformed SignerInfo: invalid signature")
+	}
+	s.SignatureAlgorithm = pclasn1.ParseAlgorithmIDParams(signatureAlgorithm)
+	s.Signature = signature
+
+	return s, nil
+}
+
+func (s *SignerInfo) parseSignedAttrs(element cryptobyte.String) error {
+	var attrs cryptobyte.String
+	if !element.ReadASN1(&attrs, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) {
+		return errors.New("malformed SignerInfo: invalid signedAttrs")
+	}
+
+	for !attrs.Empty() {
+		var attr, values, value cryptobyte.String
+		var oid asn1.ObjectIdentifier
+		if !attrs.ReadASN1(&attr, cryptobyte_asn1.SEQUENCE) ||
+			!attr.ReadASN1ObjectIdentifier(&oid) ||
+			!attr.ReadASN1(&values, cryptobyte_asn1.SET) {
+			return errors.New("malformed SignerInfo: invalid signed attribute")
+		}
+
+		var ok bool
+		switch oid.String() {
+		case oidContentType:
+			var contentType asn1.ObjectIdentifier
+			ok = values.ReadASN1ObjectIdentifier(&contentType)
+			s.ContentType = contentType.String()
+		case oidMessageDigest:
+			ok = values.ReadASN1(&value, cryptobyte_asn1.OCTET_STRING)
+			s.MessageDigest = value
+		case oidSigningTime:
+			if values.PeekASN1Tag(cryptobyte_asn1.UTCTime) {
+				ok = values.ReadASN1UTCTime(&s.SigningTime)
+			} else {
+				ok = values.ReadASN1GeneralizedTime(&s.SigningTime)
+			}
+		case oidSigningCertificate:
+			s.ESSVersion = 1
+			ok = s.parseSigningCertificate(values, false)
+		case oidSigningCertificateV2:
+			s.ESSVersion = 2
+			ok = s.parseSigningCertificate(values, true)
+		default:
+			ok = true
+		}
+		if !ok {
+			return fmt.Errorf("malformed SignerInfo: invalid signed attribute %s", oid)
+		}
+	}
+
+	return nil
+}
+
+// parseSigningCertificate parses the certificate IDs of a
+// signingCertificate or signingCertificateV2 attribute.
+//
+// ASN.1 structure (RFC 2634 5.4, RFC 5035 3):
+//
+//	SigningCertificate ::= SEQUENCE {
+//	    certs    SEQUENCE OF ESSCertID,
+//	    policies SEQUENCE OF PolicyInformation OPTIONAL }
+//	ESSCertID ::= SEQUENCE {
+//	    certHash     Hash, -- SHA-1
+//	    issuerSerial IssuerSerial OPTIONAL }
+//	ESSCertIDv2 ::= SEQUENCE {
+//	    hashAlgorithm AlgorithmIdentifier DEFAULT {algorithm id-sha256},
+//	    certHash      Hash,
+//	    issuerSerial  IssuerSerial OPTIONAL }
+//	IssuerSerial ::= SEQUENCE {
+//	    issuer       GeneralNames,
+//	    serialNumber CertificateSerialNumber }
+func (s *SignerInfo) parseSigningCertificate(values cryptobyte.String, v2 bool) bool {
+	var signingCertificate, certs cryptobyte.String
+	if !values.ReadASN1(&signingCertificate, cryptobyte_asn1.SEQUENCE) ||
+		!signingCertificate.ReadASN1(&certs, cryptobyte_asn1.SEQUENCE) {
+		return false
+	}
+
+	for !certs.Empty() {
+		var certID, certHash cryptobyte.String
+		if !certs.ReadASN1(&certID, cryptobyte_asn1.SEQUENCE) {
+			return false
+		}
+
+		id := ESSCertID{HashAlgorithm: oidSHA1}
+		if v2 {
+			id.HashAlgorithm = oidSHA256
+			if certID.PeekASN1Tag(cryptobyte_asn1.SEQUENCE) {
+				var hashAlgorithm cryptobyte.String
+				if !certID.ReadASN1Element(&hashAlgorithm, cryptobyte_asn1.SEQUENCE) {
+					return false
+				}
+				id.HashAlgorithm = pclasn1.ParseAlgorithmIDParams(hashAlgorithm).OID
+			}
+		}
+		if !certID.ReadASN1(&certHash, cryptobyte_asn1.OCTET_STRING) {
+			return false
+		}
+		id.CertHash = certHash
+
+		if certID.PeekASN1Tag(cryptobyte_asn1.SEQUENCE) {
+			var issuerSerial cryptobyte.String
+			id.SerialNumber = new(big.Int)
+			if !certID.ReadASN1(&issuerSerial, cryptobyte_asn1.SEQUENCE) ||
+				!issuerSerial.SkipASN1(cryptobyte_asn1.SEQUENCE) ||
+				!issuerSerial.ReadASN1Integer(id.SerialNumber) {
+				return false
+			}
+		}
+
+		s.ESSCertIDs = append(s.ESSCertIDs, id)
+	}
+
+	return true
+}
+
+// SignerCertificate returns the certificate of the first signer, looked up
+// in the token's certificates and then in pool. It is nil when neither
+// holds it.
+func (t *Token) SignerCertificate(pool []*x509.Certificate) *x509.Certificate {
+	if len(t.Signers) == 0 {
+		return nil
+	}
+	for _, c := range slices.Concat(t.Certificates, pool) {
+		if t.Signers[0].Identifies(c) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Identifies reports whether c is the certificate the signer identifier
+// names.
+func (s *SignerInfo) Identifies(c *x509.Certificate) bool {
+	if c == nil {
+		return false
+	}
+	if len(s.SubjectKeyID) > 0 {
+		return bytes.Equal(c.SubjectKeyId, s.SubjectKeyID)
+	}
+	return s.SerialNumber != nil && c.SerialNumber != nil &&
+		s.SerialNumber.Cmp(c.SerialNumber) == 0 &&
+		bytes.Equal(c.RawIssuer, s.Issuer)
+}
+
+// MessageDigestValid reports whether the messageDigest attribute is the
+// digest of content.
+func (s *SignerInfo) MessageDigestValid(content []byte) bool {
+	h, ok := hashes[s.DigestAlgorithm.OID]
+	if !ok || !h.Available() {
+		return false
+	}
+	d := h.New()
+	d.Write(content)
+	return bytes.Equal(d.Sum(nil), s.MessageDigest)
+}
+
+// SignatureAlgorithmOf returns the X.509 signature algorithm the signer
+// used. CMS commonly names only the key algorithm, e.g. rsaEncryption, and
+// the digest separately.
+func (s *SignerInfo) SignatureAlgorithmOf() x509.SignatureAlgorithm {
+	if alg, ok := signatureAlgorithms[s.SignatureAlgorithm.OID]; ok {
+		return alg
+	}
+	if byDigest, ok := keyAlgorithms[s.SignatureAlgorithm.OID]; ok {
+		return byDigest[s.DigestAlgorithm.OID]
+	}
+	return x509.UnknownSignatureAlgorithm
+}
+
+// CheckSignature verifies the signature over the signed attributes with the
+// key of c. It returns x509.ErrUnsupportedAlgorithm when the algorithm has
+// no verifier.
+func (s *SignerInfo) CheckSignature(c *x509.Certificate) error {
+	if len(s.SignedAttrs) == 0 {
+		return errors.New("no signed attributes")
+	}
+	alg := s.SignatureAlgorithmOf()
+	if alg == x509.UnknownSignatureAlgorithm {
+		return x509.ErrUnsupportedAlgorithm
+	}
+	return c.CheckSignature(alg, s.SignedAttrs, s.Signature)
+}
+
+var signatureAlgorithms = map[string]x509.SignatureAlgorithm{
+	"1.2.840.113549.1.1.5":  x509.SHA1WithRSA,
+	"1.2.840.113549.1.1.11": x509.SHA256WithRSA,
+	"1.2.840.113549.1.1.12": x509.SHA384WithRSA,
+	"1.2.840.113549.1.1.13": x509.SHA512WithRSA,
+	"1.2.840.10045.4.1":     x509.ECDSAWithSHA1,
+	"1.2.840.10045.4.3.2":   x509.ECDSAWithSHA256,
+	"1.2.840.10045.4.3.3":   x509.ECDSAWithSHA384,
+	"1.2.840.10045.4.3.4":   x509.ECDSAWithSHA512,
+	"1.3.101.112":           x509.Ed25519Sig,
+}
+
+var keyAlgorithms = map[string]map[string]x509.SignatureAlgorithm{
+	// rsaEncryption
+	"1.2.840.113549.1.1.1": {
+		oidSHA1:   x509.SHA1WithRSA,
+		oidSHA256: x509.SHA256WithRSA,
+		oidSHA384: x509.SHA384WithRSA,
+		oidSHA512: x509.SHA512WithRSA,
+	},
+	// id-ecPublicKey
+	"1.2.840.10045.2.1": {
+		oidSHA1:   x509.ECDSAWithSHA1,
+		oidSHA256: x509.ECDSAWithSHA256,
+		oidSHA384: x509.ECDSAWithSHA384,
+		oidSHA512: x509.ECDSAWithSHA512,
+	},
+}
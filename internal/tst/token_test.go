@@ -0,0 +1,143 @@
+package tst
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zmap/zcrypto/x509"
+)
+
+const (
+	testdata = "../../tests/tsts"
+	certs    = "../../tests/certs"
+)
+
+func loadToken(t *testing.T, name string) *Token {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(testdata, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := Parse(data)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return token
+}
+
+func loadTSACert(t *testing.T) *x509.Certificate {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(certs, "tsa.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	c, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestParse(t *testing.T) {
+	token := loadToken(t, "tst.tsr")
+
+	if token.EContentType != OIDTSTInfo || token.SignedDataVersion != 3 {
+		t.Errorf("eContentType %s, SignedData version %d", token.EContentType, token.SignedDataVersion)
+	}
+	if len(token.Certificates) != 2 || len(token.Signers) != 1 {
+		t.Fatalf("got %d certificates and %d signers, want 2 and 1", len(token.Certificates), len(token.Signers))
+	}
+
+	info := token.Info
+	if info.Version != 1 || info.HashAlgorithm.OID != oidSHA256 || len(info.HashedMessage) != 32 {
+		t.Errorf("version %d, imprint %s with %d bytes", info.Version, info.HashAlgorithm.OID, len(info.HashedMessage))
+	}
+	if info.Accuracy == nil || *info.Accuracy != (Accuracy{Seconds: 1, Millis: 500, Micros: 100}) {
+		t.Errorf("accuracy = %+v, want 1s 500ms 100us", info.Accuracy)
+	}
+	if !info.Ordering || info.Nonce == nil || info.TSA == nil {
+		t.Errorf("ordering %v, nonce %v, tsa %x", info.Ordering, info.Nonce, info.TSA)
+	}
+	if info.GenTime.IsZero() || info.GenTime.Location() != time.UTC {
+		t.Errorf("genTime = %v", info.GenTime)
+	}
+
+	signer := token.Signers[0]
+	if signer.ContentType != OIDTSTInfo || signer.ESSVersion != 2 || len(signer.ESSCertIDs) != 1 {
+		t.Errorf("contentType %s, ESS version %d with %d IDs", signer.ContentType, signer.ESSVersion, len(signer.ESSCertIDs))
+	}
+	if !signer.MessageDigestValid(token.EContent) {
+		t.Error("messageDigest does not match the TSTInfo")
+	}
+	if signer.SignatureAlgorithmOf() != x509.SHA256WithRSA {
+		t.Errorf("signature algorithm = %v", signer.SignatureAlgorithmOf())
+	}
+}
+
+func TestParse_WithoutOptionalFields(t *testing.T) {
+	token := loadToken(t, "tst-bad.tsr")
+
+	info := token.Info
+	if info.HashAlgorithm.OID != oidSHA1 || info.Accuracy != nil || info.Ordering || info.Nonce != nil || info.TSA != nil {
+		t.Errorf("unexpected optional fields: %+v", info)
+	}
+	if len(token.Certificates) != 0 {
+		t.Errorf("got %d certificates, want none", len(token.Certificates))
+	}
+	if signer := token.Signers[0]; signer.ESSVersion != 1 || signer.ESSCertIDs[0].HashAlgorithm != oidSHA1 {
+		t.Errorf("ESS version %d, hash %s", signer.ESSVersion, signer.ESSCertIDs[0].HashAlgorithm)
+	}
+}
+
+func TestParse_Rejected(t *testing.T) {
+	token := loadToken(t, "tst-rejected.tsr")
+
+	if token.Status.Name() != "rejection" || token.Info != nil {
+		t.Fatalf("status %s, info %+v", token.Status.Name(), token.Info)
+	}
+	if failures := token.Status.Failures(); len(failures) != 1 || failures[0] != "badAlg" {
+		t.Errorf("failures = %v, want [badAlg]", failures)
+	}
+	if len(token.Status.StatusString) != 1 {
+		t.Errorf("statusString = %v", token.Status.StatusString)
+	}
+}
+
+func TestParse_Malformed(t *testing.T) {
+	token := loadToken(t, "tst-token.tst")
+	for _, n := range []int{10, 100, len(token.Raw) / 2} {
+		if _, err := Parse(token.Raw[:n]); err == nil {
+			t.Errorf("expected error for a token truncated to %d bytes", n)
+		}
+	}
+}
+
+func TestSignerCertificate(t *testing.T) {
+	tsa := loadTSACert(t)
+
+	token := loadToken(t, "tst.tsr")
+	if c := token.SignerCertificate(nil); c == nil || !token.Signers[0].Identifies(c) {
+		t.Fatal("embedded signer certificate not found")
+	}
+	if err := token.Signers[0].CheckSignature(token.SignerCertificate(nil)); err != nil {
+		t.Errorf("CheckSignature: %v", err)
+	}
+	if !token.Signers[0].ESSCertIDs[0].Matches(tsa) {
+		t.Error("ESSCertIDv2 does not match the TSA certificate")
+	}
+
+	bad := loadToken(t, "tst-bad.tsr")
+	if bad.SignerCertificate(nil) != nil {
+		t.Error("token without certificates has no signer certificate")
+	}
+	if c := bad.SignerCertificate([]*x509.Certificate{tsa}); c != tsa {
+		t.Error("signer certificate not found in the pool")
+	}
+	if !bad.Signers[0].ESSCertIDs[0].Matches(tsa) {
+		t.Error("ESSCertID does not match the TSA certificate")
+	}
+}
@@ -0,0 +1,99 @@
+// Package tst provides RFC 3161 time-stamp token data types.
+package tst
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"slices"
+
+	fileio "github.com/cavoq/PCL/internal/io"
+	"github.com/cavoq/PCL/internal/source"
+)
+
+var extensions = []string{".tsr", ".tst", ".der", ".pem"}
+
+var pemTypes = []string{"TIME-STAMP RESPONSE", "TIMESTAMP RESPONSE", "TIME-STAMP TOKEN", "TIMESTAMP TOKEN", "PKCS7", "CMS"}
+
+type Info struct {
+	Token    *Token
+	FilePath string
+	Hash     string
+	Source   source.Info
+	Format   source.Format
+}
+
+func ParseTST(data []byte) (*Token, error) {
+	token, _, err := parseTST(data)
+	return token, err
+}
+
+func parseTST(data []byte) (*Token, source.Format, error) {
+	block, _ := pem.Decode(data)
+	if block != nil && slices.Contains(pemTypes, block.Type) {
+		token, err := Parse(block.Bytes)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse PEM time-stamp: %w", err)
+		}
+		return token, source.FormatPEM, nil
+	}
+
+	token, err := Parse(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse PEM or DER time-stamp: %w", err)
+	}
+	return token, source.FormatDER, nil
+}
+
+func GetTSTFiles(path string) ([]string, error) {
+	return fileio.GetFilesWithExtensions(path, extensions...)
+}
+
+func GetTSTs(path string) ([]*Info, error) {
+	files, err := GetTSTFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*Info, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		info, err := NewInfo(data, file, source.Info{Type: source.Local})
+		if err != nil {
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	if len(infos) == 0 && len(files) > 0 {
+		return nil, fmt.Errorf("no valid items found in %s", path)
+	}
+
+	return infos, nil
+}
+
+// NewInfo parses a TimeStampResp or bare TimeStampToken held in memory,
+// PEM or DER. Library callers have no file to point at, so name stands in
+// for the token's path in lint results.
+func NewInfo(data []byte, name string, sourceInfo source.Info) (*Info, error) {
+	token, format, err := parseTST(data)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(token.Raw)
+	sourceInfo.Format = format
+	return &Info{
+		Token:    token,
+		FilePath: name,
+		Hash:     hex.EncodeToString(hash[:]),
+		Source:   sourceInfo,
+		Format:   format,
+	}, nil
+}
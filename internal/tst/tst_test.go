@@ -0,0 +1,84 @@
+package tst
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cavoq/PCL/internal/loader"
+	"github.com/cavoq/PCL/internal/source"
+)
+
+func TestParseTST_Response(t *testing.T) {
+	token, err := loader.Load(filepath.Join(testdata, "tst.tsr"), ParseTST)
+	if err != nil {
+		t.Fatalf("failed to load time-stamp response: %v", err)
+	}
+	if token.Status == nil || token.Status.Name() != "granted" {
+		t.Fatalf("expected granted status, got %+v", token.Status)
+	}
+	if token.Info == nil {
+		t.Fatal("expected a TSTInfo")
+	}
+}
+
+func TestParseTST_Token(t *testing.T) {
+	token, err := loader.Load(filepath.Join(testdata, "tst-token.tst"), ParseTST)
+	if err != nil {
+		t.Fatalf("failed to load time-stamp token: %v", err)
+	}
+	if token.Status != nil {
+		t.Errorf("a bare token has no status, got %+v", token.Status)
+	}
+	if token.Info == nil || token.Info.Policy != "1.2.3.4.1" {
+		t.Errorf("unexpected TSTInfo %+v", token.Info)
+	}
+}
+
+func TestParseTST_PEM(t *testing.T) {
+	der, err := os.ReadFile(filepath.Join(testdata, "tst.tsr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "TIME-STAMP RESPONSE", Bytes: der})
+
+	info, err := NewInfo(data, "tst.pem", source.Info{Type: source.Local})
+	if err != nil {
+		t.Fatalf("NewInfo: %v", err)
+	}
+	if info.Format != source.FormatPEM || info.Source.Format != source.FormatPEM {
+		t.Errorf("format = %q, source format = %q, want PEM", info.Format, info.Source.Format)
+	}
+}
+
+func TestParseTST_Invalid(t *testing.T) {
+	if _, err := ParseTST([]byte("not a time-stamp")); err == nil {
+		t.Fatal("expected error for invalid time-stamp data")
+	}
+}
+
+func TestGetTSTs(t *testing.T) {
+	infos, err := GetTSTs(testdata)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(infos) != 4 {
+		t.Fatalf("expected 4 time-stamps, got %d", len(infos))
+	}
+	for _, info := range infos {
+		if info.Hash == "" || info.Source.Type != source.Local {
+			t.Errorf("%s: hash %q, source %q", info.FilePath, info.Hash, info.Source.Type)
+		}
+	}
+}
+
+func TestGetTSTs_NoValidItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.tsr")
+	if err := os.WriteFile(path, []byte("not a time-stamp"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetTSTs(path); err == nil {
+		t.Fatal("expected error when no file parses")
+	}
+}
@@ -0,0 +1,250 @@
+// Package zcrypto provides zcrypto-based time-stamp token tree building.
+package zcrypto
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zmap/zcrypto/x509"
+
+	pclasn1 "github.com/cavoq/PCL/internal/asn1"
+	certzcrypto "github.com/cavoq/PCL/internal/cert/zcrypto"
+	"github.com/cavoq/PCL/internal/node"
+	"github.com/cavoq/PCL/internal/tst"
+	"github.com/cavoq/PCL/internal/zcrypto"
+)
+
+type TSTBuilder struct{}
+
+func NewTSTBuilder() *TSTBuilder {
+	return &TSTBuilder{}
+}
+
+func (b *TSTBuilder) Build(token *tst.Token) *node.Node {
+	return buildTST(token, nil)
+}
+
+func BuildTree(token *tst.Token) *node.Node {
+	return NewTSTBuilder().Build(token)
+}
+
+// BuildTreeWithChain builds the tree like BuildTree, looking the signer
+// certificate up in issuers when the token does not carry it.
+func BuildTreeWithChain(token *tst.Token, issuers []*x509.Certificate) *node.Node {
+	return buildTST(token, issuers)
+}
+
+// Fields lists the top-level children a time-stamp token tree may contain.
+var Fields = []string{
+	"status",
+	"contentType",
+	"version",
+	"policy",
+	"messageImprint",
+	"serialNumber",
+	"genTime",
+	"accuracy",
+	"ordering",
+	"nonce",
+	"tsa",
+	"extensions",
+	"signedData",
+	"signer",
+	"signerCertificate",
+	"essCertID",
+	"signatureValid",
+}
+
+func buildTST(token *tst.Token, issuers []*x509.Certificate) *node.Node {
+	root := node.New("tst", nil)
+
+	// PKIStatusInfo, only when read from a TimeStampResp
+	if token.Status != nil {
+		root.Children["status"] = buildStatus(token.Status)
+	}
+
+	if token.ContentType == "" {
+		return root
+	}
+	root.Children["contentType"] = node.New("contentType", token.EContentType)
+	root.Children["signedData"] = buildSignedData(token)
+
+	if info := token.Info; info != nil {
+		root.Children["version"] = node.New("version", info.Version)
+		root.Children["policy"] = node.New("policy", info.Policy)
+		root.Children["messageImprint"] = buildMessageImprint(info)
+		root.Children["serialNumber"] = node.New("serialNumber", info.SerialNumber.String())
+		root.Children["genTime"] = node.New("genTime", info.GenTime)
+		if info.Accuracy != nil {
+			root.Children["accuracy"] = buildAccuracy(info.Accuracy)
+		}
+		root.Children["ordering"] = node.New("ordering", info.Ordering)
+		if info.Nonce != nil {
+			root.Children["nonce"] = node.New("nonce", info.Nonce.String())
+		}
+		if info.TSA != nil {
+			root.Children["tsa"] = zcrypto.BuildGeneralName("tsa", info.TSA)
+		}
+		if len(info.Extensions) > 0 {
+			root.Children["extensions"] = zcrypto.BuildExtensions(info.Extensions)
+		}
+	}
+
+	if len(token.Signers) == 0 {
+		return root
+	}
+	signer := token.Signers[0]
+	root.Children["signer"] = buildSigner(signer, token.EContent)
+
+	signerCert := token.SignerCertificate(issuers)
+	if signerCert != nil {
+		root.Children["signerCertificate"] = certzcrypto.BuildTree(signerCert)
+	}
+
+	if len(signer.ESSCertIDs) > 0 {
+		root.Children["essCertID"] = buildESSCertID(signer, signerCert)
+	}
+
+	// The SignerInfo can only be verified once its certificate is known. As
+	// in CMS (RFC 5652 5.6), the signature over the signed attributes only
+	// holds when their messageDigest also matches the TSTInfo.
+	if signerCert != nil {
+		err := signer.CheckSignature(signerCert)
+		if err == nil && !signer.MessageDigestValid(token.EContent) {
+			err = errors.New("messageDigest does not match the content")
+		}
+		if valid := zcrypto.BuildSignatureValid(err); valid != nil {
+			root.Children["signatureValid"] = valid
+		}
+	}
+
+	return root
+}
+
+func buildStatus(status *tst.Status) *node.Node {
+	n := node.New("status", nil)
+	n.Children["value"] = node.New("value", status.Status)
+	n.Children["name"] = node.New("name", status.Name())
+
+	if len(status.StatusString) > 0 {
+		text := node.New("statusString", nil)
+		for i, s := range status.StatusString {
+			text.Children[fmt.Sprintf("%d", i)] = node.New(fmt.Sprintf("%d", i), s)
+		}
+		n.Children["statusString"] = text
+	}
+
+	if failures := status.Failures(); len(failures) > 0 {
+		failInfo := node.New("failInfo", nil)
+		for _, f := range failures {
+			failInfo.Children[f] = node.New(f, true)
+		}
+		n.Children["failInfo"] = failInfo
+	}
+
+	return n
+}
+
+func buildSignedData(token *tst.Token) *node.Node {
+	n := node.New("signedData", nil)
+	n.Children["version"] = node.New("version", token.SignedDataVersion)
+
+	algorithms := node.New("digestAlgorithms", nil)
+	for i, params := range token.DigestAlgorithms {
+		key := fmt.Sprintf("%d", i)
+		algorithms.Children[key] = buildHashAlgorithm(key, params)
+	}
+	n.Children["digestAlgorithms"] = algorithms
+
+	n.Children["certificates"] = node.New("certificates", len(token.Certificates))
+	n.Children["signerInfos"] = node.New("signerInfos", len(token.Signers))
+	return n
+}
+
+func buildMessageImprint(info *tst.TSTInfo) *node.Node {
+	n := node.New("messageImprint", nil)
+	n.Children["hashAlgorithm"] = buildHashAlgorithm("hashAlgorithm", info.HashAlgorithm)
+	n.Children["hashedMessage"] = node.New("hashedMessage", fmt.Sprintf("%x", info.HashedMessage))
+	n.Children["length"] = node.New("length", len(info.HashedMessage))
+	if size := tst.HashSize(info.HashAlgorithm.OID); size > 0 {
+		n.Children["lengthValid"] = node.New("lengthValid", size == len(info.HashedMessage))
+	}
+	return n
+}
+
+// buildHashAlgorithm names a digest AlgorithmIdentifier. Parameters are
+// only present when encoded, so `absent` can check them.
+func buildHashAlgorithm(name string, params pclasn1.ParamsState) *node.Node {
+	n := node.New(name, nil)
+	if algorithm := tst.HashName(params.OID); algorithm != "" {
+		n.Children["algorithm"] = node.New("algorithm", algorithm)
+	}
+	n.Children["oid"] = node.New("oid", params.OID)
+	if !params.IsAbsent {
+		p := node.New("parameters", nil)
+		p.Children["null"] = node.New("null", params.IsNull)
+		n.Children["parameters"] = p
+	}
+	return n
+}
+
+func buildAccuracy(accuracy *tst.Accuracy) *node.Node {
+	n := node.New("accuracy", nil)
+	n.Children["seconds"] = node.New("seconds", accuracy.Seconds)
+	n.Children["millis"] = node.New("millis", accuracy.Millis)
+	n.Children["micros"] = node.New("micros", accuracy.Micros)
+	return n
+}
+
+func buildSigner(signer *tst.SignerInfo, content []byte) *node.Node {
+	n := node.New("signer", nil)
+	n.Children["version"] = node.New("version", signer.Version)
+
+	sid := node.New("sid", nil)
+	if len(signer.SubjectKeyID) > 0 {
+		sid.Children["subjectKeyIdentifier"] = node.New("subjectKeyIdentifier", fmt.Sprintf("%x", signer.SubjectKeyID))
+	} else {
+		ias := node.New("issuerAndSerialNumber", nil)
+		ias.Children["serialNumber"] = node.New("serialNumber", signer.SerialNumber.String())
+		sid.Children["issuerAndSerialNumber"] = ias
+	}
+	n.Children["sid"] = sid
+
+	n.Children["digestAlgorithm"] = buildHashAlgorithm("digestAlgorithm", signer.DigestAlgorithm)
+	n.Children["signatureAlgorithm"] = certzcrypto.BuildSignatureAlgorithm("signatureAlgorithm", signer.SignatureAlgorithmOf(), signer.SignatureAlgorithm.OID, signer.SignatureAlgorithm)
+
+	// Signed attributes
+	if signer.ContentType != "" {
+		n.Children["contentType"] = node.New("contentType", signer.ContentType)
+	}
+	if signer.MessageDigest != nil {
+		n.Children["messageDigest"] = node.New("messageDigest", fmt.Sprintf("%x", signer.MessageDigest))
+		n.Children["messageDigestValid"] = node.New("messageDigestValid", signer.MessageDigestValid(content))
+	}
+	if !signer.SigningTime.IsZero() {
+		n.Children["signingTime"] = node.New("signingTime", signer.SigningTime)
+	}
+
+	return n
+}
+
+// buildESSCertID describes the first certificate ID of the
+// signingCertificate(V2) attribute, which identifies the signer
+// certificate (RFC 5816).
+func buildESSCertID(signer *tst.SignerInfo, signerCert *x509.Certificate) *node.Node {
+	id := signer.ESSCertIDs[0]
+
+	n := node.New("essCertID", nil)
+	n.Children["version"] = node.New("version", signer.ESSVersion)
+	n.Children["hashAlgorithm"] = buildHashAlgorithm("hashAlgorithm", pclasn1.ParamsState{OID: id.HashAlgorithm, IsAbsent: true})
+	n.Children["certHash"] = node.New("certHash", fmt.Sprintf("%x", id.CertHash))
+	if id.SerialNumber != nil {
+		issuerSerial := node.New("issuerSerial", nil)
+		issuerSerial.Children["serialNumber"] = node.New("serialNumber", id.SerialNumber.String())
+		n.Children["issuerSerial"] = issuerSerial
+	}
+	if signerCert != nil {
+		n.Children["matchesSigner"] = node.New("matchesSigner", id.Matches(signerCert))
+	}
+	return n
+}
@@ -0,0 +1,146 @@
+package zcrypto
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zmap/zcrypto/x509"
+
+	"github.com/cavoq/PCL/internal/node/nodetest"
+	"github.com/cavoq/PCL/internal/tst"
+)
+
+const (
+	testdata = "../../../tests/tsts"
+	certs    = "../../../tests/certs"
+)
+
+func loadToken(t *testing.T, name string) *tst.Token {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(testdata, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := tst.Parse(data)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+	return token
+}
+
+func loadTSACert(t *testing.T) *x509.Certificate {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(certs, "tsa.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	c, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestBuildTree(t *testing.T) {
+	tree := BuildTree(loadToken(t, "tst.tsr"))
+
+	nodetest.AssertPathValue(t, tree, "status.value", 0)
+	nodetest.AssertPathValue(t, tree, "status.name", "granted")
+	nodetest.AssertPathValue(t, tree, "contentType", tst.OIDTSTInfo)
+	nodetest.AssertPathValue(t, tree, "version", 1)
+	nodetest.AssertPathValue(t, tree, "policy", "1.2.3.4.1")
+	nodetest.AssertPathValue(t, tree, "messageImprint.hashAlgorithm.algorithm", "SHA256")
+	nodetest.AssertPathValue(t, tree, "messageImprint.hashAlgorithm.oid", "2.16.840.1.101.3.4.2.1")
+	nodetest.AssertPathValue(t, tree, "messageImprint.length", 32)
+	nodetest.AssertPathValue(t, tree, "messageImprint.lengthValid", true)
+	nodetest.AssertPathValue(t, tree, "serialNumber", "2")
+	nodetest.AssertPathValue(t, tree, "accuracy.seconds", 1)
+	nodetest.AssertPathValue(t, tree, "accuracy.millis", 500)
+	nodetest.AssertPathValue(t, tree, "accuracy.micros", 100)
+	nodetest.AssertPathValue(t, tree, "ordering", true)
+	nodetest.AssertPathValue(t, tree, "tsa.directoryName.commonName", "PCL Test TSA")
+	nodetest.AssertPathValue(t, tree, "tsa.directoryName.attributes.0.stringType", "PrintableString")
+
+	nodetest.AssertPathValue(t, tree, "signedData.version", 3)
+	nodetest.AssertPathValue(t, tree, "signedData.certificates", 2)
+	nodetest.AssertPathValue(t, tree, "signedData.digestAlgorithms.0.algorithm", "SHA256")
+
+	nodetest.AssertPathValue(t, tree, "signer.version", 1)
+	nodetest.AssertPathValue(t, tree, "signer.contentType", tst.OIDTSTInfo)
+	nodetest.AssertPathValue(t, tree, "signer.messageDigestValid", true)
+	nodetest.AssertPathValue(t, tree, "signer.digestAlgorithm.algorithm", "SHA256")
+	nodetest.AssertPathValue(t, tree, "signer.signatureAlgorithm.algorithm", "SHA256-RSA")
+	nodetest.AssertPathValue(t, tree, "signer.signatureAlgorithm.oid", "1.2.840.113549.1.1.1")
+
+	nodetest.AssertPathValue(t, tree, "signerCertificate.subject.commonName", "PCL Test TSA")
+	nodetest.AssertPathValue(t, tree, "signerCertificate.extKeyUsage.timeStamping", true)
+	nodetest.AssertPathValue(t, tree, "essCertID.version", 2)
+	nodetest.AssertPathValue(t, tree, "essCertID.hashAlgorithm.algorithm", "SHA256")
+	nodetest.AssertPathValue(t, tree, "essCertID.matchesSigner", true)
+	nodetest.AssertPathValue(t, tree, "signatureValid", true)
+
+	for _, path := range []string{"nonce", "genTime", "signer.signingTime", "signer.sid.issuerAndSerialNumber.serialNumber"} {
+		if _, ok := tree.Resolve(path); !ok {
+			t.Errorf("path %q not found", path)
+		}
+	}
+	nodetest.AssertPathNotExists(t, tree, "status.failInfo")
+	nodetest.AssertPathNotExists(t, tree, "extensions")
+}
+
+func TestBuildTreeWithoutOptionalFields(t *testing.T) {
+	token := loadToken(t, "tst-bad.tsr")
+
+	tree := BuildTree(token)
+	nodetest.AssertPathValue(t, tree, "messageImprint.hashAlgorithm.algorithm", "SHA1")
+	nodetest.AssertPathValue(t, tree, "messageImprint.length", 20)
+	nodetest.AssertPathValue(t, tree, "ordering", false)
+	nodetest.AssertPathValue(t, tree, "signedData.certificates", 0)
+	nodetest.AssertPathValue(t, tree, "essCertID.version", 1)
+	nodetest.AssertPathValue(t, tree, "essCertID.hashAlgorithm.algorithm", "SHA1")
+	for _, path := range []string{"accuracy", "nonce", "tsa", "signerCertificate", "essCertID.matchesSigner", "signatureValid"} {
+		nodetest.AssertPathNotExists(t, tree, path)
+	}
+
+	// The signer certificate can come from the issuers instead
+	tree = BuildTreeWithChain(token, []*x509.Certificate{loadTSACert(t)})
+	nodetest.AssertPathValue(t, tree, "signerCertificate.subject.commonName", "PCL Test TSA")
+	nodetest.AssertPathValue(t, tree, "essCertID.matchesSigner", true)
+	nodetest.AssertPathValue(t, tree, "signatureValid", true)
+}
+
+func TestBuildTreeRejected(t *testing.T) {
+	tree := BuildTree(loadToken(t, "tst-rejected.tsr"))
+
+	nodetest.AssertPathValue(t, tree, "status.value", 2)
+	nodetest.AssertPathValue(t, tree, "status.name", "rejection")
+	nodetest.AssertPathValue(t, tree, "status.failInfo.badAlg", true)
+	nodetest.AssertPathValue(t, tree, "status.statusString.0", "Message digest algorithm is not supported.")
+	for _, path := range []string{"contentType", "genTime", "signer"} {
+		nodetest.AssertPathNotExists(t, tree, path)
+	}
+}
+
+func TestBuildTreeTamperedSignature(t *testing.T) {
+	token := loadToken(t, "tst.tsr")
+	signer := token.Signers[0]
+	signer.Signature = append([]byte{}, signer.Signature...)
+	signer.Signature[0] ^= 0xff
+
+	tree := BuildTree(token)
+	nodetest.AssertPathValue(t, tree, "signatureValid", false)
+	nodetest.AssertPathValue(t, tree, "signer.messageDigestValid", true)
+}
+
+func TestBuildTreeTamperedContent(t *testing.T) {
+	token := loadToken(t, "tst.tsr")
+	token.EContent = append([]byte{}, token.EContent...)
+	token.EContent[len(token.EContent)-1] ^= 0xff
+
+	tree := BuildTree(token)
+	nodetest.AssertPathValue(t, tree, "signer.messageDigestValid", false)
+	nodetest.AssertPathValue(t, tree, "signatureValid", false)
+}
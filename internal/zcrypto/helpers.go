@@ -3,6 +3,7 @@ package zcrypto
 
 import (
 	stdx509 "crypto/x509"
+	"errors"
 
 	zx509 "github.com/zmap/zcrypto/x509"
 	"github.com/zmap/zcrypto/x509/pkix"
@@ -22,13 +23,24 @@ var extensionNames = map[string]string{
 	"2.5.29.32":          "certificatePolicies",
 	"2.5.29.35":          "authorityKeyIdentifier",
 	"2.5.29.37":          "extKeyUsage",
+	"2.5.29.46":          "freshestCRL",
 	"1.3.6.1.5.5.7.1.1":  "authorityInfoAccess",
 	"1.3.6.1.5.5.7.1.11": "subjectInfoAccess",
+	"2.5.29.20":          "cRLNumber",
 	"2.5.29.21":          "cRLReason",
-	"2.5.29.29":          "cRLNumber",
-	"2.5.29.20":          "cRLDistributionPoints", // Note: this is actually issuingDistributionPoint
+	"2.5.29.27":          "deltaCRLIndicator",
+	"2.5.29.28":          "issuingDistributionPoint",
+	"2.5.29.29":          "certificateIssuer",
 	"1.3.6.1.5.5.7.48.1": "id-ad-ocsp",
 	"1.3.6.1.5.5.7.48.2": "id-ad-caIssuers",
+
+	// Attribute certificate extensions (RFC 5755 4.3)
+	"1.3.6.1.5.5.7.1.4": "auditIdentity",
+	"2.5.29.55":         "targetInformation",
+	"2.5.29.56":         "noRevAvail",
+
+	"1.3.6.1.4.1.11129.2.4.2": "signedCertificateTimestampList",
+	"1.3.6.1.4.1.11129.2.4.3": "precertificatePoison",
 }
 
 func ToStdCert(cert *zx509.Certificate) (*stdx509.Certificate, error) {
@@ -45,65 +57,20 @@ func FromStdCert(cert *stdx509.Certificate) (*zx509.Certificate, error) {
 	return zx509.ParseCertificate(cert.Raw)
 }
 
-func BuildPkixName(name string, pkixName pkix.Name) *node.Node {
-	n := node.New(name, nil)
-
-	if len(pkixName.Country) > 0 {
-		n.Children["countryName"] = node.New("countryName", pkixName.Country[0])
-	}
-	if len(pkixName.Organization) > 0 {
-		n.Children["organizationName"] = node.New("organizationName", pkixName.Organization[0])
-	}
-	if len(pkixName.OrganizationalUnit) > 0 {
-		n.Children["organizationalUnitName"] = node.New("organizationalUnitName", pkixName.OrganizationalUnit[0])
-	}
-	if pkixName.CommonName != "" {
-		n.Children["commonName"] = node.New("commonName", pkixName.CommonName)
-	}
-	if len(pkixName.Locality) > 0 {
-		n.Children["localityName"] = node.New("localityName", pkixName.Locality[0])
-	}
-	if len(pkixName.Province) > 0 {
-		n.Children["stateOrProvinceName"] = node.New("stateOrProvinceName", pkixName.Province[0])
-	}
-	if len(pkixName.StreetAddress) > 0 {
-		n.Children["streetAddress"] = node.New("streetAddress", pkixName.StreetAddress[0])
-	}
-	if len(pkixName.PostalCode) > 0 {
-		n.Children["postalCode"] = node.New("postalCode", pkixName.PostalCode[0])
-	}
-	if pkixName.SerialNumber != "" {
-		n.Children["serialNumber"] = node.New("serialNumber", pkixName.SerialNumber)
-	}
-	if len(pkixName.OrganizationIDs) > 0 {
-		n.Children["organizationIdentifier"] = node.New("organizationIdentifier", pkixName.OrganizationIDs[0])
-	}
-
-	// EV-specific fields
-	if len(pkixName.JurisdictionCountry) > 0 {
-		n.Children["jurisdictionCountryName"] = node.New("jurisdictionCountryName", pkixName.JurisdictionCountry[0])
-	}
-	if len(pkixName.JurisdictionProvince) > 0 {
-		n.Children["jurisdictionStateOrProvinceName"] = node.New("jurisdictionStateOrProvinceName", pkixName.JurisdictionProvince[0])
-	}
-	if len(pkixName.JurisdictionLocality) > 0 {
-		n.Children["jurisdictionLocalityName"] = node.New("jurisdictionLocalityName", pkixName.JurisdictionLocality[0])
-	}
-
-	// Parse additional attributes from Names (e.g., businessCategory)
-	// OID 2.5.4.15 = businessCategory
-	for _, atv := range pkixName.Names {
-		if len(atv.Type) == 4 && atv.Type[0] == 2 && atv.Type[1] == 5 && atv.Type[2] == 4 {
-			switch atv.Type[3] {
-			case 15: // businessCategory (2.5.4.15)
-				if val, ok := atv.Value.(string); ok {
-					n.Children["businessCategory"] = node.New("businessCategory", val)
-				}
-			}
-		}
+// BuildSignatureValid renders the outcome err of a signature check as a
+// signatureValid node. It returns nil when there is no verifier for the
+// algorithm, so that rules on the signature are skipped rather than failed.
+func BuildSignatureValid(err error) *node.Node {
+	if errors.Is(err, zx509.ErrUnsupportedAlgorithm) {
+		return nil
 	}
+	return node.New("signatureValid", err == nil)
+}
 
-	return n
+// BuildPkixName renders a name without its DER encoding, so attributes carry
+// no string types. See BuildName.
+func BuildPkixName(name string, pkixName pkix.Name) *node.Node {
+	return BuildName(name, pkixName, nil)
 }
 
 func BuildExtensions(extensions []pkix.Extension) *node.Node {
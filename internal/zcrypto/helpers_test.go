@@ -1,9 +1,12 @@
 package zcrypto
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509"
 	"github.com/zmap/zcrypto/x509/pkix"
 )
 
@@ -90,8 +93,9 @@ func TestBuildPkixName_PartialFields(t *testing.T) {
 
 	node := BuildPkixName("subject", name)
 
-	if len(node.Children) != 2 {
-		t.Errorf("expected 2 children, got %d", len(node.Children))
+	// Two shortcuts plus the attributes and rdns lists.
+	if len(node.Children) != 4 {
+		t.Errorf("expected 4 children, got %d", len(node.Children))
 	}
 
 	if _, ok := node.Children["commonName"]; !ok {
@@ -181,6 +185,32 @@ func TestBuildExtensions_WithExtensions(t *testing.T) {
 	}
 }
 
+func TestBuildExtensions_CRLExtensionNames(t *testing.T) {
+	names := map[string]asn1.ObjectIdentifier{
+		"cRLNumber":                {2, 5, 29, 20},
+		"deltaCRLIndicator":        {2, 5, 29, 27},
+		"issuingDistributionPoint": {2, 5, 29, 28},
+		"certificateIssuer":        {2, 5, 29, 29},
+	}
+
+	var extensions []pkix.Extension
+	for _, id := range names {
+		extensions = append(extensions, pkix.Extension{Id: id})
+	}
+	node := BuildExtensions(extensions)
+
+	for name, id := range names {
+		ext, ok := node.Children[name]
+		if !ok {
+			t.Errorf("expected friendly name %q", name)
+			continue
+		}
+		if ext != node.Children[id.String()] {
+			t.Errorf("%s and %s should point to same node", name, id)
+		}
+	}
+}
+
 func TestBuildExtensions_ExtensionStructure(t *testing.T) {
 	extensions := []pkix.Extension{
 		{
@@ -201,3 +231,15 @@ func TestBuildExtensions_ExtensionStructure(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildSignatureValid(t *testing.T) {
+	if n := BuildSignatureValid(nil); n == nil || n.Value != true {
+		t.Errorf("valid signature: got %v", n)
+	}
+	if n := BuildSignatureValid(errors.New("bad signature")); n == nil || n.Value != false {
+		t.Errorf("invalid signature: got %v", n)
+	}
+	if n := BuildSignatureValid(fmt.Errorf("verify: %w", x509.ErrUnsupportedAlgorithm)); n != nil {
+		t.Errorf("unsupported algorithm should be omitted, got %v", n)
+	}
+}
@@ -0,0 +1,303 @@
+package zcrypto
+
+import (
+	"encoding/asn1"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"unicode/utf16"
+
+	zasn1 "github.com/zmap/zcrypto/encoding/asn1"
+	"github.com/zmap/zcrypto/x509/pkix"
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+
+	"github.com/cavoq/PCL/internal/node"
+)
+
+// attributeNames maps DN attribute type OIDs to the names used as node keys.
+// Attributes not listed here are keyed by their OID.
+var attributeNames = map[string]string{
+	"2.5.4.3":                    "commonName",
+	"2.5.4.4":                    "surname",
+	"2.5.4.5":                    "serialNumber",
+	"2.5.4.6":                    "countryName",
+	"2.5.4.7":                    "localityName",
+	"2.5.4.8":                    "stateOrProvinceName",
+	"2.5.4.9":                    "streetAddress",
+	"2.5.4.10":                   "organizationName",
+	"2.5.4.11":                   "organizationalUnitName",
+	"2.5.4.12":                   "title",
+	"2.5.4.15":                   "businessCategory",
+	"2.5.4.17":                   "postalCode",
+	"2.5.4.18":                   "postOfficeBox",
+	"2.5.4.42":                   "givenName",
+	"2.5.4.43":                   "initials",
+	"2.5.4.44":                   "generationQualifier",
+	"2.5.4.46":                   "dnQualifier",
+	"2.5.4.65":                   "pseudonym",
+	"2.5.4.97":                   "organizationIdentifier",
+	"1.2.840.113549.1.9.1":       "emailAddress",
+	"0.9.2342.19200300.100.1.1":  "userId",
+	"0.9.2342.19200300.100.1.25": "domainComponent",
+	"1.3.6.1.4.1.311.60.2.1.1":   "jurisdictionLocalityName",
+	"1.3.6.1.4.1.311.60.2.1.2":   "jurisdictionStateOrProvinceName",
+	"1.3.6.1.4.1.311.60.2.1.3":   "jurisdictionCountryName",
+}
+
+// lastValueAttributes are the shortcuts that, like pkix.Name.CommonName and
+// pkix.Name.SerialNumber, hold the last value rather than the first.
+var lastValueAttributes = map[string]bool{
+	"commonName":   true,
+	"serialNumber": true,
+}
+
+// stringTypes names the ASN.1 universal tags a DirectoryString or other DN
+// attribute value may be encoded with.
+var stringTypes = map[int]string{
+	asn1.TagUTF8String:      "UTF8String",
+	asn1.TagNumericString:   "NumericString",
+	asn1.TagPrintableString: "PrintableString",
+	asn1.TagT61String:       "TeletexString",
+	asn1.TagIA5String:       "IA5String",
+	26:                      "VisibleString",
+	28:                      "UniversalString",
+	asn1.TagBMPString:       "BMPString",
+}
+
+// attribute is one AttributeTypeAndValue of a name together with the index
+// of the RDN it belongs to. tag is -1 when the encoding is unknown.
+type attribute struct {
+	oid   string
+	value any
+	tag   int
+	rdn   int
+}
+
+type rawAttribute struct {
+	Type  asn1.ObjectIdentifier
+	Value asn1.RawValue
+}
+
+// rawRDNSET decodes as a SET OF, as encoding/asn1 does for slice types whose
+// name ends in SET.
+type rawRDNSET []rawAttribute
+
+// BuildName renders a distinguished name. Every attribute is listed in RDN
+// order under attributes, and grouped per RDN under rdns, with its OID and,
+// when raw holds the DER encoding of the name, its ASN.1 string type. Each
+// attribute type also gets a shortcut keyed by its name (commonName,
+// organizationalUnitName, ...) holding the first value, or the last for
+// commonName and serialNumber, with every value as an indexed child:
+//
+//	subject.organizationalUnitName       first OU
+//	subject.organizationalUnitName.1     second OU
+//	subject.attributes.3.oid             "2.5.4.11"
+//	subject.attributes.3.stringType      "PrintableString"
+//	subject.rdns.0.1                     second attribute of a multi-valued RDN
+func BuildName(name string, pkixName pkix.Name, raw []byte) *node.Node {
+	n := node.New(name, nil)
+
+	attrs, ok := rawAttributes(raw)
+	if !ok {
+		attrs = rdnAttributes(pkixName.ToRDNSequence())
+	}
+	if len(attrs) == 0 {
+		return n
+	}
+
+	attrsNode := node.New("attributes", nil)
+	rdnsNode := node.New("rdns", nil)
+	byName := map[string][]*node.Node{}
+	var order []string
+
+	for i, a := range attrs {
+		attrName := attributeName(a.oid)
+		an := buildAttribute(fmt.Sprintf("%d", i), attrName, a)
+		attrsNode.Children[an.Name] = an
+
+		rdnKey := fmt.Sprintf("%d", a.rdn)
+		rdnNode, ok := rdnsNode.Children[rdnKey]
+		if !ok {
+			rdnNode = node.New(rdnKey, nil)
+			rdnsNode.Children[rdnKey] = rdnNode
+		}
+		rdnNode.Children[fmt.Sprintf("%d", len(rdnNode.Children))] = an
+
+		if _, seen := byName[attrName]; !seen {
+			order = append(order, attrName)
+		}
+		byName[attrName] = append(byName[attrName], an)
+	}
+
+	for _, attrName := range order {
+		n.Children[attrName] = buildShortcut(attrName, byName[attrName])
+	}
+	n.Children["attributes"] = attrsNode
+	n.Children["rdns"] = rdnsNode
+	return n
+}
+
+// BuildGeneralName renders the DER of a GeneralName (RFC 5280 4.2.1.6). A
+// directoryName is built like a certificate subject; other name forms carry
+// their string value. Name forms without a rendering leave the node empty.
+func BuildGeneralName(name string, raw []byte) *node.Node {
+	n := node.New(name, nil)
+
+	input := cryptobyte.String(raw)
+	var value cryptobyte.String
+	var tag cryptobyte_asn1.Tag
+	if !input.ReadAnyASN1(&value, &tag) {
+		return n
+	}
+
+	switch tag {
+	case cryptobyte_asn1.Tag(1).ContextSpecific():
+		n.Children["rfc822Name"] = node.New("rfc822Name", string(value))
+	case cryptobyte_asn1.Tag(2).ContextSpecific():
+		n.Children["dNSName"] = node.New("dNSName", string(value))
+	case cryptobyte_asn1.Tag(6).ContextSpecific():
+		n.Children["uniformResourceIdentifier"] = node.New("uniformResourceIdentifier", string(value))
+	case cryptobyte_asn1.Tag(7).ContextSpecific():
+		n.Children["iPAddress"] = node.New("iPAddress", net.IP(value).String())
+	case cryptobyte_asn1.Tag(8).ContextSpecific():
+		var id zasn1.ObjectIdentifier
+		if _, err := zasn1.UnmarshalWithParams(raw, &id, "tag:8"); err == nil {
+			n.Children["registeredID"] = node.New("registeredID", id.String())
+		}
+	case cryptobyte_asn1.Tag(4).Constructed().ContextSpecific():
+		var rdns pkix.RDNSequence
+		if _, err := zasn1.Unmarshal(value, &rdns); err == nil {
+			var pkixName pkix.Name
+			pkixName.FillFromRDNSequence(&rdns)
+			n.Children["directoryName"] = BuildName("directoryName", pkixName, value)
+		}
+	}
+
+	return n
+}
+
+func attributeName(oid string) string {
+	if name, ok := attributeNames[oid]; ok {
+		return name
+	}
+	return oid
+}
+
+func buildAttribute(key, attrName string, a attribute) *node.Node {
+	an := node.New(key, a.value)
+	an.Children["oid"] = node.New("oid", a.oid)
+	an.Children["type"] = node.New("type", attrName)
+	an.Children["rdn"] = node.New("rdn", a.rdn)
+	if a.tag >= 0 {
+		an.Children["encoding"] = node.New("encoding", a.tag)
+		if st, ok := stringTypes[a.tag]; ok {
+			an.Children["stringType"] = node.New("stringType", st)
+		}
+	}
+	return an
+}
+
+// BuildValue renders a single ASN.1 value the way BuildName renders DN
+// attribute values: strings as Go strings with their encoding and string
+// type, anything else as its raw bytes. It is used for attributes outside
+// names, such as the challengePassword of a certificate request.
+func BuildValue(key string, v asn1.RawValue) *node.Node {
+	n := node.New(key, decodeValue(v))
+	n.Children["encoding"] = node.New("encoding", v.Tag)
+	if st, ok := stringTypes[v.Tag]; ok && v.Class == asn1.ClassUniversal {
+		n.Children["stringType"] = node.New("stringType", st)
+	}
+	return n
+}
+
+// buildShortcut returns the node for one attribute type: the shortcut value
+// with the OID and encoding of that value, and every value indexed.
+func buildShortcut(attrName string, values []*node.Node) *node.Node {
+	first := values[0]
+	if lastValueAttributes[attrName] {
+		first = values[len(values)-1]
+	}
+
+	s := node.New(attrName, first.Value)
+	for _, k := range []string{"oid", "encoding", "stringType"} {
+		if c, ok := first.Children[k]; ok {
+			s.Children[k] = c
+		}
+	}
+	for i, v := range values {
+		s.Children[fmt.Sprintf("%d", i)] = v
+	}
+	return s
+}
+
+// rawAttributes decodes the attributes of a DER encoded Name, keeping the
+// string type of each value.
+func rawAttributes(raw []byte) ([]attribute, bool) {
+	if len(raw) == 0 {
+		return nil, false
+	}
+	var rdns []rawRDNSET
+	if rest, err := asn1.Unmarshal(raw, &rdns); err != nil || len(rest) > 0 {
+		return nil, false
+	}
+
+	var attrs []attribute
+	for i, rdn := range rdns {
+		for _, atv := range rdn {
+			attrs = append(attrs, attribute{
+				oid:   atv.Type.String(),
+				value: decodeValue(atv.Value),
+				tag:   atv.Value.Tag,
+				rdn:   i,
+			})
+		}
+	}
+	return attrs, true
+}
+
+func rdnAttributes(rdns pkix.RDNSequence) []attribute {
+	var attrs []attribute
+	for i, rdn := range rdns {
+		for _, atv := range rdn {
+			value := atv.Value
+			if _, ok := value.(string); !ok {
+				value = fmt.Sprint(value)
+			}
+			attrs = append(attrs, attribute{oid: atv.Type.String(), value: value, tag: -1, rdn: i})
+		}
+	}
+	return attrs
+}
+
+// decodeValue returns string values as Go strings and anything else as its
+// raw bytes.
+func decodeValue(v asn1.RawValue) any {
+	if v.Class != asn1.ClassUniversal {
+		return v.Bytes
+	}
+	switch v.Tag {
+	case asn1.TagBMPString:
+		if len(v.Bytes)%2 != 0 {
+			return v.Bytes
+		}
+		units := make([]uint16, len(v.Bytes)/2)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(v.Bytes[2*i:])
+		}
+		return string(utf16.Decode(units))
+	case 28: // UniversalString
+		if len(v.Bytes)%4 != 0 {
+			return v.Bytes
+		}
+		runes := make([]rune, len(v.Bytes)/4)
+		for i := range runes {
+			runes[i] = rune(binary.BigEndian.Uint32(v.Bytes[4*i:]))
+		}
+		return string(runes)
+	}
+	if _, ok := stringTypes[v.Tag]; ok {
+		return string(v.Bytes)
+	}
+	return v.Bytes
+}
@@ -0,0 +1,213 @@
+package zcrypto
+
+import (
+	stdpkix "crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/zmap/zcrypto/x509/pkix"
+
+	"github.com/cavoq/PCL/internal/node"
+)
+
+var (
+	oidCN = asn1.ObjectIdentifier{2, 5, 4, 3}
+	oidOU = asn1.ObjectIdentifier{2, 5, 4, 11}
+	oidDC = asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}
+	oidC  = asn1.ObjectIdentifier{2, 5, 4, 6}
+)
+
+func bmpString(s string) asn1.RawValue {
+	var b []byte
+	for _, r := range s {
+		b = append(b, byte(r>>8), byte(r))
+	}
+	return asn1.RawValue{Tag: asn1.TagBMPString, Bytes: b}
+}
+
+// testName encodes DC=com, DC=example, C=US, OU=Ops+OU=Web, CN=host (BMPString).
+func testName(t *testing.T) []byte {
+	t.Helper()
+	raw, err := asn1.Marshal(stdpkix.RDNSequence{
+		{{Type: oidDC, Value: asn1.RawValue{Tag: asn1.TagIA5String, Bytes: []byte("com")}}},
+		{{Type: oidDC, Value: asn1.RawValue{Tag: asn1.TagIA5String, Bytes: []byte("example")}}},
+		{{Type: oidC, Value: "US"}},
+		{{Type: oidOU, Value: "Ops"}, {Type: oidOU, Value: "Web"}},
+		{{Type: oidCN, Value: bmpString("host")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func resolve(t *testing.T, n *node.Node, path string) *node.Node {
+	t.Helper()
+	got, ok := n.Resolve(path)
+	if !ok {
+		t.Fatalf("%s not found", path)
+	}
+	return got
+}
+
+func TestBuildNameAttributes(t *testing.T) {
+	n := BuildName("subject", pkix.Name{}, testName(t))
+
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"attributes.0", "com"},
+		{"attributes.0.type", "domainComponent"},
+		{"attributes.0.stringType", "IA5String"},
+		{"attributes.1", "example"},
+		{"attributes.2.oid", "2.5.4.6"},
+		{"attributes.2.encoding", asn1.TagPrintableString},
+		{"attributes.3", "Ops"},
+		{"attributes.3.rdn", 3},
+		{"attributes.4", "Web"},
+		{"attributes.4.rdn", 3},
+		{"attributes.5", "host"},
+		{"attributes.5.stringType", "BMPString"},
+		{"rdns.3.0", "Ops"},
+		{"rdns.3.1", "Web"},
+		{"rdns.4.0", "host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := resolve(t, n, tt.path).Value; got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+	if got := len(resolve(t, n, "rdns").Children); got != 5 {
+		t.Errorf("got %d RDNs, want 5", got)
+	}
+}
+
+func TestBuildNameShortcuts(t *testing.T) {
+	n := BuildName("subject", pkix.Name{}, testName(t))
+
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"organizationalUnitName", "Ops"},
+		{"organizationalUnitName.0", "Ops"},
+		{"organizationalUnitName.1", "Web"},
+		{"organizationalUnitName.oid", "2.5.4.11"},
+		{"domainComponent", "com"},
+		{"domainComponent.1", "example"},
+		{"domainComponent.stringType", "IA5String"},
+		{"countryName", "US"},
+		{"commonName", "host"},
+		{"commonName.stringType", "BMPString"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := resolve(t, n, tt.path).Value; got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildNameLastCommonName(t *testing.T) {
+	raw, err := asn1.Marshal(stdpkix.RDNSequence{
+		{{Type: oidCN, Value: "first"}},
+		{{Type: oidCN, Value: "last"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := BuildName("subject", pkix.Name{}, raw)
+
+	// Like pkix.Name.CommonName, the shortcut keeps the last CN.
+	if got := resolve(t, n, "commonName").Value; got != "last" {
+		t.Errorf("commonName = %v, want last", got)
+	}
+	if got := resolve(t, n, "commonName.0").Value; got != "first" {
+		t.Errorf("commonName.0 = %v, want first", got)
+	}
+}
+
+func TestBuildNameWithoutRaw(t *testing.T) {
+	n := BuildName("subject", pkix.Name{
+		OrganizationalUnit: []string{"Ops", "Web"},
+		CommonName:         "host",
+	}, nil)
+
+	if got := resolve(t, n, "organizationalUnitName.1").Value; got != "Web" {
+		t.Errorf("organizationalUnitName.1 = %v, want Web", got)
+	}
+	if _, ok := resolve(t, n, "commonName").Children["stringType"]; ok {
+		t.Error("string type should be unknown without the DER encoding")
+	}
+}
+
+func TestBuildNameInvalidRaw(t *testing.T) {
+	n := BuildName("issuer", pkix.Name{CommonName: "fallback"}, []byte{0x30, 0x05})
+
+	if got := resolve(t, n, "commonName").Value; got != "fallback" {
+		t.Errorf("commonName = %v, want fallback from the parsed name", got)
+	}
+}
+
+func TestBuildValue(t *testing.T) {
+	n := BuildValue("challengePassword", bmpString("pässwort"))
+	if n.Value != "pässwort" {
+		t.Errorf("value = %v, want pässwort", n.Value)
+	}
+	if got := resolve(t, n, "stringType").Value; got != "BMPString" {
+		t.Errorf("stringType = %v, want BMPString", got)
+	}
+
+	raw := asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagOctetString, Bytes: []byte{1, 2}}
+	n = BuildValue("value", raw)
+	if _, ok := n.Value.([]byte); !ok {
+		t.Errorf("value = %T, want raw bytes", n.Value)
+	}
+	if _, ok := n.Children["stringType"]; ok {
+		t.Error("an OCTET STRING has no string type")
+	}
+}
+
+func TestBuildGeneralName(t *testing.T) {
+	generalName := func(tag int, compound bool, value []byte) []byte {
+		raw, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: tag, IsCompound: compound, Bytes: value})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+	oid, err := asn1.Marshal(asn1.ObjectIdentifier{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		raw  []byte
+		path string
+		want any
+	}{
+		{"rfc822Name", generalName(1, false, []byte("ca@example.com")), "rfc822Name", "ca@example.com"},
+		{"dNSName", generalName(2, false, []byte("example.com")), "dNSName", "example.com"},
+		{"uniformResourceIdentifier", generalName(6, false, []byte("http://crl/ca.crl")), "uniformResourceIdentifier", "http://crl/ca.crl"},
+		{"iPAddress", generalName(7, false, []byte{192, 0, 2, 1}), "iPAddress", "192.0.2.1"},
+		{"registeredID", generalName(8, false, oid[2:]), "registeredID", "1.2.3.4"},
+		{"directoryName", generalName(4, true, testName(t)), "directoryName.commonName", "host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := BuildGeneralName("name", tt.raw)
+			if got := resolve(t, n, tt.path).Value; got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	if n := BuildGeneralName("name", []byte{0x01}); len(n.Children) != 0 {
+		t.Errorf("malformed GeneralName should render empty, got %v", n.Children)
+	}
+}
@@ -0,0 +1,252 @@
+// Package pcl exposes the PCL linter as a Go library.
+//
+// It lints certificates, CRLs, OCSP responses, SCT lists, CSRs, RFC 3161
+// time-stamp tokens and RFC 5755 attribute certificates held in memory
+// against parsed policies and returns the same structured output the pcl
+// command produces, without reading files, fetching resources or writing to
+// stdout.
+package pcl
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cavoq/PCL/internal/attrcert"
+	"github.com/cavoq/PCL/internal/cert"
+	"github.com/cavoq/PCL/internal/crl"
+	"github.com/cavoq/PCL/internal/csr"
+	"github.com/cavoq/PCL/internal/linter"
+	"github.com/cavoq/PCL/internal/ocsp"
+	"github.com/cavoq/PCL/internal/operator"
+	"github.com/cavoq/PCL/internal/output"
+	"github.com/cavoq/PCL/internal/policy"
+	"github.com/cavoq/PCL/internal/rule"
+	"github.com/cavoq/PCL/internal/sct"
+	"github.com/cavoq/PCL/internal/source"
+	"github.com/cavoq/PCL/internal/trust"
+	"github.com/cavoq/PCL/internal/tst"
+)
+
+type (
+	Policy       = policy.Policy
+	PolicyResult = policy.Result
+	Rule         = rule.Rule
+	RuleResult   = rule.Result
+	LintOutput   = output.LintOutput
+	LintMeta     = output.LintMeta
+	Options      = output.Options
+)
+
+const (
+	VerdictPass = rule.VerdictPass
+	VerdictFail = rule.VerdictFail
+	VerdictSkip = rule.VerdictSkip
+)
+
+// Item is a single PEM or DER encoded input. Name identifies the item in
+// lint results and defaults to its position in the input list.
+type Item struct {
+	Name string
+	Data []byte
+}
+
+// Input holds the objects to lint. Certificates are assembled into a chain
+// together with Issuers; CRLs and OCSP responses are evaluated alongside
+// that chain, or on their own when no certificates are given. SCT lists
+// delivered via TLS or OCSP are likewise evaluated with the chain, whose
+// first certificate they are verified over. CSRs, time-stamp tokens and
+// attribute certificates are always evaluated on their own; a token's signer
+// certificate and an attribute certificate's issuing attribute authority may
+// be passed among the Issuers.
+type Input struct {
+	Certificates []Item
+	Issuers      []Item
+	CRLs         []Item
+	OCSPs        []Item
+	SCTs         []Item
+	CSRs         []Item
+	TSTs         []Item
+	AttrCerts    []Item
+
+	// FinalCertificates are the final certificates CT precertificates among
+	// the Certificates are compared with, matched by serial number.
+	FinalCertificates []Item
+
+	// At is the time date and validity checks are evaluated at. The zero
+	// value means the current time.
+	At time.Time
+
+	// TrustAnchors are the certificates chains must end at; each item may
+	// be a PEM bundle. SystemRoots adds the system CA bundle. With neither,
+	// the self-signed top of a chain is its trust anchor.
+	TrustAnchors []Item
+	SystemRoots  bool
+
+	// AllPaths lints every valid certification path through the
+	// certificates and issuers; results carry the path number. By default
+	// only the best ranked path is linted.
+	AllPaths bool
+}
+
+// Linter evaluates inputs against a fixed set of policies. It is safe to
+// reuse a Linter for many inputs.
+type Linter struct {
+	policies []Policy
+	registry *operator.Registry
+}
+
+// New returns a Linter for the given policies using the default operators.
+func New(policies ...Policy) *Linter {
+	return &Linter{
+		policies: policies,
+		registry: operator.DefaultRegistry(),
+	}
+}
+
+// Lint parses the input and evaluates it against the linter's policies.
+func (l *Linter) Lint(in Input) (LintOutput, error) {
+	certs, err := parseItems(in.Certificates, "certificate", cert.NewInfo)
+	if err != nil {
+		return LintOutput{}, err
+	}
+	issuers, err := parseItems(in.Issuers, "issuer", cert.NewInfo)
+	if err != nil {
+		return LintOutput{}, err
+	}
+	crls, err := parseItems(in.CRLs, "crl", crl.NewInfo)
+	if err != nil {
+		return LintOutput{}, err
+	}
+	ocsps, err := parseItems(in.OCSPs, "ocsp", ocsp.NewInfo)
+	if err != nil {
+		return LintOutput{}, err
+	}
+	scts, err := parseItems(in.SCTs, "sct", sct.NewInfo)
+	if err != nil {
+		return LintOutput{}, err
+	}
+	finals, err := parseItems(in.FinalCertificates, "final", cert.NewInfo)
+	if err != nil {
+		return LintOutput{}, err
+	}
+
+	csrs, err := parseItems(in.CSRs, "csr", csr.NewInfo)
+	if err != nil {
+		return LintOutput{}, err
+	}
+	tsts, err := parseItems(in.TSTs, "tst", tst.NewInfo)
+	if err != nil {
+		return LintOutput{}, err
+	}
+	attrCerts, err := parseItems(in.AttrCerts, "attrcert", attrcert.NewInfo)
+	if err != nil {
+		return LintOutput{}, err
+	}
+
+	store, err := trustStore(in)
+	if err != nil {
+		return LintOutput{}, err
+	}
+
+	results, err := linter.Evaluate(l.policies, l.registry, linter.Inputs{
+		Certs:    certs,
+		Issuers:  issuers,
+		CRLs:     crls,
+		OCSPs:    ocsps,
+		SCTs:     scts,
+		CSRs:     csrs,
+		TSTs:     tsts,
+		At:       in.At,
+		Trust:    store,
+		AllPaths: in.AllPaths,
+
+		FinalCerts: finals,
+		AttrCerts:  attrCerts,
+	})
+	if err != nil {
+		return LintOutput{}, err
+	}
+	return output.FromPolicyResults(results), nil
+}
+
+// LintCertificate is a convenience wrapper that lints a single certificate
+// together with its issuers.
+func (l *Linter) LintCertificate(certificate []byte, issuers ...[]byte) (LintOutput, error) {
+	in := Input{Certificates: []Item{{Data: certificate}}}
+	for _, issuer := range issuers {
+		in.Issuers = append(in.Issuers, Item{Data: issuer})
+	}
+	return l.Lint(in)
+}
+
+// ParsePolicy parses a policy from YAML. Includes are not resolved.
+func ParsePolicy(data []byte) (Policy, error) {
+	return policy.Parse(data)
+}
+
+// ParsePolicyFile parses a policy file and resolves its includes.
+func ParsePolicyFile(path string) (Policy, error) {
+	return policy.ParseFile(path)
+}
+
+// ParsePolicyDir parses every policy file in a directory.
+func ParsePolicyDir(dir string) ([]Policy, error) {
+	return policy.ParseDir(dir)
+}
+
+// Failed reports whether any policy result has a failing verdict.
+func Failed(out LintOutput) bool {
+	for _, pr := range out.Results {
+		if pr.Verdict == VerdictFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Format writes out in the named format (text, json or yaml) after applying
+// the visibility options.
+func Format(w io.Writer, out LintOutput, format string, opts Options) error {
+	out = output.FilterRules(out, opts)
+	return output.GetFormatter(format, opts).Format(w, out)
+}
+
+func trustStore(in Input) (*trust.Store, error) {
+	if len(in.TrustAnchors) == 0 && !in.SystemRoots {
+		return nil, nil
+	}
+
+	anchors, err := parseItems(in.TrustAnchors, "trust anchor", cert.NewInfos)
+	if err != nil {
+		return nil, err
+	}
+	store := trust.NewStore()
+	for _, bundle := range anchors {
+		for _, a := range bundle {
+			store.Add(a.Cert, trust.SourceTrustAnchors)
+		}
+	}
+	if in.SystemRoots {
+		if err := store.AddSystemRoots(); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+func parseItems[T any](items []Item, kind string, parse func([]byte, string, source.Info) (T, error)) ([]T, error) {
+	parsed := make([]T, 0, len(items))
+	for i, item := range items {
+		name := item.Name
+		if name == "" {
+			name = fmt.Sprintf("%s[%d]", kind, i)
+		}
+		v, err := parse(item.Data, name, source.Info{Type: source.Memory})
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		parsed = append(parsed, v)
+	}
+	return parsed, nil
+}
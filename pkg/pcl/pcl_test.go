@@ -0,0 +1,422 @@
+package pcl
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPolicy = `
+id: library-test
+version: 1.0
+rules:
+  - id: version-v3
+    target: certificate.version
+    operator: eq
+    operands: [3]
+    severity: error
+  - id: leaf-not-ca
+    target: certificate.basicConstraints.cA
+    operator: eq
+    operands: [true]
+    certType: [leaf]
+    severity: error
+`
+
+func readTestCert(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "internal", "cert", "testdata", name))
+	if err != nil {
+		t.Fatalf("reading %s: %v", name, err)
+	}
+	return data
+}
+
+func TestLintCertificate(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+
+	out, err := New(p).LintCertificate(readTestCert(t, "leaf.pem"), readTestCert(t, "intermediate.pem"))
+	if err != nil {
+		t.Fatalf("LintCertificate: %v", err)
+	}
+
+	if out.Meta.TotalCerts != 2 {
+		t.Fatalf("TotalCerts = %d, want 2", out.Meta.TotalCerts)
+	}
+	if !Failed(out) {
+		t.Fatal("expected leaf-not-ca to fail the leaf")
+	}
+	if out.Results[0].CertType != "leaf" || out.Results[0].Verdict != VerdictFail {
+		t.Errorf("leaf result = %s/%s, want leaf/fail", out.Results[0].CertType, out.Results[0].Verdict)
+	}
+	if out.Results[0].Source != "memory" {
+		t.Errorf("Source = %q, want memory", out.Results[0].Source)
+	}
+}
+
+func TestLintNamesItems(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+
+	out, err := New(p).Lint(Input{
+		Certificates: []Item{{Name: "to-be-signed", Data: readTestCert(t, "leaf.pem")}},
+	})
+	if err != nil {
+		t.Fatalf("Lint: %v", err)
+	}
+	if out.Results[0].CertPath != "to-be-signed" {
+		t.Errorf("CertPath = %q, want to-be-signed", out.Results[0].CertPath)
+	}
+}
+
+func TestLintAt(t *testing.T) {
+	p, err := ParsePolicy([]byte(`
+id: library-at
+version: 1.0
+rules:
+  - id: not-expired
+    target: certificate.validity.notAfter
+    operator: after
+    severity: error
+`))
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+
+	at := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	out, err := New(p).Lint(Input{
+		Certificates: []Item{{Data: readTestCert(t, "leaf.pem")}},
+		At:           at,
+	})
+	if err != nil {
+		t.Fatalf("Lint: %v", err)
+	}
+	if !Failed(out) {
+		t.Error("expected not-expired to fail in 2100")
+	}
+	if !out.Meta.CheckedAt.Equal(at) {
+		t.Errorf("CheckedAt = %v, want %v", out.Meta.CheckedAt, at)
+	}
+}
+
+func TestLintTrustAnchors(t *testing.T) {
+	p, err := ParsePolicy([]byte(`
+id: library-trust
+version: 1.0
+rules:
+  - id: chains-to-anchor
+    target: certificate
+    operator: chainsToTrustAnchor
+    certType: [leaf]
+    severity: error
+`))
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+
+	lint := func(anchor string) LintOutput {
+		t.Helper()
+		out, err := New(p).Lint(Input{
+			Certificates: []Item{{Data: readTestCert(t, "leaf.pem")}},
+			Issuers:      []Item{{Data: readTestCert(t, "intermediate.pem")}},
+			TrustAnchors: []Item{{Data: readTestCert(t, anchor)}},
+		})
+		if err != nil {
+			t.Fatalf("Lint: %v", err)
+		}
+		return out
+	}
+
+	if out := lint("intermediate.pem"); Failed(out) {
+		t.Error("leaf should chain to the intermediate trust anchor")
+	}
+	if out := lint("nc_ca.pem"); !Failed(out) {
+		t.Error("leaf should not chain to an unrelated trust anchor")
+	}
+}
+
+// issue returns a PEM certificate for key named cn, signed by parent with
+// parentKey, or self-signed if parent is nil.
+func issue(t *testing.T, cn string, key *ecdsa.PrivateKey, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, []byte) {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil || cn != "leaf",
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestLintAllPaths(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+
+	key := func() *ecdsa.PrivateKey {
+		k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	rootAKey, rootBKey, interKey := key(), key(), key()
+	rootA, rootAPEM := issue(t, "Root A", rootAKey, nil, nil)
+	rootB, rootBPEM := issue(t, "Root B", rootBKey, nil, nil)
+	inter, interAPEM := issue(t, "Intermediate", interKey, rootA, rootAKey)
+	_, interBPEM := issue(t, "Intermediate", interKey, rootB, rootBKey)
+	_, leafPEM := issue(t, "leaf", key(), inter, interKey)
+
+	in := Input{
+		Certificates: []Item{{Data: leafPEM}},
+		Issuers:      []Item{{Data: interAPEM}, {Data: interBPEM}, {Data: rootAPEM}, {Data: rootBPEM}},
+	}
+
+	out, err := New(p).Lint(in)
+	if err != nil {
+		t.Fatalf("Lint: %v", err)
+	}
+	if out.Meta.TotalCerts != 3 {
+		t.Errorf("TotalCerts = %d, want 3 for the best path only", out.Meta.TotalCerts)
+	}
+
+	in.AllPaths = true
+	out, err = New(p).Lint(in)
+	if err != nil {
+		t.Fatalf("Lint: %v", err)
+	}
+	leafPaths := map[int]bool{}
+	for _, r := range out.Results {
+		if r.CertType == "leaf" {
+			leafPaths[r.Path] = true
+		}
+	}
+	if !leafPaths[1] || !leafPaths[2] || len(leafPaths) != 2 {
+		t.Errorf("leaf linted on paths %v, want 1 and 2", leafPaths)
+	}
+}
+
+func TestLintInvalidInput(t *testing.T) {
+	_, err := New().Lint(Input{Certificates: []Item{{Data: []byte("not a cert")}}})
+	if err == nil {
+		t.Fatal("expected parse error")
+	}
+	if !strings.Contains(err.Error(), "certificate[0]") {
+		t.Errorf("error %q does not name the item", err)
+	}
+}
+
+func TestLintEmptyInput(t *testing.T) {
+	if _, err := New().Lint(Input{}); err == nil {
+		t.Fatal("expected error for empty input")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+	out, err := New(p).LintCertificate(readTestCert(t, "leaf.pem"))
+	if err != nil {
+		t.Fatalf("LintCertificate: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Format(&buf, out, "json", Options{ShowFailed: true}); err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if !strings.Contains(buf.String(), "leaf-not-ca") {
+		t.Errorf("output missing failed rule:\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), "version-v3") {
+		t.Errorf("output should hide passed rules:\n%s", buf.String())
+	}
+}
+
+// readTestData reads a test file by its path from the repository root.
+func readTestData(t *testing.T, path ...string) []byte {
+	t.Helper()
+	name := filepath.Join(path...)
+	data, err := os.ReadFile(filepath.Join("..", "..", name))
+	if err != nil {
+		t.Fatalf("reading %s: %v", name, err)
+	}
+	return data
+}
+
+// TestLintInputKinds lints each input kind once with a conforming and once
+// with a non-conforming file against a policy for that kind.
+func TestLintInputKinds(t *testing.T) {
+	const csrPolicy = `
+id: library-csr
+rules:
+  - id: csr-cn-hostname
+    target: csr.subject.commonName
+    operator: regex
+    operands: ["^[a-z.]+$"]
+    severity: error
+`
+	const tstPolicy = `
+id: library-tst
+tstType: [response]
+rules:
+  - id: tst-signature-valid
+    target: tst.signatureValid
+    operator: eq
+    operands: [true]
+    severity: error
+  - id: tst-nonce
+    target: tst.nonce
+    operator: present
+    severity: error
+`
+	const attrCertPolicy = `
+id: library-attrcert
+rules:
+  - id: attrcert-signature-valid
+    target: attrCert.signatureValid
+    operator: eq
+    operands: [true]
+    severity: error
+`
+	const sctPolicy = `
+id: library-sct
+rules:
+  - id: sct-tls-delivery
+    target: sct.delivery
+    operator: eq
+    operands: [tls]
+    severity: error
+`
+	const precertPolicy = `
+id: library-precert
+certType: [precertificate]
+rules:
+  - id: precert-tbs-matches
+    target: certificate.precertificate.finalCertificate.tbsMatches
+    operator: eq
+    operands: [true]
+    severity: error
+  - id: leaf-rule-applies
+    target: certificate.isPrecertificate
+    operator: eq
+    operands: [true]
+    severity: error
+    certType: [leaf]
+`
+	tsa := []Item{{Data: readTestData(t, "tests", "certs", "tsa.pem")}}
+	aa := []Item{{Data: readTestData(t, "tests", "certs", "aa.pem")}}
+	precert := func(name string) Input {
+		return Input{
+			Certificates:      []Item{{Data: readTestData(t, "tests", "certs", name)}},
+			Issuers:           []Item{{Data: readTestData(t, "tests", "certs", "ct-root.pem")}},
+			FinalCertificates: []Item{{Data: readTestData(t, "tests", "certs", "ct-final.pem")}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		policy   string
+		input    Input
+		certType string
+		certPath string
+		verdict  string
+		passed   int
+	}{
+		{"csr", csrPolicy, Input{CSRs: []Item{{Data: readTestData(t, "tests", "csrs", "csr.pem")}}}, "csr", "csr[0]", VerdictPass, 1},
+		{"csr bad", csrPolicy, Input{CSRs: []Item{{Data: readTestData(t, "tests", "csrs", "csr-bad.pem")}}}, "csr", "csr[0]", VerdictFail, 0},
+		{"tst", tstPolicy, Input{TSTs: []Item{{Data: readTestData(t, "tests", "tsts", "tst.tsr")}}, Issuers: tsa}, "tst", "tst[0]", VerdictPass, 2},
+		{"tst bad", tstPolicy, Input{TSTs: []Item{{Data: readTestData(t, "tests", "tsts", "tst-bad.tsr")}}, Issuers: tsa}, "tst", "tst[0]", VerdictFail, 1},
+		{"attribute certificate", attrCertPolicy, Input{AttrCerts: []Item{{Data: readTestData(t, "tests", "attrcerts", "attrcert.pem")}}, Issuers: aa}, "attrCert", "attrcert[0]", VerdictPass, 1},
+		{"attribute certificate bad", attrCertPolicy, Input{AttrCerts: []Item{{Data: readTestData(t, "tests", "attrcerts", "attrcert-bad.pem")}}, Issuers: aa}, "attrCert", "attrcert[0]", VerdictFail, 0},
+		{"sct list", sctPolicy, Input{SCTs: []Item{{Data: readTestData(t, "tests", "scts", "ct-tls-leaf.sct")}}}, "sct", "sct[0]", VerdictPass, 1},
+		{"sct list bad", sctPolicy, Input{SCTs: []Item{{Data: readTestData(t, "tests", "scts", "ct-tls-leaf-ocsp.der")}}}, "sct", "sct[0]", VerdictFail, 0},
+		{"precertificate", precertPolicy, precert("ct-precert.pem"), "leaf", "certificate[0]", VerdictPass, 2},
+		{"precertificate bad", precertPolicy, precert("ct-precert-bad.pem"), "leaf", "certificate[0]", VerdictFail, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParsePolicy([]byte(tt.policy))
+			if err != nil {
+				t.Fatalf("ParsePolicy: %v", err)
+			}
+			out, err := New(p).Lint(tt.input)
+			if err != nil {
+				t.Fatalf("Lint: %v", err)
+			}
+			if len(out.Results) != 1 {
+				t.Fatalf("got %d results, want 1", len(out.Results))
+			}
+			r := out.Results[0]
+			if r.CertType != tt.certType || r.CertPath != tt.certPath || r.Verdict != tt.verdict || r.Counts.Passed != tt.passed {
+				t.Errorf("result = %s %s %s passed %d, want %s %s %s passed %d",
+					r.CertType, r.CertPath, r.Verdict, r.Counts.Passed, tt.certType, tt.certPath, tt.verdict, tt.passed)
+			}
+		})
+	}
+}
+
+func TestLintSCTWithCRL(t *testing.T) {
+	p, err := ParsePolicy([]byte(`
+id: library-sct
+version: 1.0
+sctType: [tls]
+rules:
+  - id: sct-count
+    target: sct.count
+    operator: gte
+    operands: [1]
+    severity: error
+`))
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+	out, err := New(p).Lint(Input{
+		SCTs: []Item{{Data: readTestData(t, "tests", "scts", "ct-tls-leaf.sct")}},
+		CRLs: []Item{{Data: readTestData(t, "internal", "crl", "testdata", "test.crl")}},
+	})
+	if err != nil {
+		t.Fatalf("Lint: %v", err)
+	}
+	var sctResults int
+	for _, r := range out.Results {
+		if r.CertType == "sct" {
+			sctResults++
+		}
+	}
+	if sctResults != 1 {
+		t.Errorf("got %d SCT results alongside a CRL, want 1", sctResults)
+	}
+}
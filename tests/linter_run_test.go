@@ -3,15 +3,19 @@ package tests
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/cavoq/PCL/internal/data"
 	"github.com/cavoq/PCL/internal/linter"
 	"github.com/cavoq/PCL/internal/output"
+	"github.com/cavoq/PCL/internal/policy"
 )
 
 func TestLinterRunCases(t *testing.T) {
@@ -39,11 +43,22 @@ type linterCase struct {
 	Policy        string         `yaml:"policy"`
 	Certs         string         `yaml:"certs,omitempty"`
 	Issuers       []string       `yaml:"issuers,omitempty"`
+	TrustAnchors  []string       `yaml:"trust_anchors,omitempty"`
+	Batch         bool           `yaml:"batch,omitempty"`
+	At            string         `yaml:"at,omitempty"`
 	CRL           string         `yaml:"crl,omitempty"`
 	OCSP          string         `yaml:"ocsp,omitempty"`
+	CSR           string         `yaml:"csr,omitempty"`
+	TST           string         `yaml:"tst,omitempty"`
+	SCT           string         `yaml:"sct,omitempty"`
+	AttrCert      string         `yaml:"attr_cert,omitempty"`
+	FinalCert     string         `yaml:"final_cert,omitempty"`
+	CTLogList     string         `yaml:"ct_log_list,omitempty"`
 	Output        string         `yaml:"output,omitempty"`
 	Verbosity     int            `yaml:"verbosity,omitempty"`
 	ShowMeta      bool           `yaml:"show_meta,omitempty"`
+	FailOn        string         `yaml:"fail_on,omitempty"`
+	ExitCode      int            `yaml:"exit_code,omitempty"`
 	WantError     bool           `yaml:"want_error,omitempty"`
 	ErrorContains string         `yaml:"error_contains,omitempty"`
 	Contains      []string       `yaml:"contains,omitempty"`
@@ -56,6 +71,7 @@ type linterExpected struct {
 	Pass       int                    `yaml:"pass"`
 	Fail       int                    `yaml:"fail"`
 	Skip       int                    `yaml:"skip"`
+	CheckedAt  string                 `yaml:"checked_at,omitempty"`
 	Results    []linterExpectedResult `yaml:"results"`
 }
 
@@ -90,6 +106,15 @@ func runLinterCase(t *testing.T, caseDir string, tc linterCase) {
 		OutputFmt:   tc.Output,
 		Verbosity:   tc.Verbosity,
 		ShowMeta:    tc.ShowMeta,
+		FailOn:      tc.FailOn,
+		Batch:       tc.Batch,
+	}
+	if tc.At != "" {
+		at, err := time.Parse(time.RFC3339, tc.At)
+		if err != nil {
+			t.Fatalf("invalid at %q: %v", tc.At, err)
+		}
+		cfg.At = at
 	}
 	if tc.Certs != "" {
 		cfg.CertPath = filepath.Join(testsDir, tc.Certs)
@@ -97,12 +122,38 @@ func runLinterCase(t *testing.T, caseDir string, tc linterCase) {
 	for _, issuer := range tc.Issuers {
 		cfg.IssuerPaths = append(cfg.IssuerPaths, filepath.Join(testsDir, issuer))
 	}
+	for _, anchor := range tc.TrustAnchors {
+		cfg.TrustAnchorPaths = append(cfg.TrustAnchorPaths, filepath.Join(testsDir, anchor))
+	}
 	if tc.CRL != "" {
 		cfg.CRLPath = filepath.Join(testsDir, tc.CRL)
 	}
 	if tc.OCSP != "" {
 		cfg.OCSPPath = filepath.Join(testsDir, tc.OCSP)
 	}
+	if tc.CSR != "" {
+		cfg.CSRPath = filepath.Join(testsDir, tc.CSR)
+	}
+	if tc.TST != "" {
+		cfg.TSTPath = filepath.Join(testsDir, tc.TST)
+	}
+	if tc.AttrCert != "" {
+		cfg.AttrCertPath = filepath.Join(testsDir, tc.AttrCert)
+	}
+	if tc.SCT != "" {
+		cfg.SCTPath = filepath.Join(testsDir, tc.SCT)
+	}
+	if tc.FinalCert != "" {
+		cfg.FinalCertPath = filepath.Join(testsDir, tc.FinalCert)
+	}
+	if tc.CTLogList != "" {
+		saved := data.DefaultLoader
+		data.DefaultLoader = &data.Loader{}
+		t.Cleanup(func() { data.DefaultLoader = saved })
+		if err := data.DefaultLoader.LoadCTLogs(filepath.Join(testsDir, tc.CTLogList)); err != nil {
+			t.Fatalf("LoadCTLogs: %v", err)
+		}
+	}
 
 	var buf bytes.Buffer
 	err := linter.Run(cfg, &buf)
@@ -115,9 +166,16 @@ func runLinterCase(t *testing.T, caseDir string, tc linterCase) {
 		}
 		return
 	}
-	if err != nil {
+	exitCode := linter.ExitOK
+	var verdictErr *linter.VerdictError
+	if errors.As(err, &verdictErr) {
+		exitCode = verdictErr.Code
+	} else if err != nil {
 		t.Fatalf("Run returned error: %v", err)
 	}
+	if exitCode != tc.ExitCode {
+		t.Fatalf("exit code = %d, want %d", exitCode, tc.ExitCode)
+	}
 
 	for _, want := range tc.Contains {
 		if !strings.Contains(buf.String(), want) {
@@ -153,22 +211,25 @@ func assertLinterJSONOutput(t *testing.T, data []byte, want linterExpected) {
 	if got.Meta.SkippedRules != want.Skip {
 		t.Fatalf("SkippedRules = %d, want %d", got.Meta.SkippedRules, want.Skip)
 	}
+	if want.CheckedAt != "" && got.Meta.CheckedAt.Format(time.RFC3339) != want.CheckedAt {
+		t.Fatalf("CheckedAt = %s, want %s", got.Meta.CheckedAt.Format(time.RFC3339), want.CheckedAt)
+	}
 	if len(got.Results) != len(want.Results) {
 		t.Fatalf("got %d results, want %d", len(got.Results), len(want.Results))
 	}
 
-	expectedByType := make(map[string]linterExpectedResult, len(want.Results))
+	// A certificate gets one result per applicable policy, so results are
+	// matched on cert type and policy together.
+	type resultKey struct{ certType, policy string }
+	expectedByKey := make(map[resultKey]linterExpectedResult, len(want.Results))
 	for _, expected := range want.Results {
-		expectedByType[expected.CertType] = expected
+		expectedByKey[resultKey{expected.CertType, expected.Policy}] = expected
 	}
 
 	for _, result := range got.Results {
-		expected, ok := expectedByType[result.CertType]
+		expected, ok := expectedByKey[resultKey{result.CertType, result.PolicyID}]
 		if !ok {
-			t.Fatalf("unexpected result for cert type %q", result.CertType)
-		}
-		if result.PolicyID != expected.Policy {
-			t.Fatalf("cert %s PolicyID = %q, want %q", result.CertType, result.PolicyID, expected.Policy)
+			t.Fatalf("unexpected result for cert type %q and policy %q", result.CertType, result.PolicyID)
 		}
 		if result.Verdict != expected.Verdict {
 			t.Fatalf("cert %s Verdict = %q, want %q", result.CertType, result.Verdict, expected.Verdict)
@@ -178,3 +239,72 @@ func assertLinterJSONOutput(t *testing.T, data []byte, want linterExpected) {
 		}
 	}
 }
+
+func TestLinterJobsDeterministicOrder(t *testing.T) {
+	pslFile := filepath.Join(t.TempDir(), "public_suffix_list.dat")
+	psl := "// ===BEGIN ICANN DOMAINS===\ntest\n// ===END ICANN DOMAINS===\n"
+	if err := os.WriteFile(pslFile, []byte(psl), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	saved := data.DefaultLoader
+	data.DefaultLoader = &data.Loader{}
+	t.Cleanup(func() { data.DefaultLoader = saved })
+	if err := data.DefaultLoader.LoadPSL(pslFile); err != nil {
+		t.Fatalf("LoadPSL: %v", err)
+	}
+
+	run := func(jobs int) []policy.Result {
+		cfg := linter.Config{
+			PolicyPaths: []string{"policies/basic.yaml", "policies/psl-san.yaml"},
+			CertPath:    "certs-batch",
+			IssuerPaths: []string{"certs/intermediate.pem", "certs/root.pem", "certs/nc-intermediate.pem", "certs/nc-root.pem"},
+			Batch:       true,
+			OutputFmt:   "json",
+			Verbosity:   2,
+			ShowMeta:    true,
+			Jobs:        jobs,
+		}
+		var buf bytes.Buffer
+		if err := linter.Run(cfg, &buf); err != nil {
+			t.Fatalf("Run(jobs=%d): %v", jobs, err)
+		}
+		var got output.LintOutput
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("decoding output: %v", err)
+		}
+		return got.Results
+	}
+
+	sequential := run(1)
+	for range 5 {
+		parallel := run(8)
+		if len(parallel) != len(sequential) {
+			t.Fatalf("got %d results with 8 jobs, want %d", len(parallel), len(sequential))
+		}
+		for i := range sequential {
+			s, p := sequential[i], parallel[i]
+			if s.CertPath != p.CertPath || s.PolicyID != p.PolicyID || s.Verdict != p.Verdict {
+				t.Fatalf("result %d: got %s/%s/%s with 8 jobs, want %s/%s/%s",
+					i, p.CertPath, p.PolicyID, p.Verdict, s.CertPath, s.PolicyID, s.Verdict)
+			}
+			for j := range s.Results {
+				if s.Results[j].RuleID != p.Results[j].RuleID || s.Results[j].Verdict != p.Results[j].Verdict {
+					t.Fatalf("result %d rule %d: got %s=%s with 8 jobs, want %s=%s",
+						i, j, p.Results[j].RuleID, p.Results[j].Verdict, s.Results[j].RuleID, s.Results[j].Verdict)
+				}
+			}
+		}
+	}
+
+	var pslPasses int
+	for _, r := range sequential {
+		for _, rr := range r.Results {
+			if strings.HasPrefix(rr.RuleID, "leaf-san-") && rr.Verdict == "pass" {
+				pslPasses++
+			}
+		}
+	}
+	if pslPasses != 6 {
+		t.Errorf("got %d passing PSL rules, want 6", pslPasses)
+	}
+}